@@ -0,0 +1,121 @@
+package api
+
+import (
+	"net"
+	"strings"
+
+	"github.com/cad/ovpm"
+	"github.com/sirupsen/logrus"
+	gcontext "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// auditedMethods maps the methods that make administrative changes to the
+// kind of their targets.
+var auditedMethods = map[string]string{
+	"/pb.UserService/Create":      ovpm.AuditTargetUser,
+	"/pb.UserService/Update":      ovpm.AuditTargetUser,
+	"/pb.UserService/Delete":      ovpm.AuditTargetUser,
+	"/pb.UserService/Renew":       ovpm.AuditTargetUser,
+	"/pb.UserService/Disconnect":  ovpm.AuditTargetUser,
+	"/pb.UserService/SignCSR":     ovpm.AuditTargetUser,
+	"/pb.UserService/EnrollTOTP":  ovpm.AuditTargetUser,
+	"/pb.UserService/ConfirmTOTP": ovpm.AuditTargetUser,
+	"/pb.UserService/ResetTOTP":   ovpm.AuditTargetUser,
+	"/pb.OIDCService/Link":        ovpm.AuditTargetUser,
+
+	"/pb.VPNService/Init":              ovpm.AuditTargetVPN,
+	"/pb.VPNService/Update":            ovpm.AuditTargetVPN,
+	"/pb.VPNService/Restart":           ovpm.AuditTargetVPN,
+	"/pb.VPNService/RotateCA":          ovpm.AuditTargetVPN,
+	"/pb.VPNService/RotateTLSCryptKey": ovpm.AuditTargetVPN,
+	"/pb.VPNService/TakeCAOffline":     ovpm.AuditTargetVPN,
+
+	"/pb.NetworkService/Create":     ovpm.AuditTargetNetwork,
+	"/pb.NetworkService/Delete":     ovpm.AuditTargetNetwork,
+	"/pb.NetworkService/Associate":  ovpm.AuditTargetNetwork,
+	"/pb.NetworkService/Dissociate": ovpm.AuditTargetNetwork,
+
+	"/pb.LDAPService/SetConfig":     ovpm.AuditTargetLDAP,
+	"/pb.LDAPService/Disable":       ovpm.AuditTargetLDAP,
+	"/pb.LDAPService/Sync":          ovpm.AuditTargetAll,
+	"/pb.OIDCService/SetConfig":     ovpm.AuditTargetOIDC,
+	"/pb.OIDCService/Disable":       ovpm.AuditTargetOIDC,
+	"/pb.AuthService/RevokeSession": ovpm.AuditTargetSession,
+}
+
+// AuditUnaryInterceptor records the administrative changes in the audit log.
+//
+// It should be chained after AuthUnaryInterceptor, so that the caller is
+// known.
+func AuditUnaryInterceptor(ctx gcontext.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	targetType, ok := auditedMethods[info.FullMethod]
+	if !ok {
+		return handler(ctx, req)
+	}
+
+	actor, _ := GetUsernameFromContext(ctx)
+	var target string
+	switch r := req.(type) {
+	case interface{ GetName() string }: // Network requests also have a username.
+		target = r.GetName()
+	case interface{ GetUsername() string }:
+		target = r.GetUsername()
+	}
+	switch targetType {
+	case ovpm.AuditTargetVPN:
+		target = "server"
+	case ovpm.AuditTargetLDAP, ovpm.AuditTargetOIDC:
+		target = "config"
+	case ovpm.AuditTargetSession:
+		if target == "" {
+			// Caller's own session is revoked.
+			target = actor
+		}
+	}
+
+	before := ovpm.AuditSnapshot(targetType, target)
+	resp, err = handler(ctx, req)
+	after := ovpm.AuditSnapshot(targetType, target)
+
+	origin, sourceIP := originFromContext(ctx)
+	entry := &ovpm.AuditEntry{
+		Actor:      actor,
+		Origin:     origin,
+		SourceIP:   sourceIP,
+		Action:     strings.TrimPrefix(info.FullMethod, "/pb."),
+		TargetType: targetType,
+		Target:     target,
+		Changes:    ovpm.AuditDiff(before, after),
+		Result:     ovpm.AuditResultOK,
+	}
+	if err != nil {
+		entry.Result = status.Convert(err).Message()
+	}
+	if auditErr := ovpm.RecordAudit(entry); auditErr != nil {
+		logrus.Errorf("audit entry can not be recorded: %v", auditErr)
+	}
+	return resp, err
+}
+
+// originFromContext returns where the request came from and the IP address
+// of the client.
+//
+// Requests that are proxied by the REST gateway carry the address of the
+// HTTP client in the x-forwarded-for metadata.
+func originFromContext(ctx gcontext.Context) (string, string) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md["x-forwarded-for"]) > 0 {
+		return "rest", strings.TrimSpace(strings.Split(md["x-forwarded-for"][0], ",")[0])
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		return "grpc", host
+	}
+	return "grpc", ""
+}
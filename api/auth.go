@@ -61,3 +61,18 @@ func authzTokenFromContext(ctx gcontext.Context) (string, error) {
 	token = strings.TrimSpace(token)
 	return token, nil
 }
+
+// userAgentFromContext returns the user agent of the client. The REST gateway
+// forwards the user agent of the HTTP client.
+func userAgentFromContext(ctx gcontext.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	for _, key := range []string{"grpcgateway-user-agent", "user-agent"} {
+		if values := md[key]; len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cad/ovpm"
+	gcontext "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// healthWatchInterval is how often the readiness is checked for the health
+// watchers.
+const healthWatchInterval = 5 * time.Second
+
+// healthzHandler reports whether ovpmd is alive.
+func healthzHandler(w http.ResponseWriter, r *http.Request) {
+	writeHealthReport(w, ovpm.CheckLiveness())
+}
+
+// readyzHandler reports whether ovpmd is serving the VPN traffic.
+func readyzHandler(w http.ResponseWriter, r *http.Request) {
+	writeHealthReport(w, ovpm.CheckReadiness())
+}
+
+func writeHealthReport(w http.ResponseWriter, report *ovpm.HealthReport) {
+	status := http.StatusOK
+	if !report.IsHealthy() {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, report)
+}
+
+// HealthService implements the gRPC health checking protocol.
+//
+// The overall health ("") and the "ovpm" service report the readiness.
+// See https://github.com/grpc/grpc/blob/master/doc/health-checking.md.
+type HealthService struct {
+	grpc_health_v1.UnimplementedHealthServer
+}
+
+func (s *HealthService) Check(ctx gcontext.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	status, err := healthStatus(req.Service)
+	if err != nil {
+		return nil, err
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: status}, nil
+}
+
+func (s *HealthService) Watch(req *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	if _, err := healthStatus(req.Service); err != nil {
+		return stream.Send(&grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN})
+	}
+
+	ticker := time.NewTicker(healthWatchInterval)
+	defer ticker.Stop()
+	last := grpc_health_v1.HealthCheckResponse_UNKNOWN
+	for {
+		status, _ := healthStatus(req.Service)
+		if status != last {
+			if err := stream.Send(&grpc_health_v1.HealthCheckResponse{Status: status}); err != nil {
+				return err
+			}
+			last = status
+		}
+		select {
+		case <-stream.Context().Done():
+			return grpc.Errorf(codes.Canceled, "stream has ended")
+		case <-ticker.C:
+		}
+	}
+}
+
+// healthStatus returns the serving status of the service.
+func healthStatus(service string) (grpc_health_v1.HealthCheckResponse_ServingStatus, error) {
+	if service != "" && service != "ovpm" {
+		return grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN, grpc.Errorf(codes.NotFound, "unknown service: %s", service)
+	}
+	if !ovpm.CheckReadiness().IsHealthy() {
+		return grpc_health_v1.HealthCheckResponse_NOT_SERVING, nil
+	}
+	return grpc_health_v1.HealthCheckResponse_SERVING, nil
+}
@@ -61,6 +61,10 @@ func AuthUnaryInterceptor(ctx gcontext.Context, req interface{}, info *grpc.Unar
 		// AuthService methods
 		case "/pb.AuthService/Status":
 			return authRequired(ctx, req, handler)
+		case "/pb.AuthService/ListSessions":
+			return authRequired(ctx, req, handler)
+		case "/pb.AuthService/RevokeSession":
+			return authRequired(ctx, req, handler)
 
 		// UserService methods
 		case "/pb.UserService/List":
@@ -75,6 +79,18 @@ func AuthUnaryInterceptor(ctx gcontext.Context, req interface{}, info *grpc.Unar
 			return authRequired(ctx, req, handler)
 		case "/pb.UserService/GenConfig":
 			return authRequired(ctx, req, handler)
+		case "/pb.UserService/Disconnect":
+			return authRequired(ctx, req, handler)
+		case "/pb.UserService/SignCSR":
+			return authRequired(ctx, req, handler)
+		case "/pb.UserService/EnrollTOTP":
+			return authRequired(ctx, req, handler)
+		case "/pb.UserService/ConfirmTOTP":
+			return authRequired(ctx, req, handler)
+		case "/pb.UserService/ResetTOTP":
+			return authRequired(ctx, req, handler)
+		case "/pb.UserService/Sessions":
+			return authRequired(ctx, req, handler)
 
 		// VPNService methods
 		case "/pb.VPNService/Status":
@@ -85,6 +101,40 @@ func AuthUnaryInterceptor(ctx gcontext.Context, req interface{}, info *grpc.Unar
 			return authRequired(ctx, req, handler)
 		case "/pb.VPNService/Restart":
 			return authRequired(ctx, req, handler)
+		case "/pb.VPNService/RotateCA":
+			return authRequired(ctx, req, handler)
+		case "/pb.VPNService/RotateTLSCryptKey":
+			return authRequired(ctx, req, handler)
+		case "/pb.VPNService/TakeCAOffline":
+			return authRequired(ctx, req, handler)
+
+		// CertService methods
+		case "/pb.CertService/ListRevoked":
+			return authRequired(ctx, req, handler)
+
+		// LDAPService methods
+		case "/pb.LDAPService/GetConfig":
+			return authRequired(ctx, req, handler)
+		case "/pb.LDAPService/SetConfig":
+			return authRequired(ctx, req, handler)
+		case "/pb.LDAPService/Disable":
+			return authRequired(ctx, req, handler)
+		case "/pb.LDAPService/Sync":
+			return authRequired(ctx, req, handler)
+
+		// OIDCService methods
+		case "/pb.OIDCService/GetConfig":
+			return authRequired(ctx, req, handler)
+		case "/pb.OIDCService/SetConfig":
+			return authRequired(ctx, req, handler)
+		case "/pb.OIDCService/Disable":
+			return authRequired(ctx, req, handler)
+		case "/pb.OIDCService/Link":
+			return authRequired(ctx, req, handler)
+
+		// AuditService methods
+		case "/pb.AuditService/List":
+			return authRequired(ctx, req, handler)
 
 		// NetworkService methods
 		case "/pb.NetworkService/Create":
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cad/ovpm"
+	"github.com/sirupsen/logrus"
+)
+
+// oidcStateCookie ties the state of a login to the browser that started it,
+// so that the callback can't be completed in another browser.
+const oidcStateCookie = "ovpm_oidc_state"
+
+// oidcStatusHandler tells whether the OIDC login is available, so that the web
+// UI can offer it.
+func oidcStatusHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]bool{"enabled": ovpm.IsOIDCEnabled()})
+}
+
+// oidcLoginHandler sends the user to the OIDC provider.
+//
+// If return_to is given, the user is sent back there with the token in the
+// URL fragment (e.g. /#token=...) once the login is completed. Otherwise the
+// callback responds with the token as JSON, like /api/v1/auth/authenticate.
+func oidcLoginHandler(w http.ResponseWriter, r *http.Request) {
+	returnTo := r.URL.Query().Get("return_to")
+	if returnTo != "" && !isLocalPath(returnTo) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "return_to should be a path on this server"})
+		return
+	}
+	if !ovpm.IsOIDCEnabled() {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "oidc is not configured"})
+		return
+	}
+	authURL, state, err := ovpm.BeginOIDCLogin(returnTo)
+	if err != nil {
+		logrus.Warnf("oidc login can not be started: %v", err)
+		writeJSON(w, http.StatusBadGateway, map[string]string{"error": "oidc provider is not available"})
+		return
+	}
+	// Provider redirects back with a top level navigation, so the cookie
+	// should be sent on cross site navigations.
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     "/api/v1/auth/oidc/",
+		MaxAge:   int(ovpm.OIDCLoginTimeout.Seconds()),
+		Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// oidcCallbackHandler completes the login with the code that the OIDC
+// provider sent back and issues a token for the user.
+func oidcCallbackHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if e := q.Get("error"); e != "" {
+		logrus.Infof("oidc login is rejected by the provider: %s %s", e, q.Get("error_description"))
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "oidc login is rejected by the provider"})
+		return
+	}
+	// The cookie is used only once.
+	http.SetCookie(w, &http.Cookie{Name: oidcStateCookie, Path: "/api/v1/auth/oidc/", MaxAge: -1, HttpOnly: true})
+	cookie, err := r.Cookie(oidcStateCookie)
+	if err != nil || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(q.Get("state"))) != 1 {
+		logrus.Warnf("oidc login failed: state doesn't match the browser that started the login")
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "access denied"})
+		return
+	}
+	token, returnTo, err := ovpm.CompleteOIDCLogin(q.Get("state"), q.Get("code"), r.UserAgent())
+	if err != nil {
+		logrus.Warnf("oidc login failed: %v", err)
+		if returnTo != "" {
+			http.Redirect(w, r, returnTo+"#error=access_denied", http.StatusFound)
+			return
+		}
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "access denied"})
+		return
+	}
+	if returnTo != "" {
+		http.Redirect(w, r, returnTo+"#token="+url.QueryEscape(token), http.StatusFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"token": token})
+}
+
+// isLocalPath returns whether p is an absolute path on this server, so that
+// the login can't be used to redirect the users to other sites.
+func isLocalPath(p string) bool {
+	u, err := url.Parse(p)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Fragment != "" {
+		return false
+	}
+	return strings.HasPrefix(p, "/") && !strings.HasPrefix(p, "//") && !strings.Contains(p, "\\")
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -20,6 +20,8 @@ const _ = grpc.SupportPackageIsVersion7
 type AuthServiceClient interface {
 	Status(ctx context.Context, in *AuthStatusRequest, opts ...grpc.CallOption) (*AuthStatusResponse, error)
 	Authenticate(ctx context.Context, in *AuthAuthenticateRequest, opts ...grpc.CallOption) (*AuthAuthenticateResponse, error)
+	ListSessions(ctx context.Context, in *AuthListSessionsRequest, opts ...grpc.CallOption) (*AuthSessionsResponse, error)
+	RevokeSession(ctx context.Context, in *AuthRevokeSessionRequest, opts ...grpc.CallOption) (*AuthSessionsResponse, error)
 }
 
 type authServiceClient struct {
@@ -48,12 +50,32 @@ func (c *authServiceClient) Authenticate(ctx context.Context, in *AuthAuthentica
 	return out, nil
 }
 
+func (c *authServiceClient) ListSessions(ctx context.Context, in *AuthListSessionsRequest, opts ...grpc.CallOption) (*AuthSessionsResponse, error) {
+	out := new(AuthSessionsResponse)
+	err := c.cc.Invoke(ctx, "/pb.AuthService/ListSessions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authServiceClient) RevokeSession(ctx context.Context, in *AuthRevokeSessionRequest, opts ...grpc.CallOption) (*AuthSessionsResponse, error) {
+	out := new(AuthSessionsResponse)
+	err := c.cc.Invoke(ctx, "/pb.AuthService/RevokeSession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AuthServiceServer is the server API for AuthService service.
 // All implementations must embed UnimplementedAuthServiceServer
 // for forward compatibility
 type AuthServiceServer interface {
 	Status(context.Context, *AuthStatusRequest) (*AuthStatusResponse, error)
 	Authenticate(context.Context, *AuthAuthenticateRequest) (*AuthAuthenticateResponse, error)
+	ListSessions(context.Context, *AuthListSessionsRequest) (*AuthSessionsResponse, error)
+	RevokeSession(context.Context, *AuthRevokeSessionRequest) (*AuthSessionsResponse, error)
 	mustEmbedUnimplementedAuthServiceServer()
 }
 
@@ -67,6 +89,12 @@ func (UnimplementedAuthServiceServer) Status(context.Context, *AuthStatusRequest
 func (UnimplementedAuthServiceServer) Authenticate(context.Context, *AuthAuthenticateRequest) (*AuthAuthenticateResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Authenticate not implemented")
 }
+func (UnimplementedAuthServiceServer) ListSessions(context.Context, *AuthListSessionsRequest) (*AuthSessionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSessions not implemented")
+}
+func (UnimplementedAuthServiceServer) RevokeSession(context.Context, *AuthRevokeSessionRequest) (*AuthSessionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeSession not implemented")
+}
 func (UnimplementedAuthServiceServer) mustEmbedUnimplementedAuthServiceServer() {}
 
 // UnsafeAuthServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -116,6 +144,42 @@ func _AuthService_Authenticate_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AuthService_ListSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuthListSessionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).ListSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.AuthService/ListSessions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).ListSessions(ctx, req.(*AuthListSessionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthService_RevokeSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuthRevokeSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).RevokeSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.AuthService/RevokeSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).RevokeSession(ctx, req.(*AuthRevokeSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // AuthService_ServiceDesc is the grpc.ServiceDesc for AuthService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -131,6 +195,14 @@ var AuthService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Authenticate",
 			Handler:    _AuthService_Authenticate_Handler,
 		},
+		{
+			MethodName: "ListSessions",
+			Handler:    _AuthService_ListSessions_Handler,
+		},
+		{
+			MethodName: "RevokeSession",
+			Handler:    _AuthService_RevokeSession_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "auth.proto",
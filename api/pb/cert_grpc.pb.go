@@ -0,0 +1,101 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// CertServiceClient is the client API for CertService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CertServiceClient interface {
+	ListRevoked(ctx context.Context, in *CertListRevokedRequest, opts ...grpc.CallOption) (*CertListRevokedResponse, error)
+}
+
+type certServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCertServiceClient(cc grpc.ClientConnInterface) CertServiceClient {
+	return &certServiceClient{cc}
+}
+
+func (c *certServiceClient) ListRevoked(ctx context.Context, in *CertListRevokedRequest, opts ...grpc.CallOption) (*CertListRevokedResponse, error) {
+	out := new(CertListRevokedResponse)
+	err := c.cc.Invoke(ctx, "/pb.CertService/ListRevoked", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CertServiceServer is the server API for CertService service.
+// All implementations must embed UnimplementedCertServiceServer
+// for forward compatibility
+type CertServiceServer interface {
+	ListRevoked(context.Context, *CertListRevokedRequest) (*CertListRevokedResponse, error)
+	mustEmbedUnimplementedCertServiceServer()
+}
+
+// UnimplementedCertServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedCertServiceServer struct {
+}
+
+func (UnimplementedCertServiceServer) ListRevoked(context.Context, *CertListRevokedRequest) (*CertListRevokedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRevoked not implemented")
+}
+func (UnimplementedCertServiceServer) mustEmbedUnimplementedCertServiceServer() {}
+
+// UnsafeCertServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CertServiceServer will
+// result in compilation errors.
+type UnsafeCertServiceServer interface {
+	mustEmbedUnimplementedCertServiceServer()
+}
+
+func RegisterCertServiceServer(s grpc.ServiceRegistrar, srv CertServiceServer) {
+	s.RegisterService(&CertService_ServiceDesc, srv)
+}
+
+func _CertService_ListRevoked_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CertListRevokedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CertServiceServer).ListRevoked(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.CertService/ListRevoked",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CertServiceServer).ListRevoked(ctx, req.(*CertListRevokedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CertService_ServiceDesc is the grpc.ServiceDesc for CertService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CertService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.CertService",
+	HandlerType: (*CertServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListRevoked",
+			Handler:    _CertService_ListRevoked_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "cert.proto",
+}
@@ -0,0 +1,209 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// LDAPServiceClient is the client API for LDAPService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type LDAPServiceClient interface {
+	GetConfig(ctx context.Context, in *LDAPGetConfigRequest, opts ...grpc.CallOption) (*LDAPConfigResponse, error)
+	SetConfig(ctx context.Context, in *LDAPSetConfigRequest, opts ...grpc.CallOption) (*LDAPConfigResponse, error)
+	Disable(ctx context.Context, in *LDAPDisableRequest, opts ...grpc.CallOption) (*LDAPConfigResponse, error)
+	Sync(ctx context.Context, in *LDAPSyncRequest, opts ...grpc.CallOption) (*LDAPSyncResponse, error)
+}
+
+type lDAPServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLDAPServiceClient(cc grpc.ClientConnInterface) LDAPServiceClient {
+	return &lDAPServiceClient{cc}
+}
+
+func (c *lDAPServiceClient) GetConfig(ctx context.Context, in *LDAPGetConfigRequest, opts ...grpc.CallOption) (*LDAPConfigResponse, error) {
+	out := new(LDAPConfigResponse)
+	err := c.cc.Invoke(ctx, "/pb.LDAPService/GetConfig", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lDAPServiceClient) SetConfig(ctx context.Context, in *LDAPSetConfigRequest, opts ...grpc.CallOption) (*LDAPConfigResponse, error) {
+	out := new(LDAPConfigResponse)
+	err := c.cc.Invoke(ctx, "/pb.LDAPService/SetConfig", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lDAPServiceClient) Disable(ctx context.Context, in *LDAPDisableRequest, opts ...grpc.CallOption) (*LDAPConfigResponse, error) {
+	out := new(LDAPConfigResponse)
+	err := c.cc.Invoke(ctx, "/pb.LDAPService/Disable", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lDAPServiceClient) Sync(ctx context.Context, in *LDAPSyncRequest, opts ...grpc.CallOption) (*LDAPSyncResponse, error) {
+	out := new(LDAPSyncResponse)
+	err := c.cc.Invoke(ctx, "/pb.LDAPService/Sync", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// LDAPServiceServer is the server API for LDAPService service.
+// All implementations must embed UnimplementedLDAPServiceServer
+// for forward compatibility
+type LDAPServiceServer interface {
+	GetConfig(context.Context, *LDAPGetConfigRequest) (*LDAPConfigResponse, error)
+	SetConfig(context.Context, *LDAPSetConfigRequest) (*LDAPConfigResponse, error)
+	Disable(context.Context, *LDAPDisableRequest) (*LDAPConfigResponse, error)
+	Sync(context.Context, *LDAPSyncRequest) (*LDAPSyncResponse, error)
+	mustEmbedUnimplementedLDAPServiceServer()
+}
+
+// UnimplementedLDAPServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedLDAPServiceServer struct {
+}
+
+func (UnimplementedLDAPServiceServer) GetConfig(context.Context, *LDAPGetConfigRequest) (*LDAPConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConfig not implemented")
+}
+func (UnimplementedLDAPServiceServer) SetConfig(context.Context, *LDAPSetConfigRequest) (*LDAPConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetConfig not implemented")
+}
+func (UnimplementedLDAPServiceServer) Disable(context.Context, *LDAPDisableRequest) (*LDAPConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Disable not implemented")
+}
+func (UnimplementedLDAPServiceServer) Sync(context.Context, *LDAPSyncRequest) (*LDAPSyncResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Sync not implemented")
+}
+func (UnimplementedLDAPServiceServer) mustEmbedUnimplementedLDAPServiceServer() {}
+
+// UnsafeLDAPServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LDAPServiceServer will
+// result in compilation errors.
+type UnsafeLDAPServiceServer interface {
+	mustEmbedUnimplementedLDAPServiceServer()
+}
+
+func RegisterLDAPServiceServer(s grpc.ServiceRegistrar, srv LDAPServiceServer) {
+	s.RegisterService(&LDAPService_ServiceDesc, srv)
+}
+
+func _LDAPService_GetConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LDAPGetConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LDAPServiceServer).GetConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.LDAPService/GetConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LDAPServiceServer).GetConfig(ctx, req.(*LDAPGetConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LDAPService_SetConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LDAPSetConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LDAPServiceServer).SetConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.LDAPService/SetConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LDAPServiceServer).SetConfig(ctx, req.(*LDAPSetConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LDAPService_Disable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LDAPDisableRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LDAPServiceServer).Disable(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.LDAPService/Disable",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LDAPServiceServer).Disable(ctx, req.(*LDAPDisableRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LDAPService_Sync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LDAPSyncRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LDAPServiceServer).Sync(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.LDAPService/Sync",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LDAPServiceServer).Sync(ctx, req.(*LDAPSyncRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// LDAPService_ServiceDesc is the grpc.ServiceDesc for LDAPService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var LDAPService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.LDAPService",
+	HandlerType: (*LDAPServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetConfig",
+			Handler:    _LDAPService_GetConfig_Handler,
+		},
+		{
+			MethodName: "SetConfig",
+			Handler:    _LDAPService_SetConfig_Handler,
+		},
+		{
+			MethodName: "Disable",
+			Handler:    _LDAPService_Disable_Handler,
+		},
+		{
+			MethodName: "Sync",
+			Handler:    _LDAPService_Sync_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "ldap.proto",
+}
@@ -0,0 +1,213 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// OIDCServiceClient is the client API for OIDCService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type OIDCServiceClient interface {
+	GetConfig(ctx context.Context, in *OIDCGetConfigRequest, opts ...grpc.CallOption) (*OIDCConfigResponse, error)
+	SetConfig(ctx context.Context, in *OIDCSetConfigRequest, opts ...grpc.CallOption) (*OIDCConfigResponse, error)
+	Disable(ctx context.Context, in *OIDCDisableRequest, opts ...grpc.CallOption) (*OIDCConfigResponse, error)
+	// Link links the user to the subject (sub) of an OIDC account. Admins
+	// can only log in with OIDC after they are linked.
+	Link(ctx context.Context, in *OIDCLinkRequest, opts ...grpc.CallOption) (*OIDCLinkResponse, error)
+}
+
+type oIDCServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewOIDCServiceClient(cc grpc.ClientConnInterface) OIDCServiceClient {
+	return &oIDCServiceClient{cc}
+}
+
+func (c *oIDCServiceClient) GetConfig(ctx context.Context, in *OIDCGetConfigRequest, opts ...grpc.CallOption) (*OIDCConfigResponse, error) {
+	out := new(OIDCConfigResponse)
+	err := c.cc.Invoke(ctx, "/pb.OIDCService/GetConfig", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oIDCServiceClient) SetConfig(ctx context.Context, in *OIDCSetConfigRequest, opts ...grpc.CallOption) (*OIDCConfigResponse, error) {
+	out := new(OIDCConfigResponse)
+	err := c.cc.Invoke(ctx, "/pb.OIDCService/SetConfig", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oIDCServiceClient) Disable(ctx context.Context, in *OIDCDisableRequest, opts ...grpc.CallOption) (*OIDCConfigResponse, error) {
+	out := new(OIDCConfigResponse)
+	err := c.cc.Invoke(ctx, "/pb.OIDCService/Disable", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oIDCServiceClient) Link(ctx context.Context, in *OIDCLinkRequest, opts ...grpc.CallOption) (*OIDCLinkResponse, error) {
+	out := new(OIDCLinkResponse)
+	err := c.cc.Invoke(ctx, "/pb.OIDCService/Link", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// OIDCServiceServer is the server API for OIDCService service.
+// All implementations must embed UnimplementedOIDCServiceServer
+// for forward compatibility
+type OIDCServiceServer interface {
+	GetConfig(context.Context, *OIDCGetConfigRequest) (*OIDCConfigResponse, error)
+	SetConfig(context.Context, *OIDCSetConfigRequest) (*OIDCConfigResponse, error)
+	Disable(context.Context, *OIDCDisableRequest) (*OIDCConfigResponse, error)
+	// Link links the user to the subject (sub) of an OIDC account. Admins
+	// can only log in with OIDC after they are linked.
+	Link(context.Context, *OIDCLinkRequest) (*OIDCLinkResponse, error)
+	mustEmbedUnimplementedOIDCServiceServer()
+}
+
+// UnimplementedOIDCServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedOIDCServiceServer struct {
+}
+
+func (UnimplementedOIDCServiceServer) GetConfig(context.Context, *OIDCGetConfigRequest) (*OIDCConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConfig not implemented")
+}
+func (UnimplementedOIDCServiceServer) SetConfig(context.Context, *OIDCSetConfigRequest) (*OIDCConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetConfig not implemented")
+}
+func (UnimplementedOIDCServiceServer) Disable(context.Context, *OIDCDisableRequest) (*OIDCConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Disable not implemented")
+}
+func (UnimplementedOIDCServiceServer) Link(context.Context, *OIDCLinkRequest) (*OIDCLinkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Link not implemented")
+}
+func (UnimplementedOIDCServiceServer) mustEmbedUnimplementedOIDCServiceServer() {}
+
+// UnsafeOIDCServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to OIDCServiceServer will
+// result in compilation errors.
+type UnsafeOIDCServiceServer interface {
+	mustEmbedUnimplementedOIDCServiceServer()
+}
+
+func RegisterOIDCServiceServer(s grpc.ServiceRegistrar, srv OIDCServiceServer) {
+	s.RegisterService(&OIDCService_ServiceDesc, srv)
+}
+
+func _OIDCService_GetConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OIDCGetConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OIDCServiceServer).GetConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.OIDCService/GetConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OIDCServiceServer).GetConfig(ctx, req.(*OIDCGetConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OIDCService_SetConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OIDCSetConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OIDCServiceServer).SetConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.OIDCService/SetConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OIDCServiceServer).SetConfig(ctx, req.(*OIDCSetConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OIDCService_Disable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OIDCDisableRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OIDCServiceServer).Disable(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.OIDCService/Disable",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OIDCServiceServer).Disable(ctx, req.(*OIDCDisableRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OIDCService_Link_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OIDCLinkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OIDCServiceServer).Link(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.OIDCService/Link",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OIDCServiceServer).Link(ctx, req.(*OIDCLinkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// OIDCService_ServiceDesc is the grpc.ServiceDesc for OIDCService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var OIDCService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.OIDCService",
+	HandlerType: (*OIDCServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetConfig",
+			Handler:    _OIDCService_GetConfig_Handler,
+		},
+		{
+			MethodName: "SetConfig",
+			Handler:    _OIDCService_SetConfig_Handler,
+		},
+		{
+			MethodName: "Disable",
+			Handler:    _OIDCService_Disable_Handler,
+		},
+		{
+			MethodName: "Link",
+			Handler:    _OIDCService_Link_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "oidc.proto",
+}
@@ -24,6 +24,12 @@ type UserServiceClient interface {
 	Delete(ctx context.Context, in *UserDeleteRequest, opts ...grpc.CallOption) (*UserResponse, error)
 	Renew(ctx context.Context, in *UserRenewRequest, opts ...grpc.CallOption) (*UserResponse, error)
 	GenConfig(ctx context.Context, in *UserGenConfigRequest, opts ...grpc.CallOption) (*UserGenConfigResponse, error)
+	Disconnect(ctx context.Context, in *UserDisconnectRequest, opts ...grpc.CallOption) (*UserResponse, error)
+	SignCSR(ctx context.Context, in *UserSignCSRRequest, opts ...grpc.CallOption) (*UserResponse, error)
+	EnrollTOTP(ctx context.Context, in *UserEnrollTOTPRequest, opts ...grpc.CallOption) (*UserEnrollTOTPResponse, error)
+	ConfirmTOTP(ctx context.Context, in *UserConfirmTOTPRequest, opts ...grpc.CallOption) (*UserConfirmTOTPResponse, error)
+	ResetTOTP(ctx context.Context, in *UserResetTOTPRequest, opts ...grpc.CallOption) (*UserResponse, error)
+	Sessions(ctx context.Context, in *UserSessionsRequest, opts ...grpc.CallOption) (*UserSessionsResponse, error)
 }
 
 type userServiceClient struct {
@@ -88,6 +94,60 @@ func (c *userServiceClient) GenConfig(ctx context.Context, in *UserGenConfigRequ
 	return out, nil
 }
 
+func (c *userServiceClient) Disconnect(ctx context.Context, in *UserDisconnectRequest, opts ...grpc.CallOption) (*UserResponse, error) {
+	out := new(UserResponse)
+	err := c.cc.Invoke(ctx, "/pb.UserService/Disconnect", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) SignCSR(ctx context.Context, in *UserSignCSRRequest, opts ...grpc.CallOption) (*UserResponse, error) {
+	out := new(UserResponse)
+	err := c.cc.Invoke(ctx, "/pb.UserService/SignCSR", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) EnrollTOTP(ctx context.Context, in *UserEnrollTOTPRequest, opts ...grpc.CallOption) (*UserEnrollTOTPResponse, error) {
+	out := new(UserEnrollTOTPResponse)
+	err := c.cc.Invoke(ctx, "/pb.UserService/EnrollTOTP", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) ConfirmTOTP(ctx context.Context, in *UserConfirmTOTPRequest, opts ...grpc.CallOption) (*UserConfirmTOTPResponse, error) {
+	out := new(UserConfirmTOTPResponse)
+	err := c.cc.Invoke(ctx, "/pb.UserService/ConfirmTOTP", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) ResetTOTP(ctx context.Context, in *UserResetTOTPRequest, opts ...grpc.CallOption) (*UserResponse, error) {
+	out := new(UserResponse)
+	err := c.cc.Invoke(ctx, "/pb.UserService/ResetTOTP", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) Sessions(ctx context.Context, in *UserSessionsRequest, opts ...grpc.CallOption) (*UserSessionsResponse, error) {
+	out := new(UserSessionsResponse)
+	err := c.cc.Invoke(ctx, "/pb.UserService/Sessions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // UserServiceServer is the server API for UserService service.
 // All implementations must embed UnimplementedUserServiceServer
 // for forward compatibility
@@ -98,6 +158,12 @@ type UserServiceServer interface {
 	Delete(context.Context, *UserDeleteRequest) (*UserResponse, error)
 	Renew(context.Context, *UserRenewRequest) (*UserResponse, error)
 	GenConfig(context.Context, *UserGenConfigRequest) (*UserGenConfigResponse, error)
+	Disconnect(context.Context, *UserDisconnectRequest) (*UserResponse, error)
+	SignCSR(context.Context, *UserSignCSRRequest) (*UserResponse, error)
+	EnrollTOTP(context.Context, *UserEnrollTOTPRequest) (*UserEnrollTOTPResponse, error)
+	ConfirmTOTP(context.Context, *UserConfirmTOTPRequest) (*UserConfirmTOTPResponse, error)
+	ResetTOTP(context.Context, *UserResetTOTPRequest) (*UserResponse, error)
+	Sessions(context.Context, *UserSessionsRequest) (*UserSessionsResponse, error)
 	mustEmbedUnimplementedUserServiceServer()
 }
 
@@ -123,6 +189,24 @@ func (UnimplementedUserServiceServer) Renew(context.Context, *UserRenewRequest)
 func (UnimplementedUserServiceServer) GenConfig(context.Context, *UserGenConfigRequest) (*UserGenConfigResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GenConfig not implemented")
 }
+func (UnimplementedUserServiceServer) Disconnect(context.Context, *UserDisconnectRequest) (*UserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Disconnect not implemented")
+}
+func (UnimplementedUserServiceServer) SignCSR(context.Context, *UserSignCSRRequest) (*UserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SignCSR not implemented")
+}
+func (UnimplementedUserServiceServer) EnrollTOTP(context.Context, *UserEnrollTOTPRequest) (*UserEnrollTOTPResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnrollTOTP not implemented")
+}
+func (UnimplementedUserServiceServer) ConfirmTOTP(context.Context, *UserConfirmTOTPRequest) (*UserConfirmTOTPResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmTOTP not implemented")
+}
+func (UnimplementedUserServiceServer) ResetTOTP(context.Context, *UserResetTOTPRequest) (*UserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResetTOTP not implemented")
+}
+func (UnimplementedUserServiceServer) Sessions(context.Context, *UserSessionsRequest) (*UserSessionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Sessions not implemented")
+}
 func (UnimplementedUserServiceServer) mustEmbedUnimplementedUserServiceServer() {}
 
 // UnsafeUserServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -244,6 +328,114 @@ func _UserService_GenConfig_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _UserService_Disconnect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserDisconnectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).Disconnect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.UserService/Disconnect",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).Disconnect(ctx, req.(*UserDisconnectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_SignCSR_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserSignCSRRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).SignCSR(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.UserService/SignCSR",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).SignCSR(ctx, req.(*UserSignCSRRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_EnrollTOTP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserEnrollTOTPRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).EnrollTOTP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.UserService/EnrollTOTP",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).EnrollTOTP(ctx, req.(*UserEnrollTOTPRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_ConfirmTOTP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserConfirmTOTPRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).ConfirmTOTP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.UserService/ConfirmTOTP",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).ConfirmTOTP(ctx, req.(*UserConfirmTOTPRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_ResetTOTP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserResetTOTPRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).ResetTOTP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.UserService/ResetTOTP",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).ResetTOTP(ctx, req.(*UserResetTOTPRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_Sessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserSessionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).Sessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.UserService/Sessions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).Sessions(ctx, req.(*UserSessionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // UserService_ServiceDesc is the grpc.ServiceDesc for UserService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -275,6 +467,30 @@ var UserService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GenConfig",
 			Handler:    _UserService_GenConfig_Handler,
 		},
+		{
+			MethodName: "Disconnect",
+			Handler:    _UserService_Disconnect_Handler,
+		},
+		{
+			MethodName: "SignCSR",
+			Handler:    _UserService_SignCSR_Handler,
+		},
+		{
+			MethodName: "EnrollTOTP",
+			Handler:    _UserService_EnrollTOTP_Handler,
+		},
+		{
+			MethodName: "ConfirmTOTP",
+			Handler:    _UserService_ConfirmTOTP_Handler,
+		},
+		{
+			MethodName: "ResetTOTP",
+			Handler:    _UserService_ResetTOTP_Handler,
+		},
+		{
+			MethodName: "Sessions",
+			Handler:    _UserService_Sessions_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "user.proto",
@@ -119,6 +119,153 @@ func (VPNLZOPref) EnumDescriptor() ([]byte, []int) {
 	return file_vpn_proto_rawDescGZIP(), []int{1}
 }
 
+type VPNRemoteCertTLSPref int32
+
+const (
+	VPNRemoteCertTLSPref_REMOTE_CERT_TLS_NOPREF  VPNRemoteCertTLSPref = 0
+	VPNRemoteCertTLSPref_REMOTE_CERT_TLS_ENABLE  VPNRemoteCertTLSPref = 1
+	VPNRemoteCertTLSPref_REMOTE_CERT_TLS_DISABLE VPNRemoteCertTLSPref = 2
+)
+
+// Enum value maps for VPNRemoteCertTLSPref.
+var (
+	VPNRemoteCertTLSPref_name = map[int32]string{
+		0: "REMOTE_CERT_TLS_NOPREF",
+		1: "REMOTE_CERT_TLS_ENABLE",
+		2: "REMOTE_CERT_TLS_DISABLE",
+	}
+	VPNRemoteCertTLSPref_value = map[string]int32{
+		"REMOTE_CERT_TLS_NOPREF":  0,
+		"REMOTE_CERT_TLS_ENABLE":  1,
+		"REMOTE_CERT_TLS_DISABLE": 2,
+	}
+)
+
+func (x VPNRemoteCertTLSPref) Enum() *VPNRemoteCertTLSPref {
+	p := new(VPNRemoteCertTLSPref)
+	*p = x
+	return p
+}
+
+func (x VPNRemoteCertTLSPref) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (VPNRemoteCertTLSPref) Descriptor() protoreflect.EnumDescriptor {
+	return file_vpn_proto_enumTypes[2].Descriptor()
+}
+
+func (VPNRemoteCertTLSPref) Type() protoreflect.EnumType {
+	return &file_vpn_proto_enumTypes[2]
+}
+
+func (x VPNRemoteCertTLSPref) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use VPNRemoteCertTLSPref.Descriptor instead.
+func (VPNRemoteCertTLSPref) EnumDescriptor() ([]byte, []int) {
+	return file_vpn_proto_rawDescGZIP(), []int{2}
+}
+
+type VPNECDHOnlyPref int32
+
+const (
+	VPNECDHOnlyPref_ECDH_ONLY_NOPREF  VPNECDHOnlyPref = 0
+	VPNECDHOnlyPref_ECDH_ONLY_ENABLE  VPNECDHOnlyPref = 1
+	VPNECDHOnlyPref_ECDH_ONLY_DISABLE VPNECDHOnlyPref = 2
+)
+
+// Enum value maps for VPNECDHOnlyPref.
+var (
+	VPNECDHOnlyPref_name = map[int32]string{
+		0: "ECDH_ONLY_NOPREF",
+		1: "ECDH_ONLY_ENABLE",
+		2: "ECDH_ONLY_DISABLE",
+	}
+	VPNECDHOnlyPref_value = map[string]int32{
+		"ECDH_ONLY_NOPREF":  0,
+		"ECDH_ONLY_ENABLE":  1,
+		"ECDH_ONLY_DISABLE": 2,
+	}
+)
+
+func (x VPNECDHOnlyPref) Enum() *VPNECDHOnlyPref {
+	p := new(VPNECDHOnlyPref)
+	*p = x
+	return p
+}
+
+func (x VPNECDHOnlyPref) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (VPNECDHOnlyPref) Descriptor() protoreflect.EnumDescriptor {
+	return file_vpn_proto_enumTypes[3].Descriptor()
+}
+
+func (VPNECDHOnlyPref) Type() protoreflect.EnumType {
+	return &file_vpn_proto_enumTypes[3]
+}
+
+func (x VPNECDHOnlyPref) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use VPNECDHOnlyPref.Descriptor instead.
+func (VPNECDHOnlyPref) EnumDescriptor() ([]byte, []int) {
+	return file_vpn_proto_rawDescGZIP(), []int{3}
+}
+
+type VPNAuthUserPassPref int32
+
+const (
+	VPNAuthUserPassPref_AUTH_USER_PASS_NOPREF  VPNAuthUserPassPref = 0
+	VPNAuthUserPassPref_AUTH_USER_PASS_ENABLE  VPNAuthUserPassPref = 1
+	VPNAuthUserPassPref_AUTH_USER_PASS_DISABLE VPNAuthUserPassPref = 2
+)
+
+// Enum value maps for VPNAuthUserPassPref.
+var (
+	VPNAuthUserPassPref_name = map[int32]string{
+		0: "AUTH_USER_PASS_NOPREF",
+		1: "AUTH_USER_PASS_ENABLE",
+		2: "AUTH_USER_PASS_DISABLE",
+	}
+	VPNAuthUserPassPref_value = map[string]int32{
+		"AUTH_USER_PASS_NOPREF":  0,
+		"AUTH_USER_PASS_ENABLE":  1,
+		"AUTH_USER_PASS_DISABLE": 2,
+	}
+)
+
+func (x VPNAuthUserPassPref) Enum() *VPNAuthUserPassPref {
+	p := new(VPNAuthUserPassPref)
+	*p = x
+	return p
+}
+
+func (x VPNAuthUserPassPref) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (VPNAuthUserPassPref) Descriptor() protoreflect.EnumDescriptor {
+	return file_vpn_proto_enumTypes[4].Descriptor()
+}
+
+func (VPNAuthUserPassPref) Type() protoreflect.EnumType {
+	return &file_vpn_proto_enumTypes[4]
+}
+
+func (x VPNAuthUserPassPref) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use VPNAuthUserPassPref.Descriptor instead.
+func (VPNAuthUserPassPref) EnumDescriptor() ([]byte, []int) {
+	return file_vpn_proto_rawDescGZIP(), []int{4}
+}
+
 type VPNStatusRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -162,14 +309,32 @@ type VPNInitRequest struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Hostname         string   `protobuf:"bytes,1,opt,name=hostname,proto3" json:"hostname,omitempty"`
-	Port             string   `protobuf:"bytes,2,opt,name=port,proto3" json:"port,omitempty"`
-	ProtoPref        VPNProto `protobuf:"varint,3,opt,name=proto_pref,json=protoPref,proto3,enum=pb.VPNProto" json:"proto_pref,omitempty"`
-	IpBlock          string   `protobuf:"bytes,4,opt,name=ip_block,json=ipBlock,proto3" json:"ip_block,omitempty"`
-	Dns              string   `protobuf:"bytes,5,opt,name=dns,proto3" json:"dns,omitempty"`
-	KeepalivePeriod  string   `protobuf:"bytes,6,opt,name=keepalive_period,json=keepalivePeriod,proto3" json:"keepalive_period,omitempty"`
-	KeepaliveTimeout string   `protobuf:"bytes,7,opt,name=keepalive_timeout,json=keepaliveTimeout,proto3" json:"keepalive_timeout,omitempty"`
-	UseLzo           bool     `protobuf:"varint,8,opt,name=use_lzo,json=useLzo,proto3" json:"use_lzo,omitempty"`
+	Hostname               string               `protobuf:"bytes,1,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Port                   string               `protobuf:"bytes,2,opt,name=port,proto3" json:"port,omitempty"`
+	ProtoPref              VPNProto             `protobuf:"varint,3,opt,name=proto_pref,json=protoPref,proto3,enum=pb.VPNProto" json:"proto_pref,omitempty"`
+	IpBlock                string               `protobuf:"bytes,4,opt,name=ip_block,json=ipBlock,proto3" json:"ip_block,omitempty"`
+	Dns                    string               `protobuf:"bytes,5,opt,name=dns,proto3" json:"dns,omitempty"`
+	KeepalivePeriod        string               `protobuf:"bytes,6,opt,name=keepalive_period,json=keepalivePeriod,proto3" json:"keepalive_period,omitempty"`
+	KeepaliveTimeout       string               `protobuf:"bytes,7,opt,name=keepalive_timeout,json=keepaliveTimeout,proto3" json:"keepalive_timeout,omitempty"`
+	UseLzo                 bool                 `protobuf:"varint,8,opt,name=use_lzo,json=useLzo,proto3" json:"use_lzo,omitempty"`
+	CaValidityDays         int32                `protobuf:"varint,9,opt,name=ca_validity_days,json=caValidityDays,proto3" json:"ca_validity_days,omitempty"`
+	ServerCertValidityDays int32                `protobuf:"varint,10,opt,name=server_cert_validity_days,json=serverCertValidityDays,proto3" json:"server_cert_validity_days,omitempty"`
+	ClientCertValidityDays int32                `protobuf:"varint,11,opt,name=client_cert_validity_days,json=clientCertValidityDays,proto3" json:"client_cert_validity_days,omitempty"`
+	CertRenewWindowDays    int32                `protobuf:"varint,12,opt,name=cert_renew_window_days,json=certRenewWindowDays,proto3" json:"cert_renew_window_days,omitempty"`
+	KeyType                string               `protobuf:"bytes,13,opt,name=key_type,json=keyType,proto3" json:"key_type,omitempty"`
+	CaCert                 string               `protobuf:"bytes,14,opt,name=ca_cert,json=caCert,proto3" json:"ca_cert,omitempty"`
+	CaKey                  string               `protobuf:"bytes,15,opt,name=ca_key,json=caKey,proto3" json:"ca_key,omitempty"`
+	TlsCrypt               string               `protobuf:"bytes,16,opt,name=tls_crypt,json=tlsCrypt,proto3" json:"tls_crypt,omitempty"`
+	CryptoPreset           string               `protobuf:"bytes,17,opt,name=crypto_preset,json=cryptoPreset,proto3" json:"crypto_preset,omitempty"`
+	DataCiphers            string               `protobuf:"bytes,18,opt,name=data_ciphers,json=dataCiphers,proto3" json:"data_ciphers,omitempty"`
+	DataCiphersFallback    string               `protobuf:"bytes,19,opt,name=data_ciphers_fallback,json=dataCiphersFallback,proto3" json:"data_ciphers_fallback,omitempty"`
+	AuthDigest             string               `protobuf:"bytes,20,opt,name=auth_digest,json=authDigest,proto3" json:"auth_digest,omitempty"`
+	TlsVersionMin          string               `protobuf:"bytes,21,opt,name=tls_version_min,json=tlsVersionMin,proto3" json:"tls_version_min,omitempty"`
+	TlsCipher              string               `protobuf:"bytes,22,opt,name=tls_cipher,json=tlsCipher,proto3" json:"tls_cipher,omitempty"`
+	RemoteCertTlsPref      VPNRemoteCertTLSPref `protobuf:"varint,23,opt,name=remote_cert_tls_pref,json=remoteCertTlsPref,proto3,enum=pb.VPNRemoteCertTLSPref" json:"remote_cert_tls_pref,omitempty"`
+	EcdhOnlyPref           VPNECDHOnlyPref      `protobuf:"varint,24,opt,name=ecdh_only_pref,json=ecdhOnlyPref,proto3,enum=pb.VPNECDHOnlyPref" json:"ecdh_only_pref,omitempty"`
+	AuthUserPass           bool                 `protobuf:"varint,25,opt,name=auth_user_pass,json=authUserPass,proto3" json:"auth_user_pass,omitempty"`
+	CaCrl                  string               `protobuf:"bytes,26,opt,name=ca_crl,json=caCrl,proto3" json:"ca_crl,omitempty"` // CRLs of the issuers, required to import an intermediate CA.
 }
 
 func (x *VPNInitRequest) Reset() {
@@ -260,14 +425,157 @@ func (x *VPNInitRequest) GetUseLzo() bool {
 	return false
 }
 
+func (x *VPNInitRequest) GetCaValidityDays() int32 {
+	if x != nil {
+		return x.CaValidityDays
+	}
+	return 0
+}
+
+func (x *VPNInitRequest) GetServerCertValidityDays() int32 {
+	if x != nil {
+		return x.ServerCertValidityDays
+	}
+	return 0
+}
+
+func (x *VPNInitRequest) GetClientCertValidityDays() int32 {
+	if x != nil {
+		return x.ClientCertValidityDays
+	}
+	return 0
+}
+
+func (x *VPNInitRequest) GetCertRenewWindowDays() int32 {
+	if x != nil {
+		return x.CertRenewWindowDays
+	}
+	return 0
+}
+
+func (x *VPNInitRequest) GetKeyType() string {
+	if x != nil {
+		return x.KeyType
+	}
+	return ""
+}
+
+func (x *VPNInitRequest) GetCaCert() string {
+	if x != nil {
+		return x.CaCert
+	}
+	return ""
+}
+
+func (x *VPNInitRequest) GetCaKey() string {
+	if x != nil {
+		return x.CaKey
+	}
+	return ""
+}
+
+func (x *VPNInitRequest) GetTlsCrypt() string {
+	if x != nil {
+		return x.TlsCrypt
+	}
+	return ""
+}
+
+func (x *VPNInitRequest) GetCryptoPreset() string {
+	if x != nil {
+		return x.CryptoPreset
+	}
+	return ""
+}
+
+func (x *VPNInitRequest) GetDataCiphers() string {
+	if x != nil {
+		return x.DataCiphers
+	}
+	return ""
+}
+
+func (x *VPNInitRequest) GetDataCiphersFallback() string {
+	if x != nil {
+		return x.DataCiphersFallback
+	}
+	return ""
+}
+
+func (x *VPNInitRequest) GetAuthDigest() string {
+	if x != nil {
+		return x.AuthDigest
+	}
+	return ""
+}
+
+func (x *VPNInitRequest) GetTlsVersionMin() string {
+	if x != nil {
+		return x.TlsVersionMin
+	}
+	return ""
+}
+
+func (x *VPNInitRequest) GetTlsCipher() string {
+	if x != nil {
+		return x.TlsCipher
+	}
+	return ""
+}
+
+func (x *VPNInitRequest) GetRemoteCertTlsPref() VPNRemoteCertTLSPref {
+	if x != nil {
+		return x.RemoteCertTlsPref
+	}
+	return VPNRemoteCertTLSPref_REMOTE_CERT_TLS_NOPREF
+}
+
+func (x *VPNInitRequest) GetEcdhOnlyPref() VPNECDHOnlyPref {
+	if x != nil {
+		return x.EcdhOnlyPref
+	}
+	return VPNECDHOnlyPref_ECDH_ONLY_NOPREF
+}
+
+func (x *VPNInitRequest) GetAuthUserPass() bool {
+	if x != nil {
+		return x.AuthUserPass
+	}
+	return false
+}
+
+func (x *VPNInitRequest) GetCaCrl() string {
+	if x != nil {
+		return x.CaCrl
+	}
+	return ""
+}
+
 type VPNUpdateRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	IpBlock string     `protobuf:"bytes,1,opt,name=ip_block,json=ipBlock,proto3" json:"ip_block,omitempty"`
-	Dns     string     `protobuf:"bytes,2,opt,name=dns,proto3" json:"dns,omitempty"`
-	LzoPref VPNLZOPref `protobuf:"varint,3,opt,name=lzo_pref,json=lzoPref,proto3,enum=pb.VPNLZOPref" json:"lzo_pref,omitempty"`
+	IpBlock                string               `protobuf:"bytes,1,opt,name=ip_block,json=ipBlock,proto3" json:"ip_block,omitempty"`
+	Dns                    string               `protobuf:"bytes,2,opt,name=dns,proto3" json:"dns,omitempty"`
+	LzoPref                VPNLZOPref           `protobuf:"varint,3,opt,name=lzo_pref,json=lzoPref,proto3,enum=pb.VPNLZOPref" json:"lzo_pref,omitempty"`
+	CaValidityDays         int32                `protobuf:"varint,4,opt,name=ca_validity_days,json=caValidityDays,proto3" json:"ca_validity_days,omitempty"`
+	ServerCertValidityDays int32                `protobuf:"varint,5,opt,name=server_cert_validity_days,json=serverCertValidityDays,proto3" json:"server_cert_validity_days,omitempty"`
+	ClientCertValidityDays int32                `protobuf:"varint,6,opt,name=client_cert_validity_days,json=clientCertValidityDays,proto3" json:"client_cert_validity_days,omitempty"`
+	CertRenewWindowDays    int32                `protobuf:"varint,7,opt,name=cert_renew_window_days,json=certRenewWindowDays,proto3" json:"cert_renew_window_days,omitempty"`
+	KeyType                string               `protobuf:"bytes,8,opt,name=key_type,json=keyType,proto3" json:"key_type,omitempty"`
+	TlsCrypt               string               `protobuf:"bytes,9,opt,name=tls_crypt,json=tlsCrypt,proto3" json:"tls_crypt,omitempty"`
+	CryptoPreset           string               `protobuf:"bytes,10,opt,name=crypto_preset,json=cryptoPreset,proto3" json:"crypto_preset,omitempty"`
+	DataCiphers            string               `protobuf:"bytes,11,opt,name=data_ciphers,json=dataCiphers,proto3" json:"data_ciphers,omitempty"`
+	DataCiphersFallback    string               `protobuf:"bytes,12,opt,name=data_ciphers_fallback,json=dataCiphersFallback,proto3" json:"data_ciphers_fallback,omitempty"`
+	AuthDigest             string               `protobuf:"bytes,13,opt,name=auth_digest,json=authDigest,proto3" json:"auth_digest,omitempty"`
+	TlsVersionMin          string               `protobuf:"bytes,14,opt,name=tls_version_min,json=tlsVersionMin,proto3" json:"tls_version_min,omitempty"`
+	TlsCipher              string               `protobuf:"bytes,15,opt,name=tls_cipher,json=tlsCipher,proto3" json:"tls_cipher,omitempty"`
+	RemoteCertTlsPref      VPNRemoteCertTLSPref `protobuf:"varint,16,opt,name=remote_cert_tls_pref,json=remoteCertTlsPref,proto3,enum=pb.VPNRemoteCertTLSPref" json:"remote_cert_tls_pref,omitempty"`
+	EcdhOnlyPref           VPNECDHOnlyPref      `protobuf:"varint,17,opt,name=ecdh_only_pref,json=ecdhOnlyPref,proto3,enum=pb.VPNECDHOnlyPref" json:"ecdh_only_pref,omitempty"`
+	AuthUserPassPref       VPNAuthUserPassPref  `protobuf:"varint,18,opt,name=auth_user_pass_pref,json=authUserPassPref,proto3,enum=pb.VPNAuthUserPassPref" json:"auth_user_pass_pref,omitempty"`
+	CaCrl                  string               `protobuf:"bytes,19,opt,name=ca_crl,json=caCrl,proto3" json:"ca_crl,omitempty"`                    // Replaces the CRLs of the imported intermediate CA's issuers.
+	CryptoForce            bool                 `protobuf:"varint,20,opt,name=crypto_force,json=cryptoForce,proto3" json:"crypto_force,omitempty"` // Applies the crypto profile even if it locks out the existing clients.
 }
 
 func (x *VPNUpdateRequest) Reset() {
@@ -323,6 +631,125 @@ func (x *VPNUpdateRequest) GetLzoPref() VPNLZOPref {
 	return VPNLZOPref_USE_LZO_NOPREF
 }
 
+func (x *VPNUpdateRequest) GetCaValidityDays() int32 {
+	if x != nil {
+		return x.CaValidityDays
+	}
+	return 0
+}
+
+func (x *VPNUpdateRequest) GetServerCertValidityDays() int32 {
+	if x != nil {
+		return x.ServerCertValidityDays
+	}
+	return 0
+}
+
+func (x *VPNUpdateRequest) GetClientCertValidityDays() int32 {
+	if x != nil {
+		return x.ClientCertValidityDays
+	}
+	return 0
+}
+
+func (x *VPNUpdateRequest) GetCertRenewWindowDays() int32 {
+	if x != nil {
+		return x.CertRenewWindowDays
+	}
+	return 0
+}
+
+func (x *VPNUpdateRequest) GetKeyType() string {
+	if x != nil {
+		return x.KeyType
+	}
+	return ""
+}
+
+func (x *VPNUpdateRequest) GetTlsCrypt() string {
+	if x != nil {
+		return x.TlsCrypt
+	}
+	return ""
+}
+
+func (x *VPNUpdateRequest) GetCryptoPreset() string {
+	if x != nil {
+		return x.CryptoPreset
+	}
+	return ""
+}
+
+func (x *VPNUpdateRequest) GetDataCiphers() string {
+	if x != nil {
+		return x.DataCiphers
+	}
+	return ""
+}
+
+func (x *VPNUpdateRequest) GetDataCiphersFallback() string {
+	if x != nil {
+		return x.DataCiphersFallback
+	}
+	return ""
+}
+
+func (x *VPNUpdateRequest) GetAuthDigest() string {
+	if x != nil {
+		return x.AuthDigest
+	}
+	return ""
+}
+
+func (x *VPNUpdateRequest) GetTlsVersionMin() string {
+	if x != nil {
+		return x.TlsVersionMin
+	}
+	return ""
+}
+
+func (x *VPNUpdateRequest) GetTlsCipher() string {
+	if x != nil {
+		return x.TlsCipher
+	}
+	return ""
+}
+
+func (x *VPNUpdateRequest) GetRemoteCertTlsPref() VPNRemoteCertTLSPref {
+	if x != nil {
+		return x.RemoteCertTlsPref
+	}
+	return VPNRemoteCertTLSPref_REMOTE_CERT_TLS_NOPREF
+}
+
+func (x *VPNUpdateRequest) GetEcdhOnlyPref() VPNECDHOnlyPref {
+	if x != nil {
+		return x.EcdhOnlyPref
+	}
+	return VPNECDHOnlyPref_ECDH_ONLY_NOPREF
+}
+
+func (x *VPNUpdateRequest) GetAuthUserPassPref() VPNAuthUserPassPref {
+	if x != nil {
+		return x.AuthUserPassPref
+	}
+	return VPNAuthUserPassPref_AUTH_USER_PASS_NOPREF
+}
+
+func (x *VPNUpdateRequest) GetCaCrl() string {
+	if x != nil {
+		return x.CaCrl
+	}
+	return ""
+}
+
+func (x *VPNUpdateRequest) GetCryptoForce() bool {
+	if x != nil {
+		return x.CryptoForce
+	}
+	return false
+}
+
 type VPNRestartRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -361,29 +788,16 @@ func (*VPNRestartRequest) Descriptor() ([]byte, []int) {
 	return file_vpn_proto_rawDescGZIP(), []int{3}
 }
 
-type VPNStatusResponse struct {
+type VPNRotateCARequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Name         string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	SerialNumber string `protobuf:"bytes,2,opt,name=serial_number,json=serialNumber,proto3" json:"serial_number,omitempty"`
-	Hostname     string `protobuf:"bytes,3,opt,name=hostname,proto3" json:"hostname,omitempty"`
-	Port         string `protobuf:"bytes,4,opt,name=port,proto3" json:"port,omitempty"`
-	Cert         string `protobuf:"bytes,5,opt,name=cert,proto3" json:"cert,omitempty"`
-	CaCert       string `protobuf:"bytes,6,opt,name=ca_cert,json=caCert,proto3" json:"ca_cert,omitempty"`
-	Net          string `protobuf:"bytes,7,opt,name=net,proto3" json:"net,omitempty"`
-	Mask         string `protobuf:"bytes,8,opt,name=mask,proto3" json:"mask,omitempty"`
-	CreatedAt    string `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	Proto        string `protobuf:"bytes,10,opt,name=proto,proto3" json:"proto,omitempty"`
-	Dns          string `protobuf:"bytes,11,opt,name=dns,proto3" json:"dns,omitempty"`
-	ExpiresAt    string `protobuf:"bytes,12,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
-	CaExpiresAt  string `protobuf:"bytes,13,opt,name=ca_expires_at,json=caExpiresAt,proto3" json:"ca_expires_at,omitempty"`
-	UseLzo       bool   `protobuf:"varint,14,opt,name=use_lzo,json=useLzo,proto3" json:"use_lzo,omitempty"`
+	GracePeriodDays int32 `protobuf:"varint,1,opt,name=grace_period_days,json=gracePeriodDays,proto3" json:"grace_period_days,omitempty"`
 }
 
-func (x *VPNStatusResponse) Reset() {
-	*x = VPNStatusResponse{}
+func (x *VPNRotateCARequest) Reset() {
+	*x = VPNRotateCARequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_vpn_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -391,13 +805,13 @@ func (x *VPNStatusResponse) Reset() {
 	}
 }
 
-func (x *VPNStatusResponse) String() string {
+func (x *VPNRotateCARequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*VPNStatusResponse) ProtoMessage() {}
+func (*VPNRotateCARequest) ProtoMessage() {}
 
-func (x *VPNStatusResponse) ProtoReflect() protoreflect.Message {
+func (x *VPNRotateCARequest) ProtoReflect() protoreflect.Message {
 	mi := &file_vpn_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -406,110 +820,502 @@ func (x *VPNStatusResponse) ProtoReflect() protoreflect.Message {
 		}
 		return ms
 	}
-	return mi.MessageOf(x)
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VPNRotateCARequest.ProtoReflect.Descriptor instead.
+func (*VPNRotateCARequest) Descriptor() ([]byte, []int) {
+	return file_vpn_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *VPNRotateCARequest) GetGracePeriodDays() int32 {
+	if x != nil {
+		return x.GracePeriodDays
+	}
+	return 0
+}
+
+type VPNRotateTLSCryptKeyRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *VPNRotateTLSCryptKeyRequest) Reset() {
+	*x = VPNRotateTLSCryptKeyRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vpn_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VPNRotateTLSCryptKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VPNRotateTLSCryptKeyRequest) ProtoMessage() {}
+
+func (x *VPNRotateTLSCryptKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vpn_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VPNRotateTLSCryptKeyRequest.ProtoReflect.Descriptor instead.
+func (*VPNRotateTLSCryptKeyRequest) Descriptor() ([]byte, []int) {
+	return file_vpn_proto_rawDescGZIP(), []int{5}
+}
+
+type VPNTakeCAOfflineRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	KeyFile    string `protobuf:"bytes,1,opt,name=key_file,json=keyFile,proto3" json:"key_file,omitempty"`
+	Passphrase string `protobuf:"bytes,2,opt,name=passphrase,proto3" json:"passphrase,omitempty"`
+}
+
+func (x *VPNTakeCAOfflineRequest) Reset() {
+	*x = VPNTakeCAOfflineRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vpn_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VPNTakeCAOfflineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VPNTakeCAOfflineRequest) ProtoMessage() {}
+
+func (x *VPNTakeCAOfflineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vpn_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VPNTakeCAOfflineRequest.ProtoReflect.Descriptor instead.
+func (*VPNTakeCAOfflineRequest) Descriptor() ([]byte, []int) {
+	return file_vpn_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *VPNTakeCAOfflineRequest) GetKeyFile() string {
+	if x != nil {
+		return x.KeyFile
+	}
+	return ""
+}
+
+func (x *VPNTakeCAOfflineRequest) GetPassphrase() string {
+	if x != nil {
+		return x.Passphrase
+	}
+	return ""
+}
+
+type VPNVerifyUserPassRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CommonName string `protobuf:"bytes,1,opt,name=common_name,json=commonName,proto3" json:"common_name,omitempty"`
+	Username   string `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	Password   string `protobuf:"bytes,3,opt,name=password,proto3" json:"password,omitempty"`
+}
+
+func (x *VPNVerifyUserPassRequest) Reset() {
+	*x = VPNVerifyUserPassRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vpn_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VPNVerifyUserPassRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VPNVerifyUserPassRequest) ProtoMessage() {}
+
+func (x *VPNVerifyUserPassRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vpn_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VPNVerifyUserPassRequest.ProtoReflect.Descriptor instead.
+func (*VPNVerifyUserPassRequest) Descriptor() ([]byte, []int) {
+	return file_vpn_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *VPNVerifyUserPassRequest) GetCommonName() string {
+	if x != nil {
+		return x.CommonName
+	}
+	return ""
+}
+
+func (x *VPNVerifyUserPassRequest) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *VPNVerifyUserPassRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+type VPNStatusResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name                   string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	SerialNumber           string `protobuf:"bytes,2,opt,name=serial_number,json=serialNumber,proto3" json:"serial_number,omitempty"`
+	Hostname               string `protobuf:"bytes,3,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Port                   string `protobuf:"bytes,4,opt,name=port,proto3" json:"port,omitempty"`
+	Cert                   string `protobuf:"bytes,5,opt,name=cert,proto3" json:"cert,omitempty"`
+	CaCert                 string `protobuf:"bytes,6,opt,name=ca_cert,json=caCert,proto3" json:"ca_cert,omitempty"`
+	Net                    string `protobuf:"bytes,7,opt,name=net,proto3" json:"net,omitempty"`
+	Mask                   string `protobuf:"bytes,8,opt,name=mask,proto3" json:"mask,omitempty"`
+	CreatedAt              string `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Proto                  string `protobuf:"bytes,10,opt,name=proto,proto3" json:"proto,omitempty"`
+	Dns                    string `protobuf:"bytes,11,opt,name=dns,proto3" json:"dns,omitempty"`
+	ExpiresAt              string `protobuf:"bytes,12,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	CaExpiresAt            string `protobuf:"bytes,13,opt,name=ca_expires_at,json=caExpiresAt,proto3" json:"ca_expires_at,omitempty"`
+	UseLzo                 bool   `protobuf:"varint,14,opt,name=use_lzo,json=useLzo,proto3" json:"use_lzo,omitempty"`
+	CaValidityDays         int32  `protobuf:"varint,15,opt,name=ca_validity_days,json=caValidityDays,proto3" json:"ca_validity_days,omitempty"`
+	ServerCertValidityDays int32  `protobuf:"varint,16,opt,name=server_cert_validity_days,json=serverCertValidityDays,proto3" json:"server_cert_validity_days,omitempty"`
+	ClientCertValidityDays int32  `protobuf:"varint,17,opt,name=client_cert_validity_days,json=clientCertValidityDays,proto3" json:"client_cert_validity_days,omitempty"`
+	CertRenewWindowDays    int32  `protobuf:"varint,18,opt,name=cert_renew_window_days,json=certRenewWindowDays,proto3" json:"cert_renew_window_days,omitempty"`
+	CaRetiresAt            string `protobuf:"bytes,19,opt,name=ca_retires_at,json=caRetiresAt,proto3" json:"ca_retires_at,omitempty"`
+	KeyType                string `protobuf:"bytes,20,opt,name=key_type,json=keyType,proto3" json:"key_type,omitempty"`
+	CaOffline              bool   `protobuf:"varint,21,opt,name=ca_offline,json=caOffline,proto3" json:"ca_offline,omitempty"`
+	CaUnlocked             bool   `protobuf:"varint,22,opt,name=ca_unlocked,json=caUnlocked,proto3" json:"ca_unlocked,omitempty"`
+	TlsCrypt               string `protobuf:"bytes,23,opt,name=tls_crypt,json=tlsCrypt,proto3" json:"tls_crypt,omitempty"`
+	CryptoPreset           string `protobuf:"bytes,24,opt,name=crypto_preset,json=cryptoPreset,proto3" json:"crypto_preset,omitempty"`
+	DataCiphers            string `protobuf:"bytes,25,opt,name=data_ciphers,json=dataCiphers,proto3" json:"data_ciphers,omitempty"`
+	DataCiphersFallback    string `protobuf:"bytes,26,opt,name=data_ciphers_fallback,json=dataCiphersFallback,proto3" json:"data_ciphers_fallback,omitempty"`
+	AuthDigest             string `protobuf:"bytes,27,opt,name=auth_digest,json=authDigest,proto3" json:"auth_digest,omitempty"`
+	TlsVersionMin          string `protobuf:"bytes,28,opt,name=tls_version_min,json=tlsVersionMin,proto3" json:"tls_version_min,omitempty"`
+	TlsCipher              string `protobuf:"bytes,29,opt,name=tls_cipher,json=tlsCipher,proto3" json:"tls_cipher,omitempty"`
+	RemoteCertTls          bool   `protobuf:"varint,30,opt,name=remote_cert_tls,json=remoteCertTls,proto3" json:"remote_cert_tls,omitempty"`
+	EcdhOnly               bool   `protobuf:"varint,31,opt,name=ecdh_only,json=ecdhOnly,proto3" json:"ecdh_only,omitempty"`
+	DhParams               string `protobuf:"bytes,32,opt,name=dh_params,json=dhParams,proto3" json:"dh_params,omitempty"`
+	AuthUserPass           bool   `protobuf:"varint,33,opt,name=auth_user_pass,json=authUserPass,proto3" json:"auth_user_pass,omitempty"`
+	ProcessState           string `protobuf:"bytes,34,opt,name=process_state,json=processState,proto3" json:"process_state,omitempty"`
+	ProcessRestarts        uint64 `protobuf:"varint,35,opt,name=process_restarts,json=processRestarts,proto3" json:"process_restarts,omitempty"`
+	ProcessLastExit        string `protobuf:"bytes,36,opt,name=process_last_exit,json=processLastExit,proto3" json:"process_last_exit,omitempty"` // Empty if the process never exited unexpectedly.
+}
+
+func (x *VPNStatusResponse) Reset() {
+	*x = VPNStatusResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vpn_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VPNStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VPNStatusResponse) ProtoMessage() {}
+
+func (x *VPNStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_vpn_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VPNStatusResponse.ProtoReflect.Descriptor instead.
+func (*VPNStatusResponse) Descriptor() ([]byte, []int) {
+	return file_vpn_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *VPNStatusResponse) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *VPNStatusResponse) GetSerialNumber() string {
+	if x != nil {
+		return x.SerialNumber
+	}
+	return ""
+}
+
+func (x *VPNStatusResponse) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *VPNStatusResponse) GetPort() string {
+	if x != nil {
+		return x.Port
+	}
+	return ""
+}
+
+func (x *VPNStatusResponse) GetCert() string {
+	if x != nil {
+		return x.Cert
+	}
+	return ""
+}
+
+func (x *VPNStatusResponse) GetCaCert() string {
+	if x != nil {
+		return x.CaCert
+	}
+	return ""
+}
+
+func (x *VPNStatusResponse) GetNet() string {
+	if x != nil {
+		return x.Net
+	}
+	return ""
+}
+
+func (x *VPNStatusResponse) GetMask() string {
+	if x != nil {
+		return x.Mask
+	}
+	return ""
+}
+
+func (x *VPNStatusResponse) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *VPNStatusResponse) GetProto() string {
+	if x != nil {
+		return x.Proto
+	}
+	return ""
+}
+
+func (x *VPNStatusResponse) GetDns() string {
+	if x != nil {
+		return x.Dns
+	}
+	return ""
+}
+
+func (x *VPNStatusResponse) GetExpiresAt() string {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return ""
+}
+
+func (x *VPNStatusResponse) GetCaExpiresAt() string {
+	if x != nil {
+		return x.CaExpiresAt
+	}
+	return ""
+}
+
+func (x *VPNStatusResponse) GetUseLzo() bool {
+	if x != nil {
+		return x.UseLzo
+	}
+	return false
+}
+
+func (x *VPNStatusResponse) GetCaValidityDays() int32 {
+	if x != nil {
+		return x.CaValidityDays
+	}
+	return 0
+}
+
+func (x *VPNStatusResponse) GetServerCertValidityDays() int32 {
+	if x != nil {
+		return x.ServerCertValidityDays
+	}
+	return 0
+}
+
+func (x *VPNStatusResponse) GetClientCertValidityDays() int32 {
+	if x != nil {
+		return x.ClientCertValidityDays
+	}
+	return 0
+}
+
+func (x *VPNStatusResponse) GetCertRenewWindowDays() int32 {
+	if x != nil {
+		return x.CertRenewWindowDays
+	}
+	return 0
+}
+
+func (x *VPNStatusResponse) GetCaRetiresAt() string {
+	if x != nil {
+		return x.CaRetiresAt
+	}
+	return ""
+}
+
+func (x *VPNStatusResponse) GetKeyType() string {
+	if x != nil {
+		return x.KeyType
+	}
+	return ""
 }
 
-// Deprecated: Use VPNStatusResponse.ProtoReflect.Descriptor instead.
-func (*VPNStatusResponse) Descriptor() ([]byte, []int) {
-	return file_vpn_proto_rawDescGZIP(), []int{4}
+func (x *VPNStatusResponse) GetCaOffline() bool {
+	if x != nil {
+		return x.CaOffline
+	}
+	return false
 }
 
-func (x *VPNStatusResponse) GetName() string {
+func (x *VPNStatusResponse) GetCaUnlocked() bool {
 	if x != nil {
-		return x.Name
+		return x.CaUnlocked
 	}
-	return ""
+	return false
 }
 
-func (x *VPNStatusResponse) GetSerialNumber() string {
+func (x *VPNStatusResponse) GetTlsCrypt() string {
 	if x != nil {
-		return x.SerialNumber
+		return x.TlsCrypt
 	}
 	return ""
 }
 
-func (x *VPNStatusResponse) GetHostname() string {
+func (x *VPNStatusResponse) GetCryptoPreset() string {
 	if x != nil {
-		return x.Hostname
+		return x.CryptoPreset
 	}
 	return ""
 }
 
-func (x *VPNStatusResponse) GetPort() string {
+func (x *VPNStatusResponse) GetDataCiphers() string {
 	if x != nil {
-		return x.Port
+		return x.DataCiphers
 	}
 	return ""
 }
 
-func (x *VPNStatusResponse) GetCert() string {
+func (x *VPNStatusResponse) GetDataCiphersFallback() string {
 	if x != nil {
-		return x.Cert
+		return x.DataCiphersFallback
 	}
 	return ""
 }
 
-func (x *VPNStatusResponse) GetCaCert() string {
+func (x *VPNStatusResponse) GetAuthDigest() string {
 	if x != nil {
-		return x.CaCert
+		return x.AuthDigest
 	}
 	return ""
 }
 
-func (x *VPNStatusResponse) GetNet() string {
+func (x *VPNStatusResponse) GetTlsVersionMin() string {
 	if x != nil {
-		return x.Net
+		return x.TlsVersionMin
 	}
 	return ""
 }
 
-func (x *VPNStatusResponse) GetMask() string {
+func (x *VPNStatusResponse) GetTlsCipher() string {
 	if x != nil {
-		return x.Mask
+		return x.TlsCipher
 	}
 	return ""
 }
 
-func (x *VPNStatusResponse) GetCreatedAt() string {
+func (x *VPNStatusResponse) GetRemoteCertTls() bool {
 	if x != nil {
-		return x.CreatedAt
+		return x.RemoteCertTls
 	}
-	return ""
+	return false
 }
 
-func (x *VPNStatusResponse) GetProto() string {
+func (x *VPNStatusResponse) GetEcdhOnly() bool {
 	if x != nil {
-		return x.Proto
+		return x.EcdhOnly
 	}
-	return ""
+	return false
 }
 
-func (x *VPNStatusResponse) GetDns() string {
+func (x *VPNStatusResponse) GetDhParams() string {
 	if x != nil {
-		return x.Dns
+		return x.DhParams
 	}
 	return ""
 }
 
-func (x *VPNStatusResponse) GetExpiresAt() string {
+func (x *VPNStatusResponse) GetAuthUserPass() bool {
 	if x != nil {
-		return x.ExpiresAt
+		return x.AuthUserPass
 	}
-	return ""
+	return false
 }
 
-func (x *VPNStatusResponse) GetCaExpiresAt() string {
+func (x *VPNStatusResponse) GetProcessState() string {
 	if x != nil {
-		return x.CaExpiresAt
+		return x.ProcessState
 	}
 	return ""
 }
 
-func (x *VPNStatusResponse) GetUseLzo() bool {
+func (x *VPNStatusResponse) GetProcessRestarts() uint64 {
 	if x != nil {
-		return x.UseLzo
+		return x.ProcessRestarts
 	}
-	return false
+	return 0
+}
+
+func (x *VPNStatusResponse) GetProcessLastExit() string {
+	if x != nil {
+		return x.ProcessLastExit
+	}
+	return ""
 }
 
 type VPNInitResponse struct {
@@ -521,7 +1327,7 @@ type VPNInitResponse struct {
 func (x *VPNInitResponse) Reset() {
 	*x = VPNInitResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_vpn_proto_msgTypes[5]
+		mi := &file_vpn_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -534,7 +1340,7 @@ func (x *VPNInitResponse) String() string {
 func (*VPNInitResponse) ProtoMessage() {}
 
 func (x *VPNInitResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_vpn_proto_msgTypes[5]
+	mi := &file_vpn_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -547,7 +1353,7 @@ func (x *VPNInitResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use VPNInitResponse.ProtoReflect.Descriptor instead.
 func (*VPNInitResponse) Descriptor() ([]byte, []int) {
-	return file_vpn_proto_rawDescGZIP(), []int{5}
+	return file_vpn_proto_rawDescGZIP(), []int{9}
 }
 
 type VPNUpdateResponse struct {
@@ -559,7 +1365,7 @@ type VPNUpdateResponse struct {
 func (x *VPNUpdateResponse) Reset() {
 	*x = VPNUpdateResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_vpn_proto_msgTypes[6]
+		mi := &file_vpn_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -572,7 +1378,7 @@ func (x *VPNUpdateResponse) String() string {
 func (*VPNUpdateResponse) ProtoMessage() {}
 
 func (x *VPNUpdateResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_vpn_proto_msgTypes[6]
+	mi := &file_vpn_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -585,7 +1391,7 @@ func (x *VPNUpdateResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use VPNUpdateResponse.ProtoReflect.Descriptor instead.
 func (*VPNUpdateResponse) Descriptor() ([]byte, []int) {
-	return file_vpn_proto_rawDescGZIP(), []int{6}
+	return file_vpn_proto_rawDescGZIP(), []int{10}
 }
 
 type VPNRestartResponse struct {
@@ -597,7 +1403,7 @@ type VPNRestartResponse struct {
 func (x *VPNRestartResponse) Reset() {
 	*x = VPNRestartResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_vpn_proto_msgTypes[7]
+		mi := &file_vpn_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -610,7 +1416,7 @@ func (x *VPNRestartResponse) String() string {
 func (*VPNRestartResponse) ProtoMessage() {}
 
 func (x *VPNRestartResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_vpn_proto_msgTypes[7]
+	mi := &file_vpn_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -623,7 +1429,159 @@ func (x *VPNRestartResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use VPNRestartResponse.ProtoReflect.Descriptor instead.
 func (*VPNRestartResponse) Descriptor() ([]byte, []int) {
-	return file_vpn_proto_rawDescGZIP(), []int{7}
+	return file_vpn_proto_rawDescGZIP(), []int{11}
+}
+
+type VPNRotateCAResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *VPNRotateCAResponse) Reset() {
+	*x = VPNRotateCAResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vpn_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VPNRotateCAResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VPNRotateCAResponse) ProtoMessage() {}
+
+func (x *VPNRotateCAResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_vpn_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VPNRotateCAResponse.ProtoReflect.Descriptor instead.
+func (*VPNRotateCAResponse) Descriptor() ([]byte, []int) {
+	return file_vpn_proto_rawDescGZIP(), []int{12}
+}
+
+type VPNRotateTLSCryptKeyResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *VPNRotateTLSCryptKeyResponse) Reset() {
+	*x = VPNRotateTLSCryptKeyResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vpn_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VPNRotateTLSCryptKeyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VPNRotateTLSCryptKeyResponse) ProtoMessage() {}
+
+func (x *VPNRotateTLSCryptKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_vpn_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VPNRotateTLSCryptKeyResponse.ProtoReflect.Descriptor instead.
+func (*VPNRotateTLSCryptKeyResponse) Descriptor() ([]byte, []int) {
+	return file_vpn_proto_rawDescGZIP(), []int{13}
+}
+
+type VPNTakeCAOfflineResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *VPNTakeCAOfflineResponse) Reset() {
+	*x = VPNTakeCAOfflineResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vpn_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VPNTakeCAOfflineResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VPNTakeCAOfflineResponse) ProtoMessage() {}
+
+func (x *VPNTakeCAOfflineResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_vpn_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VPNTakeCAOfflineResponse.ProtoReflect.Descriptor instead.
+func (*VPNTakeCAOfflineResponse) Descriptor() ([]byte, []int) {
+	return file_vpn_proto_rawDescGZIP(), []int{14}
+}
+
+type VPNVerifyUserPassResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *VPNVerifyUserPassResponse) Reset() {
+	*x = VPNVerifyUserPassResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vpn_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VPNVerifyUserPassResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VPNVerifyUserPassResponse) ProtoMessage() {}
+
+func (x *VPNVerifyUserPassResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_vpn_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VPNVerifyUserPassResponse.ProtoReflect.Descriptor instead.
+func (*VPNVerifyUserPassResponse) Descriptor() ([]byte, []int) {
+	return file_vpn_proto_rawDescGZIP(), []int{15}
 }
 
 var File_vpn_proto protoreflect.FileDescriptor
@@ -633,7 +1591,7 @@ var file_vpn_proto_rawDesc = []byte{
 	0x1c, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x61, 0x6e, 0x6e, 0x6f,
 	0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x12, 0x0a,
 	0x10, 0x56, 0x50, 0x4e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x22, 0x8b, 0x02, 0x0a, 0x0e, 0x56, 0x50, 0x4e, 0x49, 0x6e, 0x69, 0x74, 0x52, 0x65, 0x71,
+	0x74, 0x22, 0xef, 0x07, 0x0a, 0x0e, 0x56, 0x50, 0x4e, 0x49, 0x6e, 0x69, 0x74, 0x52, 0x65, 0x71,
 	0x75, 0x65, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65,
 	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65,
 	0x12, 0x12, 0x0a, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
@@ -649,72 +1607,288 @@ var file_vpn_proto_rawDesc = []byte{
 	0x70, 0x61, 0x6c, 0x69, 0x76, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x07,
 	0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x6c, 0x69, 0x76, 0x65, 0x54,
 	0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x75, 0x73, 0x65, 0x5f, 0x6c, 0x7a,
-	0x6f, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x75, 0x73, 0x65, 0x4c, 0x7a, 0x6f, 0x22,
-	0x6a, 0x0a, 0x10, 0x56, 0x50, 0x4e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x69, 0x70, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x69, 0x70, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x10,
-	0x0a, 0x03, 0x64, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x64, 0x6e, 0x73,
-	0x12, 0x29, 0x0a, 0x08, 0x6c, 0x7a, 0x6f, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x18, 0x03, 0x20, 0x01,
-	0x28, 0x0e, 0x32, 0x0e, 0x2e, 0x70, 0x62, 0x2e, 0x56, 0x50, 0x4e, 0x4c, 0x5a, 0x4f, 0x50, 0x72,
-	0x65, 0x66, 0x52, 0x07, 0x6c, 0x7a, 0x6f, 0x50, 0x72, 0x65, 0x66, 0x22, 0x13, 0x0a, 0x11, 0x56,
-	0x50, 0x4e, 0x52, 0x65, 0x73, 0x74, 0x61, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x22, 0xf2, 0x02, 0x0a, 0x11, 0x56, 0x50, 0x4e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x73, 0x65,
-	0x72, 0x69, 0x61, 0x6c, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x0c, 0x73, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12,
-	0x1a, 0x0a, 0x08, 0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x08, 0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x70,
-	0x6f, 0x72, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x12,
-	0x12, 0x0a, 0x04, 0x63, 0x65, 0x72, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63,
-	0x65, 0x72, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x63, 0x61, 0x5f, 0x63, 0x65, 0x72, 0x74, 0x18, 0x06,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63, 0x61, 0x43, 0x65, 0x72, 0x74, 0x12, 0x10, 0x0a, 0x03,
-	0x6e, 0x65, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6e, 0x65, 0x74, 0x12, 0x12,
-	0x0a, 0x04, 0x6d, 0x61, 0x73, 0x6b, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6d, 0x61,
-	0x73, 0x6b, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74,
-	0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41,
-	0x74, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x05, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x10, 0x0a, 0x03, 0x64, 0x6e, 0x73, 0x18, 0x0b,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x64, 0x6e, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x65, 0x78, 0x70,
-	0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x65,
-	0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x12, 0x22, 0x0a, 0x0d, 0x63, 0x61, 0x5f, 0x65,
-	0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x0b, 0x63, 0x61, 0x45, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x12, 0x17, 0x0a, 0x07,
-	0x75, 0x73, 0x65, 0x5f, 0x6c, 0x7a, 0x6f, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x75,
-	0x73, 0x65, 0x4c, 0x7a, 0x6f, 0x22, 0x11, 0x0a, 0x0f, 0x56, 0x50, 0x4e, 0x49, 0x6e, 0x69, 0x74,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x13, 0x0a, 0x11, 0x56, 0x50, 0x4e, 0x55,
-	0x70, 0x64, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x14, 0x0a,
-	0x12, 0x56, 0x50, 0x4e, 0x52, 0x65, 0x73, 0x74, 0x61, 0x72, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x73, 0x65, 0x2a, 0x28, 0x0a, 0x08, 0x56, 0x50, 0x4e, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x12,
-	0x0a, 0x0a, 0x06, 0x4e, 0x4f, 0x50, 0x52, 0x45, 0x46, 0x10, 0x00, 0x12, 0x07, 0x0a, 0x03, 0x55,
-	0x44, 0x50, 0x10, 0x01, 0x12, 0x07, 0x0a, 0x03, 0x54, 0x43, 0x50, 0x10, 0x02, 0x2a, 0x49, 0x0a,
-	0x0a, 0x56, 0x50, 0x4e, 0x4c, 0x5a, 0x4f, 0x50, 0x72, 0x65, 0x66, 0x12, 0x12, 0x0a, 0x0e, 0x55,
-	0x53, 0x45, 0x5f, 0x4c, 0x5a, 0x4f, 0x5f, 0x4e, 0x4f, 0x50, 0x52, 0x45, 0x46, 0x10, 0x00, 0x12,
-	0x12, 0x0a, 0x0e, 0x55, 0x53, 0x45, 0x5f, 0x4c, 0x5a, 0x4f, 0x5f, 0x45, 0x4e, 0x41, 0x42, 0x4c,
-	0x45, 0x10, 0x01, 0x12, 0x13, 0x0a, 0x0f, 0x55, 0x53, 0x45, 0x5f, 0x4c, 0x5a, 0x4f, 0x5f, 0x44,
-	0x49, 0x53, 0x41, 0x42, 0x4c, 0x45, 0x10, 0x03, 0x32, 0xda, 0x02, 0x0a, 0x0a, 0x56, 0x50, 0x4e,
-	0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x51, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75,
-	0x73, 0x12, 0x14, 0x2e, 0x70, 0x62, 0x2e, 0x56, 0x50, 0x4e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x6f, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x75, 0x73, 0x65, 0x4c, 0x7a, 0x6f, 0x12,
+	0x28, 0x0a, 0x10, 0x63, 0x61, 0x5f, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x69, 0x74, 0x79, 0x5f, 0x64,
+	0x61, 0x79, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x63, 0x61, 0x56, 0x61, 0x6c,
+	0x69, 0x64, 0x69, 0x74, 0x79, 0x44, 0x61, 0x79, 0x73, 0x12, 0x39, 0x0a, 0x19, 0x73, 0x65, 0x72,
+	0x76, 0x65, 0x72, 0x5f, 0x63, 0x65, 0x72, 0x74, 0x5f, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x69, 0x74,
+	0x79, 0x5f, 0x64, 0x61, 0x79, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x05, 0x52, 0x16, 0x73, 0x65,
+	0x72, 0x76, 0x65, 0x72, 0x43, 0x65, 0x72, 0x74, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x69, 0x74, 0x79,
+	0x44, 0x61, 0x79, 0x73, 0x12, 0x39, 0x0a, 0x19, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x63,
+	0x65, 0x72, 0x74, 0x5f, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x69, 0x74, 0x79, 0x5f, 0x64, 0x61, 0x79,
+	0x73, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x05, 0x52, 0x16, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x43,
+	0x65, 0x72, 0x74, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x69, 0x74, 0x79, 0x44, 0x61, 0x79, 0x73, 0x12,
+	0x33, 0x0a, 0x16, 0x63, 0x65, 0x72, 0x74, 0x5f, 0x72, 0x65, 0x6e, 0x65, 0x77, 0x5f, 0x77, 0x69,
+	0x6e, 0x64, 0x6f, 0x77, 0x5f, 0x64, 0x61, 0x79, 0x73, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x13, 0x63, 0x65, 0x72, 0x74, 0x52, 0x65, 0x6e, 0x65, 0x77, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77,
+	0x44, 0x61, 0x79, 0x73, 0x12, 0x19, 0x0a, 0x08, 0x6b, 0x65, 0x79, 0x5f, 0x74, 0x79, 0x70, 0x65,
+	0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6b, 0x65, 0x79, 0x54, 0x79, 0x70, 0x65, 0x12,
+	0x17, 0x0a, 0x07, 0x63, 0x61, 0x5f, 0x63, 0x65, 0x72, 0x74, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x63, 0x61, 0x43, 0x65, 0x72, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x63, 0x61, 0x5f, 0x6b,
+	0x65, 0x79, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x63, 0x61, 0x4b, 0x65, 0x79, 0x12,
+	0x1b, 0x0a, 0x09, 0x74, 0x6c, 0x73, 0x5f, 0x63, 0x72, 0x79, 0x70, 0x74, 0x18, 0x10, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x74, 0x6c, 0x73, 0x43, 0x72, 0x79, 0x70, 0x74, 0x12, 0x23, 0x0a, 0x0d,
+	0x63, 0x72, 0x79, 0x70, 0x74, 0x6f, 0x5f, 0x70, 0x72, 0x65, 0x73, 0x65, 0x74, 0x18, 0x11, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0c, 0x63, 0x72, 0x79, 0x70, 0x74, 0x6f, 0x50, 0x72, 0x65, 0x73, 0x65,
+	0x74, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x63, 0x69, 0x70, 0x68, 0x65, 0x72,
+	0x73, 0x18, 0x12, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x61, 0x74, 0x61, 0x43, 0x69, 0x70,
+	0x68, 0x65, 0x72, 0x73, 0x12, 0x32, 0x0a, 0x15, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x63, 0x69, 0x70,
+	0x68, 0x65, 0x72, 0x73, 0x5f, 0x66, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x18, 0x13, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x13, 0x64, 0x61, 0x74, 0x61, 0x43, 0x69, 0x70, 0x68, 0x65, 0x72, 0x73,
+	0x46, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x12, 0x1f, 0x0a, 0x0b, 0x61, 0x75, 0x74, 0x68,
+	0x5f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x14, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x61,
+	0x75, 0x74, 0x68, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x26, 0x0a, 0x0f, 0x74, 0x6c, 0x73,
+	0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x69, 0x6e, 0x18, 0x15, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0d, 0x74, 0x6c, 0x73, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x4d, 0x69,
+	0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x74, 0x6c, 0x73, 0x5f, 0x63, 0x69, 0x70, 0x68, 0x65, 0x72, 0x18,
+	0x16, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x74, 0x6c, 0x73, 0x43, 0x69, 0x70, 0x68, 0x65, 0x72,
+	0x12, 0x49, 0x0a, 0x14, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x63, 0x65, 0x72, 0x74, 0x5f,
+	0x74, 0x6c, 0x73, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x18, 0x17, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x18,
+	0x2e, 0x70, 0x62, 0x2e, 0x56, 0x50, 0x4e, 0x52, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x43, 0x65, 0x72,
+	0x74, 0x54, 0x4c, 0x53, 0x50, 0x72, 0x65, 0x66, 0x52, 0x11, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65,
+	0x43, 0x65, 0x72, 0x74, 0x54, 0x6c, 0x73, 0x50, 0x72, 0x65, 0x66, 0x12, 0x39, 0x0a, 0x0e, 0x65,
+	0x63, 0x64, 0x68, 0x5f, 0x6f, 0x6e, 0x6c, 0x79, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x18, 0x18, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x13, 0x2e, 0x70, 0x62, 0x2e, 0x56, 0x50, 0x4e, 0x45, 0x43, 0x44, 0x48,
+	0x4f, 0x6e, 0x6c, 0x79, 0x50, 0x72, 0x65, 0x66, 0x52, 0x0c, 0x65, 0x63, 0x64, 0x68, 0x4f, 0x6e,
+	0x6c, 0x79, 0x50, 0x72, 0x65, 0x66, 0x12, 0x24, 0x0a, 0x0e, 0x61, 0x75, 0x74, 0x68, 0x5f, 0x75,
+	0x73, 0x65, 0x72, 0x5f, 0x70, 0x61, 0x73, 0x73, 0x18, 0x19, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c,
+	0x61, 0x75, 0x74, 0x68, 0x55, 0x73, 0x65, 0x72, 0x50, 0x61, 0x73, 0x73, 0x12, 0x15, 0x0a, 0x06,
+	0x63, 0x61, 0x5f, 0x63, 0x72, 0x6c, 0x18, 0x1a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x63, 0x61,
+	0x43, 0x72, 0x6c, 0x22, 0xe3, 0x06, 0x0a, 0x10, 0x56, 0x50, 0x4e, 0x55, 0x70, 0x64, 0x61, 0x74,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x69, 0x70, 0x5f, 0x62,
+	0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x69, 0x70, 0x42, 0x6c,
+	0x6f, 0x63, 0x6b, 0x12, 0x10, 0x0a, 0x03, 0x64, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x64, 0x6e, 0x73, 0x12, 0x29, 0x0a, 0x08, 0x6c, 0x7a, 0x6f, 0x5f, 0x70, 0x72, 0x65,
+	0x66, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0e, 0x2e, 0x70, 0x62, 0x2e, 0x56, 0x50, 0x4e,
+	0x4c, 0x5a, 0x4f, 0x50, 0x72, 0x65, 0x66, 0x52, 0x07, 0x6c, 0x7a, 0x6f, 0x50, 0x72, 0x65, 0x66,
+	0x12, 0x28, 0x0a, 0x10, 0x63, 0x61, 0x5f, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x69, 0x74, 0x79, 0x5f,
+	0x64, 0x61, 0x79, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x63, 0x61, 0x56, 0x61,
+	0x6c, 0x69, 0x64, 0x69, 0x74, 0x79, 0x44, 0x61, 0x79, 0x73, 0x12, 0x39, 0x0a, 0x19, 0x73, 0x65,
+	0x72, 0x76, 0x65, 0x72, 0x5f, 0x63, 0x65, 0x72, 0x74, 0x5f, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x69,
+	0x74, 0x79, 0x5f, 0x64, 0x61, 0x79, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x16, 0x73,
+	0x65, 0x72, 0x76, 0x65, 0x72, 0x43, 0x65, 0x72, 0x74, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x69, 0x74,
+	0x79, 0x44, 0x61, 0x79, 0x73, 0x12, 0x39, 0x0a, 0x19, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f,
+	0x63, 0x65, 0x72, 0x74, 0x5f, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x69, 0x74, 0x79, 0x5f, 0x64, 0x61,
+	0x79, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x16, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74,
+	0x43, 0x65, 0x72, 0x74, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x69, 0x74, 0x79, 0x44, 0x61, 0x79, 0x73,
+	0x12, 0x33, 0x0a, 0x16, 0x63, 0x65, 0x72, 0x74, 0x5f, 0x72, 0x65, 0x6e, 0x65, 0x77, 0x5f, 0x77,
+	0x69, 0x6e, 0x64, 0x6f, 0x77, 0x5f, 0x64, 0x61, 0x79, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x13, 0x63, 0x65, 0x72, 0x74, 0x52, 0x65, 0x6e, 0x65, 0x77, 0x57, 0x69, 0x6e, 0x64, 0x6f,
+	0x77, 0x44, 0x61, 0x79, 0x73, 0x12, 0x19, 0x0a, 0x08, 0x6b, 0x65, 0x79, 0x5f, 0x74, 0x79, 0x70,
+	0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6b, 0x65, 0x79, 0x54, 0x79, 0x70, 0x65,
+	0x12, 0x1b, 0x0a, 0x09, 0x74, 0x6c, 0x73, 0x5f, 0x63, 0x72, 0x79, 0x70, 0x74, 0x18, 0x09, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x6c, 0x73, 0x43, 0x72, 0x79, 0x70, 0x74, 0x12, 0x23, 0x0a,
+	0x0d, 0x63, 0x72, 0x79, 0x70, 0x74, 0x6f, 0x5f, 0x70, 0x72, 0x65, 0x73, 0x65, 0x74, 0x18, 0x0a,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x63, 0x72, 0x79, 0x70, 0x74, 0x6f, 0x50, 0x72, 0x65, 0x73,
+	0x65, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x63, 0x69, 0x70, 0x68, 0x65,
+	0x72, 0x73, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x61, 0x74, 0x61, 0x43, 0x69,
+	0x70, 0x68, 0x65, 0x72, 0x73, 0x12, 0x32, 0x0a, 0x15, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x63, 0x69,
+	0x70, 0x68, 0x65, 0x72, 0x73, 0x5f, 0x66, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x18, 0x0c,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x13, 0x64, 0x61, 0x74, 0x61, 0x43, 0x69, 0x70, 0x68, 0x65, 0x72,
+	0x73, 0x46, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x12, 0x1f, 0x0a, 0x0b, 0x61, 0x75, 0x74,
+	0x68, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a,
+	0x61, 0x75, 0x74, 0x68, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x26, 0x0a, 0x0f, 0x74, 0x6c,
+	0x73, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x69, 0x6e, 0x18, 0x0e, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0d, 0x74, 0x6c, 0x73, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x4d,
+	0x69, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x74, 0x6c, 0x73, 0x5f, 0x63, 0x69, 0x70, 0x68, 0x65, 0x72,
+	0x18, 0x0f, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x74, 0x6c, 0x73, 0x43, 0x69, 0x70, 0x68, 0x65,
+	0x72, 0x12, 0x49, 0x0a, 0x14, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x63, 0x65, 0x72, 0x74,
+	0x5f, 0x74, 0x6c, 0x73, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x18, 0x10, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x18, 0x2e, 0x70, 0x62, 0x2e, 0x56, 0x50, 0x4e, 0x52, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x43, 0x65,
+	0x72, 0x74, 0x54, 0x4c, 0x53, 0x50, 0x72, 0x65, 0x66, 0x52, 0x11, 0x72, 0x65, 0x6d, 0x6f, 0x74,
+	0x65, 0x43, 0x65, 0x72, 0x74, 0x54, 0x6c, 0x73, 0x50, 0x72, 0x65, 0x66, 0x12, 0x39, 0x0a, 0x0e,
+	0x65, 0x63, 0x64, 0x68, 0x5f, 0x6f, 0x6e, 0x6c, 0x79, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x18, 0x11,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x13, 0x2e, 0x70, 0x62, 0x2e, 0x56, 0x50, 0x4e, 0x45, 0x43, 0x44,
+	0x48, 0x4f, 0x6e, 0x6c, 0x79, 0x50, 0x72, 0x65, 0x66, 0x52, 0x0c, 0x65, 0x63, 0x64, 0x68, 0x4f,
+	0x6e, 0x6c, 0x79, 0x50, 0x72, 0x65, 0x66, 0x12, 0x46, 0x0a, 0x13, 0x61, 0x75, 0x74, 0x68, 0x5f,
+	0x75, 0x73, 0x65, 0x72, 0x5f, 0x70, 0x61, 0x73, 0x73, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x18, 0x12,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x70, 0x62, 0x2e, 0x56, 0x50, 0x4e, 0x41, 0x75, 0x74,
+	0x68, 0x55, 0x73, 0x65, 0x72, 0x50, 0x61, 0x73, 0x73, 0x50, 0x72, 0x65, 0x66, 0x52, 0x10, 0x61,
+	0x75, 0x74, 0x68, 0x55, 0x73, 0x65, 0x72, 0x50, 0x61, 0x73, 0x73, 0x50, 0x72, 0x65, 0x66, 0x12,
+	0x15, 0x0a, 0x06, 0x63, 0x61, 0x5f, 0x63, 0x72, 0x6c, 0x18, 0x13, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x63, 0x61, 0x43, 0x72, 0x6c, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x72, 0x79, 0x70, 0x74, 0x6f,
+	0x5f, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x18, 0x14, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x63, 0x72,
+	0x79, 0x70, 0x74, 0x6f, 0x46, 0x6f, 0x72, 0x63, 0x65, 0x22, 0x13, 0x0a, 0x11, 0x56, 0x50, 0x4e,
+	0x52, 0x65, 0x73, 0x74, 0x61, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x40,
+	0x0a, 0x12, 0x56, 0x50, 0x4e, 0x52, 0x6f, 0x74, 0x61, 0x74, 0x65, 0x43, 0x41, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x2a, 0x0a, 0x11, 0x67, 0x72, 0x61, 0x63, 0x65, 0x5f, 0x70, 0x65,
+	0x72, 0x69, 0x6f, 0x64, 0x5f, 0x64, 0x61, 0x79, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x0f, 0x67, 0x72, 0x61, 0x63, 0x65, 0x50, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x44, 0x61, 0x79, 0x73,
+	0x22, 0x1d, 0x0a, 0x1b, 0x56, 0x50, 0x4e, 0x52, 0x6f, 0x74, 0x61, 0x74, 0x65, 0x54, 0x4c, 0x53,
+	0x43, 0x72, 0x79, 0x70, 0x74, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22,
+	0x54, 0x0a, 0x17, 0x56, 0x50, 0x4e, 0x54, 0x61, 0x6b, 0x65, 0x43, 0x41, 0x4f, 0x66, 0x66, 0x6c,
+	0x69, 0x6e, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6b, 0x65,
+	0x79, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6b, 0x65,
+	0x79, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x70, 0x61, 0x73, 0x73, 0x70, 0x68, 0x72,
+	0x61, 0x73, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x70, 0x61, 0x73, 0x73, 0x70,
+	0x68, 0x72, 0x61, 0x73, 0x65, 0x22, 0x73, 0x0a, 0x18, 0x56, 0x50, 0x4e, 0x56, 0x65, 0x72, 0x69,
+	0x66, 0x79, 0x55, 0x73, 0x65, 0x72, 0x50, 0x61, 0x73, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x5f, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x4e, 0x61,
+	0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1a,
+	0x0a, 0x08, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x22, 0xcb, 0x09, 0x0a, 0x11, 0x56,
+	0x50, 0x4e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x6e, 0x61, 0x6d, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x73, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x5f, 0x6e,
+	0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x73, 0x65, 0x72,
+	0x69, 0x61, 0x6c, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x1a, 0x0a, 0x08, 0x68, 0x6f, 0x73,
+	0x74, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x68, 0x6f, 0x73,
+	0x74, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x65, 0x72,
+	0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x65, 0x72, 0x74, 0x12, 0x17, 0x0a,
+	0x07, 0x63, 0x61, 0x5f, 0x63, 0x65, 0x72, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x63, 0x61, 0x43, 0x65, 0x72, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6e, 0x65, 0x74, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x6e, 0x65, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6d, 0x61, 0x73, 0x6b,
+	0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6d, 0x61, 0x73, 0x6b, 0x12, 0x1d, 0x0a, 0x0a,
+	0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x12, 0x10, 0x0a, 0x03, 0x64, 0x6e, 0x73, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x64, 0x6e, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61,
+	0x74, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73,
+	0x41, 0x74, 0x12, 0x22, 0x0a, 0x0d, 0x63, 0x61, 0x5f, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73,
+	0x5f, 0x61, 0x74, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x61, 0x45, 0x78, 0x70,
+	0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x75, 0x73, 0x65, 0x5f, 0x6c, 0x7a,
+	0x6f, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x75, 0x73, 0x65, 0x4c, 0x7a, 0x6f, 0x12,
+	0x28, 0x0a, 0x10, 0x63, 0x61, 0x5f, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x69, 0x74, 0x79, 0x5f, 0x64,
+	0x61, 0x79, 0x73, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x63, 0x61, 0x56, 0x61, 0x6c,
+	0x69, 0x64, 0x69, 0x74, 0x79, 0x44, 0x61, 0x79, 0x73, 0x12, 0x39, 0x0a, 0x19, 0x73, 0x65, 0x72,
+	0x76, 0x65, 0x72, 0x5f, 0x63, 0x65, 0x72, 0x74, 0x5f, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x69, 0x74,
+	0x79, 0x5f, 0x64, 0x61, 0x79, 0x73, 0x18, 0x10, 0x20, 0x01, 0x28, 0x05, 0x52, 0x16, 0x73, 0x65,
+	0x72, 0x76, 0x65, 0x72, 0x43, 0x65, 0x72, 0x74, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x69, 0x74, 0x79,
+	0x44, 0x61, 0x79, 0x73, 0x12, 0x39, 0x0a, 0x19, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x63,
+	0x65, 0x72, 0x74, 0x5f, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x69, 0x74, 0x79, 0x5f, 0x64, 0x61, 0x79,
+	0x73, 0x18, 0x11, 0x20, 0x01, 0x28, 0x05, 0x52, 0x16, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x43,
+	0x65, 0x72, 0x74, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x69, 0x74, 0x79, 0x44, 0x61, 0x79, 0x73, 0x12,
+	0x33, 0x0a, 0x16, 0x63, 0x65, 0x72, 0x74, 0x5f, 0x72, 0x65, 0x6e, 0x65, 0x77, 0x5f, 0x77, 0x69,
+	0x6e, 0x64, 0x6f, 0x77, 0x5f, 0x64, 0x61, 0x79, 0x73, 0x18, 0x12, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x13, 0x63, 0x65, 0x72, 0x74, 0x52, 0x65, 0x6e, 0x65, 0x77, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77,
+	0x44, 0x61, 0x79, 0x73, 0x12, 0x22, 0x0a, 0x0d, 0x63, 0x61, 0x5f, 0x72, 0x65, 0x74, 0x69, 0x72,
+	0x65, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x13, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x61, 0x52,
+	0x65, 0x74, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6b, 0x65, 0x79, 0x5f,
+	0x74, 0x79, 0x70, 0x65, 0x18, 0x14, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6b, 0x65, 0x79, 0x54,
+	0x79, 0x70, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x61, 0x5f, 0x6f, 0x66, 0x66, 0x6c, 0x69, 0x6e,
+	0x65, 0x18, 0x15, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x63, 0x61, 0x4f, 0x66, 0x66, 0x6c, 0x69,
+	0x6e, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x63, 0x61, 0x5f, 0x75, 0x6e, 0x6c, 0x6f, 0x63, 0x6b, 0x65,
+	0x64, 0x18, 0x16, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x63, 0x61, 0x55, 0x6e, 0x6c, 0x6f, 0x63,
+	0x6b, 0x65, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x6c, 0x73, 0x5f, 0x63, 0x72, 0x79, 0x70, 0x74,
+	0x18, 0x17, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x6c, 0x73, 0x43, 0x72, 0x79, 0x70, 0x74,
+	0x12, 0x23, 0x0a, 0x0d, 0x63, 0x72, 0x79, 0x70, 0x74, 0x6f, 0x5f, 0x70, 0x72, 0x65, 0x73, 0x65,
+	0x74, 0x18, 0x18, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x63, 0x72, 0x79, 0x70, 0x74, 0x6f, 0x50,
+	0x72, 0x65, 0x73, 0x65, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x63, 0x69,
+	0x70, 0x68, 0x65, 0x72, 0x73, 0x18, 0x19, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x61, 0x74,
+	0x61, 0x43, 0x69, 0x70, 0x68, 0x65, 0x72, 0x73, 0x12, 0x32, 0x0a, 0x15, 0x64, 0x61, 0x74, 0x61,
+	0x5f, 0x63, 0x69, 0x70, 0x68, 0x65, 0x72, 0x73, 0x5f, 0x66, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63,
+	0x6b, 0x18, 0x1a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x13, 0x64, 0x61, 0x74, 0x61, 0x43, 0x69, 0x70,
+	0x68, 0x65, 0x72, 0x73, 0x46, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x12, 0x1f, 0x0a, 0x0b,
+	0x61, 0x75, 0x74, 0x68, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x1b, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0a, 0x61, 0x75, 0x74, 0x68, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x26, 0x0a,
+	0x0f, 0x74, 0x6c, 0x73, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x69, 0x6e,
+	0x18, 0x1c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x74, 0x6c, 0x73, 0x56, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x4d, 0x69, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x74, 0x6c, 0x73, 0x5f, 0x63, 0x69, 0x70,
+	0x68, 0x65, 0x72, 0x18, 0x1d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x74, 0x6c, 0x73, 0x43, 0x69,
+	0x70, 0x68, 0x65, 0x72, 0x12, 0x26, 0x0a, 0x0f, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x63,
+	0x65, 0x72, 0x74, 0x5f, 0x74, 0x6c, 0x73, 0x18, 0x1e, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x72,
+	0x65, 0x6d, 0x6f, 0x74, 0x65, 0x43, 0x65, 0x72, 0x74, 0x54, 0x6c, 0x73, 0x12, 0x1b, 0x0a, 0x09,
+	0x65, 0x63, 0x64, 0x68, 0x5f, 0x6f, 0x6e, 0x6c, 0x79, 0x18, 0x1f, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x08, 0x65, 0x63, 0x64, 0x68, 0x4f, 0x6e, 0x6c, 0x79, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x68, 0x5f,
+	0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x20, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64, 0x68,
+	0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x24, 0x0a, 0x0e, 0x61, 0x75, 0x74, 0x68, 0x5f, 0x75,
+	0x73, 0x65, 0x72, 0x5f, 0x70, 0x61, 0x73, 0x73, 0x18, 0x21, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c,
+	0x61, 0x75, 0x74, 0x68, 0x55, 0x73, 0x65, 0x72, 0x50, 0x61, 0x73, 0x73, 0x12, 0x23, 0x0a, 0x0d,
+	0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x22, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0c, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x12, 0x29, 0x0a, 0x10, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x5f, 0x72, 0x65, 0x73,
+	0x74, 0x61, 0x72, 0x74, 0x73, 0x18, 0x23, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0f, 0x70, 0x72, 0x6f,
+	0x63, 0x65, 0x73, 0x73, 0x52, 0x65, 0x73, 0x74, 0x61, 0x72, 0x74, 0x73, 0x12, 0x2a, 0x0a, 0x11,
+	0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x5f, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x65, 0x78, 0x69,
+	0x74, 0x18, 0x24, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73,
+	0x4c, 0x61, 0x73, 0x74, 0x45, 0x78, 0x69, 0x74, 0x22, 0x11, 0x0a, 0x0f, 0x56, 0x50, 0x4e, 0x49,
+	0x6e, 0x69, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x13, 0x0a, 0x11, 0x56,
+	0x50, 0x4e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x14, 0x0a, 0x12, 0x56, 0x50, 0x4e, 0x52, 0x65, 0x73, 0x74, 0x61, 0x72, 0x74, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x15, 0x0a, 0x13, 0x56, 0x50, 0x4e, 0x52, 0x6f, 0x74,
+	0x61, 0x74, 0x65, 0x43, 0x41, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1e, 0x0a,
+	0x1c, 0x56, 0x50, 0x4e, 0x52, 0x6f, 0x74, 0x61, 0x74, 0x65, 0x54, 0x4c, 0x53, 0x43, 0x72, 0x79,
+	0x70, 0x74, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1a, 0x0a,
+	0x18, 0x56, 0x50, 0x4e, 0x54, 0x61, 0x6b, 0x65, 0x43, 0x41, 0x4f, 0x66, 0x66, 0x6c, 0x69, 0x6e,
+	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1b, 0x0a, 0x19, 0x56, 0x50, 0x4e,
+	0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x55, 0x73, 0x65, 0x72, 0x50, 0x61, 0x73, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2a, 0x28, 0x0a, 0x08, 0x56, 0x50, 0x4e, 0x50, 0x72, 0x6f,
+	0x74, 0x6f, 0x12, 0x0a, 0x0a, 0x06, 0x4e, 0x4f, 0x50, 0x52, 0x45, 0x46, 0x10, 0x00, 0x12, 0x07,
+	0x0a, 0x03, 0x55, 0x44, 0x50, 0x10, 0x01, 0x12, 0x07, 0x0a, 0x03, 0x54, 0x43, 0x50, 0x10, 0x02,
+	0x2a, 0x49, 0x0a, 0x0a, 0x56, 0x50, 0x4e, 0x4c, 0x5a, 0x4f, 0x50, 0x72, 0x65, 0x66, 0x12, 0x12,
+	0x0a, 0x0e, 0x55, 0x53, 0x45, 0x5f, 0x4c, 0x5a, 0x4f, 0x5f, 0x4e, 0x4f, 0x50, 0x52, 0x45, 0x46,
+	0x10, 0x00, 0x12, 0x12, 0x0a, 0x0e, 0x55, 0x53, 0x45, 0x5f, 0x4c, 0x5a, 0x4f, 0x5f, 0x45, 0x4e,
+	0x41, 0x42, 0x4c, 0x45, 0x10, 0x01, 0x12, 0x13, 0x0a, 0x0f, 0x55, 0x53, 0x45, 0x5f, 0x4c, 0x5a,
+	0x4f, 0x5f, 0x44, 0x49, 0x53, 0x41, 0x42, 0x4c, 0x45, 0x10, 0x03, 0x2a, 0x6b, 0x0a, 0x14, 0x56,
+	0x50, 0x4e, 0x52, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x43, 0x65, 0x72, 0x74, 0x54, 0x4c, 0x53, 0x50,
+	0x72, 0x65, 0x66, 0x12, 0x1a, 0x0a, 0x16, 0x52, 0x45, 0x4d, 0x4f, 0x54, 0x45, 0x5f, 0x43, 0x45,
+	0x52, 0x54, 0x5f, 0x54, 0x4c, 0x53, 0x5f, 0x4e, 0x4f, 0x50, 0x52, 0x45, 0x46, 0x10, 0x00, 0x12,
+	0x1a, 0x0a, 0x16, 0x52, 0x45, 0x4d, 0x4f, 0x54, 0x45, 0x5f, 0x43, 0x45, 0x52, 0x54, 0x5f, 0x54,
+	0x4c, 0x53, 0x5f, 0x45, 0x4e, 0x41, 0x42, 0x4c, 0x45, 0x10, 0x01, 0x12, 0x1b, 0x0a, 0x17, 0x52,
+	0x45, 0x4d, 0x4f, 0x54, 0x45, 0x5f, 0x43, 0x45, 0x52, 0x54, 0x5f, 0x54, 0x4c, 0x53, 0x5f, 0x44,
+	0x49, 0x53, 0x41, 0x42, 0x4c, 0x45, 0x10, 0x02, 0x2a, 0x54, 0x0a, 0x0f, 0x56, 0x50, 0x4e, 0x45,
+	0x43, 0x44, 0x48, 0x4f, 0x6e, 0x6c, 0x79, 0x50, 0x72, 0x65, 0x66, 0x12, 0x14, 0x0a, 0x10, 0x45,
+	0x43, 0x44, 0x48, 0x5f, 0x4f, 0x4e, 0x4c, 0x59, 0x5f, 0x4e, 0x4f, 0x50, 0x52, 0x45, 0x46, 0x10,
+	0x00, 0x12, 0x14, 0x0a, 0x10, 0x45, 0x43, 0x44, 0x48, 0x5f, 0x4f, 0x4e, 0x4c, 0x59, 0x5f, 0x45,
+	0x4e, 0x41, 0x42, 0x4c, 0x45, 0x10, 0x01, 0x12, 0x15, 0x0a, 0x11, 0x45, 0x43, 0x44, 0x48, 0x5f,
+	0x4f, 0x4e, 0x4c, 0x59, 0x5f, 0x44, 0x49, 0x53, 0x41, 0x42, 0x4c, 0x45, 0x10, 0x02, 0x2a, 0x67,
+	0x0a, 0x13, 0x56, 0x50, 0x4e, 0x41, 0x75, 0x74, 0x68, 0x55, 0x73, 0x65, 0x72, 0x50, 0x61, 0x73,
+	0x73, 0x50, 0x72, 0x65, 0x66, 0x12, 0x19, 0x0a, 0x15, 0x41, 0x55, 0x54, 0x48, 0x5f, 0x55, 0x53,
+	0x45, 0x52, 0x5f, 0x50, 0x41, 0x53, 0x53, 0x5f, 0x4e, 0x4f, 0x50, 0x52, 0x45, 0x46, 0x10, 0x00,
+	0x12, 0x19, 0x0a, 0x15, 0x41, 0x55, 0x54, 0x48, 0x5f, 0x55, 0x53, 0x45, 0x52, 0x5f, 0x50, 0x41,
+	0x53, 0x53, 0x5f, 0x45, 0x4e, 0x41, 0x42, 0x4c, 0x45, 0x10, 0x01, 0x12, 0x1a, 0x0a, 0x16, 0x41,
+	0x55, 0x54, 0x48, 0x5f, 0x55, 0x53, 0x45, 0x52, 0x5f, 0x50, 0x41, 0x53, 0x53, 0x5f, 0x44, 0x49,
+	0x53, 0x41, 0x42, 0x4c, 0x45, 0x10, 0x02, 0x32, 0xdb, 0x05, 0x0a, 0x0a, 0x56, 0x50, 0x4e, 0x53,
+	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x51, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x12, 0x14, 0x2e, 0x70, 0x62, 0x2e, 0x56, 0x50, 0x4e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x70, 0x62, 0x2e, 0x56, 0x50, 0x4e, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1a, 0x82,
+	0xd3, 0xe4, 0x93, 0x02, 0x14, 0x12, 0x12, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x2f, 0x76,
+	0x70, 0x6e, 0x2f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x4c, 0x0a, 0x04, 0x49, 0x6e, 0x69,
+	0x74, 0x12, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x56, 0x50, 0x4e, 0x49, 0x6e, 0x69, 0x74, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x70, 0x62, 0x2e, 0x56, 0x50, 0x4e, 0x49, 0x6e,
+	0x69, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1b, 0x82, 0xd3, 0xe4, 0x93,
+	0x02, 0x15, 0x22, 0x10, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x2f, 0x76, 0x70, 0x6e, 0x2f,
+	0x69, 0x6e, 0x69, 0x74, 0x3a, 0x01, 0x2a, 0x12, 0x54, 0x0a, 0x06, 0x55, 0x70, 0x64, 0x61, 0x74,
+	0x65, 0x12, 0x14, 0x2e, 0x70, 0x62, 0x2e, 0x56, 0x50, 0x4e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65,
 	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x70, 0x62, 0x2e, 0x56, 0x50, 0x4e,
-	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1a,
-	0x82, 0xd3, 0xe4, 0x93, 0x02, 0x14, 0x12, 0x12, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x2f,
-	0x76, 0x70, 0x6e, 0x2f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x4c, 0x0a, 0x04, 0x49, 0x6e,
-	0x69, 0x74, 0x12, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x56, 0x50, 0x4e, 0x49, 0x6e, 0x69, 0x74, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x70, 0x62, 0x2e, 0x56, 0x50, 0x4e, 0x49,
-	0x6e, 0x69, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1b, 0x82, 0xd3, 0xe4,
-	0x93, 0x02, 0x15, 0x22, 0x10, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x2f, 0x76, 0x70, 0x6e,
-	0x2f, 0x69, 0x6e, 0x69, 0x74, 0x3a, 0x01, 0x2a, 0x12, 0x54, 0x0a, 0x06, 0x55, 0x70, 0x64, 0x61,
-	0x74, 0x65, 0x12, 0x14, 0x2e, 0x70, 0x62, 0x2e, 0x56, 0x50, 0x4e, 0x55, 0x70, 0x64, 0x61, 0x74,
-	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x70, 0x62, 0x2e, 0x56, 0x50,
-	0x4e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
-	0x1d, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x17, 0x22, 0x12, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31,
-	0x2f, 0x76, 0x70, 0x6e, 0x2f, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x3a, 0x01, 0x2a, 0x12, 0x55,
-	0x0a, 0x07, 0x52, 0x65, 0x73, 0x74, 0x61, 0x72, 0x74, 0x12, 0x15, 0x2e, 0x70, 0x62, 0x2e, 0x56,
-	0x50, 0x4e, 0x52, 0x65, 0x73, 0x74, 0x61, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x16, 0x2e, 0x70, 0x62, 0x2e, 0x56, 0x50, 0x4e, 0x52, 0x65, 0x73, 0x74, 0x61, 0x72, 0x74,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1b, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x15,
-	0x22, 0x13, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x2f, 0x76, 0x70, 0x6e, 0x2f, 0x72, 0x65,
-	0x73, 0x74, 0x61, 0x72, 0x74, 0x42, 0x1c, 0x5a, 0x1a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e,
+	0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1d,
+	0x82, 0xd3, 0xe4, 0x93, 0x02, 0x17, 0x22, 0x12, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x2f,
+	0x76, 0x70, 0x6e, 0x2f, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x3a, 0x01, 0x2a, 0x12, 0x55, 0x0a,
+	0x07, 0x52, 0x65, 0x73, 0x74, 0x61, 0x72, 0x74, 0x12, 0x15, 0x2e, 0x70, 0x62, 0x2e, 0x56, 0x50,
+	0x4e, 0x52, 0x65, 0x73, 0x74, 0x61, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x16, 0x2e, 0x70, 0x62, 0x2e, 0x56, 0x50, 0x4e, 0x52, 0x65, 0x73, 0x74, 0x61, 0x72, 0x74, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1b, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x15, 0x22,
+	0x13, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x2f, 0x76, 0x70, 0x6e, 0x2f, 0x72, 0x65, 0x73,
+	0x74, 0x61, 0x72, 0x74, 0x12, 0x5d, 0x0a, 0x08, 0x52, 0x6f, 0x74, 0x61, 0x74, 0x65, 0x43, 0x41,
+	0x12, 0x16, 0x2e, 0x70, 0x62, 0x2e, 0x56, 0x50, 0x4e, 0x52, 0x6f, 0x74, 0x61, 0x74, 0x65, 0x43,
+	0x41, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x70, 0x62, 0x2e, 0x56, 0x50,
+	0x4e, 0x52, 0x6f, 0x74, 0x61, 0x74, 0x65, 0x43, 0x41, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x22, 0x20, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1a, 0x22, 0x15, 0x2f, 0x61, 0x70, 0x69, 0x2f,
+	0x76, 0x31, 0x2f, 0x76, 0x70, 0x6e, 0x2f, 0x72, 0x6f, 0x74, 0x61, 0x74, 0x65, 0x2d, 0x63, 0x61,
+	0x3a, 0x01, 0x2a, 0x12, 0x80, 0x01, 0x0a, 0x11, 0x52, 0x6f, 0x74, 0x61, 0x74, 0x65, 0x54, 0x4c,
+	0x53, 0x43, 0x72, 0x79, 0x70, 0x74, 0x4b, 0x65, 0x79, 0x12, 0x1f, 0x2e, 0x70, 0x62, 0x2e, 0x56,
+	0x50, 0x4e, 0x52, 0x6f, 0x74, 0x61, 0x74, 0x65, 0x54, 0x4c, 0x53, 0x43, 0x72, 0x79, 0x70, 0x74,
+	0x4b, 0x65, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x70, 0x62, 0x2e,
+	0x56, 0x50, 0x4e, 0x52, 0x6f, 0x74, 0x61, 0x74, 0x65, 0x54, 0x4c, 0x53, 0x43, 0x72, 0x79, 0x70,
+	0x74, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x28, 0x82, 0xd3,
+	0xe4, 0x93, 0x02, 0x22, 0x22, 0x20, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x2f, 0x76, 0x70,
+	0x6e, 0x2f, 0x72, 0x6f, 0x74, 0x61, 0x74, 0x65, 0x2d, 0x74, 0x6c, 0x73, 0x2d, 0x63, 0x72, 0x79,
+	0x70, 0x74, 0x2d, 0x6b, 0x65, 0x79, 0x12, 0x4c, 0x0a, 0x0d, 0x54, 0x61, 0x6b, 0x65, 0x43, 0x41,
+	0x4f, 0x66, 0x66, 0x6c, 0x69, 0x6e, 0x65, 0x12, 0x1b, 0x2e, 0x70, 0x62, 0x2e, 0x56, 0x50, 0x4e,
+	0x54, 0x61, 0x6b, 0x65, 0x43, 0x41, 0x4f, 0x66, 0x66, 0x6c, 0x69, 0x6e, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x70, 0x62, 0x2e, 0x56, 0x50, 0x4e, 0x54, 0x61, 0x6b,
+	0x65, 0x43, 0x41, 0x4f, 0x66, 0x66, 0x6c, 0x69, 0x6e, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x00, 0x12, 0x4f, 0x0a, 0x0e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x55, 0x73,
+	0x65, 0x72, 0x50, 0x61, 0x73, 0x73, 0x12, 0x1c, 0x2e, 0x70, 0x62, 0x2e, 0x56, 0x50, 0x4e, 0x56,
+	0x65, 0x72, 0x69, 0x66, 0x79, 0x55, 0x73, 0x65, 0x72, 0x50, 0x61, 0x73, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x70, 0x62, 0x2e, 0x56, 0x50, 0x4e, 0x56, 0x65, 0x72,
+	0x69, 0x66, 0x79, 0x55, 0x73, 0x65, 0x72, 0x50, 0x61, 0x73, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x1c, 0x5a, 0x1a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e,
 	0x63, 0x6f, 0x6d, 0x2f, 0x63, 0x61, 0x64, 0x2f, 0x6f, 0x76, 0x70, 0x6d, 0x2f, 0x61, 0x70, 0x69,
 	0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
@@ -731,36 +1905,60 @@ func file_vpn_proto_rawDescGZIP() []byte {
 	return file_vpn_proto_rawDescData
 }
 
-var file_vpn_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_vpn_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_vpn_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_vpn_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
 var file_vpn_proto_goTypes = []interface{}{
-	(VPNProto)(0),              // 0: pb.VPNProto
-	(VPNLZOPref)(0),            // 1: pb.VPNLZOPref
-	(*VPNStatusRequest)(nil),   // 2: pb.VPNStatusRequest
-	(*VPNInitRequest)(nil),     // 3: pb.VPNInitRequest
-	(*VPNUpdateRequest)(nil),   // 4: pb.VPNUpdateRequest
-	(*VPNRestartRequest)(nil),  // 5: pb.VPNRestartRequest
-	(*VPNStatusResponse)(nil),  // 6: pb.VPNStatusResponse
-	(*VPNInitResponse)(nil),    // 7: pb.VPNInitResponse
-	(*VPNUpdateResponse)(nil),  // 8: pb.VPNUpdateResponse
-	(*VPNRestartResponse)(nil), // 9: pb.VPNRestartResponse
+	(VPNProto)(0),                        // 0: pb.VPNProto
+	(VPNLZOPref)(0),                      // 1: pb.VPNLZOPref
+	(VPNRemoteCertTLSPref)(0),            // 2: pb.VPNRemoteCertTLSPref
+	(VPNECDHOnlyPref)(0),                 // 3: pb.VPNECDHOnlyPref
+	(VPNAuthUserPassPref)(0),             // 4: pb.VPNAuthUserPassPref
+	(*VPNStatusRequest)(nil),             // 5: pb.VPNStatusRequest
+	(*VPNInitRequest)(nil),               // 6: pb.VPNInitRequest
+	(*VPNUpdateRequest)(nil),             // 7: pb.VPNUpdateRequest
+	(*VPNRestartRequest)(nil),            // 8: pb.VPNRestartRequest
+	(*VPNRotateCARequest)(nil),           // 9: pb.VPNRotateCARequest
+	(*VPNRotateTLSCryptKeyRequest)(nil),  // 10: pb.VPNRotateTLSCryptKeyRequest
+	(*VPNTakeCAOfflineRequest)(nil),      // 11: pb.VPNTakeCAOfflineRequest
+	(*VPNVerifyUserPassRequest)(nil),     // 12: pb.VPNVerifyUserPassRequest
+	(*VPNStatusResponse)(nil),            // 13: pb.VPNStatusResponse
+	(*VPNInitResponse)(nil),              // 14: pb.VPNInitResponse
+	(*VPNUpdateResponse)(nil),            // 15: pb.VPNUpdateResponse
+	(*VPNRestartResponse)(nil),           // 16: pb.VPNRestartResponse
+	(*VPNRotateCAResponse)(nil),          // 17: pb.VPNRotateCAResponse
+	(*VPNRotateTLSCryptKeyResponse)(nil), // 18: pb.VPNRotateTLSCryptKeyResponse
+	(*VPNTakeCAOfflineResponse)(nil),     // 19: pb.VPNTakeCAOfflineResponse
+	(*VPNVerifyUserPassResponse)(nil),    // 20: pb.VPNVerifyUserPassResponse
 }
 var file_vpn_proto_depIdxs = []int32{
-	0, // 0: pb.VPNInitRequest.proto_pref:type_name -> pb.VPNProto
-	1, // 1: pb.VPNUpdateRequest.lzo_pref:type_name -> pb.VPNLZOPref
-	2, // 2: pb.VPNService.Status:input_type -> pb.VPNStatusRequest
-	3, // 3: pb.VPNService.Init:input_type -> pb.VPNInitRequest
-	4, // 4: pb.VPNService.Update:input_type -> pb.VPNUpdateRequest
-	5, // 5: pb.VPNService.Restart:input_type -> pb.VPNRestartRequest
-	6, // 6: pb.VPNService.Status:output_type -> pb.VPNStatusResponse
-	7, // 7: pb.VPNService.Init:output_type -> pb.VPNInitResponse
-	8, // 8: pb.VPNService.Update:output_type -> pb.VPNUpdateResponse
-	9, // 9: pb.VPNService.Restart:output_type -> pb.VPNRestartResponse
-	6, // [6:10] is the sub-list for method output_type
-	2, // [2:6] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	0,  // 0: pb.VPNInitRequest.proto_pref:type_name -> pb.VPNProto
+	2,  // 1: pb.VPNInitRequest.remote_cert_tls_pref:type_name -> pb.VPNRemoteCertTLSPref
+	3,  // 2: pb.VPNInitRequest.ecdh_only_pref:type_name -> pb.VPNECDHOnlyPref
+	1,  // 3: pb.VPNUpdateRequest.lzo_pref:type_name -> pb.VPNLZOPref
+	2,  // 4: pb.VPNUpdateRequest.remote_cert_tls_pref:type_name -> pb.VPNRemoteCertTLSPref
+	3,  // 5: pb.VPNUpdateRequest.ecdh_only_pref:type_name -> pb.VPNECDHOnlyPref
+	4,  // 6: pb.VPNUpdateRequest.auth_user_pass_pref:type_name -> pb.VPNAuthUserPassPref
+	5,  // 7: pb.VPNService.Status:input_type -> pb.VPNStatusRequest
+	6,  // 8: pb.VPNService.Init:input_type -> pb.VPNInitRequest
+	7,  // 9: pb.VPNService.Update:input_type -> pb.VPNUpdateRequest
+	8,  // 10: pb.VPNService.Restart:input_type -> pb.VPNRestartRequest
+	9,  // 11: pb.VPNService.RotateCA:input_type -> pb.VPNRotateCARequest
+	10, // 12: pb.VPNService.RotateTLSCryptKey:input_type -> pb.VPNRotateTLSCryptKeyRequest
+	11, // 13: pb.VPNService.TakeCAOffline:input_type -> pb.VPNTakeCAOfflineRequest
+	12, // 14: pb.VPNService.VerifyUserPass:input_type -> pb.VPNVerifyUserPassRequest
+	13, // 15: pb.VPNService.Status:output_type -> pb.VPNStatusResponse
+	14, // 16: pb.VPNService.Init:output_type -> pb.VPNInitResponse
+	15, // 17: pb.VPNService.Update:output_type -> pb.VPNUpdateResponse
+	16, // 18: pb.VPNService.Restart:output_type -> pb.VPNRestartResponse
+	17, // 19: pb.VPNService.RotateCA:output_type -> pb.VPNRotateCAResponse
+	18, // 20: pb.VPNService.RotateTLSCryptKey:output_type -> pb.VPNRotateTLSCryptKeyResponse
+	19, // 21: pb.VPNService.TakeCAOffline:output_type -> pb.VPNTakeCAOfflineResponse
+	20, // 22: pb.VPNService.VerifyUserPass:output_type -> pb.VPNVerifyUserPassResponse
+	15, // [15:23] is the sub-list for method output_type
+	7,  // [7:15] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_vpn_proto_init() }
@@ -818,7 +2016,7 @@ func file_vpn_proto_init() {
 			}
 		}
 		file_vpn_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*VPNStatusResponse); i {
+			switch v := v.(*VPNRotateCARequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -830,7 +2028,7 @@ func file_vpn_proto_init() {
 			}
 		}
 		file_vpn_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*VPNInitResponse); i {
+			switch v := v.(*VPNRotateTLSCryptKeyRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -842,7 +2040,7 @@ func file_vpn_proto_init() {
 			}
 		}
 		file_vpn_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*VPNUpdateResponse); i {
+			switch v := v.(*VPNTakeCAOfflineRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -854,6 +2052,54 @@ func file_vpn_proto_init() {
 			}
 		}
 		file_vpn_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VPNVerifyUserPassRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vpn_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VPNStatusResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vpn_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VPNInitResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vpn_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VPNUpdateResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vpn_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*VPNRestartResponse); i {
 			case 0:
 				return &v.state
@@ -865,14 +2111,62 @@ func file_vpn_proto_init() {
 				return nil
 			}
 		}
+		file_vpn_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VPNRotateCAResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vpn_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VPNRotateTLSCryptKeyResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vpn_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VPNTakeCAOfflineResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vpn_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VPNVerifyUserPassResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_vpn_proto_rawDesc,
-			NumEnums:      2,
-			NumMessages:   8,
+			NumEnums:      5,
+			NumMessages:   16,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -22,6 +22,14 @@ type VPNServiceClient interface {
 	Init(ctx context.Context, in *VPNInitRequest, opts ...grpc.CallOption) (*VPNInitResponse, error)
 	Update(ctx context.Context, in *VPNUpdateRequest, opts ...grpc.CallOption) (*VPNUpdateResponse, error)
 	Restart(ctx context.Context, in *VPNRestartRequest, opts ...grpc.CallOption) (*VPNRestartResponse, error)
+	RotateCA(ctx context.Context, in *VPNRotateCARequest, opts ...grpc.CallOption) (*VPNRotateCAResponse, error)
+	RotateTLSCryptKey(ctx context.Context, in *VPNRotateTLSCryptKeyRequest, opts ...grpc.CallOption) (*VPNRotateTLSCryptKeyResponse, error)
+	// TakeCAOffline is not exposed over REST, since it writes the CA key file
+	// on the host that ovpmd runs on.
+	TakeCAOffline(ctx context.Context, in *VPNTakeCAOfflineRequest, opts ...grpc.CallOption) (*VPNTakeCAOfflineResponse, error)
+	// VerifyUserPass is not exposed over REST, it's only for the OpenVPN
+	// process to verify the users' passwords.
+	VerifyUserPass(ctx context.Context, in *VPNVerifyUserPassRequest, opts ...grpc.CallOption) (*VPNVerifyUserPassResponse, error)
 }
 
 type vPNServiceClient struct {
@@ -68,6 +76,42 @@ func (c *vPNServiceClient) Restart(ctx context.Context, in *VPNRestartRequest, o
 	return out, nil
 }
 
+func (c *vPNServiceClient) RotateCA(ctx context.Context, in *VPNRotateCARequest, opts ...grpc.CallOption) (*VPNRotateCAResponse, error) {
+	out := new(VPNRotateCAResponse)
+	err := c.cc.Invoke(ctx, "/pb.VPNService/RotateCA", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vPNServiceClient) RotateTLSCryptKey(ctx context.Context, in *VPNRotateTLSCryptKeyRequest, opts ...grpc.CallOption) (*VPNRotateTLSCryptKeyResponse, error) {
+	out := new(VPNRotateTLSCryptKeyResponse)
+	err := c.cc.Invoke(ctx, "/pb.VPNService/RotateTLSCryptKey", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vPNServiceClient) TakeCAOffline(ctx context.Context, in *VPNTakeCAOfflineRequest, opts ...grpc.CallOption) (*VPNTakeCAOfflineResponse, error) {
+	out := new(VPNTakeCAOfflineResponse)
+	err := c.cc.Invoke(ctx, "/pb.VPNService/TakeCAOffline", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vPNServiceClient) VerifyUserPass(ctx context.Context, in *VPNVerifyUserPassRequest, opts ...grpc.CallOption) (*VPNVerifyUserPassResponse, error) {
+	out := new(VPNVerifyUserPassResponse)
+	err := c.cc.Invoke(ctx, "/pb.VPNService/VerifyUserPass", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // VPNServiceServer is the server API for VPNService service.
 // All implementations must embed UnimplementedVPNServiceServer
 // for forward compatibility
@@ -76,6 +120,14 @@ type VPNServiceServer interface {
 	Init(context.Context, *VPNInitRequest) (*VPNInitResponse, error)
 	Update(context.Context, *VPNUpdateRequest) (*VPNUpdateResponse, error)
 	Restart(context.Context, *VPNRestartRequest) (*VPNRestartResponse, error)
+	RotateCA(context.Context, *VPNRotateCARequest) (*VPNRotateCAResponse, error)
+	RotateTLSCryptKey(context.Context, *VPNRotateTLSCryptKeyRequest) (*VPNRotateTLSCryptKeyResponse, error)
+	// TakeCAOffline is not exposed over REST, since it writes the CA key file
+	// on the host that ovpmd runs on.
+	TakeCAOffline(context.Context, *VPNTakeCAOfflineRequest) (*VPNTakeCAOfflineResponse, error)
+	// VerifyUserPass is not exposed over REST, it's only for the OpenVPN
+	// process to verify the users' passwords.
+	VerifyUserPass(context.Context, *VPNVerifyUserPassRequest) (*VPNVerifyUserPassResponse, error)
 	mustEmbedUnimplementedVPNServiceServer()
 }
 
@@ -95,6 +147,18 @@ func (UnimplementedVPNServiceServer) Update(context.Context, *VPNUpdateRequest)
 func (UnimplementedVPNServiceServer) Restart(context.Context, *VPNRestartRequest) (*VPNRestartResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Restart not implemented")
 }
+func (UnimplementedVPNServiceServer) RotateCA(context.Context, *VPNRotateCARequest) (*VPNRotateCAResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RotateCA not implemented")
+}
+func (UnimplementedVPNServiceServer) RotateTLSCryptKey(context.Context, *VPNRotateTLSCryptKeyRequest) (*VPNRotateTLSCryptKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RotateTLSCryptKey not implemented")
+}
+func (UnimplementedVPNServiceServer) TakeCAOffline(context.Context, *VPNTakeCAOfflineRequest) (*VPNTakeCAOfflineResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TakeCAOffline not implemented")
+}
+func (UnimplementedVPNServiceServer) VerifyUserPass(context.Context, *VPNVerifyUserPassRequest) (*VPNVerifyUserPassResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyUserPass not implemented")
+}
 func (UnimplementedVPNServiceServer) mustEmbedUnimplementedVPNServiceServer() {}
 
 // UnsafeVPNServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -180,6 +244,78 @@ func _VPNService_Restart_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _VPNService_RotateCA_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VPNRotateCARequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VPNServiceServer).RotateCA(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.VPNService/RotateCA",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VPNServiceServer).RotateCA(ctx, req.(*VPNRotateCARequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VPNService_RotateTLSCryptKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VPNRotateTLSCryptKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VPNServiceServer).RotateTLSCryptKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.VPNService/RotateTLSCryptKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VPNServiceServer).RotateTLSCryptKey(ctx, req.(*VPNRotateTLSCryptKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VPNService_TakeCAOffline_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VPNTakeCAOfflineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VPNServiceServer).TakeCAOffline(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.VPNService/TakeCAOffline",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VPNServiceServer).TakeCAOffline(ctx, req.(*VPNTakeCAOfflineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VPNService_VerifyUserPass_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VPNVerifyUserPassRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VPNServiceServer).VerifyUserPass(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.VPNService/VerifyUserPass",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VPNServiceServer).VerifyUserPass(ctx, req.(*VPNVerifyUserPassRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // VPNService_ServiceDesc is the grpc.ServiceDesc for VPNService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -203,6 +339,22 @@ var VPNService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Restart",
 			Handler:    _VPNService_Restart_Handler,
 		},
+		{
+			MethodName: "RotateCA",
+			Handler:    _VPNService_RotateCA_Handler,
+		},
+		{
+			MethodName: "RotateTLSCryptKey",
+			Handler:    _VPNService_RotateTLSCryptKey_Handler,
+		},
+		{
+			MethodName: "TakeCAOffline",
+			Handler:    _VPNService_TakeCAOffline_Handler,
+		},
+		{
+			MethodName: "VerifyUserPass",
+			Handler:    _VPNService_VerifyUserPass_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "vpn.proto",
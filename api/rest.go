@@ -57,7 +57,32 @@ func NewRESTServer(grpcPort string) (http.Handler, context.CancelFunc, error) {
 		return nil, cancel, err
 	}
 
+	err = pb.RegisterCertServiceHandlerFromEndpoint(ctx, gmux, endPoint, opts)
+	if err != nil {
+		return nil, cancel, err
+	}
+
+	err = pb.RegisterLDAPServiceHandlerFromEndpoint(ctx, gmux, endPoint, opts)
+	if err != nil {
+		return nil, cancel, err
+	}
+
+	err = pb.RegisterOIDCServiceHandlerFromEndpoint(ctx, gmux, endPoint, opts)
+	if err != nil {
+		return nil, cancel, err
+	}
+
+	err = pb.RegisterAuditServiceHandlerFromEndpoint(ctx, gmux, endPoint, opts)
+	if err != nil {
+		return nil, cancel, err
+	}
+
 	mux.HandleFunc("/api/specs/", specsHandler)
+	mux.HandleFunc("/healthz", healthzHandler)
+	mux.HandleFunc("/readyz", readyzHandler)
+	mux.HandleFunc("/api/v1/auth/oidc", oidcStatusHandler)
+	mux.HandleFunc("/api/v1/auth/oidc/login", oidcLoginHandler)
+	mux.HandleFunc("/api/v1/auth/oidc/callback", oidcCallbackHandler)
 	mware := middleware.Redoc(middleware.RedocOpts{
 		BasePath: "/api/docs/",
 		SpecURL:  "/api/specs/user.swagger.json",
@@ -78,6 +103,26 @@ func NewRESTServer(grpcPort string) (http.Handler, context.CancelFunc, error) {
 		SpecURL:  "/api/specs/auth.swagger.json",
 		Path:     "auth",
 	}, mware)
+	mware = middleware.Redoc(middleware.RedocOpts{
+		BasePath: "/api/docs/",
+		SpecURL:  "/api/specs/cert.swagger.json",
+		Path:     "cert",
+	}, mware)
+	mware = middleware.Redoc(middleware.RedocOpts{
+		BasePath: "/api/docs/",
+		SpecURL:  "/api/specs/ldap.swagger.json",
+		Path:     "ldap",
+	}, mware)
+	mware = middleware.Redoc(middleware.RedocOpts{
+		BasePath: "/api/docs/",
+		SpecURL:  "/api/specs/oidc.swagger.json",
+		Path:     "oidc",
+	}, mware)
+	mware = middleware.Redoc(middleware.RedocOpts{
+		BasePath: "/api/docs/",
+		SpecURL:  "/api/specs/audit.swagger.json",
+		Path:     "audit",
+	}, mware)
 	mux.Handle("/api/", mware)
 	mux.Handle("/", http.FileServer(
 		&assetfs.AssetFS{Asset: bundle.Asset, AssetDir: bundle.AssetDir, Prefix: "bundle"}))
@@ -113,6 +158,30 @@ func specsHandler(w http.ResponseWriter, r *http.Request) {
 			logrus.Warn(err)
 		}
 		w.Write(vpnData)
+	case "/api/specs/cert.swagger.json":
+		certData, err := bundle.Asset("bundle/cert.swagger.json")
+		if err != nil {
+			logrus.Warn(err)
+		}
+		w.Write(certData)
+	case "/api/specs/ldap.swagger.json":
+		ldapData, err := bundle.Asset("bundle/ldap.swagger.json")
+		if err != nil {
+			logrus.Warn(err)
+		}
+		w.Write(ldapData)
+	case "/api/specs/oidc.swagger.json":
+		oidcData, err := bundle.Asset("bundle/oidc.swagger.json")
+		if err != nil {
+			logrus.Warn(err)
+		}
+		w.Write(oidcData)
+	case "/api/specs/audit.swagger.json":
+		auditData, err := bundle.Asset("bundle/audit.swagger.json")
+		if err != nil {
+			logrus.Warn(err)
+		}
+		w.Write(auditData)
 	}
 }
 
@@ -1,16 +1,19 @@
 package api
 
 import (
+	"encoding/json"
 	"go.uber.org/thriftrw/ptr"
 	"os"
 	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
 
 	"github.com/cad/ovpm"
 	"github.com/cad/ovpm/api/pb"
 	"github.com/cad/ovpm/permset"
+	"github.com/cad/ovpm/pki"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
 )
@@ -58,8 +61,20 @@ func (s *AuthService) Authenticate(ctx context.Context, req *pb.AuthAuthenticate
 	if !user.CheckPassword(req.Password) {
 		return nil, grpc.Errorf(codes.Unauthenticated, "user not found with the provided credentials")
 	}
+	if user.IsTOTPEnabled() {
+		if req.TotpCode == "" {
+			return nil, grpc.Errorf(codes.Unauthenticated, "totp code is required")
+		}
+		if user.IsTOTPLocked() {
+			return nil, grpc.Errorf(codes.Unauthenticated, "too many wrong totp codes, try again later")
+		}
+		if err := user.VerifyTOTP(req.TotpCode); err != nil {
+			logrus.Debugln(err)
+			return nil, grpc.Errorf(codes.Unauthenticated, "totp code is wrong")
+		}
+	}
 
-	token, err := user.RenewToken()
+	token, err := user.IssueToken(req.Label, userAgentFromContext(ctx))
 	if err != nil {
 		return nil, grpc.Errorf(codes.Internal, "token can not be generated")
 	}
@@ -67,6 +82,87 @@ func (s *AuthService) Authenticate(ctx context.Context, req *pb.AuthAuthenticate
 	return &pb.AuthAuthenticateResponse{Token: token}, nil
 }
 
+func (s *AuthService) ListSessions(ctx context.Context, req *pb.AuthListSessionsRequest) (*pb.AuthSessionsResponse, error) {
+	logrus.Debugf("rpc call: auth list sessions: %s", req.Username)
+	user, err := sessionUserFromContext(ctx, req.Username, ovpm.ListSessionsAnyUserPerm, ovpm.ListSessionsSelfPerm)
+	if err != nil {
+		return nil, err
+	}
+
+	return sessionsResponse(ctx, user)
+}
+
+func (s *AuthService) RevokeSession(ctx context.Context, req *pb.AuthRevokeSessionRequest) (*pb.AuthSessionsResponse, error) {
+	logrus.Debugf("rpc call: auth revoke session: %s %d", req.Username, req.Id)
+	user, err := sessionUserFromContext(ctx, req.Username, ovpm.RevokeSessionAnyUserPerm, ovpm.RevokeSessionSelfPerm)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := user.RevokeToken(uint(req.Id)); err != nil {
+		return nil, grpc.Errorf(codes.NotFound, err.Error())
+	}
+
+	return sessionsResponse(ctx, user)
+}
+
+// sessionUserFromContext returns the user whose sessions are managed. It's
+// the caller if username is empty.
+func sessionUserFromContext(ctx context.Context, username string, anyPerm, selfPerm permset.Perm) (*ovpm.User, error) {
+	callerUsername, err := GetUsernameFromContext(ctx)
+	if err != nil {
+		logrus.Debugln(err)
+		return nil, grpc.Errorf(codes.Unauthenticated, "username not found with the provided credentials")
+	}
+	if username == "" {
+		username = callerUsername
+	}
+
+	perms, err := permset.FromContext(ctx)
+	if err != nil {
+		return nil, grpc.Errorf(codes.Unauthenticated, "Can't get permset from context")
+	}
+
+	if !perms.Contains(anyPerm) {
+		if !perms.Contains(selfPerm) {
+			return nil, grpc.Errorf(codes.PermissionDenied, "Permissions are required for this operation.")
+		}
+		if username != callerUsername {
+			return nil, grpc.Errorf(codes.PermissionDenied, "Caller can only manage their own sessions.")
+		}
+	}
+
+	user, err := ovpm.GetUser(username)
+	if err != nil {
+		return nil, grpc.Errorf(codes.NotFound, err.Error())
+	}
+	return user, nil
+}
+
+// sessionsResponse returns the sessions of the user, marking the one that the
+// caller is authenticated with.
+func sessionsResponse(ctx context.Context, user *ovpm.User) (*pb.AuthSessionsResponse, error) {
+	tokens, err := user.GetTokens()
+	if err != nil {
+		return nil, grpc.Errorf(codes.Internal, err.Error())
+	}
+	callerToken, _ := authzTokenFromContext(ctx)
+
+	resp := &pb.AuthSessionsResponse{Username: user.GetUsername()}
+	for _, t := range tokens {
+		resp.Sessions = append(resp.Sessions, &pb.AuthSessionsResponse_Session{
+			Id:         uint32(t.GetID()),
+			Label:      t.GetLabel(),
+			UserAgent:  t.GetUserAgent(),
+			CreatedAt:  t.GetCreatedAt().UTC().Format(time.RFC3339),
+			LastUsedAt: t.GetLastUsedAt().UTC().Format(time.RFC3339),
+			ExpiresAt:  t.ExpiresAt().UTC().Format(time.RFC3339),
+			Current:    callerToken != "" && t.Matches(callerToken),
+		})
+	}
+	return resp, nil
+}
+
 type UserService struct {
 	pb.UnimplementedUserServiceServer
 }
@@ -107,6 +203,9 @@ func (s *UserService) List(ctx context.Context, req *pb.UserListRequest) (*pb.Us
 			BytesReceived:      bytesReceived,
 			ExpiresAt:          user.ExpiresAt().UTC().Format(time.RFC3339),
 			Description:        user.GetDescription(),
+			TotpEnabled:        user.IsTOTPEnabled(),
+			AuthSource:         user.GetAuthSource(),
+			Disabled:           user.IsDisabled(),
 		})
 	}
 
@@ -250,7 +349,8 @@ func (s *UserService) Delete(ctx context.Context, req *pb.UserDeleteRequest) (*p
 	}
 	ut = append(ut, &pbUser)
 
-	err = user.Delete()
+	actor, _ := GetUsernameFromContext(ctx)
+	err = user.DeleteBy(actor)
 	if err != nil {
 		return nil, err
 	}
@@ -283,8 +383,18 @@ func (s *UserService) Renew(ctx context.Context, req *pb.UserRenewRequest) (*pb.
 		return nil, grpc.Errorf(codes.PermissionDenied, "ovpm.RenewAnyUserPerm is required for this operation.")
 	}
 
-	err = user.Renew()
+	actor, _ := GetUsernameFromContext(ctx)
+	if req.KeyCompromised {
+		if err := user.RevokeCert(pki.ReasonKeyCompromise, actor); err != nil {
+			return nil, err
+		}
+	}
+
+	err = user.RenewBy(actor, time.Duration(req.GracePeriodHours)*time.Hour)
 	if err != nil {
+		if user.HasClientKey() {
+			return nil, grpc.Errorf(codes.FailedPrecondition, "%v", err)
+		}
 		return nil, err
 	}
 
@@ -292,7 +402,7 @@ func (s *UserService) Renew(ctx context.Context, req *pb.UserRenewRequest) (*pb.
 }
 
 func (s *UserService) GenConfig(ctx context.Context, req *pb.UserGenConfigRequest) (*pb.UserGenConfigResponse, error) {
-	logrus.Debugf("rpc call: user genconfig: %s", req.Username)
+	logrus.Debugf("rpc call: user genconfig: %s %s", req.Username, req.Format)
 	user, err := ovpm.GetUser(req.Username)
 	if err != nil {
 		return nil, err
@@ -308,28 +418,257 @@ func (s *UserService) GenConfig(ctx context.Context, req *pb.UserGenConfigReques
 		return nil, grpc.Errorf(codes.Unauthenticated, "Can't get permset from context")
 	}
 
+	format, err := ovpm.ParseClientConfigFormat(req.Format)
+	if err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, err.Error())
+	}
+
 	if perms.Contains(ovpm.GenConfigAnyUserPerm) {
-		configBlob, err := ovpm.TheServer().DumpsClientConfig(user.GetUsername())
-		if err != nil {
-			return nil, err
-		}
-		return &pb.UserGenConfigResponse{ClientConfig: configBlob}, nil
+		return genConfig(user, format, req.Password)
 	}
 
 	if perms.Contains(ovpm.GenConfigSelfPerm) {
 		if user.GetUsername() != username {
 			return nil, grpc.Errorf(codes.PermissionDenied, "Caller can only genconfig for their user.")
 		}
-		configBlob, err := ovpm.TheServer().DumpsClientConfig(user.GetUsername())
-		if err != nil {
-			return nil, err
-		}
-		return &pb.UserGenConfigResponse{ClientConfig: configBlob}, nil
+		return genConfig(user, format, req.Password)
 	}
 
 	return nil, grpc.Errorf(codes.PermissionDenied, "Permissions are required for this operation.")
 }
 
+// genConfig exports the client config of the user in the given format.
+func genConfig(user *ovpm.User, format ovpm.ClientConfigFormat, password string) (*pb.UserGenConfigResponse, error) {
+	payload, err := ovpm.TheServer().ExportClientConfig(user.GetUsername(), format, password)
+	if err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, err.Error())
+	}
+	resp := &pb.UserGenConfigResponse{
+		Payload:  payload,
+		Filename: format.FileName(user.GetUsername()),
+	}
+	if format == ovpm.OVPNFormat {
+		resp.ClientConfig = string(payload)
+	}
+	return resp, nil
+}
+
+func (s *UserService) Disconnect(ctx context.Context, req *pb.UserDisconnectRequest) (*pb.UserResponse, error) {
+	logrus.Debugf("rpc call: user disconnect: %s", req.Username)
+	var ut []*pb.UserResponse_User
+	user, err := ovpm.GetUser(req.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	perms, err := permset.FromContext(ctx)
+	if err != nil {
+		return nil, grpc.Errorf(codes.Unauthenticated, "Can't get permset from context")
+	}
+
+	if !perms.Contains(ovpm.DisconnectAnyUserPerm) {
+		return nil, grpc.Errorf(codes.PermissionDenied, "ovpm.DisconnectAnyUserPerm is required for this operation.")
+	}
+
+	pbUser := pb.UserResponse_User{
+		Username:           user.GetUsername(),
+		ServerSerialNumber: user.GetServerSerialNumber(),
+		HostId:             user.GetHostID(),
+		IsAdmin:            user.IsAdmin(),
+	}
+	ut = append(ut, &pbUser)
+
+	err = user.Disconnect()
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.UserResponse{Users: ut}, nil
+}
+
+func (s *UserService) SignCSR(ctx context.Context, req *pb.UserSignCSRRequest) (*pb.UserResponse, error) {
+	logrus.Debugf("rpc call: user sign-csr: %s", req.Username)
+	user, err := ovpm.GetUser(req.Username)
+	if err != nil {
+		return nil, err
+	}
+	username, err := GetUsernameFromContext(ctx)
+	if err != nil {
+		logrus.Debugln(err)
+		return nil, grpc.Errorf(codes.Unauthenticated, "username not found with the provided credentials")
+	}
+
+	perms, err := permset.FromContext(ctx)
+	if err != nil {
+		return nil, grpc.Errorf(codes.Unauthenticated, "Can't get permset from context")
+	}
+
+	if !perms.Contains(ovpm.SignCSRAnyUserPerm) {
+		if !perms.Contains(ovpm.SignCSRSelfPerm) {
+			return nil, grpc.Errorf(codes.PermissionDenied, "Permissions are required for this operation.")
+		}
+		if user.GetUsername() != username {
+			return nil, grpc.Errorf(codes.PermissionDenied, "Caller can only sign csr for their user.")
+		}
+	}
+
+	if err := user.SignCSR(req.Csr, username); err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	pbUser := pb.UserResponse_User{
+		Username:           user.GetUsername(),
+		ServerSerialNumber: user.GetServerSerialNumber(),
+		Cert:               user.GetCert(),
+		HostId:             user.GetHostID(),
+		IsAdmin:            user.IsAdmin(),
+	}
+	return &pb.UserResponse{Users: []*pb.UserResponse_User{&pbUser}}, nil
+}
+
+func (s *UserService) EnrollTOTP(ctx context.Context, req *pb.UserEnrollTOTPRequest) (*pb.UserEnrollTOTPResponse, error) {
+	logrus.Debugf("rpc call: user totp enroll: %s", req.Username)
+	user, err := totpUserFromContext(ctx, req.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	uri, err := user.EnrollTOTP()
+	if err != nil {
+		return nil, grpc.Errorf(codes.FailedPrecondition, "%v", err)
+	}
+	return &pb.UserEnrollTOTPResponse{ProvisioningUri: uri}, nil
+}
+
+func (s *UserService) ConfirmTOTP(ctx context.Context, req *pb.UserConfirmTOTPRequest) (*pb.UserConfirmTOTPResponse, error) {
+	logrus.Debugf("rpc call: user totp confirm: %s", req.Username)
+	user, err := totpUserFromContext(ctx, req.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, err := user.ConfirmTOTP(req.Code)
+	if err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	return &pb.UserConfirmTOTPResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+// totpUserFromContext returns the user to enroll for TOTP if the caller is
+// allowed to do so.
+func totpUserFromContext(ctx context.Context, username string) (*ovpm.User, error) {
+	user, err := ovpm.GetUser(username)
+	if err != nil {
+		return nil, err
+	}
+	callerUsername, err := GetUsernameFromContext(ctx)
+	if err != nil {
+		logrus.Debugln(err)
+		return nil, grpc.Errorf(codes.Unauthenticated, "username not found with the provided credentials")
+	}
+
+	perms, err := permset.FromContext(ctx)
+	if err != nil {
+		return nil, grpc.Errorf(codes.Unauthenticated, "Can't get permset from context")
+	}
+
+	if !perms.Contains(ovpm.EnrollTOTPAnyUserPerm) {
+		if !perms.Contains(ovpm.EnrollTOTPSelfPerm) {
+			return nil, grpc.Errorf(codes.PermissionDenied, "Permissions are required for this operation.")
+		}
+		if user.GetUsername() != callerUsername {
+			return nil, grpc.Errorf(codes.PermissionDenied, "Caller can only enroll totp for their user.")
+		}
+	}
+	return user, nil
+}
+
+func (s *UserService) ResetTOTP(ctx context.Context, req *pb.UserResetTOTPRequest) (*pb.UserResponse, error) {
+	logrus.Debugf("rpc call: user totp reset: %s", req.Username)
+	user, err := ovpm.GetUser(req.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	perms, err := permset.FromContext(ctx)
+	if err != nil {
+		return nil, grpc.Errorf(codes.Unauthenticated, "Can't get permset from context")
+	}
+
+	if !perms.Contains(ovpm.ResetTOTPAnyUserPerm) {
+		return nil, grpc.Errorf(codes.PermissionDenied, "ovpm.ResetTOTPAnyUserPerm is required for this operation.")
+	}
+
+	if err := user.ResetTOTP(); err != nil {
+		return nil, err
+	}
+
+	pbUser := pb.UserResponse_User{
+		Username:           user.GetUsername(),
+		ServerSerialNumber: user.GetServerSerialNumber(),
+		HostId:             user.GetHostID(),
+		IsAdmin:            user.IsAdmin(),
+		TotpEnabled:        user.IsTOTPEnabled(),
+	}
+	return &pb.UserResponse{Users: []*pb.UserResponse_User{&pbUser}}, nil
+}
+
+func (s *UserService) Sessions(ctx context.Context, req *pb.UserSessionsRequest) (*pb.UserSessionsResponse, error) {
+	logrus.Debugf("rpc call: user sessions: %s", req.Username)
+	perms, err := permset.FromContext(ctx)
+	if err != nil {
+		return nil, grpc.Errorf(codes.Unauthenticated, "Can't get permset from context")
+	}
+
+	filter := ovpm.VPNSessionFilter{Username: req.Username, Limit: int(req.Limit)}
+	if !perms.Contains(ovpm.GetSessionHistoryAnyUserPerm) {
+		if !perms.Contains(ovpm.GetSessionHistorySelfPerm) {
+			return nil, grpc.Errorf(codes.PermissionDenied, "ovpm.GetSessionHistorySelfPerm is required for this operation.")
+		}
+		username, err := GetUsernameFromContext(ctx)
+		if err != nil {
+			logrus.Debugln(err)
+			return nil, grpc.Errorf(codes.Unauthenticated, "username not found with the provided credentials")
+		}
+		if req.Username != "" && req.Username != username {
+			return nil, grpc.Errorf(codes.PermissionDenied, "Caller can only see their own sessions.")
+		}
+		filter.Username = username
+	}
+	if req.Since != "" {
+		since, err := time.Parse(time.RFC3339, req.Since)
+		if err != nil {
+			return nil, grpc.Errorf(codes.InvalidArgument, "since should be an RFC3339 timestamp: %s", req.Since)
+		}
+		filter.Since = since
+	}
+	sessions, err := ovpm.GetVPNSessions(filter)
+	if err != nil {
+		return nil, grpc.Errorf(codes.Internal, err.Error())
+	}
+
+	var resp pb.UserSessionsResponse
+	for _, s := range sessions {
+		session := &pb.UserSessionsResponse_Session{
+			Id:               uint32(s.GetID()),
+			Username:         s.GetUsername(),
+			RealAddress:      s.GetRealAddress(),
+			VirtualAddress:   s.GetVirtualAddress(),
+			StartedAt:        s.GetStartedAt().UTC().Format(time.RFC3339),
+			DurationSeconds:  uint64(s.GetDuration().Seconds()),
+			BytesReceived:    s.GetBytesReceived(),
+			BytesSent:        s.GetBytesSent(),
+			DisconnectReason: s.GetDisconnectReason(),
+			IsActive:         s.IsActive(),
+		}
+		if !s.IsActive() {
+			session.EndedAt = s.GetEndedAt().UTC().Format(time.RFC3339)
+		}
+		resp.Sessions = append(resp.Sessions, session)
+	}
+	return &resp, nil
+}
+
 type VPNService struct {
 	pb.UnimplementedVPNServiceServer
 }
@@ -347,6 +686,7 @@ func (s *VPNService) Status(ctx context.Context, req *pb.VPNStatusRequest) (*pb.
 		return nil, grpc.Errorf(codes.PermissionDenied, "ovpm.GetVPNStatusPerm is required for this operation.")
 	}
 
+	crypto := server.GetCryptoProfile()
 	response := pb.VPNStatusResponse{
 		Name:         server.GetServerName(),
 		SerialNumber: server.GetSerialNumber(),
@@ -362,6 +702,34 @@ func (s *VPNService) Status(ctx context.Context, req *pb.VPNStatusRequest) (*pb.
 		ExpiresAt:    server.ExpiresAt().UTC().Format(time.RFC3339),
 		CaExpiresAt:  server.CAExpiresAt().UTC().Format(time.RFC3339),
 		UseLzo:       server.IsUseLZO(),
+
+		CaValidityDays:         int32(server.GetCAValidityDays()),
+		ServerCertValidityDays: int32(server.GetServerCertValidityDays()),
+		ClientCertValidityDays: int32(server.GetClientCertValidityDays()),
+		CertRenewWindowDays:    int32(server.GetCertRenewWindowDays()),
+		KeyType:                string(server.GetKeyType()),
+		CaOffline:              server.IsCAOffline(),
+		CaUnlocked:             server.IsCAUnlocked(),
+		TlsCrypt:               server.GetTLSCrypt(),
+		CryptoPreset:           crypto.Preset(),
+		DataCiphers:            crypto.DataCiphers,
+		DataCiphersFallback:    crypto.DataCiphersFallback,
+		AuthDigest:             crypto.AuthDigest,
+		TlsVersionMin:          crypto.TLSVersionMin,
+		TlsCipher:              crypto.TLSCipher,
+		RemoteCertTls:          crypto.RemoteCertTLS,
+		EcdhOnly:               crypto.ECDHOnly,
+		DhParams:               server.GetDHParamsStatus(),
+		AuthUserPass:           server.IsAuthUserPass(),
+	}
+	if retiresAt := server.GetCARetiresAt(); !retiresAt.IsZero() {
+		response.CaRetiresAt = retiresAt.UTC().Format(time.RFC3339)
+	}
+	proc := ovpm.GetVPNProcStatus()
+	response.ProcessState = proc.State.String()
+	response.ProcessRestarts = proc.Restarts
+	if !proc.LastExit.IsZero() {
+		response.ProcessLastExit = proc.LastExit.UTC().Format(time.RFC3339)
 	}
 	return &response, nil
 }
@@ -387,8 +755,24 @@ func (s *VPNService) Init(ctx context.Context, req *pb.VPNInitRequest) (*pb.VPNI
 		return nil, grpc.Errorf(codes.PermissionDenied, "ovpm.InitVPNPerm is required for this operation.")
 	}
 
-	if err := ovpm.TheServer().Init(req.Hostname, req.Port, proto, req.IpBlock, req.Dns, req.KeepalivePeriod, req.KeepaliveTimeout, req.UseLzo); err != nil {
+	opts := ovpm.ServerOptions{
+		CAValidityDays:         int(req.CaValidityDays),
+		ServerCertValidityDays: int(req.ServerCertValidityDays),
+		ClientCertValidityDays: int(req.ClientCertValidityDays),
+		CertRenewWindowDays:    int(req.CertRenewWindowDays),
+		KeyType:                req.KeyType,
+		CACert:                 req.CaCert,
+		CAKey:                  req.CaKey,
+		CACRL:                  req.CaCrl,
+		TLSCrypt:               req.TlsCrypt,
+		Crypto:                 cryptoOptions(req),
+	}
+	if req.AuthUserPass {
+		opts.AuthUserPass = ptr.Bool(true)
+	}
+	if err := ovpm.TheServer().Init(req.Hostname, req.Port, proto, req.IpBlock, req.Dns, req.KeepalivePeriod, req.KeepaliveTimeout, req.UseLzo, &opts); err != nil {
 		logrus.Errorf("server can not be created: %v", err)
+		return nil, grpc.Errorf(codes.InvalidArgument, err.Error())
 	}
 	return &pb.VPNInitResponse{}, nil
 }
@@ -411,12 +795,78 @@ func (s *VPNService) Update(ctx context.Context, req *pb.VPNUpdateRequest) (*pb.
 	case pb.VPNLZOPref_USE_LZO_DISABLE:
 		useLzo = ptr.Bool(false)
 	}
-	if err := ovpm.TheServer().Update(req.IpBlock, req.Dns, useLzo); err != nil {
+	var opts *ovpm.ServerOptions
+	crypto := cryptoOptions(req)
+	if crypto != nil {
+		crypto.Force = req.CryptoForce
+	}
+	var authUserPass *bool
+	switch req.AuthUserPassPref {
+	case pb.VPNAuthUserPassPref_AUTH_USER_PASS_ENABLE:
+		authUserPass = ptr.Bool(true)
+	case pb.VPNAuthUserPassPref_AUTH_USER_PASS_DISABLE:
+		authUserPass = ptr.Bool(false)
+	}
+	if req.CaValidityDays != 0 || req.ServerCertValidityDays != 0 || req.ClientCertValidityDays != 0 || req.CertRenewWindowDays != 0 || req.KeyType != "" || req.TlsCrypt != "" || crypto != nil || authUserPass != nil || req.CaCrl != "" {
+		opts = &ovpm.ServerOptions{
+			CAValidityDays:         int(req.CaValidityDays),
+			ServerCertValidityDays: int(req.ServerCertValidityDays),
+			ClientCertValidityDays: int(req.ClientCertValidityDays),
+			CertRenewWindowDays:    int(req.CertRenewWindowDays),
+			KeyType:                req.KeyType,
+			TLSCrypt:               req.TlsCrypt,
+			Crypto:                 crypto,
+			CACRL:                  req.CaCrl,
+		}
+		opts.AuthUserPass = authUserPass
+	}
+	if err := ovpm.TheServer().Update(req.IpBlock, req.Dns, useLzo, opts); err != nil {
 		logrus.Errorf("server can not be updated: %v", err)
+		return nil, grpc.Errorf(codes.InvalidArgument, err.Error())
 	}
 	return &pb.VPNUpdateResponse{}, nil
 }
 
+// cryptoRequest is a request that has the crypto profile fields.
+type cryptoRequest interface {
+	GetCryptoPreset() string
+	GetDataCiphers() string
+	GetDataCiphersFallback() string
+	GetAuthDigest() string
+	GetTlsVersionMin() string
+	GetTlsCipher() string
+	GetRemoteCertTlsPref() pb.VPNRemoteCertTLSPref
+	GetEcdhOnlyPref() pb.VPNECDHOnlyPref
+}
+
+// cryptoOptions returns the crypto profile changes in the request or nil if there aren't any.
+func cryptoOptions(req cryptoRequest) *ovpm.CryptoOptions {
+	opts := ovpm.CryptoOptions{
+		Preset:              req.GetCryptoPreset(),
+		DataCiphers:         req.GetDataCiphers(),
+		DataCiphersFallback: req.GetDataCiphersFallback(),
+		AuthDigest:          req.GetAuthDigest(),
+		TLSVersionMin:       req.GetTlsVersionMin(),
+		TLSCipher:           req.GetTlsCipher(),
+	}
+	switch req.GetRemoteCertTlsPref() {
+	case pb.VPNRemoteCertTLSPref_REMOTE_CERT_TLS_ENABLE:
+		opts.RemoteCertTLS = ptr.Bool(true)
+	case pb.VPNRemoteCertTLSPref_REMOTE_CERT_TLS_DISABLE:
+		opts.RemoteCertTLS = ptr.Bool(false)
+	}
+	switch req.GetEcdhOnlyPref() {
+	case pb.VPNECDHOnlyPref_ECDH_ONLY_ENABLE:
+		opts.ECDHOnly = ptr.Bool(true)
+	case pb.VPNECDHOnlyPref_ECDH_ONLY_DISABLE:
+		opts.ECDHOnly = ptr.Bool(false)
+	}
+	if opts == (ovpm.CryptoOptions{}) {
+		return nil
+	}
+	return &opts
+}
+
 func (s *VPNService) Restart(ctx context.Context, req *pb.VPNRestartRequest) (*pb.VPNRestartResponse, error) {
 	logrus.Debugf("rpc call: vpn restart")
 	perms, err := permset.FromContext(ctx)
@@ -432,6 +882,78 @@ func (s *VPNService) Restart(ctx context.Context, req *pb.VPNRestartRequest) (*p
 	return &pb.VPNRestartResponse{}, nil
 }
 
+func (s *VPNService) RotateCA(ctx context.Context, req *pb.VPNRotateCARequest) (*pb.VPNRotateCAResponse, error) {
+	logrus.Debugf("rpc call: vpn rotate-ca")
+	perms, err := permset.FromContext(ctx)
+	if err != nil {
+		return nil, grpc.Errorf(codes.Unauthenticated, "Can't get permset from context")
+	}
+
+	if !perms.Contains(ovpm.RotateCAPerm) {
+		return nil, grpc.Errorf(codes.PermissionDenied, "ovpm.RotateCAPerm is required for this operation.")
+	}
+
+	if err := ovpm.TheServer().RotateCA(int(req.GracePeriodDays)); err != nil {
+		logrus.Errorf("ca can not be rotated: %v", err)
+		return nil, err
+	}
+	return &pb.VPNRotateCAResponse{}, nil
+}
+
+func (s *VPNService) RotateTLSCryptKey(ctx context.Context, req *pb.VPNRotateTLSCryptKeyRequest) (*pb.VPNRotateTLSCryptKeyResponse, error) {
+	logrus.Debugf("rpc call: vpn rotate-tls-crypt-key")
+	perms, err := permset.FromContext(ctx)
+	if err != nil {
+		return nil, grpc.Errorf(codes.Unauthenticated, "Can't get permset from context")
+	}
+
+	if !perms.Contains(ovpm.RotateCAPerm) {
+		return nil, grpc.Errorf(codes.PermissionDenied, "ovpm.RotateCAPerm is required for this operation.")
+	}
+
+	if err := ovpm.TheServer().RotateTLSCryptKey(); err != nil {
+		logrus.Errorf("tls crypt key can not be rotated: %v", err)
+		return nil, err
+	}
+	return &pb.VPNRotateTLSCryptKeyResponse{}, nil
+}
+
+func (s *VPNService) TakeCAOffline(ctx context.Context, req *pb.VPNTakeCAOfflineRequest) (*pb.VPNTakeCAOfflineResponse, error) {
+	logrus.Debugf("rpc call: vpn take-ca-offline")
+	perms, err := permset.FromContext(ctx)
+	if err != nil {
+		return nil, grpc.Errorf(codes.Unauthenticated, "Can't get permset from context")
+	}
+
+	if !perms.Contains(ovpm.TakeCAOfflinePerm) {
+		return nil, grpc.Errorf(codes.PermissionDenied, "ovpm.TakeCAOfflinePerm is required for this operation.")
+	}
+
+	if err := ovpm.TheServer().TakeCAOffline(req.KeyFile, []byte(req.Passphrase)); err != nil {
+		logrus.Errorf("ca can not be taken offline: %v", err)
+		return nil, err
+	}
+	return &pb.VPNTakeCAOfflineResponse{}, nil
+}
+
+func (s *VPNService) VerifyUserPass(ctx context.Context, req *pb.VPNVerifyUserPassRequest) (*pb.VPNVerifyUserPassResponse, error) {
+	logrus.Debugf("rpc call: vpn verify-user-pass: %s", req.Username)
+	perms, err := permset.FromContext(ctx)
+	if err != nil {
+		return nil, grpc.Errorf(codes.Unauthenticated, "Can't get permset from context")
+	}
+
+	if !perms.Contains(ovpm.VerifyUserPassPerm) {
+		return nil, grpc.Errorf(codes.PermissionDenied, "ovpm.VerifyUserPassPerm is required for this operation.")
+	}
+
+	if err := ovpm.VerifyUserPass(req.CommonName, req.Username, req.Password); err != nil {
+		logrus.Warnf("vpn client of %s is denied: %v", req.CommonName, err)
+		return nil, grpc.Errorf(codes.Unauthenticated, "username or password is wrong")
+	}
+	return &pb.VPNVerifyUserPassResponse{}, nil
+}
+
 type NetworkService struct {
 	pb.UnimplementedNetworkServiceServer
 }
@@ -614,15 +1136,324 @@ func (s *NetworkService) Dissociate(ctx context.Context, req *pb.NetworkDissocia
 	return &pb.NetworkDissociateResponse{}, nil
 }
 
+type CertService struct {
+	pb.UnimplementedCertServiceServer
+}
+
+func (s *CertService) ListRevoked(ctx context.Context, req *pb.CertListRevokedRequest) (*pb.CertListRevokedResponse, error) {
+	logrus.Debug("rpc call: cert list revoked")
+	perms, err := permset.FromContext(ctx)
+	if err != nil {
+		return nil, grpc.Errorf(codes.Unauthenticated, "Can't get permset from context")
+	}
+
+	if !perms.Contains(ovpm.ListRevokedCertsPerm) {
+		return nil, grpc.Errorf(codes.PermissionDenied, "ovpm.ListRevokedCertsPerm is required for this operation.")
+	}
+
+	revoked, err := ovpm.GetRevokedCerts()
+	if err != nil {
+		logrus.Errorf("revoked certs can not be fetched: %v", err)
+		return nil, err
+	}
+
+	var rcl []*pb.CertListRevokedResponse_RevokedCert
+	for _, r := range revoked {
+		rcl = append(rcl, &pb.CertListRevokedResponse_RevokedCert{
+			SerialNumber: r.SerialNumber,
+			Username:     r.Username,
+			Reason:       r.Reason.String(),
+			RevokedAt:    r.RevokedAt.UTC().Format(time.RFC3339),
+			RevokedBy:    r.RevokedBy,
+		})
+	}
+
+	return &pb.CertListRevokedResponse{RevokedCerts: rcl}, nil
+}
+
+type LDAPService struct {
+	pb.UnimplementedLDAPServiceServer
+}
+
+func (s *LDAPService) GetConfig(ctx context.Context, req *pb.LDAPGetConfigRequest) (*pb.LDAPConfigResponse, error) {
+	logrus.Debug("rpc call: ldap get config")
+	perms, err := permset.FromContext(ctx)
+	if err != nil {
+		return nil, grpc.Errorf(codes.Unauthenticated, "Can't get permset from context")
+	}
+
+	if !perms.Contains(ovpm.GetLDAPConfigPerm) {
+		return nil, grpc.Errorf(codes.PermissionDenied, "ovpm.GetLDAPConfigPerm is required for this operation.")
+	}
+
+	return ldapConfigResponse(), nil
+}
+
+func (s *LDAPService) SetConfig(ctx context.Context, req *pb.LDAPSetConfigRequest) (*pb.LDAPConfigResponse, error) {
+	logrus.Debug("rpc call: ldap set config")
+	perms, err := permset.FromContext(ctx)
+	if err != nil {
+		return nil, grpc.Errorf(codes.Unauthenticated, "Can't get permset from context")
+	}
+
+	if !perms.Contains(ovpm.UpdateLDAPConfigPerm) {
+		return nil, grpc.Errorf(codes.PermissionDenied, "ovpm.UpdateLDAPConfigPerm is required for this operation.")
+	}
+
+	cfg := ovpm.LDAPConfig{
+		URL:                req.Url,
+		StartTLS:           req.StartTls,
+		InsecurePlaintext:  req.InsecurePlaintext,
+		InsecureSkipVerify: req.InsecureSkipVerify,
+		BindDN:             req.BindDn,
+		BindPassword:       req.BindPassword,
+		UserBaseDN:         req.UserBaseDn,
+		UsernameAttr:       req.UsernameAttr,
+		UserFilter:         req.UserFilter,
+		GroupDN:            req.GroupDn,
+		AdminGroupDN:       req.AdminGroupDn,
+		RemovalPolicy:      req.RemovalPolicy,
+	}
+	if err := ovpm.SetLDAPConfig(cfg); err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, err.Error())
+	}
+
+	return ldapConfigResponse(), nil
+}
+
+func (s *LDAPService) Disable(ctx context.Context, req *pb.LDAPDisableRequest) (*pb.LDAPConfigResponse, error) {
+	logrus.Debug("rpc call: ldap disable")
+	perms, err := permset.FromContext(ctx)
+	if err != nil {
+		return nil, grpc.Errorf(codes.Unauthenticated, "Can't get permset from context")
+	}
+
+	if !perms.Contains(ovpm.UpdateLDAPConfigPerm) {
+		return nil, grpc.Errorf(codes.PermissionDenied, "ovpm.UpdateLDAPConfigPerm is required for this operation.")
+	}
+
+	if err := ovpm.DisableLDAP(); err != nil {
+		return nil, err
+	}
+
+	return ldapConfigResponse(), nil
+}
+
+func (s *LDAPService) Sync(ctx context.Context, req *pb.LDAPSyncRequest) (*pb.LDAPSyncResponse, error) {
+	logrus.Debug("rpc call: ldap sync")
+	perms, err := permset.FromContext(ctx)
+	if err != nil {
+		return nil, grpc.Errorf(codes.Unauthenticated, "Can't get permset from context")
+	}
+
+	if !perms.Contains(ovpm.SyncLDAPPerm) {
+		return nil, grpc.Errorf(codes.PermissionDenied, "ovpm.SyncLDAPPerm is required for this operation.")
+	}
+
+	result, err := ovpm.SyncLDAPUsers()
+	if err != nil {
+		return nil, grpc.Errorf(codes.FailedPrecondition, err.Error())
+	}
+
+	return &pb.LDAPSyncResponse{
+		Created:  result.Created,
+		Enabled:  result.Enabled,
+		Disabled: result.Disabled,
+		Deleted:  result.Deleted,
+		Promoted: result.Promoted,
+		Demoted:  result.Demoted,
+	}, nil
+}
+
+// ldapConfigResponse returns the LDAP configuration without the bind password.
+func ldapConfigResponse() *pb.LDAPConfigResponse {
+	cfg := ovpm.GetLDAPConfig()
+	if cfg == nil {
+		return &pb.LDAPConfigResponse{}
+	}
+	return &pb.LDAPConfigResponse{
+		Enabled:            true,
+		Url:                cfg.URL,
+		StartTls:           cfg.StartTLS,
+		InsecurePlaintext:  cfg.InsecurePlaintext,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+		BindDn:             cfg.BindDN,
+		UserBaseDn:         cfg.UserBaseDN,
+		UsernameAttr:       cfg.UsernameAttr,
+		UserFilter:         cfg.UserFilter,
+		GroupDn:            cfg.GroupDN,
+		AdminGroupDn:       cfg.AdminGroupDN,
+		RemovalPolicy:      cfg.RemovalPolicy,
+	}
+}
+
+type OIDCService struct {
+	pb.UnimplementedOIDCServiceServer
+}
+
+func (s *OIDCService) GetConfig(ctx context.Context, req *pb.OIDCGetConfigRequest) (*pb.OIDCConfigResponse, error) {
+	logrus.Debug("rpc call: oidc get config")
+	perms, err := permset.FromContext(ctx)
+	if err != nil {
+		return nil, grpc.Errorf(codes.Unauthenticated, "Can't get permset from context")
+	}
+
+	if !perms.Contains(ovpm.GetOIDCConfigPerm) {
+		return nil, grpc.Errorf(codes.PermissionDenied, "ovpm.GetOIDCConfigPerm is required for this operation.")
+	}
+
+	return oidcConfigResponse(), nil
+}
+
+func (s *OIDCService) SetConfig(ctx context.Context, req *pb.OIDCSetConfigRequest) (*pb.OIDCConfigResponse, error) {
+	logrus.Debug("rpc call: oidc set config")
+	perms, err := permset.FromContext(ctx)
+	if err != nil {
+		return nil, grpc.Errorf(codes.Unauthenticated, "Can't get permset from context")
+	}
+
+	if !perms.Contains(ovpm.UpdateOIDCConfigPerm) {
+		return nil, grpc.Errorf(codes.PermissionDenied, "ovpm.UpdateOIDCConfigPerm is required for this operation.")
+	}
+
+	cfg := ovpm.OIDCConfig{
+		Issuer:        req.Issuer,
+		ClientID:      req.ClientId,
+		ClientSecret:  req.ClientSecret,
+		RedirectURL:   req.RedirectUrl,
+		UsernameClaim: req.UsernameClaim,
+		GroupsClaim:   req.GroupsClaim,
+		AdminGroup:    req.AdminGroup,
+	}
+	if err := ovpm.SetOIDCConfig(cfg); err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, err.Error())
+	}
+
+	return oidcConfigResponse(), nil
+}
+
+func (s *OIDCService) Disable(ctx context.Context, req *pb.OIDCDisableRequest) (*pb.OIDCConfigResponse, error) {
+	logrus.Debug("rpc call: oidc disable")
+	perms, err := permset.FromContext(ctx)
+	if err != nil {
+		return nil, grpc.Errorf(codes.Unauthenticated, "Can't get permset from context")
+	}
+
+	if !perms.Contains(ovpm.UpdateOIDCConfigPerm) {
+		return nil, grpc.Errorf(codes.PermissionDenied, "ovpm.UpdateOIDCConfigPerm is required for this operation.")
+	}
+
+	if err := ovpm.DisableOIDC(); err != nil {
+		return nil, err
+	}
+
+	return oidcConfigResponse(), nil
+}
+
+func (s *OIDCService) Link(ctx context.Context, req *pb.OIDCLinkRequest) (*pb.OIDCLinkResponse, error) {
+	logrus.Debugf("rpc call: oidc link: %s", req.Username)
+	perms, err := permset.FromContext(ctx)
+	if err != nil {
+		return nil, grpc.Errorf(codes.Unauthenticated, "Can't get permset from context")
+	}
+
+	if !perms.Contains(ovpm.UpdateOIDCConfigPerm) {
+		return nil, grpc.Errorf(codes.PermissionDenied, "ovpm.UpdateOIDCConfigPerm is required for this operation.")
+	}
+
+	user, err := ovpm.GetUser(req.Username)
+	if err != nil {
+		return nil, err
+	}
+	if err := user.LinkOIDC(req.Subject); err != nil {
+		return nil, err
+	}
+
+	return &pb.OIDCLinkResponse{}, nil
+}
+
+// oidcConfigResponse returns the OIDC configuration without the client secret.
+func oidcConfigResponse() *pb.OIDCConfigResponse {
+	cfg := ovpm.GetOIDCConfig()
+	if cfg == nil {
+		return &pb.OIDCConfigResponse{}
+	}
+	return &pb.OIDCConfigResponse{
+		Enabled:       true,
+		Issuer:        cfg.Issuer,
+		ClientId:      cfg.ClientID,
+		RedirectUrl:   cfg.RedirectURL,
+		UsernameClaim: cfg.UsernameClaim,
+		GroupsClaim:   cfg.GroupsClaim,
+		AdminGroup:    cfg.AdminGroup,
+	}
+}
+
+type AuditService struct {
+	pb.UnimplementedAuditServiceServer
+}
+
+func (s *AuditService) List(ctx context.Context, req *pb.AuditListRequest) (*pb.AuditListResponse, error) {
+	logrus.Debug("rpc call: audit list")
+	perms, err := permset.FromContext(ctx)
+	if err != nil {
+		return nil, grpc.Errorf(codes.Unauthenticated, "Can't get permset from context")
+	}
+
+	if !perms.Contains(ovpm.ListAuditLogPerm) {
+		return nil, grpc.Errorf(codes.PermissionDenied, "ovpm.ListAuditLogPerm is required for this operation.")
+	}
+
+	filter := ovpm.AuditFilter{Actor: req.Actor, Limit: int(req.Limit)}
+	if req.Since != "" {
+		since, err := time.Parse(time.RFC3339, req.Since)
+		if err != nil {
+			return nil, grpc.Errorf(codes.InvalidArgument, "since should be an RFC3339 timestamp: %s", req.Since)
+		}
+		filter.Since = since
+	}
+	entries, err := ovpm.GetAuditEntries(filter)
+	if err != nil {
+		return nil, grpc.Errorf(codes.Internal, err.Error())
+	}
+
+	var resp pb.AuditListResponse
+	for _, e := range entries {
+		entry := &pb.AuditListResponse_Entry{
+			Id:         uint32(e.ID),
+			CreatedAt:  e.CreatedAt.UTC().Format(time.RFC3339),
+			Actor:      e.Actor,
+			Origin:     e.Origin,
+			SourceIp:   e.SourceIP,
+			Action:     e.Action,
+			TargetType: e.TargetType,
+			Target:     e.Target,
+			Result:     e.Result,
+		}
+		for _, c := range e.Changes {
+			before, _ := json.Marshal(c.Before)
+			after, _ := json.Marshal(c.After)
+			entry.Changes = append(entry.Changes, &pb.AuditListResponse_Change{Field: c.Field, Before: string(before), After: string(after)})
+		}
+		resp.Entries = append(resp.Entries, entry)
+	}
+	return &resp, nil
+}
+
 // NewRPCServer returns a new gRPC server.
 func NewRPCServer() *grpc.Server {
 	var opts []grpc.ServerOption
-	opts = append(opts, grpc.UnaryInterceptor(AuthUnaryInterceptor))
+	opts = append(opts, grpc.ChainUnaryInterceptor(MetricsUnaryInterceptor, AuthUnaryInterceptor, AuditUnaryInterceptor))
 	s := grpc.NewServer(opts...)
 	//s := grpc.NewServer()
 	pb.RegisterUserServiceServer(s, &UserService{})
 	pb.RegisterVPNServiceServer(s, &VPNService{})
 	pb.RegisterNetworkServiceServer(s, &NetworkService{})
 	pb.RegisterAuthServiceServer(s, &AuthService{})
+	pb.RegisterCertServiceServer(s, &CertService{})
+	pb.RegisterLDAPServiceServer(s, &LDAPService{})
+	pb.RegisterOIDCServiceServer(s, &OIDCService{})
+	pb.RegisterAuditServiceServer(s, &AuditService{})
+	grpc_health_v1.RegisterHealthServer(s, &HealthService{})
 	return s
 }
@@ -0,0 +1,324 @@
+package ovpm
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/cad/ovpm/pki"
+	"github.com/sirupsen/logrus"
+)
+
+// Kinds of the audited targets.
+const (
+	AuditTargetUser    = "user"
+	AuditTargetNetwork = "network"
+	AuditTargetVPN     = "vpn"
+	AuditTargetSession = "session" // Sessions (API tokens) of a user.
+	AuditTargetLDAP    = "ldap"
+	AuditTargetOIDC    = "oidc"
+
+	// AuditTargetAll is the kind of the actions that change many targets,
+	// e.g. the LDAP sync and the background jobs. Its changes are prefixed
+	// with the changed target, e.g. user/john.disabled or vpn.serial_number.
+	AuditTargetAll = "all"
+)
+
+// AuditResultOK is the result of the actions that succeeded.
+const AuditResultOK = "ok"
+
+// AuditActorSystem is the actor of the background jobs of ovpmd.
+const AuditActorSystem = "system"
+
+// dbAuditModel is database model for the audit log.
+//
+// Entries are never updated or deleted.
+type dbAuditModel struct {
+	ID         uint      `gorm:"primary_key"`
+	CreatedAt  time.Time `gorm:"index"`
+	Actor      string    `gorm:"index"`
+	Origin     string
+	SourceIP   string
+	Action     string
+	TargetType string
+	Target     string
+	Changes    string // JSON encoded []AuditChange.
+	Result     string
+}
+
+// AuditChange is a change of a field of the target.
+type AuditChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditEntry represents an administrative action.
+type AuditEntry struct {
+	ID         uint          `json:"id"`
+	CreatedAt  time.Time     `json:"created_at"`
+	Actor      string        `json:"actor"`       // Username of the caller, root for the local CLI, AuditActorSystem for the background jobs.
+	Origin     string        `json:"origin"`      // Where the request came from, e.g. rest or grpc.
+	SourceIP   string        `json:"source_ip"`   // IP address of the client.
+	Action     string        `json:"action"`      // e.g. UserService/Create
+	TargetType string        `json:"target_type"` // One of the AuditTarget* kinds.
+	Target     string        `json:"target"`      // e.g. the username
+	Changes    []AuditChange `json:"changes"`
+	Result     string        `json:"result"` // AuditResultOK or the error.
+}
+
+// AuditFilter selects the audit entries.
+type AuditFilter struct {
+	Since time.Time // Zero means from the beginning.
+	Actor string    // Empty means any actor.
+	Limit int       // Zero means no limit.
+}
+
+// RecordAudit appends the entry to the audit log.
+func RecordAudit(e *AuditEntry) error {
+	changes, err := json.Marshal(e.Changes)
+	if err != nil {
+		return fmt.Errorf("can not encode audit changes: %v", err)
+	}
+	m := dbAuditModel{
+		// Times are stored in UTC, so that they compare correctly as text
+		// regardless of the local zone and its DST changes.
+		CreatedAt:  time.Now().UTC(),
+		Actor:      e.Actor,
+		Origin:     e.Origin,
+		SourceIP:   e.SourceIP,
+		Action:     e.Action,
+		TargetType: e.TargetType,
+		Target:     e.Target,
+		Changes:    string(changes),
+		Result:     e.Result,
+	}
+	db.Create(&m)
+	if db.NewRecord(&m) {
+		return fmt.Errorf("can not save audit entry")
+	}
+	e.ID, e.CreatedAt = m.ID, m.CreatedAt
+	logrus.Debugf("audit: %s %s %s %s: %s", e.Actor, e.Action, e.TargetType, e.Target, e.Result)
+	return nil
+}
+
+// GetAuditEntries returns the audit entries that match the filter, oldest
+// first.
+func GetAuditEntries(filter AuditFilter) ([]*AuditEntry, error) {
+	q := db.Order("id asc")
+	if !filter.Since.IsZero() {
+		q = q.Where("created_at >= ?", filter.Since.UTC())
+	}
+	if filter.Actor != "" {
+		q = q.Where("actor = ?", filter.Actor)
+	}
+	if filter.Limit > 0 {
+		q = q.Limit(filter.Limit)
+	}
+	var models []*dbAuditModel
+	if err := q.Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("can not get audit entries: %v", err)
+	}
+	var entries []*AuditEntry
+	for _, m := range models {
+		e := &AuditEntry{
+			ID:         m.ID,
+			CreatedAt:  m.CreatedAt,
+			Actor:      m.Actor,
+			Origin:     m.Origin,
+			SourceIP:   m.SourceIP,
+			Action:     m.Action,
+			TargetType: m.TargetType,
+			Target:     m.Target,
+			Result:     m.Result,
+		}
+		if err := json.Unmarshal([]byte(m.Changes), &e.Changes); err != nil {
+			logrus.Warnf("audit entry %d has malformed changes: %v", m.ID, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// AuditSnapshot returns the state of the target that is recorded in the
+// audit log, or nil if the target doesn't exist.
+//
+// Secrets such as the password hashes and the keys are left out.
+func AuditSnapshot(targetType, target string) map[string]interface{} {
+	switch targetType {
+	case AuditTargetUser:
+		user, err := GetUser(target)
+		if err != nil {
+			return nil
+		}
+		return userAuditSnapshot(user)
+	case AuditTargetSession:
+		user, err := GetUser(target)
+		if err != nil {
+			return nil
+		}
+		tokens, err := user.GetTokens()
+		if err != nil {
+			return nil
+		}
+		ids := []uint{}
+		for _, t := range tokens {
+			ids = append(ids, t.GetID())
+		}
+		return map[string]interface{}{"ids": ids}
+	case AuditTargetNetwork:
+		network, err := GetNetwork(target)
+		if err != nil {
+			return nil
+		}
+		users := network.GetAssociatedUsernames()
+		sort.Strings(users)
+		return map[string]interface{}{
+			"cidr":  network.GetCIDR(),
+			"type":  network.GetType().String(),
+			"via":   network.GetVia(),
+			"users": users,
+		}
+	case AuditTargetVPN:
+		svr := TheServer()
+		if !svr.IsInitialized() {
+			return nil
+		}
+		return map[string]interface{}{
+			"hostname":                  svr.GetHostname(),
+			"port":                      svr.GetPort(),
+			"proto":                     svr.GetProto(),
+			"net":                       svr.GetNet(),
+			"mask":                      svr.GetMask(),
+			"dns":                       svr.GetDNS(),
+			"use_lzo":                   svr.IsUseLZO(),
+			"auth_user_pass":            svr.IsAuthUserPass(),
+			"serial_number":             svr.GetSerialNumber(),
+			"ca_expires_at":             svr.CAExpiresAt().UTC().Format(time.RFC3339),
+			"ca_validity_days":          svr.GetCAValidityDays(),
+			"server_cert_validity_days": svr.GetServerCertValidityDays(),
+			"client_cert_validity_days": svr.GetClientCertValidityDays(),
+			"cert_renew_window_days":    svr.GetCertRenewWindowDays(),
+			"key_type":                  string(svr.GetKeyType()),
+			"previous_cas":              len(activeCAs()),
+		}
+	case AuditTargetLDAP:
+		cfg := GetLDAPConfig()
+		if cfg == nil {
+			return nil
+		}
+		return map[string]interface{}{
+			"url":                  cfg.URL,
+			"start_tls":            cfg.StartTLS,
+			"insecure_plaintext":   cfg.InsecurePlaintext,
+			"insecure_skip_verify": cfg.InsecureSkipVerify,
+			"bind_dn":              cfg.BindDN,
+			"user_base_dn":         cfg.UserBaseDN,
+			"username_attr":        cfg.UsernameAttr,
+			"user_filter":          cfg.UserFilter,
+			"group_dn":             cfg.GroupDN,
+			"admin_group_dn":       cfg.AdminGroupDN,
+			"removal_policy":       cfg.RemovalPolicy,
+		}
+	case AuditTargetOIDC:
+		cfg := GetOIDCConfig()
+		if cfg == nil {
+			return nil
+		}
+		return map[string]interface{}{
+			"issuer":         cfg.Issuer,
+			"client_id":      cfg.ClientID,
+			"redirect_url":   cfg.RedirectURL,
+			"username_claim": cfg.UsernameClaim,
+			"groups_claim":   cfg.GroupsClaim,
+			"admin_group":    cfg.AdminGroup,
+		}
+	case AuditTargetAll:
+		s := make(map[string]interface{})
+		users, err := GetAllUsers()
+		if err != nil {
+			return nil
+		}
+		for _, user := range users {
+			for f, v := range userAuditSnapshot(user) {
+				s[AuditTargetUser+"/"+user.Username+"."+f] = v
+			}
+		}
+		for f, v := range AuditSnapshot(AuditTargetVPN, "server") {
+			s[AuditTargetVPN+"."+f] = v
+		}
+		return s
+	}
+	return nil
+}
+
+// userAuditSnapshot returns the state of the user that is recorded in the
+// audit log.
+func userAuditSnapshot(user *User) map[string]interface{} {
+	s := map[string]interface{}{
+		"admin":        user.Admin,
+		"no_gw":        user.NoGW,
+		"host_id":      user.HostID,
+		"description":  user.Description,
+		"disabled":     user.Disabled,
+		"auth_source":  user.GetAuthSource(),
+		"totp_enabled": user.TOTPEnabled,
+	}
+	if crt, err := pki.ReadCertFromPEM(user.Cert); err == nil {
+		s["cert_serial"] = crt.SerialNumber.Text(16)
+		s["cert_expires_at"] = crt.NotAfter.UTC().Format(time.RFC3339)
+	}
+	return s
+}
+
+// AuditJob runs the background job of ovpmd and records its changes to the
+// users and the VPN server in the audit log as AuditActorSystem.
+//
+// Since the jobs run periodically, they are only recorded if they change
+// something or fail.
+func AuditJob(action string, job func() error) error {
+	before := AuditSnapshot(AuditTargetAll, "")
+	err := job()
+	changes := AuditDiff(before, AuditSnapshot(AuditTargetAll, ""))
+	if err == nil && len(changes) == 0 {
+		return nil
+	}
+	entry := &AuditEntry{
+		Actor:      AuditActorSystem,
+		Origin:     "ovpmd",
+		Action:     action,
+		TargetType: AuditTargetAll,
+		Changes:    changes,
+		Result:     AuditResultOK,
+	}
+	if err != nil {
+		entry.Result = err.Error()
+	}
+	if auditErr := RecordAudit(entry); auditErr != nil {
+		logrus.Errorf("audit entry can not be recorded: %v", auditErr)
+	}
+	return err
+}
+
+// AuditDiff returns the fields that are different in the snapshots, sorted
+// by the field names.
+func AuditDiff(before, after map[string]interface{}) []AuditChange {
+	fields := make(map[string]bool)
+	for f := range before {
+		fields[f] = true
+	}
+	for f := range after {
+		fields[f] = true
+	}
+	var changes []AuditChange
+	for f := range fields {
+		b, a := before[f], after[f]
+		if !reflect.DeepEqual(b, a) {
+			changes = append(changes, AuditChange{Field: f, Before: b, After: a})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
@@ -0,0 +1,159 @@
+package ovpm
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestAuditLog(t *testing.T) {
+	// Initialize:
+	setupTestCase()
+	CreateDB("sqlite3", ":memory:")
+	defer db.Cease()
+
+	// Test:
+	var entries = []*AuditEntry{
+		{Actor: "root", Origin: "grpc", SourceIP: "127.0.0.1", Action: "UserService/Create", TargetType: AuditTargetUser, Target: "john", Result: AuditResultOK,
+			Changes: []AuditChange{{Field: "admin", Before: nil, After: false}}},
+		{Actor: "admin", Origin: "rest", SourceIP: "10.0.0.2", Action: "UserService/Delete", TargetType: AuditTargetUser, Target: "john", Result: AuditResultOK},
+		{Actor: "root", Origin: "grpc", SourceIP: "127.0.0.1", Action: "NetworkService/Create", TargetType: AuditTargetNetwork, Target: "lan", Result: "network already exists"},
+	}
+	for _, e := range entries {
+		if err := RecordAudit(e); err != nil {
+			t.Fatalf("can not record audit entry: %v", err)
+		}
+		if e.ID == 0 || e.CreatedAt.IsZero() {
+			t.Errorf("audit entry is expected to be saved: %+v", e)
+		}
+	}
+
+	all, err := GetAuditEntries(AuditFilter{})
+	if err != nil || len(all) != 3 {
+		t.Fatalf("3 audit entries are expected: %v", err)
+	}
+	if all[0].Action != "UserService/Create" || len(all[0].Changes) != 1 || all[0].Changes[0].Field != "admin" {
+		t.Errorf("audit entries are expected to be oldest first with their changes: %+v", all[0])
+	}
+	if e, _ := GetAuditEntries(AuditFilter{Actor: "root"}); len(e) != 2 {
+		t.Errorf("2 audit entries of root are expected but got %d", len(e))
+	}
+	if e, _ := GetAuditEntries(AuditFilter{Limit: 1}); len(e) != 1 {
+		t.Errorf("1 audit entry is expected but got %d", len(e))
+	}
+	if e, _ := GetAuditEntries(AuditFilter{Since: time.Now().Add(-time.Hour).In(time.FixedZone("UTC+14", 14*3600))}); len(e) == 0 {
+		t.Errorf("entries are expected to be filtered regardless of the time zone")
+	}
+	if e, _ := GetAuditEntries(AuditFilter{Since: time.Now().Add(time.Hour)}); len(e) != 0 {
+		t.Errorf("no audit entries are expected in the future but got %d", len(e))
+	}
+}
+
+func TestAuditSnapshot(t *testing.T) {
+	// Initialize:
+	setupTestCase()
+	CreateDB("sqlite3", ":memory:")
+	defer db.Cease()
+	svr := TheServer()
+
+	// Test:
+	if s := AuditSnapshot(AuditTargetVPN, "server"); s != nil {
+		t.Errorf("vpn snapshot is expected to be nil before the init: %v", s)
+	}
+	svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil)
+	if s := AuditSnapshot(AuditTargetVPN, "server"); s == nil || s["hostname"] != "localhost" {
+		t.Errorf("vpn snapshot is expected to have the hostname: %v", s)
+	}
+
+	// Creation shows every field as a change.
+	before := AuditSnapshot(AuditTargetUser, "john")
+	user, err := CreateNewUser("john", "1234", false, 0, false, "description")
+	if err != nil {
+		t.Fatalf("user creation failed: %v", err)
+	}
+	after := AuditSnapshot(AuditTargetUser, "john")
+	if before != nil || after["cert_serial"] == nil {
+		t.Errorf("user snapshot is expected to have the cert serial: %v", after)
+	}
+	if _, ok := after["password"]; ok {
+		t.Errorf("user snapshot is expected to leave out the password")
+	}
+	if changes := AuditDiff(before, after); len(changes) != len(after) {
+		t.Errorf("every field is expected to be changed: %v", changes)
+	}
+
+	// Updates show only the changed fields.
+	before = after
+	if err := user.Update("", false, 0, true, "description"); err != nil {
+		t.Fatalf("user update failed: %v", err)
+	}
+	changes := AuditDiff(before, AuditSnapshot(AuditTargetUser, "john"))
+	if len(changes) != 1 || changes[0].Field != "admin" || changes[0].Before != false || changes[0].After != true {
+		t.Errorf("only the admin field is expected to be changed: %v", changes)
+	}
+
+	// Associations show up on the network.
+	network, err := CreateNewNetwork("lan", "192.168.1.0/24", SERVERNET, "")
+	if err != nil {
+		t.Fatalf("network creation failed: %v", err)
+	}
+	before = AuditSnapshot(AuditTargetNetwork, "lan")
+	if err := network.Associate("john"); err != nil {
+		t.Fatalf("network association failed: %v", err)
+	}
+	changes = AuditDiff(before, AuditSnapshot(AuditTargetNetwork, "lan"))
+	if len(changes) != 1 || changes[0].Field != "users" {
+		t.Errorf("only the users field is expected to be changed: %v", changes)
+	}
+}
+
+func TestAuditJob(t *testing.T) {
+	// Initialize:
+	setupTestCase()
+	CreateDB("sqlite3", ":memory:")
+	defer db.Cease()
+	svr := TheServer()
+	svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil)
+	user, err := CreateNewUser("john", "1234", false, 0, false, "description")
+	if err != nil {
+		t.Fatalf("user creation failed: %v", err)
+	}
+
+	// Test:
+	// Jobs that don't change anything aren't recorded.
+	if err := AuditJob("Noop", func() error { return nil }); err != nil {
+		t.Fatalf("job is expected to succeed: %v", err)
+	}
+	if e, _ := GetAuditEntries(AuditFilter{}); len(e) != 0 {
+		t.Fatalf("no audit entries are expected but got %d", len(e))
+	}
+
+	if err := AuditJob("Promote", func() error { return user.Update("", false, 0, true, "description") }); err != nil {
+		t.Fatalf("job is expected to succeed: %v", err)
+	}
+	if err := AuditJob("Fail", func() error { return fmt.Errorf("job failed") }); err == nil {
+		t.Fatalf("job error is expected to be returned")
+	}
+	entries, _ := GetAuditEntries(AuditFilter{Actor: AuditActorSystem})
+	if len(entries) != 2 {
+		t.Fatalf("2 audit entries of the system are expected but got %d", len(entries))
+	}
+	if e := entries[0]; e.Action != "Promote" || e.TargetType != AuditTargetAll || e.Result != AuditResultOK || len(e.Changes) != 1 || e.Changes[0].Field != "user/john.admin" {
+		t.Errorf("job is expected to be recorded with the changed user: %+v", e)
+	}
+	if e := entries[1]; e.Action != "Fail" || e.Result != "job failed" {
+		t.Errorf("failed job is expected to be recorded with the error: %+v", e)
+	}
+
+	// Sessions are recorded by their ids.
+	before := AuditSnapshot(AuditTargetSession, "john")
+	if _, err := user.IssueToken("laptop", ""); err != nil {
+		t.Fatalf("can not issue token: %v", err)
+	}
+	if changes := AuditDiff(before, AuditSnapshot(AuditTargetSession, "john")); len(changes) != 1 || changes[0].Field != "ids" {
+		t.Errorf("session ids are expected to be changed: %v", changes)
+	}
+	if s := AuditSnapshot(AuditTargetLDAP, "config"); s != nil {
+		t.Errorf("ldap snapshot is expected to be nil when it's not configured: %v", s)
+	}
+}
@@ -0,0 +1,410 @@
+package ovpm
+
+import (
+	"crypto"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cad/ovpm/pki"
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+)
+
+// dbCAHistoryModel is a database model for the previous CAs of the VPN server.
+//
+// A previous CA stays trusted until it's retired, so that the existing .ovpn
+// profiles keep working while the users are re-signed by the new CA.
+type dbCAHistoryModel struct {
+	gorm.Model
+	Cert       string    // Previous CA certificate.
+	Key        string    // Previous CA private key.
+	Chain      string    // Issuer certificates of the previous CA if it was imported.
+	IssuerCRLs string    // CRLs of the issuers of the previous CA if it was an imported intermediate.
+	RetiresAt  time.Time // CA is retired after this time.
+	Retired    bool      // CA is not trusted anymore.
+}
+
+// activeCAs returns the previous CAs that are not retired yet, oldest first.
+func activeCAs() []*dbCAHistoryModel {
+	var cas []*dbCAHistoryModel
+	db.Where("retired = ?", false).Order("id asc").Find(&cas)
+	return cas
+}
+
+// GetCABundle returns the PEM encoded certificates of the current CA and the
+// previous CAs that are not retired yet along with their issuers.
+//
+// It's used as the trusted CA by both the server and the clients.
+func (svr *Server) GetCABundle() string {
+	bundle := []string{strings.TrimSpace(svr.CACert)}
+	if svr.CAChain != "" {
+		bundle = append(bundle, strings.TrimSpace(svr.CAChain))
+	}
+	for _, ca := range activeCAs() {
+		bundle = append(bundle, strings.TrimSpace(ca.Cert))
+		if ca.Chain != "" {
+			bundle = append(bundle, strings.TrimSpace(ca.Chain))
+		}
+	}
+	return strings.Join(bundle, "\n") + "\n"
+}
+
+// GetCARetiresAt returns when the oldest previous CA is going to be retired.
+//
+// It returns the zero time if there isn't any CA rotation in progress.
+func (svr *Server) GetCARetiresAt() time.Time {
+	cas := activeCAs()
+	if len(cas) == 0 {
+		return time.Time{}
+	}
+	return cas[0].RetiresAt
+}
+
+// serverCertCA returns the CA that the server certificate should be signed by.
+//
+// During a CA rotation the server certificate is signed by the oldest active
+// CA. Otherwise the clients that have the old .ovpn profiles couldn't verify
+// the server.
+func (svr *Server) serverCertCA() (*pki.CA, error) {
+	if cas := activeCAs(); len(cas) > 0 {
+		return &pki.CA{CertHolder: pki.CertHolder{Cert: cas[0].Cert, Key: cas[0].Key}}, nil
+	}
+	return svr.GetSystemCA()
+}
+
+// allCAs returns the current CA and the previous CAs that are not retired yet.
+func (svr *Server) allCAs() ([]*pki.CA, error) {
+	ca, err := svr.GetSystemCA()
+	if err != nil {
+		return nil, err
+	}
+	cas := []*pki.CA{ca}
+	for _, c := range activeCAs() {
+		cas = append(cas, &pki.CA{CertHolder: pki.CertHolder{Cert: c.Cert, Key: c.Key}})
+	}
+	return cas, nil
+}
+
+// issuerCRLs returns the CRLs of the issuers of the current CA and the
+// previous CAs that are not retired yet.
+func (svr *Server) issuerCRLs() []string {
+	var crls []string
+	if svr.CAIssuerCRLs != "" {
+		crls = append(crls, svr.CAIssuerCRLs)
+	}
+	for _, c := range activeCAs() {
+		if c.IssuerCRLs != "" {
+			crls = append(crls, c.IssuerCRLs)
+		}
+	}
+	return crls
+}
+
+// isSignedByCurrentCA returns whether the given PEM encoded certificate is signed by the current CA.
+func (svr *Server) isSignedByCurrentCA(cert string) bool {
+	caCrt, err := pki.ReadCertFromPEM(svr.CACert)
+	if err != nil {
+		return false
+	}
+	crt, err := pki.ReadCertFromPEM(cert)
+	if err != nil {
+		return false
+	}
+	return crt.CheckSignatureFrom(caCrt) == nil
+}
+
+// RotateCA generates a new CA for the VPN server without breaking the existing .ovpn profiles.
+//
+// The current CA is kept as a previous CA and trusted along with the new one
+// for 'graceDays' days. Users are re-signed by the new CA gradually by
+// ResignUsers and the previous CA is retired by RetireCAs once the grace
+// period is over. If 'graceDays' is 0, it defaults to const 'DefaultCARotationGraceDays'.
+//
+// The new CA is always self-signed, even if the current one is imported.
+//
+// The tls crypt keys are kept, since the previous keys can't be trusted
+// during the grace period. They're rotated by RotateTLSCryptKey.
+func (svr *Server) RotateCA(graceDays int) error {
+	if !svr.IsInitialized() {
+		return fmt.Errorf("server is not initialized")
+	}
+	if graceDays < 0 {
+		return fmt.Errorf("validation error: grace period can not be negative")
+	}
+	if graceDays == 0 {
+		graceDays = DefaultCARotationGraceDays
+	}
+	if svr.IsCAOffline() {
+		return fmt.Errorf("offline ca can not be rotated")
+	}
+
+	ca, err := pki.NewCAWithOptions(svr.certOptions(svr.GetCAValidityDays()))
+	if err != nil {
+		return fmt.Errorf("can not create ca creds: %s", err)
+	}
+
+	db.Create(&dbCAHistoryModel{
+		Cert:       svr.CACert,
+		Key:        svr.CAKey,
+		Chain:      svr.CAChain,
+		IssuerCRLs: svr.CAIssuerCRLs,
+		RetiresAt:  time.Now().Add(days(graceDays)),
+	})
+	svr.dbServerModel.CACert = ca.Cert
+	svr.dbServerModel.CAKey = ca.Key
+	svr.dbServerModel.CAChain = ""
+	svr.dbServerModel.CAIssuerCRLs = ""
+	db.Save(&svr.dbServerModel)
+
+	if err := svr.EmitWithRestart(); err != nil {
+		return err
+	}
+	logrus.Infof("ca rotated, previous ca is going to be retired in %d days", graceDays)
+	return nil
+}
+
+// ResignUsers re-signs at most 'limit' users whose certificates are issued by
+// a previous CA and returns them. If 'limit' is 0, all of them are re-signed.
+//
+// Re-signed users need to get their .ovpn profiles exported again before the
+// previous CA is retired.
+func ResignUsers(limit int) ([]*User, error) {
+	svr := TheServer()
+	if !svr.IsInitialized() {
+		return nil, fmt.Errorf("you first need to create server")
+	}
+	users, err := GetAllUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	var resigned []*User
+	for _, user := range users {
+		if limit > 0 && len(resigned) >= limit {
+			break
+		}
+		if svr.isSignedByCurrentCA(user.Cert) {
+			continue
+		}
+		// ovpm can't re-sign the certificate without the user's key.
+		if user.HasClientKey() {
+			logrus.Warnf("user %s needs to submit a new csr to be signed by the new ca: $ ovpm user sign-csr --user %s", user.Username, user.Username)
+			continue
+		}
+		// Keep the previous cert valid until the previous CA is retired, since
+		// the user doesn't have the new .ovpn profile yet.
+		if err := user.RenewBy("", time.Until(svr.GetCARetiresAt())); err != nil {
+			logrus.Errorf("can not re-sign user %s: %v", user.Username, err)
+			continue
+		}
+		resigned = append(resigned, user)
+	}
+	return resigned, nil
+}
+
+// RetireCAs stops trusting the previous CAs whose grace period is over.
+//
+// Retired CAs never sign again, so their private keys are removed.
+// Server certificate is re-issued if it's signed by a retired CA. It returns
+// the number of retired CAs.
+func RetireCAs() (int, error) {
+	svr := TheServer()
+	if !svr.IsInitialized() {
+		return 0, fmt.Errorf("you first need to create server")
+	}
+
+	var retired int
+	for _, ca := range activeCAs() {
+		if ca.RetiresAt.After(time.Now()) {
+			continue
+		}
+		ca.Retired = true
+		ca.Key = ""
+		db.Save(ca)
+		retired++
+	}
+	if retired == 0 {
+		return 0, nil
+	}
+
+	if !svr.isServerCertTrusted() {
+		ca, err := svr.serverCertCA()
+		if err != nil {
+			return retired, err
+		}
+		srv, err := pki.NewServerCertHolderWithOptions(ca, svr.certOptions(svr.GetServerCertValidityDays()))
+		if err != nil {
+			return retired, fmt.Errorf("can not create server cert creds: %s", err)
+		}
+		svr.dbServerModel.Cert = srv.Cert
+		svr.dbServerModel.Key = srv.Key
+		db.Save(&svr.dbServerModel)
+	}
+
+	users, err := GetAllUsers()
+	if err != nil {
+		return retired, err
+	}
+	for _, user := range users {
+		if !svr.isTrusted(user.Cert) {
+			logrus.Warnf("user %s has a certificate signed by a retired ca, you should run: $ ovpm user renew --user %s", user.Username, user.Username)
+		}
+	}
+
+	if err := svr.EmitWithRestart(); err != nil {
+		return retired, err
+	}
+	logrus.Infof("%d previous ca(s) retired", retired)
+	return retired, nil
+}
+
+// isServerCertTrusted returns whether the server certificate is signed by a trusted CA.
+func (svr *Server) isServerCertTrusted() bool {
+	return svr.isTrusted(svr.Cert)
+}
+
+// isTrusted returns whether the given PEM encoded certificate is signed by the
+// current CA or one of the previous CAs that are not retired yet.
+func (svr *Server) isTrusted(cert string) bool {
+	crt, err := pki.ReadCertFromPEM(cert)
+	if err != nil {
+		return false
+	}
+	cas, err := svr.allCAs()
+	if err != nil {
+		return false
+	}
+	for _, ca := range cas {
+		caCrt, err := pki.ReadCertFromPEM(ca.Cert)
+		if err != nil {
+			continue
+		}
+		if crt.CheckSignatureFrom(caCrt) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCAOffline returns whether the CA private key is kept out of the database.
+func (svr *Server) IsCAOffline() bool {
+	return svr.CAOffline
+}
+
+// IsCAUnlocked returns whether the CA is able to sign, which is always the
+// case unless the CA is offline and its private key isn't unlocked yet.
+func (svr *Server) IsCAUnlocked() bool {
+	return !svr.IsCAOffline() || svr.getCASigner() != nil
+}
+
+// TakeCAOffline moves the CA private key out of the database into a file
+// encrypted with the passphrase.
+//
+// The key stays unlocked in memory until ovpmd is restarted. After that, it
+// should be unlocked by UnlockCAKeyFile or UnlockCA before issuing
+// certificates and CRLs. The key file can not be under the ovpm's var
+// directory and it's not overwritten if it exists.
+//
+// The previous CAs' private keys are removed from the database as well. The
+// CA can't be taken offline while any of them is still trusted, since they
+// are able to sign certificates that OpenVPN accepts.
+func (svr *Server) TakeCAOffline(path string, passphrase []byte) error {
+	if !svr.IsInitialized() {
+		return fmt.Errorf("server is not initialized")
+	}
+	if svr.IsCAOffline() {
+		return fmt.Errorf("ca is already offline")
+	}
+	if len(activeCAs()) > 0 {
+		return fmt.Errorf("ca can not be taken offline during a ca rotation, previous cas need to be retired first")
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("can not resolve ca key file path: %v", err)
+	}
+	if strings.HasPrefix(path, filepath.Clean(varBasePath)+string(filepath.Separator)) {
+		return fmt.Errorf("validation error: ca key file can not be under %s", varBasePath)
+	}
+
+	signer, err := pki.ParsePrivateKey(svr.CAKey)
+	if err != nil {
+		return fmt.Errorf("can not parse ca key: %v", err)
+	}
+	encrypted, err := pki.EncryptPrivateKey(svr.CAKey, passphrase)
+	if err != nil {
+		return fmt.Errorf("can not encrypt ca key: %v", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("can not create ca key file: %v", err)
+	}
+	if _, err := f.WriteString(encrypted); err != nil {
+		f.Close()
+		return fmt.Errorf("can not write ca key file: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("can not write ca key file: %v", err)
+	}
+
+	svr.setCASigner(signer)
+	svr.dbServerModel.CAKey = ""
+	svr.dbServerModel.CAOffline = true
+	db.Save(&svr.dbServerModel)
+	db.Unscoped().Model(&dbCAHistoryModel{}).Where("key <> ?", "").UpdateColumn("key", "")
+
+	if err := svr.EmitWithRestart(); err != nil {
+		return err
+	}
+	logrus.Infof("ca key is taken offline to %s", path)
+	return nil
+}
+
+// UnlockCAKeyFile decrypts the offline CA key file written by TakeCAOffline
+// with the passphrase and unlocks the CA with it.
+func (svr *Server) UnlockCAKeyFile(path string, passphrase []byte) error {
+	encrypted, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("can not read ca key file: %v", err)
+	}
+	signer, err := pki.DecryptPrivateKey(string(encrypted), passphrase)
+	if err != nil {
+		return err
+	}
+	return svr.UnlockCA(signer)
+}
+
+// UnlockCA makes the offline CA sign with the given signer, which could also
+// be backed by an external device such as an HSM. The signer is only kept in
+// memory.
+func (svr *Server) UnlockCA(signer crypto.Signer) error {
+	if !svr.IsCAOffline() {
+		return fmt.Errorf("ca is not offline")
+	}
+	crt, err := pki.ReadCertFromPEM(svr.CACert)
+	if err != nil || crt == nil {
+		return fmt.Errorf("can not parse ca cert: %v", err)
+	}
+	pub, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(crt.PublicKey) {
+		return fmt.Errorf("ca key does not match the ca cert")
+	}
+	svr.setCASigner(signer)
+	logrus.Infof("offline ca is unlocked")
+	return nil
+}
+
+func (svr *Server) getCASigner() crypto.Signer {
+	svr.caSignerLock.Lock()
+	defer svr.caSignerLock.Unlock()
+	return svr.caSigner
+}
+
+func (svr *Server) setCASigner(signer crypto.Signer) {
+	svr.caSignerLock.Lock()
+	defer svr.caSignerLock.Unlock()
+	svr.caSigner = signer
+}
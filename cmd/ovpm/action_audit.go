@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/cad/ovpm/api/pb"
+	"github.com/cad/ovpm/errors"
+	"github.com/olekukonko/tablewriter"
+)
+
+func auditListAction(rpcServURLStr string, since, actor string, limit int, asJSON bool) error {
+	// Parse RPC Server's URL.
+	rpcSrvURL, err := url.Parse(rpcServURLStr)
+	if err != nil {
+		return errors.BadURL(rpcServURLStr, err)
+	}
+
+	// Create a gRPC connection to the server.
+	rpcConn, err := grpcConnect(rpcSrvURL)
+	if err != nil {
+		exit(1)
+		return err
+	}
+	defer rpcConn.Close()
+
+	// Get services.
+	var auditSvc = pb.NewAuditServiceClient(rpcConn)
+
+	resp, err := auditSvc.List(context.Background(), &pb.AuditListRequest{Since: since, Actor: actor, Limit: uint32(limit)})
+	if err != nil {
+		err := errors.UnknownGRPCError(err)
+		exit(1)
+		return err
+	}
+
+	// Print JSON lines.
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		for _, e := range resp.Entries {
+			changes := make([]map[string]json.RawMessage, 0, len(e.Changes))
+			for _, c := range e.Changes {
+				changes = append(changes, map[string]json.RawMessage{
+					"field":  json.RawMessage(fmt.Sprintf("%q", c.Field)),
+					"before": json.RawMessage(c.Before),
+					"after":  json.RawMessage(c.After),
+				})
+			}
+			enc.Encode(map[string]interface{}{
+				"id":          e.Id,
+				"created_at":  e.CreatedAt,
+				"actor":       e.Actor,
+				"origin":      e.Origin,
+				"source_ip":   e.SourceIp,
+				"action":      e.Action,
+				"target_type": e.TargetType,
+				"target":      e.Target,
+				"changes":     changes,
+				"result":      e.Result,
+			})
+		}
+		return nil
+	}
+
+	// Prepare table data and draw it on the terminal.
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"#", "time", "actor", "origin", "action", "target", "changes", "result"})
+	for _, e := range resp.Entries {
+		var changes []string
+		for _, c := range e.Changes {
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", c.Field, c.Before, c.After))
+		}
+		table.Append([]string{
+			fmt.Sprintf("%d", e.Id),
+			e.CreatedAt,
+			e.Actor,
+			fmt.Sprintf("%s %s", e.Origin, e.SourceIp),
+			e.Action,
+			fmt.Sprintf("%s %s", e.TargetType, e.Target),
+			strings.Join(changes, "\n"),
+			e.Result,
+		})
+	}
+	table.Render()
+	return nil
+}
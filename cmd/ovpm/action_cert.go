@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/cad/ovpm/api/pb"
+	"github.com/cad/ovpm/errors"
+	"github.com/olekukonko/tablewriter"
+)
+
+func certRevokedAction(rpcServURLStr string) error {
+	// Parse RPC Server's URL.
+	rpcSrvURL, err := url.Parse(rpcServURLStr)
+	if err != nil {
+		return errors.BadURL(rpcServURLStr, err)
+	}
+
+	// Create a gRPC connection to the server.
+	rpcConn, err := grpcConnect(rpcSrvURL)
+	if err != nil {
+		exit(1)
+		return err
+	}
+	defer rpcConn.Close()
+
+	// Prepare service caller.
+	var certSvc = pb.NewCertServiceClient(rpcConn)
+
+	certRevokedResp, err := certSvc.ListRevoked(context.Background(), &pb.CertListRevokedRequest{})
+	if err != nil {
+		err := errors.UnknownGRPCError(err)
+		exit(1)
+		return err
+	}
+
+	// Render the revoked cert table.
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"#", "serial", "username", "reason", "revoked at", "revoked by"})
+	for i, r := range certRevokedResp.RevokedCerts {
+		revokedBy := r.RevokedBy
+		if revokedBy == "" {
+			revokedBy = "-"
+		}
+		table.Append([]string{fmt.Sprintf("%v", i+1), r.SerialNumber, r.Username, r.Reason, r.RevokedAt, revokedBy})
+	}
+	table.Render()
+
+	return nil
+}
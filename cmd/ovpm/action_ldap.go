@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/cad/ovpm/api/pb"
+	"github.com/cad/ovpm/errors"
+	"github.com/olekukonko/tablewriter"
+	"github.com/sirupsen/logrus"
+)
+
+func ldapShowAction(rpcServURLStr string) error {
+	// Parse RPC Server's URL.
+	rpcSrvURL, err := url.Parse(rpcServURLStr)
+	if err != nil {
+		return errors.BadURL(rpcServURLStr, err)
+	}
+
+	// Create a gRPC connection to the server.
+	rpcConn, err := grpcConnect(rpcSrvURL)
+	if err != nil {
+		exit(1)
+		return err
+	}
+	defer rpcConn.Close()
+
+	// Get services.
+	var ldapSvc = pb.NewLDAPServiceClient(rpcConn)
+
+	resp, err := ldapSvc.GetConfig(context.Background(), &pb.LDAPGetConfigRequest{})
+	if err != nil {
+		err := errors.UnknownGRPCError(err)
+		exit(1)
+		return err
+	}
+	printLDAPConfig(resp)
+	return nil
+}
+
+func ldapSetAction(rpcServURLStr string, req *pb.LDAPSetConfigRequest) error {
+	// Parse RPC Server's URL.
+	rpcSrvURL, err := url.Parse(rpcServURLStr)
+	if err != nil {
+		return errors.BadURL(rpcServURLStr, err)
+	}
+
+	// Create a gRPC connection to the server.
+	rpcConn, err := grpcConnect(rpcSrvURL)
+	if err != nil {
+		exit(1)
+		return err
+	}
+	defer rpcConn.Close()
+
+	// Get services.
+	var ldapSvc = pb.NewLDAPServiceClient(rpcConn)
+
+	resp, err := ldapSvc.SetConfig(context.Background(), req)
+	if err != nil {
+		err := errors.UnknownGRPCError(err)
+		exit(1)
+		return err
+	}
+	logrus.Info("ldap configured, users are synced periodically or with: $ ovpm ldap sync")
+	printLDAPConfig(resp)
+	return nil
+}
+
+func ldapDisableAction(rpcServURLStr string) error {
+	// Parse RPC Server's URL.
+	rpcSrvURL, err := url.Parse(rpcServURLStr)
+	if err != nil {
+		return errors.BadURL(rpcServURLStr, err)
+	}
+
+	// Create a gRPC connection to the server.
+	rpcConn, err := grpcConnect(rpcSrvURL)
+	if err != nil {
+		exit(1)
+		return err
+	}
+	defer rpcConn.Close()
+
+	// Get services.
+	var ldapSvc = pb.NewLDAPServiceClient(rpcConn)
+
+	if _, err := ldapSvc.Disable(context.Background(), &pb.LDAPDisableRequest{}); err != nil {
+		err := errors.UnknownGRPCError(err)
+		exit(1)
+		return err
+	}
+	logrus.Info("ldap disabled")
+	return nil
+}
+
+func ldapSyncAction(rpcServURLStr string) error {
+	// Parse RPC Server's URL.
+	rpcSrvURL, err := url.Parse(rpcServURLStr)
+	if err != nil {
+		return errors.BadURL(rpcServURLStr, err)
+	}
+
+	// Create a gRPC connection to the server.
+	rpcConn, err := grpcConnect(rpcSrvURL)
+	if err != nil {
+		exit(1)
+		return err
+	}
+	defer rpcConn.Close()
+
+	// Get services.
+	var ldapSvc = pb.NewLDAPServiceClient(rpcConn)
+
+	resp, err := ldapSvc.Sync(context.Background(), &pb.LDAPSyncRequest{})
+	if err != nil {
+		err := errors.UnknownGRPCError(err)
+		exit(1)
+		return err
+	}
+
+	// Prepare table data and draw it on the terminal.
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"change", "users"})
+	table.Append([]string{"Created", strings.Join(resp.Created, ", ")})
+	table.Append([]string{"Enabled", strings.Join(resp.Enabled, ", ")})
+	table.Append([]string{"Disabled", strings.Join(resp.Disabled, ", ")})
+	table.Append([]string{"Deleted", strings.Join(resp.Deleted, ", ")})
+	table.Append([]string{"Promoted", strings.Join(resp.Promoted, ", ")})
+	table.Append([]string{"Demoted", strings.Join(resp.Demoted, ", ")})
+	table.Render()
+	for _, username := range resp.Created {
+		logrus.Infof("ldap user created: %s, you should run: $ ovpm user genconfig --user %s", username, username)
+	}
+	return nil
+}
+
+// printLDAPConfig draws the ldap configuration on the terminal.
+func printLDAPConfig(resp *pb.LDAPConfigResponse) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"attribute", "value"})
+	table.Append([]string{"Enabled", fmt.Sprintf("%t", resp.Enabled)})
+	if resp.Enabled {
+		table.Append([]string{"URL", resp.Url})
+		table.Append([]string{"Start TLS", fmt.Sprintf("%t", resp.StartTls)})
+		table.Append([]string{"Insecure Plaintext", fmt.Sprintf("%t", resp.InsecurePlaintext)})
+		table.Append([]string{"Insecure Skip Verify", fmt.Sprintf("%t", resp.InsecureSkipVerify)})
+		table.Append([]string{"Bind DN", resp.BindDn})
+		table.Append([]string{"User Base DN", resp.UserBaseDn})
+		table.Append([]string{"Username Attr", resp.UsernameAttr})
+		table.Append([]string{"User Filter", resp.UserFilter})
+		table.Append([]string{"Group DN", resp.GroupDn})
+		table.Append([]string{"Admin Group DN", resp.AdminGroupDn})
+		table.Append([]string{"Removal Policy", resp.RemovalPolicy})
+	}
+	table.Render()
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/cad/ovpm/api/pb"
+	"github.com/cad/ovpm/errors"
+	"github.com/olekukonko/tablewriter"
+	"github.com/sirupsen/logrus"
+)
+
+func oidcShowAction(rpcServURLStr string) error {
+	// Parse RPC Server's URL.
+	rpcSrvURL, err := url.Parse(rpcServURLStr)
+	if err != nil {
+		return errors.BadURL(rpcServURLStr, err)
+	}
+
+	// Create a gRPC connection to the server.
+	rpcConn, err := grpcConnect(rpcSrvURL)
+	if err != nil {
+		exit(1)
+		return err
+	}
+	defer rpcConn.Close()
+
+	// Get services.
+	var oidcSvc = pb.NewOIDCServiceClient(rpcConn)
+
+	resp, err := oidcSvc.GetConfig(context.Background(), &pb.OIDCGetConfigRequest{})
+	if err != nil {
+		err := errors.UnknownGRPCError(err)
+		exit(1)
+		return err
+	}
+	printOIDCConfig(resp)
+	return nil
+}
+
+func oidcSetAction(rpcServURLStr string, req *pb.OIDCSetConfigRequest) error {
+	// Parse RPC Server's URL.
+	rpcSrvURL, err := url.Parse(rpcServURLStr)
+	if err != nil {
+		return errors.BadURL(rpcServURLStr, err)
+	}
+
+	// Create a gRPC connection to the server.
+	rpcConn, err := grpcConnect(rpcSrvURL)
+	if err != nil {
+		exit(1)
+		return err
+	}
+	defer rpcConn.Close()
+
+	// Get services.
+	var oidcSvc = pb.NewOIDCServiceClient(rpcConn)
+
+	resp, err := oidcSvc.SetConfig(context.Background(), req)
+	if err != nil {
+		err := errors.UnknownGRPCError(err)
+		exit(1)
+		return err
+	}
+	logrus.Info("oidc configured, users can login at /api/v1/auth/oidc/login")
+	printOIDCConfig(resp)
+	return nil
+}
+
+func oidcDisableAction(rpcServURLStr string) error {
+	// Parse RPC Server's URL.
+	rpcSrvURL, err := url.Parse(rpcServURLStr)
+	if err != nil {
+		return errors.BadURL(rpcServURLStr, err)
+	}
+
+	// Create a gRPC connection to the server.
+	rpcConn, err := grpcConnect(rpcSrvURL)
+	if err != nil {
+		exit(1)
+		return err
+	}
+	defer rpcConn.Close()
+
+	// Get services.
+	var oidcSvc = pb.NewOIDCServiceClient(rpcConn)
+
+	if _, err := oidcSvc.Disable(context.Background(), &pb.OIDCDisableRequest{}); err != nil {
+		err := errors.UnknownGRPCError(err)
+		exit(1)
+		return err
+	}
+	logrus.Info("oidc disabled")
+	return nil
+}
+
+// oidcLinkAction links the user to the oidc subject, or unlinks it if the
+// subject is empty.
+func oidcLinkAction(rpcServURLStr, username, subject string) error {
+	// Parse RPC Server's URL.
+	rpcSrvURL, err := url.Parse(rpcServURLStr)
+	if err != nil {
+		return errors.BadURL(rpcServURLStr, err)
+	}
+
+	// Create a gRPC connection to the server.
+	rpcConn, err := grpcConnect(rpcSrvURL)
+	if err != nil {
+		exit(1)
+		return err
+	}
+	defer rpcConn.Close()
+
+	// Get services.
+	var oidcSvc = pb.NewOIDCServiceClient(rpcConn)
+
+	if _, err := oidcSvc.Link(context.Background(), &pb.OIDCLinkRequest{Username: username, Subject: subject}); err != nil {
+		err := errors.UnknownGRPCError(err)
+		exit(1)
+		return err
+	}
+	if subject == "" {
+		logrus.Infof("user %s is unlinked from oidc", username)
+	} else {
+		logrus.Infof("user %s is linked to oidc subject %s", username, subject)
+	}
+	return nil
+}
+
+// printOIDCConfig draws the oidc configuration on the terminal.
+func printOIDCConfig(resp *pb.OIDCConfigResponse) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"attribute", "value"})
+	table.Append([]string{"Enabled", fmt.Sprintf("%t", resp.Enabled)})
+	if resp.Enabled {
+		table.Append([]string{"Issuer", resp.Issuer})
+		table.Append([]string{"Client ID", resp.ClientId})
+		table.Append([]string{"Redirect URL", resp.RedirectUrl})
+		table.Append([]string{"Username Claim", resp.UsernameClaim})
+		table.Append([]string{"Groups Claim", resp.GroupsClaim})
+		table.Append([]string{"Admin Group", resp.AdminGroup})
+	}
+	table.Render()
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"net/url"
 	"os"
@@ -54,7 +55,7 @@ func userListAction(rpcServURLStr string) error {
 	}
 
 	// Prepare table data.
-	header := []string{"#", "username", "ip", "created", "crt exp", "push gw", "admin"}
+	header := []string{"#", "username", "ip", "created", "crt exp", "push gw", "admin", "totp"}
 	rows := [][]string{}
 	for i, user := range userListResp.Users {
 		isConnected := " "
@@ -65,6 +66,10 @@ func userListAction(rpcServURLStr string) error {
 		if user.HostId != 0 {
 			static = "s"
 		}
+		username := user.Username
+		if user.Disabled {
+			username += " (disabled)"
+		}
 		isAdmin := "✘"
 		if user.IsAdmin {
 			isAdmin = "✔"
@@ -89,6 +94,11 @@ func userListAction(rpcServURLStr string) error {
 			isPushGW = "✔"
 		}
 
+		isTOTP := "✘"
+		if user.TotpEnabled {
+			isTOTP = "✔"
+		}
+
 		createdAt := user.CreatedAt
 		if t, err := time.Parse(time.RFC3339, user.CreatedAt); err == nil {
 			createdAt = humanize.Time(t)
@@ -96,12 +106,13 @@ func userListAction(rpcServURLStr string) error {
 
 		row := []string{
 			fmt.Sprintf("%v", i+1),
-			isConnected + " " + user.Username,
+			isConnected + " " + username,
 			fmt.Sprintf("%s %s", user.IpNet, static),
 			createdAt,
 			isValidCRT,
 			isPushGW,
 			isAdmin,
+			isTOTP,
 		}
 		rows = append(rows, row)
 	}
@@ -306,7 +317,7 @@ func userDeleteAction(rpcSrvURLStr string, username string) error {
 }
 
 // userRenewAction renews a VPN user.
-func userRenewAction(rpcSrvURLStr string, username string) error {
+func userRenewAction(rpcSrvURLStr string, username string, compromised bool, gracePeriod int) error {
 	// Parse RPC Server's URL.
 	rpcSrvURL, err := url.Parse(rpcSrvURLStr)
 	if err != nil {
@@ -325,7 +336,11 @@ func userRenewAction(rpcSrvURLStr string, username string) error {
 	var userSvc = pb.NewUserServiceClient(rpcConn)
 
 	// Send a user renew request to the server.
-	userRenewResp, err := userSvc.Renew(context.Background(), &pb.UserRenewRequest{Username: username})
+	userRenewResp, err := userSvc.Renew(context.Background(), &pb.UserRenewRequest{
+		Username:         username,
+		KeyCompromised:   compromised,
+		GracePeriodHours: int32(gracePeriod),
+	})
 	if err != nil {
 		err := errors.UnknownGRPCError(err)
 		exit(1)
@@ -336,8 +351,39 @@ func userRenewAction(rpcSrvURLStr string, username string) error {
 	return nil
 }
 
-// userGenconfigAction generates ovpn configs for a VPN user.
-func userGenconfigAction(rpcSrvURLStr string, username string, outPath *string) error {
+// userKickAction disconnects a VPN user's live session.
+func userKickAction(rpcSrvURLStr string, username string) error {
+	// Parse RPC Server's URL.
+	rpcSrvURL, err := url.Parse(rpcSrvURLStr)
+	if err != nil {
+		return errors.BadURL(rpcSrvURLStr, err)
+	}
+
+	// Create a gRPC connection to the server.
+	rpcConn, err := grpcConnect(rpcSrvURL)
+	if err != nil {
+		exit(1)
+		return err
+	}
+	defer rpcConn.Close()
+
+	// Prepare a service caller.
+	var userSvc = pb.NewUserServiceClient(rpcConn)
+
+	// Send a user disconnect request to the server.
+	userDisconnectResp, err := userSvc.Disconnect(context.Background(), &pb.UserDisconnectRequest{Username: username})
+	if err != nil {
+		err := errors.UnknownGRPCError(err)
+		exit(1)
+		return err
+	}
+
+	logrus.Infof("user kicked: %s", userDisconnectResp.Users[0].Username)
+	return nil
+}
+
+// userGenconfigAction generates the client config of a VPN user in the given format.
+func userGenconfigAction(rpcSrvURLStr string, username string, format ovpm.ClientConfigFormat, password string, outPath *string) error {
 	// Parse RPC Server's URL.
 	rpcSrvURL, err := url.Parse(rpcSrvURLStr)
 	if err != nil {
@@ -355,7 +401,7 @@ func userGenconfigAction(rpcSrvURLStr string, username string, outPath *string)
 	// If no outPath is provided, then use the default one with
 	// the username.
 	if outPath == nil {
-		tmp := fmt.Sprintf("%s.ovpn", username)
+		tmp := format.FileName(username)
 		outPath = &tmp
 	}
 
@@ -363,7 +409,7 @@ func userGenconfigAction(rpcSrvURLStr string, username string, outPath *string)
 	var userSvc = pb.NewUserServiceClient(rpcConn)
 
 	// Send a user genconfig request to the server.
-	userGenconfigResp, err := userSvc.GenConfig(context.Background(), &pb.UserGenConfigRequest{Username: username})
+	userGenconfigResp, err := userSvc.GenConfig(context.Background(), &pb.UserGenConfigRequest{Username: username, Format: string(format), Password: password})
 	if err != nil {
 		err := errors.UnknownGRPCError(err)
 		exit(1)
@@ -371,8 +417,8 @@ func userGenconfigAction(rpcSrvURLStr string, username string, outPath *string)
 	}
 
 	// Write out the contents of the vpn profile
-	// to the filesystem.
-	if err := emitToFile(*outPath, userGenconfigResp.ClientConfig, 0); err != nil {
+	// to the filesystem. It contains the private key.
+	if err := ioutil.WriteFile(*outPath, userGenconfigResp.Payload, 0600); err != nil {
 		err := errors.UnknownFileIOError(err)
 		exit(1)
 		return err
@@ -381,3 +427,196 @@ func userGenconfigAction(rpcSrvURLStr string, username string, outPath *string)
 	logrus.Infof("exported to %s", *outPath)
 	return nil
 }
+
+// userSignCSRAction signs a certificate request of a VPN user.
+func userSignCSRAction(rpcSrvURLStr string, username string, csr string, outPath *string) error {
+	// Parse RPC Server's URL.
+	rpcSrvURL, err := url.Parse(rpcSrvURLStr)
+	if err != nil {
+		return errors.BadURL(rpcSrvURLStr, err)
+	}
+
+	// Create a gRPC connection to the server.
+	rpcConn, err := grpcConnect(rpcSrvURL)
+	if err != nil {
+		exit(1)
+		return err
+	}
+	defer rpcConn.Close()
+
+	// If no outPath is provided, then use the default one with
+	// the username.
+	if outPath == nil {
+		tmp := fmt.Sprintf("%s.crt", username)
+		outPath = &tmp
+	}
+
+	// Prepare a service caller.
+	var userSvc = pb.NewUserServiceClient(rpcConn)
+
+	// Send a user sign csr request to the server.
+	userSignCSRResp, err := userSvc.SignCSR(context.Background(), &pb.UserSignCSRRequest{Username: username, Csr: csr})
+	if err != nil {
+		err := errors.UnknownGRPCError(err)
+		exit(1)
+		return err
+	}
+
+	// Write out the signed certificate to the filesystem.
+	if err := emitToFile(*outPath, userSignCSRResp.Users[0].Cert, 0); err != nil {
+		err := errors.UnknownFileIOError(err)
+		exit(1)
+		return err
+	}
+
+	logrus.Infof("user csr signed: %s, certificate is exported to %s", username, *outPath)
+	return nil
+}
+
+func userTOTPEnrollAction(rpcSrvURLStr string, username string) error {
+	// Parse RPC Server's URL.
+	rpcSrvURL, err := url.Parse(rpcSrvURLStr)
+	if err != nil {
+		return errors.BadURL(rpcSrvURLStr, err)
+	}
+
+	// Create a gRPC connection to the server.
+	rpcConn, err := grpcConnect(rpcSrvURL)
+	if err != nil {
+		exit(1)
+		return err
+	}
+	defer rpcConn.Close()
+
+	// Prepare a service caller.
+	var userSvc = pb.NewUserServiceClient(rpcConn)
+
+	// Send a user enroll totp request to the server.
+	resp, err := userSvc.EnrollTOTP(context.Background(), &pb.UserEnrollTOTPRequest{Username: username})
+	if err != nil {
+		err := errors.UnknownGRPCError(err)
+		exit(1)
+		return err
+	}
+
+	fmt.Println(resp.ProvisioningUri)
+	logrus.Infof("totp secret generated for %s, add it to the authenticator app and confirm it with a code", username)
+	return nil
+}
+
+func userTOTPConfirmAction(rpcSrvURLStr string, username string, code string) error {
+	// Parse RPC Server's URL.
+	rpcSrvURL, err := url.Parse(rpcSrvURLStr)
+	if err != nil {
+		return errors.BadURL(rpcSrvURLStr, err)
+	}
+
+	// Create a gRPC connection to the server.
+	rpcConn, err := grpcConnect(rpcSrvURL)
+	if err != nil {
+		exit(1)
+		return err
+	}
+	defer rpcConn.Close()
+
+	// Prepare a service caller.
+	var userSvc = pb.NewUserServiceClient(rpcConn)
+
+	// Send a user confirm totp request to the server.
+	resp, err := userSvc.ConfirmTOTP(context.Background(), &pb.UserConfirmTOTPRequest{Username: username, Code: code})
+	if err != nil {
+		err := errors.UnknownGRPCError(err)
+		exit(1)
+		return err
+	}
+
+	logrus.Infof("totp enabled for %s, store the recovery codes in a safe place:", username)
+	for _, rc := range resp.RecoveryCodes {
+		fmt.Println(rc)
+	}
+	return nil
+}
+
+func userTOTPResetAction(rpcSrvURLStr string, username string) error {
+	// Parse RPC Server's URL.
+	rpcSrvURL, err := url.Parse(rpcSrvURLStr)
+	if err != nil {
+		return errors.BadURL(rpcSrvURLStr, err)
+	}
+
+	// Create a gRPC connection to the server.
+	rpcConn, err := grpcConnect(rpcSrvURL)
+	if err != nil {
+		exit(1)
+		return err
+	}
+	defer rpcConn.Close()
+
+	// Prepare a service caller.
+	var userSvc = pb.NewUserServiceClient(rpcConn)
+
+	// Send a user reset totp request to the server.
+	if _, err := userSvc.ResetTOTP(context.Background(), &pb.UserResetTOTPRequest{Username: username}); err != nil {
+		err := errors.UnknownGRPCError(err)
+		exit(1)
+		return err
+	}
+
+	logrus.Infof("totp disabled for %s", username)
+	return nil
+}
+
+// userSessionsAction lists the VPN session history of the user, or of all
+// users if username is empty.
+func userSessionsAction(rpcSrvURLStr string, username, since string, limit int) error {
+	// Parse RPC Server's URL.
+	rpcSrvURL, err := url.Parse(rpcSrvURLStr)
+	if err != nil {
+		return errors.BadURL(rpcSrvURLStr, err)
+	}
+
+	// Create a gRPC connection to the server.
+	rpcConn, err := grpcConnect(rpcSrvURL)
+	if err != nil {
+		exit(1)
+		return err
+	}
+	defer rpcConn.Close()
+
+	// Prepare a service caller.
+	var userSvc = pb.NewUserServiceClient(rpcConn)
+
+	resp, err := userSvc.Sessions(context.Background(), &pb.UserSessionsRequest{Username: username, Since: since, Limit: uint32(limit)})
+	if err != nil {
+		err := errors.UnknownGRPCError(err)
+		exit(1)
+		return err
+	}
+
+	// Prepare table data and draw it on the terminal.
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"#", "username", "real address", "vpn ip", "started", "duration", "received", "sent", "ended"})
+	for _, s := range resp.Sessions {
+		startedAt := s.StartedAt
+		if t, err := time.Parse(time.RFC3339, s.StartedAt); err == nil {
+			startedAt = t.Local().Format("2006-01-02 15:04:05")
+		}
+		ended := "●"
+		if !s.IsActive {
+			ended = s.DisconnectReason
+		}
+		table.Append([]string{
+			fmt.Sprintf("%d", s.Id),
+			s.Username,
+			s.RealAddress,
+			s.VirtualAddress,
+			startedAt,
+			(time.Duration(s.DurationSeconds) * time.Second).String(),
+			humanize.Bytes(s.BytesReceived),
+			humanize.Bytes(s.BytesSent),
+			ended,
+		})
+	}
+	table.Render()
+	return nil
+}
@@ -23,6 +23,35 @@ type vpnInitParams struct {
 	keepalivePeriod  string
 	keepaliveTimeout string
 	useLZO           bool
+	authUserPass     bool
+	certParams       certParams
+	cryptoParams     cryptoParams
+	caCert           string
+	caKey            string
+	caCRL            string
+}
+
+// certParams holds the certificate validity periods in days, the key type and the tls crypt mode.
+type certParams struct {
+	ca          int32
+	server      int32
+	client      int32
+	renewWindow int32
+	keyType     string
+	tlsCrypt    string
+}
+
+// cryptoParams holds the crypto profile preset and its overrides.
+type cryptoParams struct {
+	preset              string
+	dataCiphers         string
+	dataCiphersFallback string
+	authDigest          string
+	tlsVersionMin       string
+	tlsCipher           string
+	remoteCertTLS       pb.VPNRemoteCertTLSPref
+	ecdhOnly            pb.VPNECDHOnlyPref
+	force               bool
 }
 
 func vpnStatusAction(rpcServURLStr string) error {
@@ -55,6 +84,11 @@ func vpnStatusAction(rpcServURLStr string) error {
 	table := tablewriter.NewWriter(os.Stdout)
 	table.SetHeader([]string{"attribute", "value"})
 	table.Append([]string{"Name", vpnStatusResp.Name})
+	table.Append([]string{"Process State", vpnStatusResp.ProcessState})
+	table.Append([]string{"Process Restarts", fmt.Sprintf("%d", vpnStatusResp.ProcessRestarts)})
+	if vpnStatusResp.ProcessLastExit != "" {
+		table.Append([]string{"Process Last Exit", vpnStatusResp.ProcessLastExit})
+	}
 	table.Append([]string{"Hostname", vpnStatusResp.Hostname})
 	table.Append([]string{"Port", vpnStatusResp.Port})
 	table.Append([]string{"Proto", vpnStatusResp.Proto})
@@ -65,6 +99,33 @@ func vpnStatusAction(rpcServURLStr string) error {
 	table.Append([]string{"Cert Exp", vpnStatusResp.ExpiresAt})
 	table.Append([]string{"CA Cert Exp", vpnStatusResp.CaExpiresAt})
 	table.Append([]string{"Use LZO", fmt.Sprintf("%t", vpnStatusResp.UseLzo)})
+	table.Append([]string{"Auth User Pass", fmt.Sprintf("%t", vpnStatusResp.AuthUserPass)})
+	table.Append([]string{"CA Cert Validity", fmt.Sprintf("%d days", vpnStatusResp.CaValidityDays)})
+	table.Append([]string{"Server Cert Validity", fmt.Sprintf("%d days", vpnStatusResp.ServerCertValidityDays)})
+	table.Append([]string{"Client Cert Validity", fmt.Sprintf("%d days", vpnStatusResp.ClientCertValidityDays)})
+	table.Append([]string{"Cert Renew Window", fmt.Sprintf("%d days", vpnStatusResp.CertRenewWindowDays)})
+	table.Append([]string{"Key Type", vpnStatusResp.KeyType})
+	table.Append([]string{"TLS Crypt", vpnStatusResp.TlsCrypt})
+	table.Append([]string{"Crypto Preset", vpnStatusResp.CryptoPreset})
+	table.Append([]string{"Data Ciphers", vpnStatusResp.DataCiphers})
+	table.Append([]string{"Data Ciphers Fallback", vpnStatusResp.DataCiphersFallback})
+	table.Append([]string{"Auth Digest", vpnStatusResp.AuthDigest})
+	table.Append([]string{"TLS Version Min", vpnStatusResp.TlsVersionMin})
+	table.Append([]string{"TLS Cipher", vpnStatusResp.TlsCipher})
+	table.Append([]string{"Remote Cert TLS", fmt.Sprintf("%t", vpnStatusResp.RemoteCertTls)})
+	table.Append([]string{"ECDH Only", fmt.Sprintf("%t", vpnStatusResp.EcdhOnly)})
+	table.Append([]string{"DH Params", vpnStatusResp.DhParams})
+	caKey := "online"
+	if vpnStatusResp.CaOffline {
+		caKey = "offline (locked)"
+		if vpnStatusResp.CaUnlocked {
+			caKey = "offline (unlocked)"
+		}
+	}
+	table.Append([]string{"CA Key", caKey})
+	if vpnStatusResp.CaRetiresAt != "" {
+		table.Append([]string{"Previous CA Retires At", vpnStatusResp.CaRetiresAt})
+	}
 
 	table.Render()
 
@@ -99,6 +160,26 @@ func vpnInitAction(params vpnInitParams) error {
 		KeepalivePeriod:  params.keepalivePeriod,
 		KeepaliveTimeout: params.keepaliveTimeout,
 		UseLzo:           params.useLZO,
+		AuthUserPass:     params.authUserPass,
+
+		CaValidityDays:         params.certParams.ca,
+		ServerCertValidityDays: params.certParams.server,
+		ClientCertValidityDays: params.certParams.client,
+		CertRenewWindowDays:    params.certParams.renewWindow,
+		KeyType:                params.certParams.keyType,
+		TlsCrypt:               params.certParams.tlsCrypt,
+		CaCert:                 params.caCert,
+		CaKey:                  params.caKey,
+		CaCrl:                  params.caCRL,
+
+		CryptoPreset:        params.cryptoParams.preset,
+		DataCiphers:         params.cryptoParams.dataCiphers,
+		DataCiphersFallback: params.cryptoParams.dataCiphersFallback,
+		AuthDigest:          params.cryptoParams.authDigest,
+		TlsVersionMin:       params.cryptoParams.tlsVersionMin,
+		TlsCipher:           params.cryptoParams.tlsCipher,
+		RemoteCertTlsPref:   params.cryptoParams.remoteCertTLS,
+		EcdhOnlyPref:        params.cryptoParams.ecdhOnly,
 	})
 	if err != nil {
 		err := errors.UnknownGRPCError(err)
@@ -119,7 +200,7 @@ func vpnInitAction(params vpnInitParams) error {
 	return nil
 }
 
-func vpnUpdateAction(rpcServURLStr string, netCIDR *string, dnsAddr *string, useLzo *bool) error {
+func vpnUpdateAction(rpcServURLStr string, netCIDR *string, dnsAddr *string, useLzo *bool, authUserPass *bool, certParams certParams, cryptoParams cryptoParams, caCRL string) error {
 	// Parse RPC Server's URL.
 	rpcSrvURL, err := url.Parse(rpcServURLStr)
 	if err != nil {
@@ -186,6 +267,15 @@ func vpnUpdateAction(rpcServURLStr string, netCIDR *string, dnsAddr *string, use
 		}
 	}
 
+	// Set auth-user-pass preference if provided.
+	targetAuthUserPassPref := pb.VPNAuthUserPassPref_AUTH_USER_PASS_NOPREF
+	if authUserPass != nil {
+		targetAuthUserPassPref = pb.VPNAuthUserPassPref_AUTH_USER_PASS_DISABLE
+		if *authUserPass {
+			targetAuthUserPassPref = pb.VPNAuthUserPassPref_AUTH_USER_PASS_ENABLE
+		}
+	}
+
 	// Prepare service caller.
 	var vpnSvc = pb.NewVPNServiceClient(rpcConn)
 
@@ -194,6 +284,26 @@ func vpnUpdateAction(rpcServURLStr string, netCIDR *string, dnsAddr *string, use
 		IpBlock: targetNetCIDR,
 		Dns:     targetDNSAddr,
 		LzoPref: targetLZOPref,
+
+		AuthUserPassPref: targetAuthUserPassPref,
+
+		CaValidityDays:         certParams.ca,
+		ServerCertValidityDays: certParams.server,
+		ClientCertValidityDays: certParams.client,
+		CertRenewWindowDays:    certParams.renewWindow,
+		KeyType:                certParams.keyType,
+		TlsCrypt:               certParams.tlsCrypt,
+		CaCrl:                  caCRL,
+
+		CryptoPreset:        cryptoParams.preset,
+		DataCiphers:         cryptoParams.dataCiphers,
+		DataCiphersFallback: cryptoParams.dataCiphersFallback,
+		AuthDigest:          cryptoParams.authDigest,
+		TlsVersionMin:       cryptoParams.tlsVersionMin,
+		TlsCipher:           cryptoParams.tlsCipher,
+		RemoteCertTlsPref:   cryptoParams.remoteCertTLS,
+		EcdhOnlyPref:        cryptoParams.ecdhOnly,
+		CryptoForce:         cryptoParams.force,
 	})
 	if err != nil {
 		err := errors.UnknownGRPCError(err)
@@ -240,3 +350,121 @@ func vpnRestartAction(rpcServURLStr string) error {
 	logrus.Info("ovpm server restarted")
 	return nil
 }
+
+func vpnRotateCAAction(rpcServURLStr string, gracePeriod int) error {
+	// Parse RPC Server's URL.
+	rpcSrvURL, err := url.Parse(rpcServURLStr)
+	if err != nil {
+		return errors.BadURL(rpcServURLStr, err)
+	}
+
+	// Create a gRPC connection to the server.
+	rpcConn, err := grpcConnect(rpcSrvURL)
+	if err != nil {
+		exit(1)
+		return err
+	}
+	defer rpcConn.Close()
+
+	// Prepare service caller.
+	var vpnSvc = pb.NewVPNServiceClient(rpcConn)
+
+	_, err = vpnSvc.RotateCA(context.Background(), &pb.VPNRotateCARequest{GracePeriodDays: int32(gracePeriod)})
+	if err != nil {
+		err := errors.UnknownGRPCError(err)
+		exit(1)
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"GRACE_PERIOD": gracePeriod,
+	}).Infoln("ca rotated, users are going to be re-signed with the new ca during the grace period")
+	return nil
+}
+
+func vpnRotateTLSCryptKeyAction(rpcServURLStr string) error {
+	// Parse RPC Server's URL.
+	rpcSrvURL, err := url.Parse(rpcServURLStr)
+	if err != nil {
+		return errors.BadURL(rpcServURLStr, err)
+	}
+
+	// Create a gRPC connection to the server.
+	rpcConn, err := grpcConnect(rpcSrvURL)
+	if err != nil {
+		exit(1)
+		return err
+	}
+	defer rpcConn.Close()
+
+	// Prepare service caller.
+	var vpnSvc = pb.NewVPNServiceClient(rpcConn)
+
+	_, err = vpnSvc.RotateTLSCryptKey(context.Background(), &pb.VPNRotateTLSCryptKeyRequest{})
+	if err != nil {
+		err := errors.UnknownGRPCError(err)
+		exit(1)
+		return err
+	}
+
+	logrus.Infoln("tls crypt key rotated, users need to get their .ovpn profiles exported again: $ ovpm user genconfig --user <username>")
+	return nil
+}
+
+func vpnOfflineCAAction(rpcServURLStr string, keyFile string, passphrase string) error {
+	// Parse RPC Server's URL.
+	rpcSrvURL, err := url.Parse(rpcServURLStr)
+	if err != nil {
+		return errors.BadURL(rpcServURLStr, err)
+	}
+
+	// Create a gRPC connection to the server.
+	rpcConn, err := grpcConnect(rpcSrvURL)
+	if err != nil {
+		exit(1)
+		return err
+	}
+	defer rpcConn.Close()
+
+	// Prepare service caller.
+	var vpnSvc = pb.NewVPNServiceClient(rpcConn)
+
+	_, err = vpnSvc.TakeCAOffline(context.Background(), &pb.VPNTakeCAOfflineRequest{KeyFile: keyFile, Passphrase: passphrase})
+	if err != nil {
+		err := errors.UnknownGRPCError(err)
+		exit(1)
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"KEY_FILE": keyFile,
+	}).Infoln("ca key is taken offline, start ovpmd with --ca-key-file to unlock it")
+	return nil
+}
+
+func vpnVerifyUserAction(rpcServURLStr string, commonName, username, password string) error {
+	// Parse RPC Server's URL.
+	rpcSrvURL, err := url.Parse(rpcServURLStr)
+	if err != nil {
+		return errors.BadURL(rpcServURLStr, err)
+	}
+
+	// Create a gRPC connection to the server.
+	rpcConn, err := grpcConnect(rpcSrvURL)
+	if err != nil {
+		exit(1)
+		return err
+	}
+	defer rpcConn.Close()
+
+	// Prepare service caller.
+	var vpnSvc = pb.NewVPNServiceClient(rpcConn)
+
+	_, err = vpnSvc.VerifyUserPass(context.Background(), &pb.VPNVerifyUserPassRequest{CommonName: commonName, Username: username, Password: password})
+	if err != nil {
+		err := errors.UnknownGRPCError(err)
+		exit(1)
+		return err
+	}
+	return nil
+}
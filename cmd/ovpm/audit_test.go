@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditCmd(t *testing.T) {
+	output := new(bytes.Buffer)
+	app.Writer = output
+
+	err := app.Run([]string{"ovpm", "audit"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(output.String(), "list, l") {
+		t.Fatal("subcommand missing 'list, l'")
+	}
+}
+
+func TestAuditListCmd(t *testing.T) {
+	output := new(bytes.Buffer)
+	app.Writer = output
+
+	var err error
+
+	// Invalid since
+	err = app.Run([]string{"ovpm", "audit", "list", "--since", "yesterday"})
+	if err == nil {
+		t.Fatal("error is expected about the since, but we didn't got error")
+	}
+
+	// Negative limit
+	err = app.Run([]string{"ovpm", "audit", "list", "--limit", "-1"})
+	if err == nil {
+		t.Fatal("error is expected about the limit, but we didn't got error")
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2021, 1, 2, 15, 4, 5, 0, time.UTC)
+	var sincetests = []struct {
+		since    string
+		expected time.Time
+	}{
+		{"24h", now.Add(-24 * time.Hour)},
+		{"2020-12-31", time.Date(2020, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{"2021-01-01T10:00:00Z", time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range sincetests {
+		if got, err := parseSince(tt.since, now); err != nil || !got.Equal(tt.expected) {
+			t.Errorf("parseSince(%s) is expected to be %v but got %v %v", tt.since, tt.expected, got, err)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cad/ovpm"
+	"github.com/urfave/cli"
+)
+
+var auditListCommand = cli.Command{
+	Name:    "list",
+	Usage:   "List the administrative actions.",
+	Aliases: []string{"l"},
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "since",
+			Usage: "list the actions since a duration ago (e.g. 24h) or a date (e.g. 2006-01-02 or 2006-01-02T15:04:05Z)",
+		},
+		cli.StringFlag{
+			Name:  "actor",
+			Usage: "list only the actions of this user",
+		},
+		cli.IntFlag{
+			Name:  "limit",
+			Usage: "list at most this many actions",
+		},
+		cli.BoolFlag{
+			Name:  "json",
+			Usage: "print the actions as JSON lines, e.g. to export them",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		action = "audit:list"
+		// Use default port if no port is specified.
+		daemonPort := ovpm.DefaultDaemonPort
+		if port := c.GlobalInt("daemon-port"); port != 0 {
+			daemonPort = port
+		}
+
+		// Validate since.
+		var since string
+		if s := c.String("since"); s != "" {
+			t, err := parseSince(s, time.Now())
+			if err != nil {
+				fmt.Println(err.Error())
+				exit(1)
+				return err
+			}
+			since = t.UTC().Format(time.RFC3339)
+		}
+
+		// Validate limit.
+		if c.Int("limit") < 0 {
+			err := fmt.Errorf("limit can not be negative: %d", c.Int("limit"))
+			fmt.Println(err.Error())
+			exit(1)
+			return err
+		}
+
+		// If dry run, then don't call the action, just preprocess.
+		if c.GlobalBool("dry-run") {
+			return nil
+		}
+
+		return auditListAction(fmt.Sprintf("grpc://localhost:%d", daemonPort), since, c.String("actor"), c.Int("limit"), c.Bool("json"))
+	},
+}
+
+// parseSince parses either a duration before now or a date.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("since should be a duration (e.g. 24h) or a date (e.g. 2006-01-02): %s", s)
+}
+
+func init() {
+	app.Commands = append(app.Commands,
+		cli.Command{
+			Name:  "audit",
+			Usage: "Audit Log Operations",
+			Subcommands: []cli.Command{
+				auditListCommand,
+			},
+		},
+	)
+}
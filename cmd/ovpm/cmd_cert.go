@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/cad/ovpm"
+	"github.com/urfave/cli"
+)
+
+var certRevokedCommand = cli.Command{
+	Name:    "revoked",
+	Usage:   "List revoked certificates.",
+	Aliases: []string{"r"},
+	Action: func(c *cli.Context) error {
+		action = "cert:revoked"
+		// Use default port if no port is specified.
+		daemonPort := ovpm.DefaultDaemonPort
+		if port := c.GlobalInt("daemon-port"); port != 0 {
+			daemonPort = port
+		}
+
+		// If dry run, then don't call the action, just preprocess.
+		if c.GlobalBool("dry-run") {
+			return nil
+		}
+
+		return certRevokedAction(fmt.Sprintf("grpc://localhost:%d", daemonPort))
+	},
+}
+
+func init() {
+	app.Commands = append(app.Commands,
+		cli.Command{
+			Name:    "cert",
+			Usage:   "Certificate Operations",
+			Aliases: []string{"c"},
+			Subcommands: []cli.Command{
+				certRevokedCommand,
+			},
+		},
+	)
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/cad/ovpm"
+	"github.com/cad/ovpm/api/pb"
+	"github.com/cad/ovpm/errors"
+	"github.com/urfave/cli"
+)
+
+var ldapShowCommand = cli.Command{
+	Name:    "show",
+	Usage:   "Show the LDAP configuration.",
+	Aliases: []string{"s"},
+	Action: func(c *cli.Context) error {
+		action = "ldap:show"
+		// Use default port if no port is specified.
+		daemonPort := ovpm.DefaultDaemonPort
+		if port := c.GlobalInt("daemon-port"); port != 0 {
+			daemonPort = port
+		}
+
+		// If dry run, then don't call the action, just preprocess.
+		if c.GlobalBool("dry-run") {
+			return nil
+		}
+
+		return ldapShowAction(fmt.Sprintf("grpc://localhost:%d", daemonPort))
+	},
+}
+
+var ldapSetCommand = cli.Command{
+	Name:  "set",
+	Usage: "Authenticate and sync the users with an LDAP directory.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "url",
+			Usage: "ldap:// or ldaps:// url of the directory (required)",
+		},
+		cli.BoolFlag{
+			Name:  "start-tls",
+			Usage: "upgrade the ldap:// connections with starttls",
+		},
+		cli.BoolFlag{
+			Name:  "insecure-plaintext",
+			Usage: "allow ldap:// without starttls, the passwords are sent in plaintext",
+		},
+		cli.BoolFlag{
+			Name:  "insecure-skip-verify",
+			Usage: "don't verify the certificate of the directory",
+		},
+		cli.StringFlag{
+			Name:  "bind-dn",
+			Usage: "dn of the service account that searches the directory (required)",
+		},
+		cli.StringFlag{
+			Name:  "bind-password",
+			Usage: "password of the service account, the previous one is kept if it's not given",
+		},
+		cli.StringFlag{
+			Name:  "user-base-dn",
+			Usage: "dn that the users are searched under (required)",
+		},
+		cli.StringFlag{
+			Name:  "username-attr",
+			Usage: fmt.Sprintf("attribute that holds the usernames, e.g. sAMAccountName (default: %s)", ovpm.DefaultLDAPUsernameAttr),
+		},
+		cli.StringFlag{
+			Name:  "user-filter",
+			Usage: "filter that finds a user, %s is replaced with the username (default: (<username-attr>=%s))",
+		},
+		cli.StringFlag{
+			Name:  "group-dn",
+			Usage: "dn of the group whose members are the vpn users (required)",
+		},
+		cli.StringFlag{
+			Name:  "admin-group-dn",
+			Usage: "dn of the group whose members are the admins",
+		},
+		cli.StringFlag{
+			Name:  "removal-policy",
+			Usage: fmt.Sprintf("'%s' or '%s' the users that leave the group (default: %s)", ovpm.LDAPRemovalDisable, ovpm.LDAPRemovalDelete, ovpm.DefaultLDAPRemovalPolicy),
+		},
+	},
+	Action: func(c *cli.Context) error {
+		action = "ldap:set"
+		// Use default port if no port is specified.
+		daemonPort := ovpm.DefaultDaemonPort
+		if port := c.GlobalInt("daemon-port"); port != 0 {
+			daemonPort = port
+		}
+
+		// Validate the required fields.
+		for _, name := range []string{"url", "bind-dn", "user-base-dn", "group-dn"} {
+			if value := c.String(name); govalidator.IsNull(value) {
+				return errors.EmptyValue(name, value)
+			}
+		}
+
+		// Validate the transport.
+		url := c.String("url")
+		switch {
+		case strings.HasPrefix(url, "ldaps://") && c.Bool("start-tls"):
+			err := fmt.Errorf("--start-tls can only be used with ldap:// urls: %s", url)
+			fmt.Println(err.Error())
+			exit(1)
+			return err
+		case strings.HasPrefix(url, "ldap://") && !c.Bool("start-tls") && !c.Bool("insecure-plaintext"):
+			err := fmt.Errorf("ldap:// is plaintext, either use ldaps://, --start-tls or --insecure-plaintext: %s", url)
+			fmt.Println(err.Error())
+			exit(1)
+			return err
+		}
+
+		// Validate removal policy.
+		switch policy := c.String("removal-policy"); policy {
+		case "", ovpm.LDAPRemovalDisable, ovpm.LDAPRemovalDelete:
+		default:
+			err := fmt.Errorf("removal policy should be either '%s' or '%s': %s", ovpm.LDAPRemovalDisable, ovpm.LDAPRemovalDelete, policy)
+			fmt.Println(err.Error())
+			exit(1)
+			return err
+		}
+
+		// If dry run, then don't call the action, just preprocess.
+		if c.GlobalBool("dry-run") {
+			return nil
+		}
+
+		return ldapSetAction(fmt.Sprintf("grpc://localhost:%d", daemonPort), &pb.LDAPSetConfigRequest{
+			Url:                url,
+			StartTls:           c.Bool("start-tls"),
+			InsecurePlaintext:  c.Bool("insecure-plaintext"),
+			InsecureSkipVerify: c.Bool("insecure-skip-verify"),
+			BindDn:             c.String("bind-dn"),
+			BindPassword:       c.String("bind-password"),
+			UserBaseDn:         c.String("user-base-dn"),
+			UsernameAttr:       c.String("username-attr"),
+			UserFilter:         c.String("user-filter"),
+			GroupDn:            c.String("group-dn"),
+			AdminGroupDn:       c.String("admin-group-dn"),
+			RemovalPolicy:      c.String("removal-policy"),
+		})
+	},
+}
+
+var ldapDisableCommand = cli.Command{
+	Name:    "disable",
+	Usage:   "Remove the LDAP configuration, synced users can't login until it's set again.",
+	Aliases: []string{"d"},
+	Action: func(c *cli.Context) error {
+		action = "ldap:disable"
+		// Use default port if no port is specified.
+		daemonPort := ovpm.DefaultDaemonPort
+		if port := c.GlobalInt("daemon-port"); port != 0 {
+			daemonPort = port
+		}
+
+		// If dry run, then don't call the action, just preprocess.
+		if c.GlobalBool("dry-run") {
+			return nil
+		}
+
+		return ldapDisableAction(fmt.Sprintf("grpc://localhost:%d", daemonPort))
+	},
+}
+
+var ldapSyncCommand = cli.Command{
+	Name:  "sync",
+	Usage: "Sync the users with the LDAP group now instead of waiting for the periodic sync.",
+	Action: func(c *cli.Context) error {
+		action = "ldap:sync"
+		// Use default port if no port is specified.
+		daemonPort := ovpm.DefaultDaemonPort
+		if port := c.GlobalInt("daemon-port"); port != 0 {
+			daemonPort = port
+		}
+
+		// If dry run, then don't call the action, just preprocess.
+		if c.GlobalBool("dry-run") {
+			return nil
+		}
+
+		return ldapSyncAction(fmt.Sprintf("grpc://localhost:%d", daemonPort))
+	},
+}
+
+func init() {
+	app.Commands = append(app.Commands,
+		cli.Command{
+			Name:  "ldap",
+			Usage: "LDAP Operations",
+			Subcommands: []cli.Command{
+				ldapShowCommand,
+				ldapSetCommand,
+				ldapDisableCommand,
+				ldapSyncCommand,
+			},
+		},
+	)
+}
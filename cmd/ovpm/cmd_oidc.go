@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/cad/ovpm"
+	"github.com/cad/ovpm/api/pb"
+	"github.com/cad/ovpm/errors"
+	"github.com/urfave/cli"
+)
+
+var oidcShowCommand = cli.Command{
+	Name:    "show",
+	Usage:   "Show the OIDC configuration.",
+	Aliases: []string{"s"},
+	Action: func(c *cli.Context) error {
+		action = "oidc:show"
+		// Use default port if no port is specified.
+		daemonPort := ovpm.DefaultDaemonPort
+		if port := c.GlobalInt("daemon-port"); port != 0 {
+			daemonPort = port
+		}
+
+		// If dry run, then don't call the action, just preprocess.
+		if c.GlobalBool("dry-run") {
+			return nil
+		}
+
+		return oidcShowAction(fmt.Sprintf("grpc://localhost:%d", daemonPort))
+	},
+}
+
+var oidcSetCommand = cli.Command{
+	Name:  "set",
+	Usage: "Let the existing users login to the web UI and the REST API with an OpenID Connect provider.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "issuer",
+			Usage: "issuer url of the provider, e.g. https://accounts.example.com (required)",
+		},
+		cli.StringFlag{
+			Name:  "client-id",
+			Usage: "client id that ovpm is registered with (required)",
+		},
+		cli.StringFlag{
+			Name:  "client-secret",
+			Usage: "client secret, the previous one is kept if it's not given",
+		},
+		cli.StringFlag{
+			Name:  "redirect-url",
+			Usage: "public url of the callback, e.g. https://vpn.example.com/api/v1/auth/oidc/callback (required)",
+		},
+		cli.StringFlag{
+			Name:  "username-claim",
+			Usage: fmt.Sprintf("id token claim that holds the usernames, only a verified email links the users on their first login (default: %s)", ovpm.DefaultOIDCUsernameClaim),
+		},
+		cli.StringFlag{
+			Name:  "groups-claim",
+			Usage: fmt.Sprintf("id token claim that holds the groups of the user (default: %s)", ovpm.DefaultOIDCGroupsClaim),
+		},
+		cli.StringFlag{
+			Name:  "admin-group",
+			Usage: "members of this group are the admins",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		action = "oidc:set"
+		// Use default port if no port is specified.
+		daemonPort := ovpm.DefaultDaemonPort
+		if port := c.GlobalInt("daemon-port"); port != 0 {
+			daemonPort = port
+		}
+
+		// Validate the required fields.
+		for _, name := range []string{"issuer", "client-id", "redirect-url"} {
+			if value := c.String(name); govalidator.IsNull(value) {
+				return errors.EmptyValue(name, value)
+			}
+		}
+
+		// Validate urls.
+		for _, name := range []string{"issuer", "redirect-url"} {
+			if value := c.String(name); !govalidator.IsURL(value) {
+				err := fmt.Errorf("%s should be a url: %s", name, value)
+				fmt.Println(err.Error())
+				exit(1)
+				return err
+			}
+		}
+
+		// If dry run, then don't call the action, just preprocess.
+		if c.GlobalBool("dry-run") {
+			return nil
+		}
+
+		return oidcSetAction(fmt.Sprintf("grpc://localhost:%d", daemonPort), &pb.OIDCSetConfigRequest{
+			Issuer:        c.String("issuer"),
+			ClientId:      c.String("client-id"),
+			ClientSecret:  c.String("client-secret"),
+			RedirectUrl:   c.String("redirect-url"),
+			UsernameClaim: c.String("username-claim"),
+			GroupsClaim:   c.String("groups-claim"),
+			AdminGroup:    c.String("admin-group"),
+		})
+	},
+}
+
+var oidcDisableCommand = cli.Command{
+	Name:    "disable",
+	Usage:   "Remove the OIDC configuration, users can still login with their passwords.",
+	Aliases: []string{"d"},
+	Action: func(c *cli.Context) error {
+		action = "oidc:disable"
+		// Use default port if no port is specified.
+		daemonPort := ovpm.DefaultDaemonPort
+		if port := c.GlobalInt("daemon-port"); port != 0 {
+			daemonPort = port
+		}
+
+		// If dry run, then don't call the action, just preprocess.
+		if c.GlobalBool("dry-run") {
+			return nil
+		}
+
+		return oidcDisableAction(fmt.Sprintf("grpc://localhost:%d", daemonPort))
+	},
+}
+
+var oidcLinkCommand = cli.Command{
+	Name:  "link",
+	Usage: "Link a user to an OIDC account, which is required for the admins, and for all users unless the username claim is email.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "user, u",
+			Usage: "username of the vpn user (required)",
+		},
+		cli.StringFlag{
+			Name:  "subject, s",
+			Usage: "subject (sub claim) of the oidc account (required)",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		action = "oidc:link"
+		// Use default port if no port is specified.
+		daemonPort := ovpm.DefaultDaemonPort
+		if port := c.GlobalInt("daemon-port"); port != 0 {
+			daemonPort = port
+		}
+
+		// Validate the required fields.
+		for _, name := range []string{"user", "subject"} {
+			if value := c.String(name); govalidator.IsNull(value) {
+				return errors.EmptyValue(name, value)
+			}
+		}
+
+		// If dry run, then don't call the action, just preprocess.
+		if c.GlobalBool("dry-run") {
+			return nil
+		}
+
+		return oidcLinkAction(fmt.Sprintf("grpc://localhost:%d", daemonPort), c.String("user"), c.String("subject"))
+	},
+}
+
+var oidcUnlinkCommand = cli.Command{
+	Name:  "unlink",
+	Usage: "Unlink a user from its OIDC account.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "user, u",
+			Usage: "username of the vpn user (required)",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		action = "oidc:unlink"
+		// Use default port if no port is specified.
+		daemonPort := ovpm.DefaultDaemonPort
+		if port := c.GlobalInt("daemon-port"); port != 0 {
+			daemonPort = port
+		}
+
+		// Validate the required fields.
+		if value := c.String("user"); govalidator.IsNull(value) {
+			return errors.EmptyValue("user", value)
+		}
+
+		// If dry run, then don't call the action, just preprocess.
+		if c.GlobalBool("dry-run") {
+			return nil
+		}
+
+		return oidcLinkAction(fmt.Sprintf("grpc://localhost:%d", daemonPort), c.String("user"), "")
+	},
+}
+
+func init() {
+	app.Commands = append(app.Commands,
+		cli.Command{
+			Name:  "oidc",
+			Usage: "OpenID Connect Operations",
+			Subcommands: []cli.Command{
+				oidcShowCommand,
+				oidcSetCommand,
+				oidcDisableCommand,
+				oidcLinkCommand,
+				oidcUnlinkCommand,
+			},
+		},
+	)
+}
@@ -2,7 +2,9 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net"
+	"time"
 
 	"github.com/asaskevich/govalidator"
 	"github.com/cad/ovpm"
@@ -318,6 +320,14 @@ var userRenewCmd = cli.Command{
 			Name:  "user, u",
 			Usage: "username of the vpn user",
 		},
+		cli.BoolFlag{
+			Name:  "compromised",
+			Usage: "revoke the current certificate because its key is compromised",
+		},
+		cli.IntFlag{
+			Name:  "grace-period, g",
+			Usage: "number of hours that the current certificate stays valid after the renewal",
+		},
 	},
 	Action: func(c *cli.Context) error {
 		action = "user:renew"
@@ -332,18 +342,118 @@ var userRenewCmd = cli.Command{
 			return errors.EmptyValue("username", username)
 		}
 
+		gracePeriod := c.Int("grace-period")
+		if gracePeriod < 0 {
+			err := fmt.Errorf("--grace-period can not be negative")
+			fmt.Println(err.Error())
+			exit(1)
+			return err
+		}
+		if gracePeriod > 0 && c.Bool("compromised") {
+			err := errors.ConflictingDemands("--grace-period can not be used with --compromised")
+			exit(1)
+			return err
+		}
+
+		// If dry run, then don't call the action, just preprocess.
+		if c.GlobalBool("dry-run") {
+			return nil
+		}
+
+		return userRenewAction(fmt.Sprintf("grpc://localhost:%d", daemonPort), c.String("user"), c.Bool("compromised"), gracePeriod)
+	},
+}
+
+var userKickCmd = cli.Command{
+	Name:    "kick",
+	Usage:   "Disconnect a VPN user's live session.",
+	Aliases: []string{"k"},
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "user, u",
+			Usage: "username of the vpn user",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		action = "user:kick"
+		// Use default port if no port is specified.
+		daemonPort := ovpm.DefaultDaemonPort
+		if port := c.GlobalInt("daemon-port"); port != 0 {
+			daemonPort = port
+		}
+
+		// Validate username.
+		if username := c.String("user"); govalidator.IsNull(username) {
+			return errors.EmptyValue("username", username)
+		}
+
+		// If dry run, then don't call the action, just preprocess.
+		if c.GlobalBool("dry-run") {
+			return nil
+		}
+
+		return userKickAction(fmt.Sprintf("grpc://localhost:%d", daemonPort), c.String("user"))
+	},
+}
+
+var userSessionsCmd = cli.Command{
+	Name:    "sessions",
+	Usage:   "List the VPN session history.",
+	Aliases: []string{"s"},
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "user, u",
+			Usage: "username of the vpn user, all users if it's not given",
+		},
+		cli.StringFlag{
+			Name:  "since",
+			Usage: "list the sessions that were active since a duration ago (e.g. 24h) or a date (e.g. 2006-01-02)",
+		},
+		cli.IntFlag{
+			Name:  "limit",
+			Usage: "list at most this many sessions",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		action = "user:sessions"
+		// Use default port if no port is specified.
+		daemonPort := ovpm.DefaultDaemonPort
+		if port := c.GlobalInt("daemon-port"); port != 0 {
+			daemonPort = port
+		}
+
+		// Validate since.
+		var since string
+		if s := c.String("since"); s != "" {
+			t, err := parseSince(s, time.Now())
+			if err != nil {
+				fmt.Println(err.Error())
+				exit(1)
+				return err
+			}
+			since = t.UTC().Format(time.RFC3339)
+		}
+
+		// Validate limit.
+		if c.Int("limit") < 0 {
+			err := fmt.Errorf("limit can not be negative: %d", c.Int("limit"))
+			fmt.Println(err.Error())
+			exit(1)
+			return err
+		}
+
 		// If dry run, then don't call the action, just preprocess.
 		if c.GlobalBool("dry-run") {
 			return nil
 		}
 
-		return userRenewAction(fmt.Sprintf("grpc://localhost:%d", daemonPort), c.String("user"))
+		return userSessionsAction(fmt.Sprintf("grpc://localhost:%d", daemonPort), c.String("user"), since, c.Int("limit"))
 	},
 }
 
 var userGenconfigCmd = cli.Command{
 	Name:    "genconfig",
-	Usage:   "Generate client config for the user. (.ovpn file)",
+	Usage:   "Generate client config for the user. (.ovpn, .p12 or .zip file)",
 	Aliases: []string{"g"},
 	Flags: []cli.Flag{
 		cli.StringFlag{
@@ -352,7 +462,16 @@ var userGenconfigCmd = cli.Command{
 		},
 		cli.StringFlag{
 			Name:  "out, o",
-			Usage: ".ovpn file output path",
+			Usage: "output file path",
+		},
+		cli.StringFlag{
+			Name:  "format, f",
+			Usage: "client config format: ovpn, p12 (password protected PKCS#12 bundle) or zip (separate files)",
+			Value: string(ovpm.OVPNFormat),
+		},
+		cli.StringFlag{
+			Name:  "password-file",
+			Usage: "read the p12 password from the file instead of prompting",
 		},
 	},
 	Action: func(c *cli.Context) error {
@@ -368,6 +487,14 @@ var userGenconfigCmd = cli.Command{
 			return errors.EmptyValue("username", username)
 		}
 
+		// Validate format.
+		format, err := ovpm.ParseClientConfigFormat(c.String("format"))
+		if err != nil {
+			fmt.Println(err.Error())
+			exit(1)
+			return err
+		}
+
 		// Set outPath if it's provided.
 		var outPath *string
 		if outPathVal := c.String("out"); !govalidator.IsNull(outPathVal) {
@@ -379,7 +506,180 @@ var userGenconfigCmd = cli.Command{
 			return nil
 		}
 
-		return userGenconfigAction(fmt.Sprintf("grpc://localhost:%d", daemonPort), c.String("user"), outPath)
+		// PKCS#12 bundles are password protected.
+		var password []byte
+		if format == ovpm.P12Format {
+			password, err = readPassphrase(c.String("password-file"), true)
+			if err != nil {
+				fmt.Println(err.Error())
+				exit(1)
+				return err
+			}
+		}
+
+		return userGenconfigAction(fmt.Sprintf("grpc://localhost:%d", daemonPort), c.String("user"), format, string(password), outPath)
+	},
+}
+
+var userSignCSRCmd = cli.Command{
+	Name:  "sign-csr",
+	Usage: "Sign a certificate request (CSR) of the user, so that the user keeps the private key.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "user, u",
+			Usage: "username of the vpn user",
+		},
+		cli.StringFlag{
+			Name:  "csr, c",
+			Usage: "path to the PEM encoded certificate request, its common name should be the username",
+		},
+		cli.StringFlag{
+			Name:  "out, o",
+			Usage: "signed certificate output path",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		action = "user:sign-csr"
+		// Use default port if no port is specified.
+		daemonPort := ovpm.DefaultDaemonPort
+		if port := c.GlobalInt("daemon-port"); port != 0 {
+			daemonPort = port
+		}
+
+		// Validate username.
+		if username := c.String("user"); govalidator.IsNull(username) {
+			return errors.EmptyValue("username", username)
+		}
+
+		// Read the certificate request.
+		csrPath := c.String("csr")
+		if govalidator.IsNull(csrPath) {
+			return errors.EmptyValue("csr", csrPath)
+		}
+		csr, err := ioutil.ReadFile(csrPath)
+		if err != nil {
+			err := errors.UnknownFileIOError(err)
+			exit(1)
+			return err
+		}
+
+		// Set outPath if it's provided.
+		var outPath *string
+		if outPathVal := c.String("out"); !govalidator.IsNull(outPathVal) {
+			outPath = &outPathVal
+		}
+
+		// If dry run, then don't call the action, just preprocess.
+		if c.GlobalBool("dry-run") {
+			return nil
+		}
+
+		return userSignCSRAction(fmt.Sprintf("grpc://localhost:%d", daemonPort), c.String("user"), string(csr), outPath)
+	},
+}
+
+// userTOTPCmd manages the TOTP second factor of the users.
+var userTOTPCmd = cli.Command{
+	Name:  "totp",
+	Usage: "Manage the TOTP second factor of a user.",
+	Subcommands: []cli.Command{
+		{
+			Name:  "enroll",
+			Usage: "Generate a new TOTP secret and print its provisioning URI.",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "user, u",
+					Usage: "username of the vpn user",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				action = "user:totp:enroll"
+				// Use default port if no port is specified.
+				daemonPort := ovpm.DefaultDaemonPort
+				if port := c.GlobalInt("daemon-port"); port != 0 {
+					daemonPort = port
+				}
+
+				// Validate username.
+				if username := c.String("user"); govalidator.IsNull(username) {
+					return errors.EmptyValue("username", username)
+				}
+
+				// If dry run, then don't call the action, just preprocess.
+				if c.GlobalBool("dry-run") {
+					return nil
+				}
+
+				return userTOTPEnrollAction(fmt.Sprintf("grpc://localhost:%d", daemonPort), c.String("user"))
+			},
+		},
+		{
+			Name:  "confirm",
+			Usage: "Enable TOTP for the user with a code from the authenticator app and print the recovery codes.",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "user, u",
+					Usage: "username of the vpn user",
+				},
+				cli.StringFlag{
+					Name:  "code, c",
+					Usage: "current code shown by the authenticator app",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				action = "user:totp:confirm"
+				// Use default port if no port is specified.
+				daemonPort := ovpm.DefaultDaemonPort
+				if port := c.GlobalInt("daemon-port"); port != 0 {
+					daemonPort = port
+				}
+
+				// Validate username and code.
+				if username := c.String("user"); govalidator.IsNull(username) {
+					return errors.EmptyValue("username", username)
+				}
+				if code := c.String("code"); govalidator.IsNull(code) {
+					return errors.EmptyValue("code", code)
+				}
+
+				// If dry run, then don't call the action, just preprocess.
+				if c.GlobalBool("dry-run") {
+					return nil
+				}
+
+				return userTOTPConfirmAction(fmt.Sprintf("grpc://localhost:%d", daemonPort), c.String("user"), c.String("code"))
+			},
+		},
+		{
+			Name:  "reset",
+			Usage: "Disable TOTP for the user, e.g. when the authenticator is lost. It also lifts the lockout after too many wrong codes.",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "user, u",
+					Usage: "username of the vpn user",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				action = "user:totp:reset"
+				// Use default port if no port is specified.
+				daemonPort := ovpm.DefaultDaemonPort
+				if port := c.GlobalInt("daemon-port"); port != 0 {
+					daemonPort = port
+				}
+
+				// Validate username.
+				if username := c.String("user"); govalidator.IsNull(username) {
+					return errors.EmptyValue("username", username)
+				}
+
+				// If dry run, then don't call the action, just preprocess.
+				if c.GlobalBool("dry-run") {
+					return nil
+				}
+
+				return userTOTPResetAction(fmt.Sprintf("grpc://localhost:%d", daemonPort), c.String("user"))
+			},
+		},
 	},
 }
 
@@ -396,6 +696,10 @@ func init() {
 				userDeleteCmd,
 				userRenewCmd,
 				userGenconfigCmd,
+				userKickCmd,
+				userSessionsCmd,
+				userSignCSRCmd,
+				userTOTPCmd,
 			},
 		},
 	)
@@ -2,10 +2,16 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
 	"github.com/asaskevich/govalidator"
 	"github.com/cad/ovpm"
 	"github.com/cad/ovpm/api/pb"
 	"github.com/cad/ovpm/errors"
+	"github.com/cad/ovpm/pki"
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
 	"go.uber.org/thriftrw/ptr"
@@ -72,6 +78,93 @@ var vpnInitCommand = cli.Command{
 			Name:  "use-lzo, l",
 			Usage: "Used to determine whether to use the deprecated lzo compression algorithm to support older clients. (default: false)",
 		},
+		cli.BoolFlag{
+			Name:  "auth-user-pass",
+			Usage: "Require the users' passwords along with their certificates to connect. (default: false)",
+		},
+		cli.IntFlag{
+			Name:  "ca-validity",
+			Usage: "Validity period of the CA certificate in days.",
+			Value: ovpm.DefaultCAValidityDays,
+		},
+		cli.IntFlag{
+			Name:  "server-cert-validity",
+			Usage: "Validity period of the server certificate in days.",
+			Value: ovpm.DefaultServerCertValidityDays,
+		},
+		cli.IntFlag{
+			Name:  "client-cert-validity",
+			Usage: "Validity period of the client certificates in days.",
+			Value: ovpm.DefaultClientCertValidityDays,
+		},
+		cli.IntFlag{
+			Name:  "cert-renew-window",
+			Usage: "Renew client certificates automatically when they expire in less than this many days.",
+			Value: ovpm.DefaultCertRenewWindowDays,
+		},
+		cli.StringFlag{
+			Name:  "key-type",
+			Usage: fmt.Sprintf("Key type of the certificates %v.", pki.KeyTypes()),
+			Value: string(pki.DefaultKeyType),
+		},
+		cli.StringFlag{
+			Name:  "tls-crypt",
+			Usage: fmt.Sprintf("Control channel protection: %s, %s or %s.", ovpm.TLSCryptNone, ovpm.TLSCrypt, ovpm.TLSCryptV2),
+			Value: ovpm.DefaultTLSCrypt,
+		},
+		cli.StringFlag{
+			Name:  "crypto-preset",
+			Usage: fmt.Sprintf("Crypto profile preset %v, the flags below override it.", ovpm.CryptoPresets()),
+			Value: ovpm.DefaultCryptoPreset,
+		},
+		cli.StringFlag{
+			Name:  "data-ciphers",
+			Usage: "Colon separated data channel ciphers to negotiate, e.g. AES-256-GCM:CHACHA20-POLY1305.",
+		},
+		cli.StringFlag{
+			Name:  "data-ciphers-fallback",
+			Usage: "Data channel cipher of the clients that can't negotiate, 'none' to refuse them.",
+		},
+		cli.StringFlag{
+			Name:  "auth",
+			Usage: "HMAC digest of the non-AEAD data channel ciphers, e.g. SHA256.",
+		},
+		cli.StringFlag{
+			Name:  "tls-version-min",
+			Usage: "Minimum TLS version of the control channel, e.g. 1.2.",
+		},
+		cli.StringFlag{
+			Name:  "tls-cipher",
+			Usage: "Colon separated TLS 1.2 cipher suites of the control channel, 'default' to use the OpenVPN defaults.",
+		},
+		cli.BoolFlag{
+			Name:  "enable-remote-cert-tls",
+			Usage: "Make the clients verify the server certificate's key usage (remote-cert-tls server).",
+		},
+		cli.BoolFlag{
+			Name:  "disable-remote-cert-tls",
+			Usage: "Make the clients verify the server certificate's deprecated nsCertType (ns-cert-type server).",
+		},
+		cli.BoolFlag{
+			Name:  "enable-ecdh-only",
+			Usage: "Only use ECDHE for the key exchange (dh none), needs --tls-version-min 1.2 or later.",
+		},
+		cli.BoolFlag{
+			Name:  "disable-ecdh-only",
+			Usage: "Use the DH params that are generated in the background for the key exchange.",
+		},
+		cli.StringFlag{
+			Name:  "ca-cert",
+			Usage: "Path to a PEM encoded CA or intermediate cert followed by its issuers to import instead of generating a CA.",
+		},
+		cli.StringFlag{
+			Name:  "ca-key",
+			Usage: "Path to the PEM encoded private key of the imported CA.",
+		},
+		cli.StringFlag{
+			Name:  "ca-crl",
+			Usage: "Path to the PEM encoded CRLs of the imported CA's issuers, required for an intermediate CA.",
+		},
 	},
 	Action: func(c *cli.Context) error {
 		action = "vpn:init"
@@ -125,6 +218,41 @@ var vpnInitCommand = cli.Command{
 
 		useLZO := c.Bool("use-lzo")
 
+		// Set certificate validity periods.
+		certParams, err := certParamsFromFlags(c)
+		if err != nil {
+			fmt.Println(err.Error())
+			exit(1)
+			return err
+		}
+
+		cryptoParams, err := cryptoParamsFromFlags(c)
+		if err != nil {
+			fmt.Println(err.Error())
+			exit(1)
+			return err
+		}
+
+		// Read the CA to import if provided.
+		caCert, caKey, err := caFromFlags(c)
+		if err != nil {
+			fmt.Println(err.Error())
+			exit(1)
+			return err
+		}
+		caCRL, err := caCRLFromFlags(c)
+		if err != nil {
+			fmt.Println(err.Error())
+			exit(1)
+			return err
+		}
+		if caCRL != "" && caCert == "" {
+			e := fmt.Errorf("--ca-crl can only be used with --ca-cert")
+			fmt.Println(e.Error())
+			exit(1)
+			return e
+		}
+
 		// Ask for confirmation from the user about the destructive
 		// changes that are about to happen.
 		var uiConfirmed bool
@@ -164,7 +292,7 @@ var vpnInitCommand = cli.Command{
 			return nil
 		}
 
-		err := vpnInitAction(vpnInitParams{
+		err = vpnInitAction(vpnInitParams{
 			rpcServURLStr:    fmt.Sprintf("grpc://localhost:%d", daemonPort),
 			hostname:         hostname,
 			port:             port,
@@ -174,6 +302,12 @@ var vpnInitCommand = cli.Command{
 			keepalivePeriod:  keepalivePeriod,
 			keepaliveTimeout: keepaliveTimeout,
 			useLZO:           useLZO,
+			authUserPass:     c.Bool("auth-user-pass"),
+			certParams:       certParams,
+			cryptoParams:     cryptoParams,
+			caCert:           caCert,
+			caKey:            caKey,
+			caCRL:            caCRL,
 		})
 		if err != nil {
 			e, ok := err.(errors.Error)
@@ -212,6 +346,86 @@ var vpnUpdateCommand = cli.Command{
 			Name:  "disable-use-lzo",
 			Usage: fmt.Sprintf("Disable use of the deprecated lzo compression algorithm to support older clients."),
 		},
+		cli.BoolFlag{
+			Name:  "enable-auth-user-pass",
+			Usage: "Require the users' passwords along with their certificates to connect.",
+		},
+		cli.BoolFlag{
+			Name:  "disable-auth-user-pass",
+			Usage: "Let the users connect with their certificates alone.",
+		},
+		cli.IntFlag{
+			Name:  "ca-validity",
+			Usage: "Validity period of the CA certificate in days, applies to the next CA.",
+		},
+		cli.IntFlag{
+			Name:  "server-cert-validity",
+			Usage: "Validity period of the server certificate in days, re-issues the server certificate.",
+		},
+		cli.IntFlag{
+			Name:  "client-cert-validity",
+			Usage: "Validity period of the client certificates in days, applies to the renewed certificates.",
+		},
+		cli.IntFlag{
+			Name:  "cert-renew-window",
+			Usage: "Renew client certificates automatically when they expire in less than this many days.",
+		},
+		cli.StringFlag{
+			Name:  "key-type",
+			Usage: fmt.Sprintf("Key type of the newly issued certificates %v, re-issues the server certificate.", pki.KeyTypes()),
+		},
+		cli.StringFlag{
+			Name:  "tls-crypt",
+			Usage: fmt.Sprintf("Control channel protection: %s, %s or %s, generates new keys for the server and the users.", ovpm.TLSCryptNone, ovpm.TLSCrypt, ovpm.TLSCryptV2),
+		},
+		cli.StringFlag{
+			Name:  "crypto-preset",
+			Usage: fmt.Sprintf("Crypto profile preset %v, the flags below override it.", ovpm.CryptoPresets()),
+		},
+		cli.StringFlag{
+			Name:  "data-ciphers",
+			Usage: "Colon separated data channel ciphers to negotiate, e.g. AES-256-GCM:CHACHA20-POLY1305.",
+		},
+		cli.StringFlag{
+			Name:  "data-ciphers-fallback",
+			Usage: "Data channel cipher of the clients that can't negotiate, 'none' to refuse them.",
+		},
+		cli.StringFlag{
+			Name:  "auth",
+			Usage: "HMAC digest of the non-AEAD data channel ciphers, e.g. SHA256.",
+		},
+		cli.StringFlag{
+			Name:  "tls-version-min",
+			Usage: "Minimum TLS version of the control channel, e.g. 1.2.",
+		},
+		cli.StringFlag{
+			Name:  "tls-cipher",
+			Usage: "Colon separated TLS 1.2 cipher suites of the control channel, 'default' to use the OpenVPN defaults.",
+		},
+		cli.BoolFlag{
+			Name:  "enable-remote-cert-tls",
+			Usage: "Make the clients verify the server certificate's key usage (remote-cert-tls server).",
+		},
+		cli.BoolFlag{
+			Name:  "disable-remote-cert-tls",
+			Usage: "Make the clients verify the server certificate's deprecated nsCertType (ns-cert-type server).",
+		},
+		cli.BoolFlag{
+			Name:  "enable-ecdh-only",
+			Usage: "Only use ECDHE for the key exchange (dh none), needs --tls-version-min 1.2 or later.",
+		},
+		cli.BoolFlag{
+			Name:  "disable-ecdh-only",
+			Usage: "Use the DH params that are generated in the background for the key exchange.",
+		},
+		cli.BoolFlag{
+			Name:  "crypto-force",
+			Usage: "Apply the crypto flags even if the existing client configs can't connect anymore, they need to be exported again.",
+		},
+		cli.StringFlag{
+			Name:  "ca-crl",
+			Usage: "Path to the PEM encoded CRLs of the imported intermediate CA's issuers, replaces the current ones.",
+		},
 	},
 	Action: func(c *cli.Context) error {
 		action = "vpn:update"
@@ -245,12 +459,48 @@ var vpnUpdateCommand = cli.Command{
 			useLzo = ptr.Bool(false)
 		}
 
+		var authUserPass *bool
+		if c.Bool("enable-auth-user-pass") && c.Bool("disable-auth-user-pass") {
+			e := fmt.Errorf("can not use --enable-auth-user-pass and --disable-auth-user-pass together")
+			fmt.Println(e.Error())
+			exit(1)
+			return e
+		}
+		if c.Bool("enable-auth-user-pass") {
+			authUserPass = ptr.Bool(true)
+		}
+		if c.Bool("disable-auth-user-pass") {
+			authUserPass = ptr.Bool(false)
+		}
+
+		certParams, err := certParamsFromFlags(c)
+		if err != nil {
+			fmt.Println(err.Error())
+			exit(1)
+			return err
+		}
+
+		cryptoParams, err := cryptoParamsFromFlags(c)
+		if err != nil {
+			fmt.Println(err.Error())
+			exit(1)
+			return err
+		}
+		cryptoParams.force = c.Bool("crypto-force")
+
+		caCRL, err := caCRLFromFlags(c)
+		if err != nil {
+			fmt.Println(err.Error())
+			exit(1)
+			return err
+		}
+
 		// If dry run, then don't call the action, just preprocess.
 		if c.GlobalBool("dry-run") {
 			return nil
 		}
 
-		return vpnUpdateAction(fmt.Sprintf("grpc://localhost:%d", daemonPort), netCIDR, dnsAddr, useLzo)
+		return vpnUpdateAction(fmt.Sprintf("grpc://localhost:%d", daemonPort), netCIDR, dnsAddr, useLzo, authUserPass, certParams, cryptoParams, caCRL)
 	},
 }
 
@@ -274,6 +524,254 @@ var vpnRestartCommand = cli.Command{
 	},
 }
 
+// certParamsFromFlags reads and validates the certificate validity, key type and tls crypt flags.
+func certParamsFromFlags(c *cli.Context) (certParams, error) {
+	v := certParams{
+		ca:          int32(c.Int("ca-validity")),
+		server:      int32(c.Int("server-cert-validity")),
+		client:      int32(c.Int("client-cert-validity")),
+		renewWindow: int32(c.Int("cert-renew-window")),
+		keyType:     c.String("key-type"),
+		tlsCrypt:    c.String("tls-crypt"),
+	}
+	if v.ca < 0 || v.server < 0 || v.client < 0 || v.renewWindow < 0 {
+		return v, fmt.Errorf("certificate validity periods can not be negative")
+	}
+	if v.keyType != "" {
+		if _, err := pki.ParseKeyType(v.keyType); err != nil {
+			return v, err
+		}
+	}
+	switch v.tlsCrypt {
+	case "", ovpm.TLSCryptNone, ovpm.TLSCrypt, ovpm.TLSCryptV2:
+	default:
+		return v, fmt.Errorf("tls crypt should be either %s, %s or %s", ovpm.TLSCryptNone, ovpm.TLSCrypt, ovpm.TLSCryptV2)
+	}
+	return v, nil
+}
+
+// cryptoParamsFromFlags reads the crypto profile flags.
+//
+// The ciphers and digests are validated by the server, since it knows what the
+// existing clients use.
+func cryptoParamsFromFlags(c *cli.Context) (cryptoParams, error) {
+	v := cryptoParams{
+		preset:              c.String("crypto-preset"),
+		dataCiphers:         c.String("data-ciphers"),
+		dataCiphersFallback: c.String("data-ciphers-fallback"),
+		authDigest:          c.String("auth"),
+		tlsVersionMin:       c.String("tls-version-min"),
+		tlsCipher:           c.String("tls-cipher"),
+	}
+	if v.preset != "" && !stringInSlice(v.preset, ovpm.CryptoPresets()) {
+		return v, fmt.Errorf("crypto preset should be one of %v", ovpm.CryptoPresets())
+	}
+	if c.Bool("enable-remote-cert-tls") && c.Bool("disable-remote-cert-tls") {
+		return v, fmt.Errorf("can not use --enable-remote-cert-tls and --disable-remote-cert-tls together")
+	}
+	if c.Bool("enable-remote-cert-tls") {
+		v.remoteCertTLS = pb.VPNRemoteCertTLSPref_REMOTE_CERT_TLS_ENABLE
+	}
+	if c.Bool("disable-remote-cert-tls") {
+		v.remoteCertTLS = pb.VPNRemoteCertTLSPref_REMOTE_CERT_TLS_DISABLE
+	}
+	if c.Bool("enable-ecdh-only") && c.Bool("disable-ecdh-only") {
+		return v, fmt.Errorf("can not use --enable-ecdh-only and --disable-ecdh-only together")
+	}
+	if c.Bool("enable-ecdh-only") {
+		v.ecdhOnly = pb.VPNECDHOnlyPref_ECDH_ONLY_ENABLE
+	}
+	if c.Bool("disable-ecdh-only") {
+		v.ecdhOnly = pb.VPNECDHOnlyPref_ECDH_ONLY_DISABLE
+	}
+	return v, nil
+}
+
+// caFromFlags reads the CA cert and key files to import.
+func caFromFlags(c *cli.Context) (string, string, error) {
+	certPath, keyPath := c.String("ca-cert"), c.String("ca-key")
+	if certPath == "" && keyPath == "" {
+		return "", "", nil
+	}
+	if certPath == "" || keyPath == "" {
+		return "", "", fmt.Errorf("--ca-cert and --ca-key should be used together")
+	}
+	cert, err := ioutil.ReadFile(certPath)
+	if err != nil {
+		return "", "", fmt.Errorf("can not read ca cert: %v", err)
+	}
+	key, err := ioutil.ReadFile(keyPath)
+	if err != nil {
+		return "", "", fmt.Errorf("can not read ca key: %v", err)
+	}
+	return string(cert), string(key), nil
+}
+
+// caCRLFromFlags reads the CRLs of the imported CA's issuers.
+func caCRLFromFlags(c *cli.Context) (string, error) {
+	path := c.String("ca-crl")
+	if path == "" {
+		return "", nil
+	}
+	crl, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("can not read ca crl: %v", err)
+	}
+	return string(crl), nil
+}
+
+var vpnRotateCACommand = cli.Command{
+	Name:  "rotate-ca",
+	Usage: "Rotate the CA of the VPN server without breaking the existing client configs.",
+	Flags: []cli.Flag{
+		cli.IntFlag{
+			Name:  "grace-period, g",
+			Usage: "Number of days to keep trusting the previous CA.",
+			Value: ovpm.DefaultCARotationGraceDays,
+		},
+	},
+	Action: func(c *cli.Context) error {
+		action = "vpn:rotate-ca"
+		// Use default port if no port is specified.
+		daemonPort := ovpm.DefaultDaemonPort
+		if port := c.GlobalInt("daemon-port"); port != 0 {
+			daemonPort = port
+		}
+
+		gracePeriod := c.Int("grace-period")
+		if gracePeriod <= 0 {
+			e := fmt.Errorf("--grace-period should be a positive number of days")
+			fmt.Println(e.Error())
+			exit(1)
+			return e
+		}
+
+		// If dry run, then don't call the action, just preprocess.
+		if c.GlobalBool("dry-run") {
+			return nil
+		}
+
+		return vpnRotateCAAction(fmt.Sprintf("grpc://localhost:%d", daemonPort), gracePeriod)
+	},
+}
+
+var vpnRotateTLSCryptKeyCommand = cli.Command{
+	Name:  "rotate-tls-crypt-key",
+	Usage: "Rotate the tls-crypt key of the VPN server. Existing client configs stop working and need to be exported again.",
+	Action: func(c *cli.Context) error {
+		action = "vpn:rotate-tls-crypt-key"
+		// Use default port if no port is specified.
+		daemonPort := ovpm.DefaultDaemonPort
+		if port := c.GlobalInt("daemon-port"); port != 0 {
+			daemonPort = port
+		}
+
+		// If dry run, then don't call the action, just preprocess.
+		if c.GlobalBool("dry-run") {
+			return nil
+		}
+
+		return vpnRotateTLSCryptKeyAction(fmt.Sprintf("grpc://localhost:%d", daemonPort))
+	},
+}
+
+var vpnOfflineCACommand = cli.Command{
+	Name:  "offline-ca",
+	Usage: "Move the CA private key out of the database into an encrypted file.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "key-file, f",
+			Usage: "Path to write the encrypted CA key, it should be kept out of the ovpm's directory.",
+		},
+		cli.StringFlag{
+			Name:  "passphrase-file",
+			Usage: "Read the passphrase from a file instead of prompting for it.",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		action = "vpn:offline-ca"
+		// Use default port if no port is specified.
+		daemonPort := ovpm.DefaultDaemonPort
+		if port := c.GlobalInt("daemon-port"); port != 0 {
+			daemonPort = port
+		}
+
+		keyFile := c.String("key-file")
+		if keyFile == "" {
+			failureMsg(c, "--key-file is required")
+			exit(1)
+			return fmt.Errorf("--key-file is required")
+		}
+		// ovpmd writes the file, so it shouldn't depend on the working directory.
+		keyFile, err := filepath.Abs(keyFile)
+		if err != nil {
+			fmt.Println(err.Error())
+			exit(1)
+			return err
+		}
+
+		// If dry run, then don't call the action, just preprocess.
+		if c.GlobalBool("dry-run") {
+			return nil
+		}
+
+		passphrase, err := readPassphrase(c.String("passphrase-file"), true)
+		if err != nil {
+			fmt.Println(err.Error())
+			exit(1)
+			return err
+		}
+
+		return vpnOfflineCAAction(fmt.Sprintf("grpc://localhost:%d", daemonPort), keyFile, string(passphrase))
+	},
+}
+
+var vpnVerifyUserCommand = cli.Command{
+	Name:      "verify-user",
+	Usage:     "Verify the username and password of a VPN client, used by OpenVPN's auth-user-pass-verify.",
+	ArgsUsage: "<file>",
+	Action: func(c *cli.Context) error {
+		action = "vpn:verify-user"
+		// Use default port if no port is specified.
+		daemonPort := ovpm.DefaultDaemonPort
+		if port := c.GlobalInt("daemon-port"); port != 0 {
+			daemonPort = port
+		}
+
+		// OpenVPN writes the username and the password into the file on
+		// separate lines and passes the common name of the client's
+		// certificate in the environment.
+		if c.NArg() != 1 {
+			e := fmt.Errorf("verify-user expects the path of the file that holds the username and the password")
+			fmt.Println(e.Error())
+			exit(1)
+			return e
+		}
+		b, err := ioutil.ReadFile(c.Args().First())
+		if err != nil {
+			fmt.Println(err.Error())
+			exit(1)
+			return err
+		}
+		lines := strings.SplitN(string(b), "\n", 3)
+		if len(lines) < 2 {
+			e := fmt.Errorf("username and password file is malformed")
+			fmt.Println(e.Error())
+			exit(1)
+			return e
+		}
+		username := strings.TrimRight(lines[0], "\r")
+		password := strings.TrimRight(lines[1], "\r")
+
+		// If dry run, then don't call the action, just preprocess.
+		if c.GlobalBool("dry-run") {
+			return nil
+		}
+
+		return vpnVerifyUserAction(fmt.Sprintf("grpc://localhost:%d", daemonPort), os.Getenv("common_name"), username, password)
+	},
+}
+
 func init() {
 	app.Commands = append(app.Commands,
 		cli.Command{
@@ -285,6 +783,10 @@ func init() {
 				vpnInitCommand,
 				vpnUpdateCommand,
 				vpnRestartCommand,
+				vpnRotateCACommand,
+				vpnRotateTLSCryptKeyCommand,
+				vpnOfflineCACommand,
+				vpnVerifyUserCommand,
 			},
 		},
 	)
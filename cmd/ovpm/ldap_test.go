@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLDAPCmd(t *testing.T) {
+	output := new(bytes.Buffer)
+	app.Writer = output
+
+	err := app.Run([]string{"ovpm", "ldap"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(output.String(), "show, s") {
+		t.Fatal("subcommand missing 'show, s'")
+	}
+
+	if !strings.Contains(output.String(), "set") {
+		t.Fatal("subcommand missing 'set'")
+	}
+
+	if !strings.Contains(output.String(), "disable, d") {
+		t.Fatal("subcommand missing 'disable, d'")
+	}
+
+	if !strings.Contains(output.String(), "sync") {
+		t.Fatal("subcommand missing 'sync'")
+	}
+}
+
+func TestLDAPSetCmd(t *testing.T) {
+	output := new(bytes.Buffer)
+	app.Writer = output
+
+	var err error
+
+	// Empty call
+	err = app.Run([]string{"ovpm", "ldap", "set"})
+	if err == nil {
+		t.Fatal("error is expected about missing fields, but we didn't got error")
+	}
+
+	// Plaintext ldap://
+	err = app.Run([]string{"ovpm", "ldap", "set", "--url", "ldap://localhost", "--bind-dn", "cn=ovpm", "--user-base-dn", "ou=people", "--group-dn", "cn=vpn"})
+	if err == nil {
+		t.Fatal("error is expected about the plaintext url, but we didn't got error")
+	}
+
+	// StartTLS with ldaps://
+	err = app.Run([]string{"ovpm", "ldap", "set", "--url", "ldaps://localhost", "--start-tls", "--bind-dn", "cn=ovpm", "--user-base-dn", "ou=people", "--group-dn", "cn=vpn"})
+	if err == nil {
+		t.Fatal("error is expected about start tls, but we didn't got error")
+	}
+
+	// Unknown removal policy
+	err = app.Run([]string{"ovpm", "ldap", "set", "--url", "ldap://localhost", "--start-tls", "--bind-dn", "cn=ovpm", "--user-base-dn", "ou=people", "--group-dn", "cn=vpn", "--removal-policy", "ignore"})
+	if err == nil {
+		t.Fatal("error is expected about the removal policy, but we didn't got error")
+	}
+}
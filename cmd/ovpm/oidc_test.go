@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestOIDCCmd(t *testing.T) {
+	output := new(bytes.Buffer)
+	app.Writer = output
+
+	err := app.Run([]string{"ovpm", "oidc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(output.String(), "show, s") {
+		t.Fatal("subcommand missing 'show, s'")
+	}
+
+	if !strings.Contains(output.String(), "set") {
+		t.Fatal("subcommand missing 'set'")
+	}
+
+	if !strings.Contains(output.String(), "disable, d") {
+		t.Fatal("subcommand missing 'disable, d'")
+	}
+
+	if !strings.Contains(output.String(), "link") {
+		t.Fatal("subcommand missing 'link'")
+	}
+
+	if !strings.Contains(output.String(), "unlink") {
+		t.Fatal("subcommand missing 'unlink'")
+	}
+}
+
+func TestOIDCLinkCmd(t *testing.T) {
+	output := new(bytes.Buffer)
+	app.Writer = output
+
+	// Missing subject
+	err := app.Run([]string{"ovpm", "oidc", "link", "--user", "john"})
+	if err == nil {
+		t.Fatal("error is expected about the missing subject, but we didn't got error")
+	}
+
+	// Missing user
+	err = app.Run([]string{"ovpm", "oidc", "unlink"})
+	if err == nil {
+		t.Fatal("error is expected about the missing user, but we didn't got error")
+	}
+}
+
+func TestOIDCSetCmd(t *testing.T) {
+	output := new(bytes.Buffer)
+	app.Writer = output
+
+	var err error
+
+	// Empty call
+	err = app.Run([]string{"ovpm", "oidc", "set"})
+	if err == nil {
+		t.Fatal("error is expected about missing fields, but we didn't got error")
+	}
+
+	// Invalid issuer
+	err = app.Run([]string{"ovpm", "oidc", "set", "--issuer", "not a url", "--client-id", "ovpm", "--redirect-url", "https://vpn.example.com/api/v1/auth/oidc/callback"})
+	if err == nil {
+		t.Fatal("error is expected about the issuer, but we didn't got error")
+	}
+}
@@ -38,6 +38,22 @@ func TestUserCmd(t *testing.T) {
 	if !strings.Contains(output.String(), "genconfig, g") {
 		t.Fatal("subcommand missing 'update, u'")
 	}
+
+	if !strings.Contains(output.String(), "kick, k") {
+		t.Fatal("subcommand missing 'kick, k'")
+	}
+
+	if !strings.Contains(output.String(), "sessions, s") {
+		t.Fatal("subcommand missing 'sessions, s'")
+	}
+
+	if !strings.Contains(output.String(), "sign-csr") {
+		t.Fatal("subcommand missing 'sign-csr'")
+	}
+
+	if !strings.Contains(output.String(), "totp") {
+		t.Fatal("subcommand missing 'totp'")
+	}
 }
 
 func TestUserCreateCmd(t *testing.T) {
@@ -166,3 +182,35 @@ func TestUserGenconfigCmd(t *testing.T) {
 		t.Fatal("error is expected about missing fields, but we didn't got error")
 	}
 }
+
+func TestUserKickCmd(t *testing.T) {
+	output := new(bytes.Buffer)
+	app.Writer = output
+
+	var err error
+
+	// Empty call
+	err = app.Run([]string{"ovpm", "user", "kick"})
+	if err == nil {
+		t.Fatal("error is expected about missing fields, but we didn't got error")
+	}
+}
+
+func TestUserSessionsCmd(t *testing.T) {
+	output := new(bytes.Buffer)
+	app.Writer = output
+
+	var err error
+
+	// Invalid since
+	err = app.Run([]string{"ovpm", "user", "sessions", "--since", "yesterday"})
+	if err == nil {
+		t.Fatal("error is expected about the since, but we didn't got error")
+	}
+
+	// Negative limit
+	err = app.Run([]string{"ovpm", "user", "sessions", "--user", "sad", "--limit", "-1"})
+	if err == nil {
+		t.Fatal("error is expected about the limit, but we didn't got error")
+	}
+}
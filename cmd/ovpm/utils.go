@@ -1,8 +1,10 @@
 package main
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"net/url"
 	"os"
@@ -10,6 +12,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/cad/ovpm/errors"
 	"github.com/urfave/cli"
+	"golang.org/x/term"
 
 	"google.golang.org/grpc"
 )
@@ -76,6 +79,41 @@ func isLoopbackURL(u *url.URL) bool {
 	return true
 }
 
+// readPassphrase reads a passphrase from the file if it's given, otherwise
+// prompts for it. If confirm is true, the prompted passphrase is asked twice.
+func readPassphrase(path string, confirm bool) ([]byte, error) {
+	if path != "" {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("can not read passphrase file: %v", err)
+		}
+		return bytes.TrimRight(b, "\r\n"), nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, fmt.Errorf("can not prompt for the passphrase, stdin is not a terminal")
+	}
+	fmt.Print("Passphrase: ")
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return nil, err
+	}
+	if confirm {
+		fmt.Print("Passphrase (again): ")
+		again, err := term.ReadPassword(fd)
+		fmt.Println()
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(passphrase, again) {
+			return nil, fmt.Errorf("passphrases do not match")
+		}
+	}
+	return passphrase, nil
+}
+
 func stringInSlice(a string, list []string) bool {
 	for _, b := range list {
 		if b == a {
@@ -30,4 +30,20 @@ func TestVPNCmd(t *testing.T) {
 	if !strings.Contains(output.String(), "restart, r") {
 		t.Fatal("subcommand missing 'restart, r'")
 	}
+
+	if !strings.Contains(output.String(), "rotate-ca") {
+		t.Fatal("subcommand missing 'rotate-ca'")
+	}
+
+	if !strings.Contains(output.String(), "rotate-tls-crypt-key") {
+		t.Fatal("subcommand missing 'rotate-tls-crypt-key'")
+	}
+
+	if !strings.Contains(output.String(), "offline-ca") {
+		t.Fatal("subcommand missing 'offline-ca'")
+	}
+
+	if !strings.Contains(output.String(), "verify-user") {
+		t.Fatal("subcommand missing 'verify-user'")
+	}
 }
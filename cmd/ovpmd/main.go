@@ -1,8 +1,10 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"net"
 	"net/http"
@@ -16,8 +18,10 @@ import (
 
 	"github.com/cad/ovpm"
 	"github.com/cad/ovpm/api"
+	"github.com/cad/ovpm/supervisor"
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
+	"golang.org/x/term"
 )
 
 var action string
@@ -41,6 +45,40 @@ func main() {
 			Name:  "web-port",
 			Usage: "port number for the REST API daemon",
 		},
+		cli.StringFlag{
+			Name:   "ca-key-file",
+			Usage:  "encrypted key file to unlock the offline CA",
+			EnvVar: "OVPM_CA_KEY_FILE",
+		},
+		cli.StringFlag{
+			Name:   "ca-passphrase-file",
+			Usage:  "read the offline CA passphrase from a file instead of prompting for it",
+			EnvVar: "OVPM_CA_PASSPHRASE_FILE",
+		},
+		cli.StringFlag{
+			Name:  "metrics-port",
+			Usage: "port number to serve the Prometheus metrics at /metrics, disabled if it's not given",
+		},
+		cli.DurationFlag{
+			Name:  "session-retention",
+			Usage: "how long the ended VPN sessions are kept in the history, 0 keeps them forever",
+			Value: ovpm.DefaultSessionRetention,
+		},
+		cli.StringFlag{
+			Name:  "vpn-restart-policy",
+			Usage: "when to restart OpenVPN if it exits unexpectedly: always, on-failure or never",
+			Value: string(supervisor.DefaultRestartOptions.Policy),
+		},
+		cli.IntFlag{
+			Name:  "vpn-max-restarts",
+			Usage: "how many times OpenVPN is restarted in the restart window before giving up, 0 means no limit",
+			Value: supervisor.DefaultRestartOptions.MaxRestarts,
+		},
+		cli.DurationFlag{
+			Name:  "vpn-restart-window",
+			Usage: "time window that the OpenVPN restarts are counted in",
+			Value: supervisor.DefaultRestartOptions.Window,
+		},
 	}
 	app.Before = func(c *cli.Context) error {
 		logrus.SetLevel(logrus.InfoLevel)
@@ -65,7 +103,25 @@ func main() {
 			webPort = "8080"
 		}
 
-		s := newServer(port, webPort)
+		restartPolicy, err := supervisor.ParseRestartPolicy(c.String("vpn-restart-policy"))
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		restartOpts := supervisor.DefaultRestartOptions
+		restartOpts.Policy = restartPolicy
+		restartOpts.MaxRestarts = c.Int("vpn-max-restarts")
+		restartOpts.Window = c.Duration("vpn-restart-window")
+		if err := ovpm.SetVPNRestartOptions(restartOpts); err != nil {
+			logrus.Fatalf("can not set the OpenVPN restart options: %v", err)
+		}
+
+		if err := unlockCA(c.String("ca-key-file"), c.String("ca-passphrase-file")); err != nil {
+			logrus.Fatalf("can not unlock the offline ca: %v", err)
+		}
+
+		ovpm.TheServer().SetDaemonPort(port)
+		s := newServer(port, webPort, c.String("metrics-port"))
+		s.sessionRetention = c.Duration("session-retention")
 		s.start()
 		s.waitForInterrupt()
 		s.stop()
@@ -82,11 +138,17 @@ type server struct {
 	restServer http.Handler
 	restCancel context.CancelFunc
 	restPort   string
+	metricsLis net.Listener
 	signal     chan os.Signal
 	done       chan bool
+	stopRenew  chan struct{}
+	stopSync   chan struct{}
+
+	stopSessions     chan struct{}
+	sessionRetention time.Duration
 }
 
-func newServer(port, webPort string) *server {
+func newServer(port, webPort, metricsPort string) *server {
 	sigs := make(chan os.Signal, 1)
 	done := make(chan bool, 1)
 
@@ -113,6 +175,16 @@ func newServer(port, webPort string) *server {
 			logrus.Fatalf("could not listen to port %s: %v", webPort, err)
 		}
 
+		// NOTE: Metrics include the usernames and their traffic, so they
+		// are served on their own port that can be firewalled separately.
+		var metricsLis net.Listener
+		if metricsPort != "" {
+			metricsLis, err = net.Listen("tcp4", fmt.Sprintf("0.0.0.0:%s", metricsPort))
+			if err != nil {
+				logrus.Fatalf("could not listen to port %s: %v", metricsPort, err)
+			}
+		}
+
 		rpcServer := api.NewRPCServer()
 		restServer, restCancel, err := api.NewRESTServer(port)
 		if err != nil {
@@ -126,9 +198,14 @@ func newServer(port, webPort string) *server {
 			restServer: restServer,
 			restCancel: context.CancelFunc(restCancel),
 			restPort:   webPort,
+			metricsLis: metricsLis,
 			signal:     sigs,
 			done:       done,
 			grpcPort:   port,
+			stopRenew:  make(chan struct{}),
+			stopSync:   make(chan struct{}),
+
+			stopSessions: make(chan struct{}),
 		}
 	}
 	return &server{}
@@ -139,11 +216,21 @@ func (s *server) start() {
 	logrus.Infof("OVPM %s is running gRPC:%s, REST:%s ...", ovpm.Version, s.grpcPort, s.restPort)
 	go s.grpcServer.Serve(s.lis)
 	go http.Serve(s.restLis, s.restServer)
+	if s.metricsLis != nil {
+		logrus.Infof("serving metrics at %s/metrics", s.metricsLis.Addr())
+		go http.Serve(s.metricsLis, api.NewMetricsHandler())
+	}
 	ovpm.TheServer().StartVPNProc()
+	go maintainCertsPeriodically(s.stopRenew)
+	go syncLDAPPeriodically(s.stopSync)
+	go recordSessionsPeriodically(s.stopSessions, s.sessionRetention)
 }
 
 func (s *server) stop() {
 	logrus.Info("OVPM is shutting down ...")
+	close(s.stopRenew)
+	close(s.stopSync)
+	close(s.stopSessions)
 	s.grpcServer.Stop()
 	s.restCancel()
 	ovpm.TheServer().StopVPNProc()
@@ -155,6 +242,165 @@ func (s *server) waitForInterrupt() {
 	go timeout(8 * time.Second)
 }
 
+// unlockCA unlocks the offline CA with the encrypted key file, prompting for
+// the passphrase if the passphrase file isn't given.
+//
+// If the CA is offline but no key file is given, ovpmd keeps running with a
+// locked CA, which means that certificates and CRLs can't be issued.
+func unlockCA(keyFile, passphraseFile string) error {
+	svr := ovpm.TheServer()
+	if !svr.IsInitialized() || !svr.IsCAOffline() {
+		return nil
+	}
+	if keyFile == "" {
+		logrus.Warn("ca is offline and locked, certificates and crls can't be issued until ovpmd is started with --ca-key-file")
+		return nil
+	}
+
+	var passphrase []byte
+	if passphraseFile != "" {
+		b, err := ioutil.ReadFile(passphraseFile)
+		if err != nil {
+			return fmt.Errorf("can not read passphrase file: %v", err)
+		}
+		passphrase = bytes.TrimRight(b, "\r\n")
+	} else {
+		fd := int(os.Stdin.Fd())
+		if !term.IsTerminal(fd) {
+			return fmt.Errorf("can not prompt for the passphrase, stdin is not a terminal")
+		}
+		fmt.Print("CA key passphrase: ")
+		b, err := term.ReadPassword(fd)
+		fmt.Println()
+		if err != nil {
+			return err
+		}
+		passphrase = b
+	}
+	return svr.UnlockCAKeyFile(keyFile, passphrase)
+}
+
+// maintainCertsPeriodically renews the expiring client certificates and
+// carries on the CA rotation every ovpm.DefaultCertRenewInterval until stop
+// is closed.
+func maintainCertsPeriodically(stop <-chan struct{}) {
+	ticker := time.NewTicker(ovpm.DefaultCertRenewInterval)
+	defer ticker.Stop()
+	for {
+		renewCerts()
+		rotateCA()
+		if err := ovpm.TheServer().RefreshCRL(); err != nil {
+			logrus.Debugf("can not refresh crl: %v", err)
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// renewCerts renews the client certificates that are about to expire and
+// reports the users that need a fresh .ovpn profile.
+//
+// Background jobs are recorded in the audit log as the system actor.
+func renewCerts() {
+	if !ovpm.TheServer().IsInitialized() {
+		return
+	}
+	var users []*ovpm.User
+	err := ovpm.AuditJob("RenewExpiringCerts", func() (err error) {
+		users, err = ovpm.RenewExpiringCerts()
+		return err
+	})
+	if err != nil {
+		logrus.Errorf("can not renew expiring certs: %v", err)
+		return
+	}
+	for _, user := range users {
+		logrus.Warnf("user certificate renewed for %s, you should run: $ ovpm user genconfig --user %s", user.GetUsername(), user.GetUsername())
+	}
+}
+
+// rotateCA re-signs a batch of users with the new CA and retires the previous
+// CAs whose grace period is over.
+func rotateCA() {
+	if !ovpm.TheServer().IsInitialized() {
+		return
+	}
+	var users []*ovpm.User
+	err := ovpm.AuditJob("ResignUsers", func() (err error) {
+		users, err = ovpm.ResignUsers(ovpm.DefaultCAResignBatchSize)
+		return err
+	})
+	if err != nil {
+		logrus.Errorf("can not re-sign users with the new ca: %v", err)
+		return
+	}
+	for _, user := range users {
+		logrus.Warnf("user certificate re-signed with the new ca for %s, you should run: $ ovpm user genconfig --user %s", user.GetUsername(), user.GetUsername())
+	}
+	err = ovpm.AuditJob("RetireCAs", func() error {
+		_, err := ovpm.RetireCAs()
+		return err
+	})
+	if err != nil {
+		logrus.Errorf("can not retire previous cas: %v", err)
+	}
+}
+
+// syncLDAPPeriodically syncs the users with the LDAP group every
+// ovpm.DefaultLDAPSyncInterval until stop is closed.
+func syncLDAPPeriodically(stop <-chan struct{}) {
+	ticker := time.NewTicker(ovpm.DefaultLDAPSyncInterval)
+	defer ticker.Stop()
+	for {
+		if ovpm.IsLDAPEnabled() && ovpm.TheServer().IsInitialized() {
+			var result *ovpm.LDAPSyncResult
+			err := ovpm.AuditJob("SyncLDAPUsers", func() (err error) {
+				result, err = ovpm.SyncLDAPUsers()
+				return err
+			})
+			if err != nil {
+				logrus.Errorf("can not sync ldap users: %v", err)
+			}
+			if result != nil {
+				for _, username := range result.Created {
+					logrus.Warnf("ldap user created: %s, you should run: $ ovpm user genconfig --user %s", username, username)
+				}
+			}
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// recordSessionsPeriodically reconciles the VPN session history with the
+// connected clients and prunes the sessions that are older than retention
+// every ovpm.DefaultSessionSyncInterval until stop is closed.
+func recordSessionsPeriodically(stop <-chan struct{}, retention time.Duration) {
+	ticker := time.NewTicker(ovpm.DefaultSessionSyncInterval)
+	defer ticker.Stop()
+	for {
+		if err := ovpm.SyncVPNSessions(); err != nil {
+			logrus.Debugf("can not sync vpn sessions: %v", err)
+		}
+		if retention > 0 {
+			if _, err := ovpm.PruneVPNSessions(retention); err != nil {
+				logrus.Errorf("can not prune vpn sessions: %v", err)
+			}
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 func timeout(interval time.Duration) {
 	time.Sleep(interval)
 	log.Println("Timeout! Killing the main thread...")
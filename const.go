@@ -1,5 +1,7 @@
 package ovpm
 
+import "time"
+
 // Version defines the version of ovpm.
 var Version = "development"
 
@@ -16,6 +18,12 @@ const (
 	// DefaultVPNDNS is the default DNS to push to clients.
 	DefaultVPNDNS = "8.8.8.8"
 
+	// DefaultTLSCrypt is the default control channel protection mode of the newly created servers.
+	DefaultTLSCrypt = TLSCrypt
+
+	// DefaultDHParamsBits is the size of the DH params that are generated for the servers.
+	DefaultDHParamsBits = 2048
+
 	// DefaultDaemonPort is the port OVPMD will listen by default if something else is not specified.
 	DefaultDaemonPort = 9090
 
@@ -25,6 +33,45 @@ const (
 	// DefaultKeepaliveTimeout is the default ping timeout to assume that remote peer is down.
 	DefaultKeepaliveTimeout = "4"
 
+	// DefaultCAValidityDays is the default validity period of the CA certificate in days.
+	DefaultCAValidityDays = 3650
+
+	// DefaultServerCertValidityDays is the default validity period of the server certificate in days.
+	DefaultServerCertValidityDays = 3650
+
+	// DefaultClientCertValidityDays is the default validity period of the client certificates in days.
+	DefaultClientCertValidityDays = 3650
+
+	// DefaultCertRenewWindowDays is the default number of days before expiry that client certificates get renewed.
+	DefaultCertRenewWindowDays = 30
+
+	// DefaultCertRenewInterval is how often OVPMD checks for client certificates to renew.
+	DefaultCertRenewInterval = 1 * time.Hour
+
+	// DefaultCARotationGraceDays is the default number of days that the previous CA is trusted after a CA rotation.
+	DefaultCARotationGraceDays = 30
+
+	// DefaultCAResignBatchSize is the number of users that OVPMD re-signs with the new CA at once after a CA rotation.
+	DefaultCAResignBatchSize = 10
+
+	// DefaultLDAPSyncInterval is how often OVPMD syncs the users with the LDAP group.
+	DefaultLDAPSyncInterval = 15 * time.Minute
+
+	// DefaultTokenIdleTimeout is how long an API token stays valid without being used.
+	DefaultTokenIdleTimeout = 24 * time.Hour
+
+	// DefaultTokenLifetime is how long an API token stays valid at most, even if it's used.
+	DefaultTokenLifetime = 30 * 24 * time.Hour
+
+	// DefaultByteCountInterval is the interval in seconds that OpenVPN reports the per client byte counters.
+	DefaultByteCountInterval = 5
+
+	// DefaultSessionSyncInterval is how often OVPMD reconciles the VPN session history with the connected clients.
+	DefaultSessionSyncInterval = 1 * time.Minute
+
+	// DefaultSessionRetention is how long the ended VPN sessions are kept in the history.
+	DefaultSessionRetention = 90 * 24 * time.Hour
+
 	etcBasePath = "/etc/ovpm/"
 	varBasePath = "/var/db/ovpm/"
 
@@ -36,9 +83,12 @@ const (
 	_DefaultKeyPath       = varBasePath + "server.key"
 	_DefaultCACertPath    = varBasePath + "ca.crt"
 	_DefaultCAKeyPath     = varBasePath + "ca.key"
-	_DefaultDHParamsPath  = varBasePath + "dh4096.pem"
+	_DefaultDHParamsPath  = varBasePath + "dh.pem"
 	_DefaultCRLPath       = varBasePath + "crl.pem"
 	_DefaultStatusLogPath = varBasePath + "openvpn-status.log"
+
+	_DefaultManagementSocketPath = varBasePath + "management.sock"
+	_DefaultTLSCryptKeyPath      = varBasePath + "tls-crypt.key"
 )
 
 // Testing is used to determine whether we are testing or running normally.
@@ -0,0 +1,281 @@
+package ovpm
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Crypto profile presets.
+const (
+	// LegacyCryptoPreset is compatible with the .ovpn profiles that are
+	// exported before the crypto profiles are introduced.
+	LegacyCryptoPreset = "legacy"
+
+	// DefaultCryptoPreset negotiates the AEAD ciphers and only lets the
+	// clients that can't negotiate to fall back to AES-128-CBC.
+	DefaultCryptoPreset = "default"
+
+	// StrictCryptoPreset only allows the AEAD ciphers and TLS 1.3.
+	StrictCryptoPreset = "strict"
+)
+
+// Supported data channel ciphers, auth digests and TLS versions.
+var (
+	dataCiphers   = []string{"AES-256-GCM", "AES-192-GCM", "AES-128-GCM", "CHACHA20-POLY1305", "AES-256-CBC", "AES-192-CBC", "AES-128-CBC"}
+	aeadCiphers   = []string{"AES-256-GCM", "AES-192-GCM", "AES-128-GCM", "CHACHA20-POLY1305"}
+	authDigests   = []string{"SHA1", "SHA256", "SHA384", "SHA512"}
+	tlsVersions   = []string{"1.0", "1.1", "1.2", "1.3"}
+	tlsCipherExpr = regexp.MustCompile(`^[A-Za-z0-9\-_+!@:]+$`)
+)
+
+// CryptoProfile represents the data channel and the TLS settings of the VPN
+// server. Both the server config and the .ovpn profiles are rendered from it.
+//
+// The server config always negotiates the data ciphers, which needs OpenVPN
+// 2.5 or later on the server. Older clients ignore them and use the fallback
+// cipher.
+type CryptoProfile struct {
+	DataCiphers         string // Colon separated data channel ciphers to negotiate in the order of preference.
+	DataCiphersFallback string // Cipher of the clients that can't negotiate. "" means they can't connect.
+	AuthDigest          string // HMAC digest of the non-AEAD ciphers.
+	TLSVersionMin       string // Minimum TLS version.
+	TLSCipher           string // Colon separated TLS 1.2 cipher suites. "" means the OpenVPN defaults.
+	RemoteCertTLS       bool   // Clients verify the server cert by its key usage instead of the deprecated nsCertType.
+	ECDHOnly            bool   // Only use ECDHE for the key exchange, so that the server doesn't need DH params.
+}
+
+// CryptoPresets returns the names of the crypto profile presets.
+func CryptoPresets() []string {
+	return []string{LegacyCryptoPreset, DefaultCryptoPreset, StrictCryptoPreset}
+}
+
+// GetCryptoPreset returns the crypto profile preset with the given name.
+func GetCryptoPreset(name string) (CryptoProfile, error) {
+	switch name {
+	case LegacyCryptoPreset:
+		return CryptoProfile{
+			DataCiphers:         "AES-256-GCM:AES-128-GCM:AES-128-CBC",
+			DataCiphersFallback: "AES-128-CBC",
+			AuthDigest:          "SHA1",
+			TLSVersionMin:       "1.0",
+		}, nil
+	case DefaultCryptoPreset:
+		return CryptoProfile{
+			DataCiphers:         "AES-256-GCM:AES-128-GCM:CHACHA20-POLY1305",
+			DataCiphersFallback: "AES-128-CBC",
+			AuthDigest:          "SHA1",
+			TLSVersionMin:       "1.2",
+			RemoteCertTLS:       true,
+		}, nil
+	case StrictCryptoPreset:
+		return CryptoProfile{
+			DataCiphers:   "AES-256-GCM:CHACHA20-POLY1305",
+			AuthDigest:    "SHA256",
+			TLSVersionMin: "1.3",
+			RemoteCertTLS: true,
+			ECDHOnly:      true,
+		}, nil
+	}
+	return CryptoProfile{}, fmt.Errorf("validation error: crypto preset:`%s` should be either '%s', '%s' or '%s'", name, LegacyCryptoPreset, DefaultCryptoPreset, StrictCryptoPreset)
+}
+
+// Preset returns the name of the preset that the profile is the same with, or "custom".
+func (p CryptoProfile) Preset() string {
+	for _, name := range CryptoPresets() {
+		if preset, _ := GetCryptoPreset(name); preset == p {
+			return name
+		}
+	}
+	return "custom"
+}
+
+// validate checks that the profile only has the supported settings.
+func (p CryptoProfile) validate() error {
+	if p.DataCiphers == "" {
+		return fmt.Errorf("validation error: data ciphers can not be empty")
+	}
+	for _, c := range strings.Split(p.DataCiphers, ":") {
+		if !stringInSlice(c, dataCiphers) {
+			return fmt.Errorf("validation error: data cipher:`%s` should be one of %v", c, dataCiphers)
+		}
+	}
+	if p.DataCiphersFallback != "" && !stringInSlice(p.DataCiphersFallback, dataCiphers) {
+		return fmt.Errorf("validation error: data ciphers fallback:`%s` should be one of %v", p.DataCiphersFallback, dataCiphers)
+	}
+	if !stringInSlice(p.AuthDigest, authDigests) {
+		return fmt.Errorf("validation error: auth digest:`%s` should be one of %v", p.AuthDigest, authDigests)
+	}
+	if !stringInSlice(p.TLSVersionMin, tlsVersions) {
+		return fmt.Errorf("validation error: tls version min:`%s` should be one of %v", p.TLSVersionMin, tlsVersions)
+	}
+	if p.TLSCipher != "" && !tlsCipherExpr.MatchString(p.TLSCipher) {
+		return fmt.Errorf("validation error: tls cipher:`%s` should be a colon separated list of cipher suites", p.TLSCipher)
+	}
+	if p.ECDHOnly {
+		// TLS 1.0 and 1.1 clients may not support ECDHE.
+		if p.TLSVersionMin < "1.2" {
+			return fmt.Errorf("validation error: ecdh only needs tls version min 1.2 or later but it's %s", p.TLSVersionMin)
+		}
+		for _, c := range strings.Split(p.TLSCipher, ":") {
+			if c != "" && !strings.Contains(c, "ECDHE") {
+				return fmt.Errorf("validation error: ecdh only can not be used with the non-ECDHE tls cipher:`%s`", c)
+			}
+		}
+	}
+	return nil
+}
+
+// locksOut returns an error if the clients that use the .ovpn profiles
+// rendered from the current profile can't connect to the server with the
+// next profile.
+func (p CryptoProfile) locksOut(next CryptoProfile) error {
+	// Clients that can negotiate offer their data ciphers and the cipher they
+	// fall back to.
+	offered := strings.Split(p.DataCiphers, ":")
+	if p.DataCiphersFallback != "" {
+		offered = append(offered, p.DataCiphersFallback)
+	}
+	var common bool
+	for _, c := range strings.Split(next.DataCiphers, ":") {
+		if stringInSlice(c, offered) {
+			common = true
+			break
+		}
+	}
+	if !common {
+		return fmt.Errorf("validation error: data ciphers %s have no cipher in common with the existing clients' %s", next.DataCiphers, strings.Join(offered, ":"))
+	}
+
+	// Clients that can't negotiate only use the fallback cipher.
+	if p.DataCiphersFallback != "" {
+		if next.DataCiphersFallback != p.DataCiphersFallback {
+			return fmt.Errorf("validation error: existing clients that can't negotiate the data cipher use %s, data ciphers fallback can't be changed to '%s'", p.DataCiphersFallback, next.DataCiphersFallback)
+		}
+		if !stringInSlice(p.DataCiphersFallback, aeadCiphers) && next.AuthDigest != p.AuthDigest {
+			return fmt.Errorf("validation error: existing clients that fall back to %s use %s, auth digest can't be changed to %s", p.DataCiphersFallback, p.AuthDigest, next.AuthDigest)
+		}
+	}
+
+	// Existing clients may not support the newer TLS versions, cipher suites
+	// and key exchanges.
+	if next.TLSVersionMin > p.TLSVersionMin {
+		return fmt.Errorf("validation error: existing clients may only support tls %s, tls version min can't be raised to %s", p.TLSVersionMin, next.TLSVersionMin)
+	}
+	if next.TLSCipher != "" {
+		if p.TLSCipher == "" {
+			return fmt.Errorf("validation error: existing clients use the default tls cipher suites, tls cipher can't be narrowed to %s", next.TLSCipher)
+		}
+		suites := strings.Split(next.TLSCipher, ":")
+		for _, c := range strings.Split(p.TLSCipher, ":") {
+			if !stringInSlice(c, suites) {
+				return fmt.Errorf("validation error: existing clients may only support the tls cipher %s, it can't be removed from the tls cipher", c)
+			}
+		}
+	}
+	if next.ECDHOnly && !p.ECDHOnly {
+		return fmt.Errorf("validation error: existing clients may not support ecdh, ecdh only can't be enabled")
+	}
+	return nil
+}
+
+// CryptoOptions represents the changes to a crypto profile.
+//
+// The preset is applied first and then the non-zero fields override it.
+type CryptoOptions struct {
+	Preset              string
+	DataCiphers         string
+	DataCiphersFallback string // "none" removes the fallback cipher.
+	AuthDigest          string
+	TLSVersionMin       string
+	TLSCipher           string // "default" removes the tls cipher suites.
+	RemoteCertTLS       *bool
+	ECDHOnly            *bool
+	Force               bool // Apply the profile even if it locks out the existing clients.
+}
+
+// apply returns the base profile with the options applied.
+func (o *CryptoOptions) apply(base CryptoProfile) (CryptoProfile, error) {
+	p := base
+	if o == nil {
+		return p, nil
+	}
+	if o.Preset != "" {
+		preset, err := GetCryptoPreset(o.Preset)
+		if err != nil {
+			return p, err
+		}
+		p = preset
+	}
+	if o.DataCiphers != "" {
+		p.DataCiphers = o.DataCiphers
+	}
+	switch o.DataCiphersFallback {
+	case "":
+	case "none":
+		p.DataCiphersFallback = ""
+	default:
+		p.DataCiphersFallback = o.DataCiphersFallback
+	}
+	if o.AuthDigest != "" {
+		p.AuthDigest = o.AuthDigest
+	}
+	if o.TLSVersionMin != "" {
+		p.TLSVersionMin = o.TLSVersionMin
+	}
+	switch o.TLSCipher {
+	case "":
+	case "default":
+		p.TLSCipher = ""
+	default:
+		p.TLSCipher = o.TLSCipher
+	}
+	if o.RemoteCertTLS != nil {
+		p.RemoteCertTLS = *o.RemoteCertTLS
+	}
+	if o.ECDHOnly != nil {
+		p.ECDHOnly = *o.ECDHOnly
+	}
+	return p, p.validate()
+}
+
+// GetCryptoProfile returns the crypto profile of the server.
+//
+// Servers that are initialized before the crypto profiles are introduced use
+// the legacy preset.
+func (svr *Server) GetCryptoProfile() CryptoProfile {
+	if svr.DataCiphers == "" {
+		p, _ := GetCryptoPreset(LegacyCryptoPreset)
+		return p
+	}
+	return CryptoProfile{
+		DataCiphers:         svr.DataCiphers,
+		DataCiphersFallback: svr.DataCiphersFallback,
+		AuthDigest:          svr.AuthDigest,
+		TLSVersionMin:       svr.TLSVersionMin,
+		TLSCipher:           svr.TLSCipher,
+		RemoteCertTLS:       svr.RemoteCertTLS,
+		ECDHOnly:            svr.ECDHOnly,
+	}
+}
+
+// setCryptoProfile sets the crypto profile of the server model.
+func (m *dbServerModel) setCryptoProfile(p CryptoProfile) {
+	m.DataCiphers = p.DataCiphers
+	m.DataCiphersFallback = p.DataCiphersFallback
+	m.AuthDigest = p.AuthDigest
+	m.TLSVersionMin = p.TLSVersionMin
+	m.TLSCipher = p.TLSCipher
+	m.RemoteCertTLS = p.RemoteCertTLS
+	m.ECDHOnly = p.ECDHOnly
+}
+
+// stringInSlice returns whether the list has the string a.
+func stringInSlice(a string, list []string) bool {
+	for _, b := range list {
+		if b == a {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,193 @@
+package ovpm
+
+import (
+	"strings"
+	"testing"
+
+	"go.uber.org/thriftrw/ptr"
+)
+
+func TestCryptoPresets(t *testing.T) {
+	for _, name := range CryptoPresets() {
+		p, err := GetCryptoPreset(name)
+		if err != nil {
+			t.Fatalf("preset %s is expected to exist: %v", name, err)
+		}
+		if err := p.validate(); err != nil {
+			t.Errorf("preset %s is expected to be valid: %v", name, err)
+		}
+		if p.Preset() != name {
+			t.Errorf("preset %s is expected to be recognized but it's %s", name, p.Preset())
+		}
+	}
+	if _, err := GetCryptoPreset("paranoid"); err == nil {
+		t.Errorf("unknown preset is expected to fail")
+	}
+
+	// Overrides make a custom profile.
+	p, err := (&CryptoOptions{Preset: DefaultCryptoPreset, AuthDigest: "SHA512"}).apply(CryptoProfile{})
+	if err != nil {
+		t.Fatalf("can not apply crypto options: %v", err)
+	}
+	if p.AuthDigest != "SHA512" || p.Preset() != "custom" {
+		t.Errorf("crypto options are expected to override the preset: %+v", p)
+	}
+	p, _ = (&CryptoOptions{DataCiphersFallback: "none", RemoteCertTLS: ptr.Bool(false)}).apply(p)
+	if p.DataCiphersFallback != "" || p.RemoteCertTLS {
+		t.Errorf("crypto options are expected to clear the fallback and remote-cert-tls: %+v", p)
+	}
+
+	// Unsupported settings are rejected.
+	var invalidtests = []CryptoOptions{
+		{DataCiphers: "AES-256-GCM:BF-CBC"},
+		{DataCiphersFallback: "DES-CBC"},
+		{AuthDigest: "MD5"},
+		{TLSVersionMin: "1.4"},
+		{TLSCipher: "TLS-ECDHE-RSA-WITH-AES-256-GCM-SHA384\nup /bin/sh"},
+	}
+	for _, o := range invalidtests {
+		if _, err := o.apply(p); err == nil {
+			t.Errorf("crypto options %+v are expected to be rejected", o)
+		}
+	}
+}
+
+func TestCryptoProfileLocksOut(t *testing.T) {
+	legacy, _ := GetCryptoPreset(LegacyCryptoPreset)
+	def, _ := GetCryptoPreset(DefaultCryptoPreset)
+	strict, _ := GetCryptoPreset(StrictCryptoPreset)
+
+	var lockouttests = []struct {
+		name     string
+		from, to CryptoProfile
+		locksOut bool
+	}{
+		{"legacy to default", legacy, def, true},
+		{"default to legacy", def, legacy, false},
+		{"default to strict", def, strict, true},
+		{"legacy to strict", legacy, strict, true},
+		{"strict to default", strict, def, false},
+		{"auth change with cbc fallback", def, CryptoProfile{DataCiphers: def.DataCiphers, DataCiphersFallback: "AES-128-CBC", AuthDigest: "SHA256", TLSVersionMin: "1.2"}, true},
+		{"no cipher in common", strict, CryptoProfile{DataCiphers: "AES-128-GCM", AuthDigest: "SHA256", TLSVersionMin: "1.2"}, true},
+		{"tls version min raise", legacy, CryptoProfile{DataCiphers: legacy.DataCiphers, DataCiphersFallback: "AES-128-CBC", AuthDigest: "SHA1", TLSVersionMin: "1.1"}, true},
+		{"tls cipher from the defaults", def, CryptoProfile{DataCiphers: def.DataCiphers, DataCiphersFallback: "AES-128-CBC", AuthDigest: "SHA1", TLSVersionMin: "1.2", TLSCipher: "TLS-ECDHE-RSA-WITH-AES-256-GCM-SHA384"}, true},
+		{"tls cipher narrowing", CryptoProfile{DataCiphers: def.DataCiphers, AuthDigest: "SHA1", TLSVersionMin: "1.2", TLSCipher: "TLS-ECDHE-RSA-WITH-AES-256-GCM-SHA384:TLS-DHE-RSA-WITH-AES-256-GCM-SHA384"}, CryptoProfile{DataCiphers: def.DataCiphers, AuthDigest: "SHA1", TLSVersionMin: "1.2", TLSCipher: "TLS-ECDHE-RSA-WITH-AES-256-GCM-SHA384"}, true},
+		{"tls cipher widening", CryptoProfile{DataCiphers: def.DataCiphers, AuthDigest: "SHA1", TLSVersionMin: "1.2", TLSCipher: "TLS-ECDHE-RSA-WITH-AES-256-GCM-SHA384"}, CryptoProfile{DataCiphers: def.DataCiphers, AuthDigest: "SHA1", TLSVersionMin: "1.2"}, false},
+		{"ecdh only", def, CryptoProfile{DataCiphers: def.DataCiphers, DataCiphersFallback: "AES-128-CBC", AuthDigest: "SHA1", TLSVersionMin: "1.2", ECDHOnly: true}, true},
+	}
+	for _, tt := range lockouttests {
+		if err := tt.from.locksOut(tt.to); (err != nil) != tt.locksOut {
+			t.Errorf("%s: locks out is expected to be %t but got: %v", tt.name, tt.locksOut, err)
+		}
+	}
+}
+
+func TestVPNCryptoProfile(t *testing.T) {
+	// Initialize:
+	setupTestCase()
+	CreateDB("sqlite3", ":memory:")
+	defer db.Cease()
+	svr := TheServer()
+
+	// Prepare:
+	if err := svr.Init("localhost", "", UDPProto, "", "", "", "", false, &ServerOptions{Crypto: &CryptoOptions{AuthDigest: "MD5"}}); err == nil {
+		t.Fatalf("init is expected to fail with an unsupported auth digest")
+	}
+	if err := svr.Init("localhost", "", UDPProto, "", "", "", "", false, &ServerOptions{Crypto: &CryptoOptions{Preset: DefaultCryptoPreset}}); err != nil {
+		t.Fatalf("can not init server: %v", err)
+	}
+	svr = TheServer()
+	usr, err := CreateNewUser("usr1", "1234", false, 0, true, "description")
+	if err != nil {
+		t.Fatalf("user creation failed: %v", err)
+	}
+
+	// Test:
+	if svr.GetCryptoProfile().Preset() != DefaultCryptoPreset {
+		t.Fatalf("crypto preset is expected to be %s but it's %s", DefaultCryptoPreset, svr.GetCryptoProfile().Preset())
+	}
+	for _, line := range []string{"data-ciphers AES-256-GCM:AES-128-GCM:CHACHA20-POLY1305", "data-ciphers-fallback AES-128-CBC", "auth SHA1", "tls-version-min 1.2"} {
+		if !strings.Contains(fs[_DefaultVPNConfPath], "\n"+line+"\n") {
+			t.Errorf("server.conf is expected to have %q:\n%s", line, fs[_DefaultVPNConfPath])
+		}
+	}
+	config, _ := svr.DumpsClientConfig(usr.GetUsername())
+	for _, line := range []string{"remote-cert-tls server", "cipher AES-128-CBC", "data-ciphers AES-256-GCM:AES-128-GCM:CHACHA20-POLY1305", "tls-version-min 1.2"} {
+		if !strings.Contains(config, "\n"+line+"\n") {
+			t.Errorf("client config is expected to have %q:\n%s", line, config)
+		}
+	}
+
+	// Clients that already have a profile can't be locked out.
+	if err := svr.Update("", "", nil, &ServerOptions{Crypto: &CryptoOptions{Preset: StrictCryptoPreset}}); err == nil {
+		t.Fatalf("switching to the strict preset is expected to fail when the users would be locked out")
+	}
+	if TheServer().GetCryptoProfile().Preset() != DefaultCryptoPreset {
+		t.Errorf("crypto profile is expected to stay the same after a rejected update")
+	}
+	if err := svr.Update("", "", nil, &ServerOptions{Crypto: &CryptoOptions{TLSVersionMin: "1.3", TLSCipher: "TLS-ECDHE-ECDSA-WITH-AES-256-GCM-SHA384"}}); err == nil {
+		t.Fatalf("raising the tls version min is expected to fail when the users would be locked out")
+	}
+	if err := svr.Update("", "", nil, &ServerOptions{Crypto: &CryptoOptions{TLSVersionMin: "1.3", TLSCipher: "TLS-ECDHE-ECDSA-WITH-AES-256-GCM-SHA384", Force: true}}); err != nil {
+		t.Fatalf("can not update server: %v", err)
+	}
+	svr = TheServer()
+	if !strings.Contains(fs[_DefaultVPNConfPath], "\ntls-version-min 1.3\ntls-cipher TLS-ECDHE-ECDSA-WITH-AES-256-GCM-SHA384\n") {
+		t.Errorf("server.conf is expected to have the updated tls settings:\n%s", fs[_DefaultVPNConfPath])
+	}
+
+	// Without users nobody can be locked out.
+	if err := usr.Delete(); err != nil {
+		t.Fatalf("can not delete user: %v", err)
+	}
+	if err := svr.Update("", "", nil, &ServerOptions{Crypto: &CryptoOptions{Preset: StrictCryptoPreset}}); err != nil {
+		t.Fatalf("switching to the strict preset is expected to succeed without users: %v", err)
+	}
+	if TheServer().GetCryptoProfile().Preset() != StrictCryptoPreset {
+		t.Errorf("crypto preset is expected to be %s", StrictCryptoPreset)
+	}
+	if strings.Contains(fs[_DefaultVPNConfPath], "data-ciphers-fallback") {
+		t.Errorf("server.conf is expected to not have a fallback cipher:\n%s", fs[_DefaultVPNConfPath])
+	}
+}
+
+func TestVPNLegacyCryptoProfile(t *testing.T) {
+	// Initialize:
+	setupTestCase()
+	CreateDB("sqlite3", ":memory:")
+	defer db.Cease()
+	svr := TheServer()
+	if err := svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil); err != nil {
+		t.Fatalf("can not init server: %v", err)
+	}
+
+	// Prepare:
+	// Servers that are initialized before the crypto profiles have an empty profile.
+	var server dbServerModel
+	db.First(&server)
+	server.setCryptoProfile(CryptoProfile{})
+	db.Save(&server)
+	svr = TheServer()
+	usr, err := CreateNewUser("usr1", "1234", false, 0, true, "description")
+	if err != nil {
+		t.Fatalf("user creation failed: %v", err)
+	}
+
+	// Test:
+	if svr.GetCryptoProfile().Preset() != LegacyCryptoPreset {
+		t.Fatalf("crypto preset is expected to be %s but it's %s", LegacyCryptoPreset, svr.GetCryptoProfile().Preset())
+	}
+	config, _ := svr.DumpsClientConfig(usr.GetUsername())
+	for _, line := range []string{"ns-cert-type server", "cipher AES-128-CBC"} {
+		if !strings.Contains(config, "\n"+line+"\n") {
+			t.Errorf("client config is expected to have %q:\n%s", line, config)
+		}
+	}
+	// Legacy clients may only support TLS 1.0, so the upgrade is forced.
+	if err := svr.Update("", "", nil, &ServerOptions{Crypto: &CryptoOptions{Preset: DefaultCryptoPreset}}); err == nil {
+		t.Errorf("upgrading to the default preset is expected to fail when the users would be locked out")
+	}
+	if err := svr.Update("", "", nil, &ServerOptions{Crypto: &CryptoOptions{Preset: DefaultCryptoPreset, Force: true}}); err != nil {
+		t.Fatalf("legacy profiles are expected to be upgraded to the default preset: %v", err)
+	}
+}
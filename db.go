@@ -33,6 +33,12 @@ func CreateDB(dialect string, args ...interface{}) *DB {
 	dbase.AutoMigrate(&dbServerModel{})
 	dbase.AutoMigrate(&dbRevokedModel{})
 	dbase.AutoMigrate(&dbNetworkModel{})
+	dbase.AutoMigrate(&dbCAHistoryModel{})
+	dbase.AutoMigrate(&dbLDAPModel{})
+	dbase.AutoMigrate(&dbOIDCModel{})
+	dbase.AutoMigrate(&dbTokenModel{})
+	dbase.AutoMigrate(&dbAuditModel{})
+	dbase.AutoMigrate(&dbVPNSessionModel{})
 
 	dbPTR := &DB{DB: dbase}
 	db = dbPTR
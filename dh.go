@@ -0,0 +1,108 @@
+package ovpm
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// dhParamsGen represents a DH params generation that runs in the background.
+type dhParamsGen struct {
+	startedAt time.Time
+	tries     int // number of the prime candidates tested so far
+	running   bool
+	err       error
+}
+
+// needsDHParams returns whether the server uses DH params for the key exchange.
+//
+// Servers with EC keys or with an ECDH only crypto profile use ECDHE instead.
+func (svr *Server) needsDHParams() bool {
+	return svr.GetKeyType().ECDHCurve() == "" && !svr.GetCryptoProfile().ECDHOnly
+}
+
+// generateDHParams starts generating the DH params of the server in the
+// background unless the server doesn't need them, already has them or they are
+// already being generated.
+//
+// OpenVPN only uses ECDHE (dh none) until they are ready. It's restarted with
+// the DH params afterwards.
+func (svr *Server) generateDHParams() {
+	if !svr.needsDHParams() || svr.DHParams != "" {
+		return
+	}
+	svr.dhParamsLock.Lock()
+	if svr.dhParamsGen != nil && svr.dhParamsGen.running {
+		svr.dhParamsLock.Unlock()
+		return
+	}
+	gen := &dhParamsGen{startedAt: time.Now(), running: true}
+	svr.dhParamsGen = gen
+	svr.dhParamsLock.Unlock()
+
+	// When testing generate them right away, since nothing waits for the goroutine.
+	if Testing {
+		if svr.runDHParamsGen(gen) {
+			svr.Refresh()
+		}
+		return
+	}
+	logrus.Infof("generating %d bit dh params in the background, OpenVPN only uses ECDHE until they are ready", DefaultDHParamsBits)
+	go func() {
+		if svr.runDHParamsGen(gen) {
+			TheServer().EmitWithRestart()
+		}
+	}()
+}
+
+// runDHParamsGen generates the DH params and stores them in the db.
+//
+// It returns whether the server got the new DH params.
+func (svr *Server) runDHParamsGen(gen *dhParamsGen) bool {
+	params, err := svr.newDHParamsFunc(DefaultDHParamsBits, func(tries int) {
+		svr.dhParamsLock.Lock()
+		gen.tries = tries
+		svr.dhParamsLock.Unlock()
+	})
+
+	svr.dhParamsLock.Lock()
+	gen.running = false
+	gen.err = err
+	svr.dhParamsLock.Unlock()
+	if err != nil {
+		logrus.Errorf("can not generate dh params: %v", err)
+		return false
+	}
+
+	// DH params aren't bound to a server, so they are given to whichever
+	// server is missing them, even if it's re-initialized in the meantime.
+	if q := db.Model(&dbServerModel{}).Where("dh_params = ?", "").Updates(dbServerModel{DHParams: params}); q.Error != nil || q.RowsAffected == 0 {
+		return false
+	}
+	logrus.Infof("dh params are generated in %s", time.Since(gen.startedAt).Round(time.Second))
+	return true
+}
+
+// GetDHParamsStatus returns the state of the DH params of the server.
+func (svr *Server) GetDHParamsStatus() string {
+	if !svr.needsDHParams() {
+		return "none (ECDHE only)"
+	}
+	if svr.DHParams != "" {
+		return "ready"
+	}
+
+	svr.dhParamsLock.Lock()
+	defer svr.dhParamsLock.Unlock()
+	gen := svr.dhParamsGen
+	switch {
+	case gen == nil:
+		return "pending"
+	case gen.running:
+		return fmt.Sprintf("generating, %d candidates tested in %s", gen.tries, time.Since(gen.startedAt).Round(time.Second))
+	case gen.err != nil:
+		return fmt.Sprintf("failed: %v", gen.err)
+	}
+	return "pending"
+}
@@ -0,0 +1,125 @@
+package ovpm
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cad/ovpm/pki"
+	"go.uber.org/thriftrw/ptr"
+)
+
+func TestVPNDHParams(t *testing.T) {
+	// Initialize:
+	setupTestCase()
+	CreateDB("sqlite3", ":memory:")
+	defer db.Cease()
+	svr := TheServer()
+
+	// Prepare:
+	if err := svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil); err != nil {
+		t.Fatalf("can not init server: %v", err)
+	}
+	svr = TheServer()
+
+	// Test:
+	// Every server gets its own dh params.
+	if _, _, err := pki.DecodeDHParams(svr.DHParams); err != nil {
+		t.Fatalf("server is expected to have dh params: %v", err)
+	}
+	if fs[_DefaultDHParamsPath] != svr.DHParams {
+		t.Errorf("dh params are expected to be emitted to %s", _DefaultDHParamsPath)
+	}
+	if !strings.Contains(fs[_DefaultVPNConfPath], "\ndh "+_DefaultDHParamsPath+"\n") {
+		t.Errorf("server.conf is expected to use the dh params:\n%s", fs[_DefaultVPNConfPath])
+	}
+	if svr.GetDHParamsStatus() != "ready" {
+		t.Errorf("dh params are expected to be ready but they're %s", svr.GetDHParamsStatus())
+	}
+
+	// Re-initializing the server keeps them.
+	dhParams := svr.DHParams
+	if err := svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil); err != nil {
+		t.Fatalf("can not init server: %v", err)
+	}
+	svr = TheServer()
+	if svr.DHParams != dhParams {
+		t.Errorf("dh params are expected to be kept when the server is re-initialized")
+	}
+
+	// ECDH only needs a crypto profile that allows it.
+	if err := svr.Update("", "", nil, &ServerOptions{Crypto: &CryptoOptions{TLSVersionMin: "1.0", ECDHOnly: ptr.Bool(true)}}); err == nil {
+		t.Fatalf("ecdh only is expected to be rejected with tls version min 1.0")
+	}
+	if err := svr.Update("", "", nil, &ServerOptions{Crypto: &CryptoOptions{ECDHOnly: ptr.Bool(true)}}); err != nil {
+		t.Fatalf("can not update server: %v", err)
+	}
+	svr = TheServer()
+	if !strings.Contains(fs[_DefaultVPNConfPath], "\ndh none\n") {
+		t.Errorf("server.conf is expected to use ecdh only:\n%s", fs[_DefaultVPNConfPath])
+	}
+	if svr.GetDHParamsStatus() != "none (ECDHE only)" {
+		t.Errorf("dh params are expected to be none but they're %s", svr.GetDHParamsStatus())
+	}
+	if err := svr.Update("", "", nil, &ServerOptions{Crypto: &CryptoOptions{ECDHOnly: ptr.Bool(false)}}); err != nil {
+		t.Fatalf("can not update server: %v", err)
+	}
+	svr = TheServer()
+	if !strings.Contains(fs[_DefaultVPNConfPath], "\ndh "+_DefaultDHParamsPath+"\n") {
+		t.Errorf("server.conf is expected to use the dh params again:\n%s", fs[_DefaultVPNConfPath])
+	}
+}
+
+func TestVPNDHParamsStatus(t *testing.T) {
+	// Initialize:
+	setupTestCase()
+	CreateDB("sqlite3", ":memory:")
+	defer db.Cease()
+	svr := TheServer()
+	newDHParams := svr.newDHParamsFunc
+	defer func() { svr.newDHParamsFunc = newDHParams }()
+
+	// Prepare:
+	svr.newDHParamsFunc = func(bits int, progress func(tries int)) (string, error) {
+		progress(1)
+		return "", fmt.Errorf("out of entropy")
+	}
+	if err := svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil); err != nil {
+		t.Fatalf("can not init server: %v", err)
+	}
+	svr = TheServer()
+
+	// Test:
+	// OpenVPN uses ECDHE until the dh params are ready.
+	if svr.DHParams != "" {
+		t.Fatalf("server is expected to not have dh params")
+	}
+	if !strings.Contains(fs[_DefaultVPNConfPath], "\ndh none\n") {
+		t.Errorf("server.conf is expected to use ecdh until the dh params are ready:\n%s", fs[_DefaultVPNConfPath])
+	}
+	if status := svr.GetDHParamsStatus(); status != "failed: out of entropy" {
+		t.Errorf("dh params are expected to be failed but they're %s", status)
+	}
+
+	// Progress is shown while they're being generated.
+	svr.dhParamsLock.Lock()
+	svr.dhParamsGen = &dhParamsGen{startedAt: time.Now(), tries: 42, running: true}
+	svr.dhParamsLock.Unlock()
+	if status := svr.GetDHParamsStatus(); !strings.HasPrefix(status, "generating, 42 candidates tested") {
+		t.Errorf("dh params are expected to be generating but they're %s", status)
+	}
+	svr.dhParamsLock.Lock()
+	svr.dhParamsGen.running = false
+	svr.dhParamsLock.Unlock()
+
+	// They're generated on the next emit.
+	svr.newDHParamsFunc = newDHParams
+	if err := svr.Emit(); err != nil {
+		t.Fatalf("can not emit: %v", err)
+	}
+	svr = TheServer()
+	if svr.GetDHParamsStatus() != "ready" || fs[_DefaultDHParamsPath] != svr.DHParams {
+		t.Errorf("dh params are expected to be generated on emit: %s", svr.GetDHParamsStatus())
+	}
+}
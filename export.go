@@ -0,0 +1,139 @@
+package ovpm
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cad/ovpm/pki"
+)
+
+// ClientConfigFormat is the format that the client credentials are exported in.
+type ClientConfigFormat string
+
+// Supported client config formats.
+const (
+	// OVPNFormat is a single .ovpn file with the credentials embedded.
+	OVPNFormat ClientConfigFormat = "ovpn"
+
+	// P12Format is a password protected PKCS#12 bundle of the user's key,
+	// certificate and the CA certificates.
+	P12Format ClientConfigFormat = "p12"
+
+	// ZipFormat is a zip archive of ca.crt, the user's certificate and key
+	// files, the tls crypt key file and a .ovpn file that refers to them.
+	ZipFormat ClientConfigFormat = "zip"
+)
+
+// ClientConfigFormats returns the supported client config formats.
+func ClientConfigFormats() []ClientConfigFormat {
+	return []ClientConfigFormat{OVPNFormat, P12Format, ZipFormat}
+}
+
+// ParseClientConfigFormat returns the ClientConfigFormat with the given name. It returns OVPNFormat for "".
+func ParseClientConfigFormat(name string) (ClientConfigFormat, error) {
+	if name == "" {
+		return OVPNFormat, nil
+	}
+	for _, f := range ClientConfigFormats() {
+		if string(f) == name {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown client config format: %s", name)
+}
+
+// FileName returns the file name of the exported client config of the given user.
+func (f ClientConfigFormat) FileName(username string) string {
+	return fmt.Sprintf("%s.%s", username, f)
+}
+
+// ExportClientConfig exports the credentials of the given vpn user in the given format.
+//
+// password is only used by P12Format and it's required for it.
+func (svr *Server) ExportClientConfig(username string, format ClientConfigFormat, password string) ([]byte, error) {
+	user, err := GetUser(username)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case OVPNFormat:
+		config, err := svr.renderClientConfig(user, nil)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(config), nil
+	case P12Format:
+		if user.HasClientKey() {
+			return nil, fmt.Errorf("private key of the user %s is held by the user, pkcs12 bundle can not be exported", username)
+		}
+		return pki.EncodePKCS12(user.getKey(), user.GetCert(), svr.GetCABundle(), password)
+	case ZipFormat:
+		return svr.zipClientConfig(user)
+	}
+	return nil, fmt.Errorf("unknown client config format: %s", format)
+}
+
+// zipEntry is a file in the exported zip archive.
+type zipEntry struct {
+	name    string
+	content string
+	mode    os.FileMode
+}
+
+// zipClientConfig returns a zip archive of the user's credentials as
+// separate files along with a .ovpn file that refers to them.
+func (svr *Server) zipClientConfig(user *User) ([]byte, error) {
+	files := &clientConfigFiles{
+		CA:   "ca.crt",
+		Cert: fmt.Sprintf("%s.crt", user.GetUsername()),
+	}
+	if !user.HasClientKey() {
+		files.Key = fmt.Sprintf("%s.key", user.GetUsername())
+	}
+	tlsCryptKey := svr.clientTLSCryptKey(user)
+	if tlsCryptKey != "" {
+		files.TLSCrypt = fmt.Sprintf("%s.key", svr.GetTLSCrypt())
+	}
+	config, err := svr.renderClientConfig(user, files)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []zipEntry{
+		{OVPNFormat.FileName(user.GetUsername()), config, 0644},
+		{files.CA, svr.GetCABundle(), 0644},
+		{files.Cert, user.GetCert(), 0644},
+	}
+	if files.Key != "" {
+		entries = append(entries, zipEntry{files.Key, user.getKey(), 0600})
+	}
+	if files.TLSCrypt != "" {
+		entries = append(entries, zipEntry{files.TLSCrypt, tlsCryptKey, 0600})
+	}
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, e := range entries {
+		hdr := &zip.FileHeader{
+			Name:     e.name,
+			Method:   zip.Deflate,
+			Modified: time.Now(),
+		}
+		hdr.SetMode(e.mode)
+		f, err := w.CreateHeader(hdr)
+		if err != nil {
+			return nil, fmt.Errorf("can not create zip entry %s: %v", e.name, err)
+		}
+		if _, err := f.Write([]byte(e.content)); err != nil {
+			return nil, fmt.Errorf("can not write zip entry %s: %v", e.name, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("can not close zip archive: %v", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,111 @@
+package ovpm
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+func TestParseClientConfigFormat(t *testing.T) {
+	var formattests = []struct {
+		name   string
+		format ClientConfigFormat
+		ok     bool
+	}{
+		{"", OVPNFormat, true},
+		{"ovpn", OVPNFormat, true},
+		{"p12", P12Format, true},
+		{"zip", ZipFormat, true},
+		{"pem", "", false},
+	}
+	for _, tt := range formattests {
+		format, err := ParseClientConfigFormat(tt.name)
+		if (err == nil) != tt.ok {
+			t.Errorf("ParseClientConfigFormat(%q) ok is expected to be %t but got error: %v", tt.name, tt.ok, err)
+		}
+		if format != tt.format {
+			t.Errorf("ParseClientConfigFormat(%q) = %q, want %q", tt.name, format, tt.format)
+		}
+	}
+}
+
+func TestVPNExportClientConfig(t *testing.T) {
+	// Init:
+	setupTestCase()
+	CreateDB("sqlite3", ":memory:")
+	defer db.Cease()
+	svr := TheServer()
+	svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil)
+
+	// Prepare:
+	user, err := CreateNewUser("user", "password", false, 0, true, "description")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Test:
+	// ovpn should be the same with the dumped one.
+	ovpn, err := svr.ExportClientConfig(user.GetUsername(), OVPNFormat, "")
+	if err != nil {
+		t.Fatalf("expected to export ovpn but we got error instead: %v", err)
+	}
+	dump, _ := svr.DumpsClientConfig(user.GetUsername())
+	if string(ovpn) != dump {
+		t.Errorf("exported ovpn is expected to be the same with the dumped client config")
+	}
+
+	// p12 needs a password.
+	if _, err := svr.ExportClientConfig(user.GetUsername(), P12Format, ""); err == nil {
+		t.Errorf("p12 export without a password is expected to fail")
+	}
+	p12, err := svr.ExportClientConfig(user.GetUsername(), P12Format, "secret")
+	if err != nil {
+		t.Fatalf("expected to export p12 but we got error instead: %v", err)
+	}
+	_, crt, caCerts, err := pkcs12.DecodeChain(p12, "secret")
+	if err != nil {
+		t.Fatalf("exported p12 can not be decoded: %v", err)
+	}
+	if crt.Subject.CommonName != user.GetUsername() {
+		t.Errorf("p12 cert is expected to be of %s but it's of %s", user.GetUsername(), crt.Subject.CommonName)
+	}
+	if len(caCerts) != 1 {
+		t.Errorf("p12 is expected to have 1 ca cert but it has %d", len(caCerts))
+	}
+
+	// zip should have the separate files.
+	archive, err := svr.ExportClientConfig(user.GetUsername(), ZipFormat, "")
+	if err != nil {
+		t.Fatalf("expected to export zip but we got error instead: %v", err)
+	}
+	r, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatalf("exported zip can not be read: %v", err)
+	}
+	files := map[string]string{}
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("zip entry %s can not be opened: %v", f.Name, err)
+		}
+		b, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(b)
+	}
+	if files["ca.crt"] != svr.GetCABundle() || files["user.crt"] != user.GetCert() || files["user.key"] != user.getKey() || files["tls-crypt.key"] != svr.TLSCryptKey {
+		t.Errorf("zip is expected to have ca.crt, user.crt, user.key and tls-crypt.key files: %v", r.File)
+	}
+	config := files["user.ovpn"]
+	for _, line := range []string{"ca ca.crt", "cert user.crt", "key user.key", "tls-crypt tls-crypt.key"} {
+		if !strings.Contains(config, line+"\n") {
+			t.Errorf("zipped ovpn is expected to refer to the files with %q but it doesn't:\n%s", line, config)
+		}
+	}
+	if strings.Contains(config, "<ca>") || strings.Contains(config, "<key>") || strings.Contains(config, "<tls-crypt>") {
+		t.Errorf("zipped ovpn is expected to not embed the credentials")
+	}
+}
@@ -0,0 +1,149 @@
+package ovpm
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cad/ovpm/pki"
+	"github.com/cad/ovpm/supervisor"
+	"github.com/sirupsen/logrus"
+)
+
+// Health check statuses, from the best to the worst.
+const (
+	HealthOK   = "ok"
+	HealthWarn = "warn" // Still serving, but needs attention. (e.g. a cert is about to expire)
+	HealthFail = "fail"
+)
+
+// HealthCheck is the result of checking a part of the system.
+//
+// The reports are served on the public port without authentication, so the
+// messages never carry the error details. They are logged instead.
+type HealthCheck struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// HealthReport is the result of the health checks.
+type HealthReport struct {
+	Status string         `json:"status"` // The worst status of the checks.
+	Checks []*HealthCheck `json:"checks"`
+}
+
+// IsHealthy returns whether none of the checks failed.
+func (r *HealthReport) IsHealthy() bool {
+	return r.Status != HealthFail
+}
+
+func (r *HealthReport) add(c *HealthCheck) {
+	r.Checks = append(r.Checks, c)
+	if c.Status == HealthFail || (c.Status == HealthWarn && r.Status == HealthOK) {
+		r.Status = c.Status
+	}
+}
+
+var (
+	emitLock    sync.Mutex
+	lastEmitAt  time.Time
+	lastEmitErr error
+)
+
+// recordEmit records the result of the last emit for the health checks.
+func recordEmit(err error) {
+	if err != nil {
+		logrus.Errorf("server files can not be emitted: %v", err)
+	}
+	emitLock.Lock()
+	defer emitLock.Unlock()
+	lastEmitAt, lastEmitErr = time.Now(), err
+}
+
+// CheckLiveness reports whether ovpmd is able to serve its API.
+func CheckLiveness() *HealthReport {
+	r := &HealthReport{Status: HealthOK}
+	r.add(checkDB())
+	return r
+}
+
+// CheckReadiness reports whether ovpmd is serving the VPN traffic.
+//
+// Certificates that are in their renew window are reported as warnings, so
+// they don't make the server unready until they expire.
+func CheckReadiness() *HealthReport {
+	r := &HealthReport{Status: HealthOK}
+	r.add(checkDB())
+	if !r.IsHealthy() {
+		return r
+	}
+
+	svr := TheServer()
+	if !svr.IsInitialized() {
+		r.add(&HealthCheck{Name: "initialized", Status: HealthFail, Message: "server is not initialized"})
+		return r
+	}
+	r.add(&HealthCheck{Name: "initialized", Status: HealthOK})
+
+	state := supervisor.UNKNOWN
+	if vpnProc != nil {
+		state = vpnProc.Status()
+	}
+	c := &HealthCheck{Name: "openvpn", Status: HealthOK, Message: state.String()}
+	if state != supervisor.RUNNING {
+		c.Status = HealthFail
+	}
+	r.add(c)
+
+	emitLock.Lock()
+	c = &HealthCheck{Name: "emit", Status: HealthOK}
+	switch {
+	case lastEmitAt.IsZero():
+		c.Status, c.Message = HealthFail, "server files are not emitted yet"
+	case lastEmitErr != nil:
+		c.Status, c.Message = HealthFail, fmt.Sprintf("last emit failed at %s", lastEmitAt.UTC().Format(time.RFC3339))
+	default:
+		c.Message = fmt.Sprintf("emitted at %s", lastEmitAt.UTC().Format(time.RFC3339))
+	}
+	emitLock.Unlock()
+	r.add(c)
+
+	window := time.Duration(svr.GetCertRenewWindowDays()) * 24 * time.Hour
+	r.add(checkCertExpiry("ca_cert", svr.CACert, window))
+	r.add(checkCertExpiry("server_cert", svr.Cert, window))
+	return r
+}
+
+// checkDB checks whether the database is reachable.
+func checkDB() *HealthCheck {
+	c := &HealthCheck{Name: "db", Status: HealthOK}
+	if db == nil {
+		c.Status, c.Message = HealthFail, "database is not opened"
+	} else if err := db.DB.DB().Ping(); err != nil {
+		logrus.Errorf("database is not reachable: %v", err)
+		c.Status, c.Message = HealthFail, "database is not reachable"
+	}
+	return c
+}
+
+// checkCertExpiry checks how close the cert is to its expiry.
+func checkCertExpiry(name, certPEM string, window time.Duration) *HealthCheck {
+	c := &HealthCheck{Name: name, Status: HealthOK}
+	crt, err := pki.ReadCertFromPEM(certPEM)
+	if err != nil {
+		logrus.Errorf("%s can not be parsed: %v", name, err)
+		c.Status, c.Message = HealthFail, "can not parse cert"
+		return c
+	}
+	left := time.Until(crt.NotAfter)
+	switch {
+	case left <= 0:
+		c.Status, c.Message = HealthFail, fmt.Sprintf("expired at %s", crt.NotAfter.UTC().Format(time.RFC3339))
+	case left <= window:
+		c.Status, c.Message = HealthWarn, fmt.Sprintf("expires in %d days at %s", int(left.Hours()/24), crt.NotAfter.UTC().Format(time.RFC3339))
+	default:
+		c.Message = fmt.Sprintf("expires at %s", crt.NotAfter.UTC().Format(time.RFC3339))
+	}
+	return c
+}
@@ -0,0 +1,78 @@
+package ovpm
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// healthCheck returns the check with the name from the report.
+func healthCheck(r *HealthReport, name string) *HealthCheck {
+	for _, c := range r.Checks {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func TestHealth(t *testing.T) {
+	// Initialize:
+	setupTestCase()
+	CreateDB("sqlite3", ":memory:")
+	svr := TheServer()
+
+	// Test:
+	if r := CheckLiveness(); !r.IsHealthy() {
+		t.Errorf("ovpmd is expected to be alive: %+v", r.Checks[0])
+	}
+	r := CheckReadiness()
+	if r.IsHealthy() || healthCheck(r, "initialized").Status != HealthFail {
+		t.Errorf("ovpmd is expected to be unready before the init: %+v", r)
+	}
+
+	svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil)
+	vpnProc.Start()
+	r = CheckReadiness()
+	if !r.IsHealthy() || r.Status != HealthOK {
+		for _, c := range r.Checks {
+			t.Logf("%+v", c)
+		}
+		t.Errorf("ovpmd is expected to be ready after the init: %s", r.Status)
+	}
+
+	// Failed emits and stopped processes make it unready.
+	recordEmit(fmt.Errorf("can not emit server conf"))
+	if r := CheckReadiness(); r.IsHealthy() || healthCheck(r, "emit").Status != HealthFail {
+		t.Errorf("ovpmd is expected to be unready after a failed emit")
+	} else if msg := healthCheck(r, "emit").Message; strings.Contains(msg, "server conf") {
+		t.Errorf("emit error is expected to be left out of the report: %s", msg)
+	}
+	recordEmit(nil)
+	vpnProc.Stop()
+	if r := CheckReadiness(); r.IsHealthy() || healthCheck(r, "openvpn").Status != HealthFail {
+		t.Errorf("ovpmd is expected to be unready while openvpn is stopped")
+	}
+
+	// Certs are warned about in their renew window and fail when they expire.
+	window := time.Duration(svr.GetCertRenewWindowDays()) * 24 * time.Hour
+	if c := checkCertExpiry("server_cert", svr.Cert, window); c.Status != HealthOK {
+		t.Errorf("server cert is expected to be ok: %+v", c)
+	}
+	if c := checkCertExpiry("server_cert", svr.Cert, 20*365*24*time.Hour); c.Status != HealthWarn {
+		t.Errorf("server cert is expected to be warned about in the renew window: %+v", c)
+	}
+	if c := checkCertExpiry("server_cert", "", window); c.Status != HealthFail {
+		t.Errorf("invalid cert is expected to fail: %+v", c)
+	}
+
+	// Unreachable database fails both.
+	db.Cease()
+	if r := CheckLiveness(); r.IsHealthy() {
+		t.Errorf("ovpmd is expected to be dead when the database is closed")
+	}
+	if r := CheckReadiness(); r.IsHealthy() || len(r.Checks) != 1 {
+		t.Errorf("readiness is expected to stop at the database check: %+v", r)
+	}
+}
@@ -0,0 +1,437 @@
+package ovpm
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+)
+
+// Authentication sources of the users.
+const (
+	// LocalAuthSource users are authenticated with the password hashes in the db.
+	LocalAuthSource = "local"
+
+	// LDAPAuthSource users are synced from a directory group and authenticated
+	// against the directory.
+	LDAPAuthSource = "ldap"
+)
+
+// What happens to the synced users that aren't a member of the directory
+// group anymore.
+const (
+	LDAPRemovalDisable = "disable"
+	LDAPRemovalDelete  = "delete"
+)
+
+// Default LDAP settings.
+const (
+	DefaultLDAPUsernameAttr  = "uid"
+	DefaultLDAPRemovalPolicy = LDAPRemovalDisable
+)
+
+// ldapTimeout is the timeout of dialing the directory and of each operation.
+const ldapTimeout = 10 * time.Second
+
+// LDAPConfig represents the directory (e.g. OpenLDAP or Active Directory)
+// that the users are authenticated against and synced from.
+//
+// Group memberships are read from the member attribute of the groups, which
+// holds the DNs of the members. (e.g. groupOfNames and Active Directory groups)
+//
+// The bind password and the users' passwords are sent to the directory, so
+// ldap:// URLs either need StartTLS or should be explicitly allowed to be
+// plaintext with InsecurePlaintext.
+type LDAPConfig struct {
+	URL                string // ldap:// or ldaps:// URL of the directory.
+	StartTLS           bool   // Upgrade the ldap:// connections with StartTLS.
+	InsecurePlaintext  bool   // Allow ldap:// without StartTLS.
+	InsecureSkipVerify bool   // Don't verify the certificate of the directory.
+	BindDN             string // DN of the service account that searches the directory.
+	BindPassword       string
+	UserBaseDN         string // Where the users are searched.
+	UsernameAttr       string // Attribute that holds the usernames. (e.g. uid or sAMAccountName)
+	UserFilter         string // Filter that finds a user, %s is the escaped username. (e.g. (uid=%s))
+	GroupDN            string // Members of this group are the VPN users.
+	AdminGroupDN       string // Members of this group are the admins. "" leaves the admin flag alone.
+	RemovalPolicy      string // Either LDAPRemovalDisable or LDAPRemovalDelete.
+}
+
+// dbLDAPModel is database model for the LDAP configuration.
+type dbLDAPModel struct {
+	gorm.Model
+	LDAPConfig
+}
+
+// LDAPSyncResult lists the usernames that are changed by a sync.
+type LDAPSyncResult struct {
+	Created  []string
+	Enabled  []string
+	Disabled []string
+	Deleted  []string
+	Promoted []string // Users that become admins.
+	Demoted  []string // Users that aren't admins anymore.
+}
+
+// GetLDAPConfig returns the LDAP configuration or nil if LDAP isn't
+// configured.
+func GetLDAPConfig() *LDAPConfig {
+	var m dbLDAPModel
+	if db.First(&m).RecordNotFound() {
+		return nil
+	}
+	return &m.LDAPConfig
+}
+
+// IsLDAPEnabled returns whether LDAP is configured.
+func IsLDAPEnabled() bool {
+	return GetLDAPConfig() != nil
+}
+
+// SetLDAPConfig validates the LDAP configuration by connecting to the
+// directory and then stores it.
+//
+// If the bind password is empty, the previous one is kept.
+func SetLDAPConfig(cfg LDAPConfig) error {
+	if cfg.BindPassword == "" {
+		if prev := GetLDAPConfig(); prev != nil {
+			cfg.BindPassword = prev.BindPassword
+		}
+	}
+	if cfg.UsernameAttr == "" {
+		cfg.UsernameAttr = DefaultLDAPUsernameAttr
+	}
+	if cfg.UserFilter == "" {
+		cfg.UserFilter = fmt.Sprintf("(%s=%%s)", cfg.UsernameAttr)
+	}
+	if cfg.RemovalPolicy == "" {
+		cfg.RemovalPolicy = DefaultLDAPRemovalPolicy
+	}
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+
+	// Make sure that the directory is reachable and the groups exist.
+	conn, err := cfg.connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	for _, dn := range []string{cfg.GroupDN, cfg.AdminGroupDN} {
+		if dn == "" {
+			continue
+		}
+		if _, err := cfg.groupMembers(conn, dn); err != nil {
+			return err
+		}
+	}
+
+	db.Unscoped().Delete(dbLDAPModel{})
+	m := dbLDAPModel{LDAPConfig: cfg}
+	db.Create(&m)
+	if db.NewRecord(&m) {
+		return fmt.Errorf("can not save ldap config")
+	}
+	logrus.Infof("ldap configured: %s", cfg.URL)
+	return nil
+}
+
+// DisableLDAP removes the LDAP configuration.
+//
+// Synced users are kept but they can't authenticate until LDAP is configured
+// again.
+func DisableLDAP() error {
+	db.Unscoped().Delete(dbLDAPModel{})
+	logrus.Info("ldap disabled")
+	return nil
+}
+
+func (cfg *LDAPConfig) validate() error {
+	if err := cfg.validateTransport(); err != nil {
+		return err
+	}
+	for _, field := range [][2]string{{"bind dn", cfg.BindDN}, {"bind password", cfg.BindPassword}, {"user base dn", cfg.UserBaseDN}, {"group dn", cfg.GroupDN}} {
+		if govalidator.IsNull(field[1]) {
+			return fmt.Errorf("validation error: %s can not be empty", field[0])
+		}
+	}
+	if strings.Count(cfg.UserFilter, "%s") != 1 || strings.Count(cfg.UserFilter, "%") != 1 {
+		return fmt.Errorf("validation error: user filter:`%s` should have a single %%s in place of the username", cfg.UserFilter)
+	}
+	if cfg.RemovalPolicy != LDAPRemovalDisable && cfg.RemovalPolicy != LDAPRemovalDelete {
+		return fmt.Errorf("validation error: removal policy:`%s` should be either '%s' or '%s'", cfg.RemovalPolicy, LDAPRemovalDisable, LDAPRemovalDelete)
+	}
+	return nil
+}
+
+// validateTransport makes sure that the credentials aren't sent in plaintext
+// unless it's explicitly allowed.
+func (cfg *LDAPConfig) validateTransport() error {
+	switch {
+	case strings.HasPrefix(cfg.URL, "ldaps://"):
+		if cfg.StartTLS {
+			return fmt.Errorf("validation error: ldap url:`%s` is already tls, start tls can only be used with ldap://", cfg.URL)
+		}
+	case strings.HasPrefix(cfg.URL, "ldap://"):
+		if !cfg.StartTLS && !cfg.InsecurePlaintext {
+			return fmt.Errorf("validation error: ldap url:`%s` is plaintext, either use ldaps://, enable start tls or explicitly allow insecure plaintext", cfg.URL)
+		}
+	default:
+		return fmt.Errorf("validation error: ldap url:`%s` should start with ldap:// or ldaps://", cfg.URL)
+	}
+	return nil
+}
+
+// connect connects to the directory and binds as the service account.
+//
+// Stored configs are checked again, so that the credentials are never sent
+// in plaintext unless it's allowed.
+func (cfg *LDAPConfig) connect() (*ldap.Conn, error) {
+	if err := cfg.validateTransport(); err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("can not parse ldap url %s: %v", cfg.URL, err)
+	}
+	tlsConfig := &tls.Config{ServerName: u.Hostname(), InsecureSkipVerify: cfg.InsecureSkipVerify}
+	conn, err := ldap.DialURL(cfg.URL, ldap.DialWithDialer(&net.Dialer{Timeout: ldapTimeout}), ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, fmt.Errorf("can not connect to ldap: %v", err)
+	}
+	conn.SetTimeout(ldapTimeout)
+	if cfg.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("can not start tls with ldap: %v", err)
+		}
+	}
+	if err := conn.Bind(cfg.BindDN, cfg.BindPassword); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("can not bind to ldap as %s: %v", cfg.BindDN, err)
+	}
+	return conn, nil
+}
+
+// groupMembers returns the normalized DNs of the group's members.
+func (cfg *LDAPConfig) groupMembers(conn *ldap.Conn, groupDN string) (map[string]bool, error) {
+	res, err := conn.Search(ldap.NewSearchRequest(groupDN, ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, int(ldapTimeout/time.Second), false, "(objectClass=*)", []string{"member"}, nil))
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) || (err == nil && len(res.Entries) != 1) {
+		return nil, fmt.Errorf("ldap group not found: %s", groupDN)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can not read ldap group %s: %v", groupDN, err)
+	}
+	members := make(map[string]bool)
+	for _, dn := range res.Entries[0].GetEqualFoldAttributeValues("member") {
+		members[normalizeDN(dn)] = true
+	}
+	return members, nil
+}
+
+// searchUsers returns the users that match the user filter with the given
+// filter value.
+func (cfg *LDAPConfig) searchUsers(conn *ldap.Conn, value string) ([]*ldap.Entry, error) {
+	filter := fmt.Sprintf(cfg.UserFilter, value)
+	res, err := conn.Search(ldap.NewSearchRequest(cfg.UserBaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, int(ldapTimeout/time.Second), false, filter, []string{cfg.UsernameAttr}, nil))
+	if err != nil {
+		return nil, fmt.Errorf("can not search ldap users: %v", err)
+	}
+	return res.Entries, nil
+}
+
+// authenticateLDAP checks the user's password against the directory.
+//
+// The user should still be a member of the group, so that the users that
+// leave the group are locked out before the next sync.
+func authenticateLDAP(username, password string) error {
+	cfg := GetLDAPConfig()
+	if cfg == nil {
+		return fmt.Errorf("ldap is not configured")
+	}
+	conn, err := cfg.connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	entries, err := cfg.searchUsers(conn, ldap.EscapeFilter(username))
+	if err != nil {
+		return err
+	}
+	if len(entries) != 1 {
+		return fmt.Errorf("%d ldap users found for %s", len(entries), username)
+	}
+	members, err := cfg.groupMembers(conn, cfg.GroupDN)
+	if err != nil {
+		return err
+	}
+	if !members[normalizeDN(entries[0].DN)] {
+		return fmt.Errorf("%s is not a member of %s", entries[0].DN, cfg.GroupDN)
+	}
+	return conn.Bind(entries[0].DN, password)
+}
+
+// SyncLDAPUsers creates the members of the directory group that don't exist
+// yet, disables or deletes the synced users that aren't a member anymore and
+// sets the admin flag according to the admin group.
+//
+// Local users are never touched, even if there is a member with the same
+// username.
+func SyncLDAPUsers() (*LDAPSyncResult, error) {
+	cfg := GetLDAPConfig()
+	if cfg == nil {
+		return nil, fmt.Errorf("ldap is not configured")
+	}
+	svr := TheServer()
+	if !svr.IsInitialized() {
+		return nil, fmt.Errorf("you first need to create server")
+	}
+
+	conn, err := cfg.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	members, err := cfg.groupMembers(conn, cfg.GroupDN)
+	if err != nil {
+		return nil, err
+	}
+	var admins map[string]bool
+	if cfg.AdminGroupDN != "" {
+		if admins, err = cfg.groupMembers(conn, cfg.AdminGroupDN); err != nil {
+			return nil, err
+		}
+	}
+	entries, err := cfg.searchUsers(conn, "*")
+	if err != nil {
+		return nil, err
+	}
+
+	// Usernames of the group members and whether they're admins.
+	directory := make(map[string]bool)
+	for _, e := range entries {
+		dn := normalizeDN(e.DN)
+		if !members[dn] {
+			continue
+		}
+		username := e.GetEqualFoldAttributeValue(cfg.UsernameAttr)
+		if !govalidator.Matches(username, "^([\\w\\.]+)$") || username == "root" {
+			logrus.Warnf("ldap user %s is skipped, username `%s` can't be used", e.DN, username)
+			continue
+		}
+		directory[username] = admins[dn]
+	}
+
+	users, err := GetAllUsers()
+	if err != nil {
+		return nil, err
+	}
+	var synced int
+	for _, user := range users {
+		if user.AuthSource == LDAPAuthSource {
+			synced++
+		}
+	}
+	if len(directory) == 0 && synced > 0 {
+		// Most likely the directory or the configuration is broken.
+		return nil, fmt.Errorf("ldap group %s has no members, refusing to remove all of the %d synced users", cfg.GroupDN, synced)
+	}
+
+	result := new(LDAPSyncResult)
+	var changed bool
+	for _, user := range users {
+		isAdmin, isMember := directory[user.Username]
+		if user.AuthSource != LDAPAuthSource {
+			if isMember {
+				logrus.Warnf("ldap user %s is skipped, a local user with the same username exists", user.Username)
+				delete(directory, user.Username)
+			}
+			continue
+		}
+		delete(directory, user.Username)
+
+		if !isMember {
+			switch {
+			case cfg.RemovalPolicy == LDAPRemovalDelete:
+				if err := user.DeleteBy(LDAPAuthSource); err != nil {
+					return result, err
+				}
+				result.Deleted = append(result.Deleted, user.Username)
+			case !user.Disabled:
+				user.setDisabled(true)
+				result.Disabled = append(result.Disabled, user.Username)
+				changed = true
+			}
+			continue
+		}
+
+		if user.Disabled {
+			user.setDisabled(false)
+			result.Enabled = append(result.Enabled, user.Username)
+			changed = true
+		}
+		if admins != nil && user.Admin != isAdmin {
+			user.Admin = isAdmin
+			db.Save(user.dbUserModel)
+			if isAdmin {
+				result.Promoted = append(result.Promoted, user.Username)
+			} else {
+				result.Demoted = append(result.Demoted, user.Username)
+			}
+		}
+	}
+
+	// Create the new members.
+	var usernames []string
+	for username := range directory {
+		usernames = append(usernames, username)
+	}
+	sort.Strings(usernames)
+	for _, username := range usernames {
+		// Synced users never use the local password.
+		user, err := CreateNewUser(username, uuid.New().String(), false, 0, directory[username], "synced from ldap")
+		if err != nil {
+			return result, err
+		}
+		user.AuthSource = LDAPAuthSource
+		db.Save(user.dbUserModel)
+		result.Created = append(result.Created, username)
+	}
+
+	if changed {
+		// Disabled users are rejected by their ccd files.
+		if err := svr.EmitWithRestart(); err != nil {
+			return result, err
+		}
+		for _, username := range result.Disabled {
+			user, err := GetUser(username)
+			if err != nil {
+				continue
+			}
+			if err := user.Disconnect(); err != nil {
+				logrus.Debugf("user is not disconnected: %v", err)
+			}
+		}
+	}
+	logrus.Infof("ldap users synced: %d created, %d enabled, %d disabled, %d deleted", len(result.Created), len(result.Enabled), len(result.Disabled), len(result.Deleted))
+	return result, nil
+}
+
+// normalizeDN returns the DN in lower case without the spaces around the RDNs.
+func normalizeDN(dn string) string {
+	parts := strings.Split(dn, ",")
+	for i, p := range parts {
+		parts[i] = strings.ToLower(strings.TrimSpace(p))
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,430 @@
+// Package ldaptest provides an in-process LDAP server for the tests.
+//
+// It keeps its entries in memory and only implements the operations that
+// ovpm uses: simple bind, search, StartTLS and unbind.
+package ldaptest
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+)
+
+// startTLSOID is the name of the StartTLS extended operation.
+const startTLSOID = "1.3.6.1.4.1.1466.20037"
+
+// Server is an in-process LDAP server listening on a loopback port.
+type Server struct {
+	URL string // e.g. ldap://127.0.0.1:40389
+
+	// AllowAnonymous lets the connections that aren't bound to search.
+	AllowAnonymous bool
+
+	lis        net.Listener
+	tlsConfig  *tls.Config
+	mu         sync.Mutex
+	requireTLS bool
+	entries    map[string]map[string][]string // dn -> attributes
+	passwords  map[string]string              // dn -> password
+	dns        map[string]string              // normalized dn -> dn
+}
+
+// NewServer starts a new server. Close should be called when it's no longer
+// used.
+//
+// StartTLS is served with a self-signed certificate, so the clients shouldn't
+// verify it.
+func NewServer() (*Server, error) {
+	cert, err := selfSignedCert()
+	if err != nil {
+		return nil, err
+	}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("can not listen: %v", err)
+	}
+	s := &Server{
+		URL:       "ldap://" + lis.Addr().String(),
+		lis:       lis,
+		tlsConfig: &tls.Config{Certificates: []tls.Certificate{cert}},
+		entries:   make(map[string]map[string][]string),
+		passwords: make(map[string]string),
+		dns:       make(map[string]string),
+	}
+	go s.serve()
+	return s, nil
+}
+
+// Close stops the server.
+func (s *Server) Close() error {
+	return s.lis.Close()
+}
+
+// AddEntry adds or replaces the entry with the given DN.
+func (s *Server) AddEntry(dn string, attrs map[string][]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry := make(map[string][]string)
+	for k, v := range attrs {
+		entry[k] = append([]string(nil), v...)
+	}
+	s.entries[normalizeDN(dn)] = entry
+	s.dns[normalizeDN(dn)] = dn
+}
+
+// RemoveEntry removes the entry with the given DN.
+func (s *Server) RemoveEntry(dn string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, normalizeDN(dn))
+	delete(s.passwords, normalizeDN(dn))
+	delete(s.dns, normalizeDN(dn))
+}
+
+// SetPassword sets the password that the entry with the given DN binds with.
+func (s *Server) SetPassword(dn, password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.passwords[normalizeDN(dn)] = password
+}
+
+// SetRequireTLS makes the server refuse the binds on the connections that
+// didn't start TLS.
+func (s *Server) SetRequireTLS(require bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requireTLS = require
+}
+
+// AddValue adds a value to the attribute of the entry, e.g. a member to a
+// group.
+func (s *Server) AddValue(dn, attr, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry, ok := s.entries[normalizeDN(dn)]; ok {
+		entry[attr] = append(entry[attr], value)
+	}
+}
+
+// RemoveValue removes a value from the attribute of the entry.
+func (s *Server) RemoveValue(dn, attr, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[normalizeDN(dn)]
+	if !ok {
+		return
+	}
+	var values []string
+	for _, v := range entry[attr] {
+		if !strings.EqualFold(v, value) {
+			values = append(values, v)
+		}
+	}
+	entry[attr] = values
+}
+
+func (s *Server) serve() {
+	for {
+		conn, err := s.lis.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer func() { conn.Close() }()
+	r := bufio.NewReader(conn)
+	var bound, secure bool
+	for {
+		msg, err := ber.ReadPacket(r)
+		if err != nil || len(msg.Children) < 2 {
+			return
+		}
+		id, _ := msg.Children[0].Value.(int64)
+		op := msg.Children[1]
+		if op.ClassType != ber.ClassApplication {
+			return
+		}
+		switch op.Tag {
+		case 0: // BindRequest
+			s.mu.Lock()
+			requireTLS := s.requireTLS
+			s.mu.Unlock()
+			if requireTLS && !secure {
+				reply(conn, id, result(1, 13, "tls is required")) // confidentialityRequired
+				continue
+			}
+			code, diag := s.bind(op)
+			bound = code == 0 && len(op.Children) == 3 && str(op.Children[2]) != ""
+			reply(conn, id, result(1, code, diag))
+		case 2: // UnbindRequest
+			return
+		case 3: // SearchRequest
+			if !bound && !s.AllowAnonymous {
+				reply(conn, id, result(5, 50, "anonymous search is not allowed"))
+				continue
+			}
+			entries, code, diag := s.search(op)
+			for _, e := range entries {
+				reply(conn, id, e)
+			}
+			reply(conn, id, result(5, code, diag))
+		case 23: // ExtendedRequest
+			if len(op.Children) < 1 || str(op.Children[0]) != startTLSOID {
+				reply(conn, id, result(24, 2, "extended operation is not supported"))
+				continue
+			}
+			if secure {
+				reply(conn, id, result(24, 1, "tls is already started")) // operationsError
+				continue
+			}
+			reply(conn, id, result(24, 0, ""))
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, r, secure = tlsConn, bufio.NewReader(tlsConn), true
+		default:
+			reply(conn, id, result(1, 2, "operation is not supported")) // protocolError
+		}
+	}
+}
+
+func (s *Server) bind(op *ber.Packet) (code int, diag string) {
+	if len(op.Children) != 3 || op.Children[2].ClassType != ber.ClassContext || op.Children[2].Tag != 0 {
+		return 7, "only simple bind is supported" // authMethodNotSupported
+	}
+	dn, password := str(op.Children[1]), str(op.Children[2])
+	if password == "" {
+		return 0, ""
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if expected, ok := s.passwords[normalizeDN(dn)]; !ok || expected != password {
+		return 49, "invalid credentials"
+	}
+	return 0, ""
+}
+
+func (s *Server) search(op *ber.Packet) ([]*ber.Packet, int, string) {
+	if len(op.Children) < 8 {
+		return nil, 2, "malformed search request"
+	}
+	base := normalizeDN(str(op.Children[0]))
+	scope, _ := op.Children[1].Value.(int64)
+	filter := op.Children[6]
+	var attrs []string
+	for _, a := range op.Children[7].Children {
+		attrs = append(attrs, str(a))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[base]; !ok && base != "" {
+		return nil, 32, "no such object"
+	}
+	var results []*ber.Packet
+	for dn, entry := range s.entries {
+		if !inScope(dn, base, scope) || !match(filter, entry) {
+			continue
+		}
+		e := ber.Encode(ber.ClassApplication, ber.TypeConstructed, 4, nil, "SearchResultEntry")
+		e.AppendChild(octetString(s.dns[dn]))
+		list := ber.NewSequence("attributes")
+		for name, values := range entry {
+			if !selected(name, attrs) {
+				continue
+			}
+			attr := ber.NewSequence("attribute")
+			attr.AppendChild(octetString(name))
+			vals := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "values")
+			for _, v := range values {
+				vals.AppendChild(octetString(v))
+			}
+			attr.AppendChild(vals)
+			list.AppendChild(attr)
+		}
+		e.AppendChild(list)
+		results = append(results, e)
+	}
+	return results, 0, ""
+}
+
+// match evaluates the BER encoded filter against the entry.
+func match(f *ber.Packet, entry map[string][]string) bool {
+	switch f.Tag {
+	case 0: // and
+		for _, c := range f.Children {
+			if !match(c, entry) {
+				return false
+			}
+		}
+		return true
+	case 1: // or
+		for _, c := range f.Children {
+			if match(c, entry) {
+				return true
+			}
+		}
+		return false
+	case 2: // not
+		return len(f.Children) == 1 && !match(f.Children[0], entry)
+	case 3, 5, 6, 8: // equalityMatch, greaterOrEqual, lessOrEqual, approxMatch
+		if len(f.Children) != 2 {
+			return false
+		}
+		for _, v := range values(entry, str(f.Children[0])) {
+			a, b := strings.ToLower(v), strings.ToLower(str(f.Children[1]))
+			if ((f.Tag == 3 || f.Tag == 8) && a == b) || (f.Tag == 5 && a >= b) || (f.Tag == 6 && a <= b) {
+				return true
+			}
+		}
+		return false
+	case 4: // substrings
+		if len(f.Children) != 2 {
+			return false
+		}
+		for _, v := range values(entry, str(f.Children[0])) {
+			if matchSubstrings(strings.ToLower(v), f.Children[1].Children) {
+				return true
+			}
+		}
+		return false
+	case 7: // present
+		return len(values(entry, str(f))) > 0
+	}
+	return false
+}
+
+func matchSubstrings(v string, substrings []*ber.Packet) bool {
+	for _, sub := range substrings {
+		s := strings.ToLower(str(sub))
+		switch sub.Tag {
+		case 0: // initial
+			if !strings.HasPrefix(v, s) {
+				return false
+			}
+			v = v[len(s):]
+		case 1: // any
+			i := strings.Index(v, s)
+			if i < 0 {
+				return false
+			}
+			v = v[i+len(s):]
+		case 2: // final
+			if !strings.HasSuffix(v, s) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// values returns the values of the attribute in a case insensitive way.
+func values(entry map[string][]string, attr string) []string {
+	for name, values := range entry {
+		if strings.EqualFold(name, attr) {
+			return values
+		}
+	}
+	return nil
+}
+
+func selected(attr string, attrs []string) bool {
+	if len(attrs) == 0 {
+		return true
+	}
+	for _, a := range attrs {
+		if a == "*" || strings.EqualFold(a, attr) {
+			return true
+		}
+	}
+	return false
+}
+
+func inScope(dn, base string, scope int64) bool {
+	switch scope {
+	case 0: // baseObject
+		return dn == base
+	case 1: // singleLevel
+		i := strings.IndexByte(dn, ',')
+		return i >= 0 && dn[i+1:] == base
+	default: // wholeSubtree
+		return base == "" || dn == base || strings.HasSuffix(dn, ","+base)
+	}
+}
+
+func normalizeDN(dn string) string {
+	parts := strings.Split(dn, ",")
+	for i, p := range parts {
+		parts[i] = strings.ToLower(strings.TrimSpace(p))
+	}
+	return strings.Join(parts, ",")
+}
+
+// str returns the content of a primitive packet as a string, regardless of
+// its class.
+func str(p *ber.Packet) string {
+	if s, ok := p.Value.(string); ok {
+		return s
+	}
+	if p.Data != nil {
+		return p.Data.String()
+	}
+	return ""
+}
+
+func octetString(s string) *ber.Packet {
+	return ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, s, "")
+}
+
+func result(tag ber.Tag, code int, diag string) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "LDAPResult")
+	op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "resultCode"))
+	op.AppendChild(octetString(""))
+	op.AppendChild(octetString(diag))
+	return op
+}
+
+func reply(conn net.Conn, id int64, op *ber.Packet) {
+	msg := ber.NewSequence("LDAPMessage")
+	msg.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, "messageID"))
+	msg.AppendChild(op)
+	conn.Write(msg.Bytes())
+}
+
+// selfSignedCert returns a certificate for the loopback address.
+func selfSignedCert() (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("can not generate key: %v", err)
+	}
+	tml := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &tml, &tml, key.Public(), key)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("can not create certificate: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
@@ -0,0 +1,144 @@
+package ldaptest_test
+
+import (
+	"crypto/tls"
+	"sort"
+	"testing"
+
+	"github.com/cad/ovpm/ldap/ldaptest"
+	"github.com/go-ldap/ldap/v3"
+)
+
+func newTestDirectory(t *testing.T) *ldaptest.Server {
+	s, err := ldaptest.NewServer()
+	if err != nil {
+		t.Fatalf("can not start ldap server: %v", err)
+	}
+	s.AddEntry("dc=example,dc=com", map[string][]string{"objectClass": {"domain"}})
+	s.AddEntry("cn=admin,dc=example,dc=com", map[string][]string{"objectClass": {"person"}, "cn": {"admin"}})
+	s.SetPassword("cn=admin,dc=example,dc=com", "secret")
+	s.AddEntry("ou=people,dc=example,dc=com", map[string][]string{"objectClass": {"organizationalUnit"}})
+	for _, uid := range []string{"john", "jane", "joe(x)"} {
+		s.AddEntry("uid="+uid+",ou=people,dc=example,dc=com", map[string][]string{"objectClass": {"person"}, "uid": {uid}, "mail": {uid + "@example.com"}})
+	}
+	return s
+}
+
+func TestBind(t *testing.T) {
+	s := newTestDirectory(t)
+	defer s.Close()
+
+	conn, err := ldap.DialURL(s.URL)
+	if err != nil {
+		t.Fatalf("can not dial: %v", err)
+	}
+	defer conn.Close()
+
+	if err := conn.Bind("cn=admin,dc=example,dc=com", "wrong"); !ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+		t.Errorf("bind with a wrong password is expected to fail with invalid credentials but got: %v", err)
+	}
+	if err := conn.Bind("cn=admin,dc=example,dc=com", "secret"); err != nil {
+		t.Errorf("bind is expected to succeed but got: %v", err)
+	}
+}
+
+func TestStartTLS(t *testing.T) {
+	s := newTestDirectory(t)
+	defer s.Close()
+	s.SetRequireTLS(true)
+
+	conn, err := ldap.DialURL(s.URL)
+	if err != nil {
+		t.Fatalf("can not dial: %v", err)
+	}
+	defer conn.Close()
+	if err := conn.Bind("cn=admin,dc=example,dc=com", "secret"); !ldap.IsErrorWithCode(err, ldap.LDAPResultConfidentialityRequired) {
+		t.Errorf("bind without tls is expected to fail with confidentiality required but got: %v", err)
+	}
+	if err := conn.StartTLS(&tls.Config{InsecureSkipVerify: true}); err != nil {
+		t.Fatalf("can not start tls: %v", err)
+	}
+	if err := conn.Bind("cn=admin,dc=example,dc=com", "secret"); err != nil {
+		t.Errorf("bind over tls is expected to succeed but got: %v", err)
+	}
+
+	// The certificate is self-signed.
+	conn, err = ldap.DialURL(s.URL)
+	if err != nil {
+		t.Fatalf("can not dial: %v", err)
+	}
+	defer conn.Close()
+	if err := conn.StartTLS(&tls.Config{ServerName: "127.0.0.1"}); err == nil {
+		t.Errorf("start tls is expected to fail when the certificate is verified")
+	}
+}
+
+func TestSearch(t *testing.T) {
+	s := newTestDirectory(t)
+	defer s.Close()
+
+	conn, err := ldap.DialURL(s.URL)
+	if err != nil {
+		t.Fatalf("can not dial: %v", err)
+	}
+	defer conn.Close()
+
+	// Anonymous searches aren't allowed.
+	req := ldap.NewSearchRequest("ou=people,dc=example,dc=com", ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false, "(uid=john)", nil, nil)
+	if _, err := conn.Search(req); err == nil {
+		t.Fatalf("anonymous search is expected to fail")
+	}
+	if err := conn.Bind("cn=admin,dc=example,dc=com", "secret"); err != nil {
+		t.Fatalf("can not bind: %v", err)
+	}
+
+	var searchtests = []struct {
+		filter string
+		uids   []string
+	}{
+		{"(uid=john)", []string{"john"}},
+		{"(UID=JOHN)", []string{"john"}},
+		{"(uid=" + ldap.EscapeFilter("joe(x)") + ")", []string{"joe(x)"}},
+		{"(uid=" + ldap.EscapeFilter("*") + ")", nil},
+		{"(uid=j*n*)", []string{"jane", "john"}},
+		{"(&(objectClass=person)(|(uid=jane)(mail=john@*)))", []string{"jane", "john"}},
+		{"(&(uid=*)(!(uid=jo*)))", []string{"jane"}},
+	}
+	for _, tt := range searchtests {
+		req.Filter = tt.filter
+		res, err := conn.Search(req)
+		if err != nil {
+			t.Fatalf("search %s failed: %v", tt.filter, err)
+		}
+		var uids []string
+		for _, e := range res.Entries {
+			uids = append(uids, e.GetAttributeValue("uid"))
+		}
+		sort.Strings(uids)
+		if len(uids) != len(tt.uids) {
+			t.Errorf("search %s is expected to return %v but returned %v", tt.filter, tt.uids, uids)
+			continue
+		}
+		for i := range uids {
+			if uids[i] != tt.uids[i] {
+				t.Errorf("search %s is expected to return %v but returned %v", tt.filter, tt.uids, uids)
+				break
+			}
+		}
+	}
+
+	// Only the requested attributes are returned.
+	res, err := conn.Search(ldap.NewSearchRequest("uid=jane,ou=people,dc=example,dc=com", ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false, "(objectClass=*)", []string{"mail"}, nil))
+	if err != nil || len(res.Entries) != 1 {
+		t.Fatalf("base object search is expected to return the entry: %v", err)
+	}
+	if e := res.Entries[0]; e.DN != "uid=jane,ou=people,dc=example,dc=com" || e.GetEqualFoldAttributeValue("MAIL") != "jane@example.com" || e.GetAttributeValue("uid") != "" {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+
+	// Unknown base DNs are reported.
+	req.BaseDN, req.Filter = "ou=nobody,dc=example,dc=com", "(objectClass=*)"
+	if _, err := conn.Search(req); !ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		t.Errorf("search with an unknown base dn is expected to fail with no such object but got: %v", err)
+	}
+}
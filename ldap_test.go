@@ -0,0 +1,214 @@
+package ovpm
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cad/ovpm/ldap/ldaptest"
+)
+
+const (
+	testLDAPBindDN     = "cn=ovpm,dc=example,dc=com"
+	testLDAPGroupDN    = "cn=vpn,ou=groups,dc=example,dc=com"
+	testLDAPAdminDN    = "cn=vpn-admins,ou=groups,dc=example,dc=com"
+	testLDAPPeopleDN   = "ou=people,dc=example,dc=com"
+	testLDAPPassword   = "secret"
+	testLDAPUserSuffix = "," + testLDAPPeopleDN
+)
+
+func newTestLDAPServer(t *testing.T) *ldaptest.Server {
+	s, err := ldaptest.NewServer()
+	if err != nil {
+		t.Fatalf("can not start ldap server: %v", err)
+	}
+	s.SetRequireTLS(true)
+	s.AddEntry(testLDAPBindDN, map[string][]string{"objectClass": {"person"}, "cn": {"ovpm"}})
+	s.SetPassword(testLDAPBindDN, testLDAPPassword)
+	s.AddEntry(testLDAPPeopleDN, map[string][]string{"objectClass": {"organizationalUnit"}, "ou": {"people"}})
+	for _, uid := range []string{"john", "jane", "usr1", "bad name"} {
+		s.AddEntry("uid="+uid+testLDAPUserSuffix, map[string][]string{"objectClass": {"person"}, "uid": {uid}})
+		s.SetPassword("uid="+uid+testLDAPUserSuffix, uid+"-pass")
+	}
+	s.AddEntry("ou=groups,dc=example,dc=com", map[string][]string{"objectClass": {"organizationalUnit"}, "ou": {"groups"}})
+	s.AddEntry(testLDAPGroupDN, map[string][]string{"objectClass": {"groupOfNames"}, "cn": {"vpn"}, "member": {"uid=john" + testLDAPUserSuffix, "UID=jane, OU=people, DC=example, DC=com", "uid=usr1" + testLDAPUserSuffix, "uid=bad name" + testLDAPUserSuffix}})
+	s.AddEntry(testLDAPAdminDN, map[string][]string{"objectClass": {"groupOfNames"}, "cn": {"vpn-admins"}, "member": {"uid=john" + testLDAPUserSuffix}})
+	return s
+}
+
+func TestLDAPConfig(t *testing.T) {
+	// Initialize:
+	setupTestCase()
+	CreateDB("sqlite3", ":memory:")
+	defer db.Cease()
+	s := newTestLDAPServer(t)
+	defer s.Close()
+
+	// Test:
+	cfg := LDAPConfig{URL: s.URL, StartTLS: true, InsecureSkipVerify: true, BindDN: testLDAPBindDN, BindPassword: "wrong", UserBaseDN: testLDAPPeopleDN, GroupDN: testLDAPGroupDN}
+	if err := SetLDAPConfig(cfg); err == nil {
+		t.Fatalf("ldap config is expected to be rejected with a wrong bind password")
+	}
+	var invalidtests = []LDAPConfig{
+		{URL: s.URL, BindDN: testLDAPBindDN, BindPassword: testLDAPPassword, UserBaseDN: testLDAPPeopleDN, GroupDN: testLDAPGroupDN},
+		{URL: "ldaps://" + strings.TrimPrefix(s.URL, "ldap://"), StartTLS: true, BindDN: testLDAPBindDN, BindPassword: testLDAPPassword, UserBaseDN: testLDAPPeopleDN, GroupDN: testLDAPGroupDN},
+		{URL: s.URL, StartTLS: true, BindDN: testLDAPBindDN, BindPassword: testLDAPPassword, UserBaseDN: testLDAPPeopleDN, GroupDN: testLDAPGroupDN},
+		{URL: "http://localhost", BindDN: testLDAPBindDN, BindPassword: testLDAPPassword, UserBaseDN: testLDAPPeopleDN, GroupDN: testLDAPGroupDN},
+		{URL: s.URL, StartTLS: true, InsecureSkipVerify: true, BindDN: testLDAPBindDN, BindPassword: testLDAPPassword, UserBaseDN: testLDAPPeopleDN},
+		{URL: s.URL, StartTLS: true, InsecureSkipVerify: true, BindDN: testLDAPBindDN, BindPassword: testLDAPPassword, UserBaseDN: testLDAPPeopleDN, GroupDN: testLDAPGroupDN, UserFilter: "(uid=*)"},
+		{URL: s.URL, StartTLS: true, InsecureSkipVerify: true, BindDN: testLDAPBindDN, BindPassword: testLDAPPassword, UserBaseDN: testLDAPPeopleDN, GroupDN: testLDAPGroupDN, RemovalPolicy: "ignore"},
+		{URL: s.URL, StartTLS: true, InsecureSkipVerify: true, BindDN: testLDAPBindDN, BindPassword: testLDAPPassword, UserBaseDN: testLDAPPeopleDN, GroupDN: "cn=nobody,dc=example,dc=com"},
+	}
+	for _, cfg := range invalidtests {
+		if err := SetLDAPConfig(cfg); err == nil {
+			t.Errorf("ldap config %+v is expected to be rejected", cfg)
+		}
+	}
+	if IsLDAPEnabled() {
+		t.Fatalf("ldap is expected to be disabled after the rejected configs")
+	}
+
+	cfg.BindPassword = testLDAPPassword
+	if err := SetLDAPConfig(cfg); err != nil {
+		t.Fatalf("can not set ldap config: %v", err)
+	}
+	saved := GetLDAPConfig()
+	if saved == nil || saved.UserFilter != "(uid=%s)" || saved.RemovalPolicy != LDAPRemovalDisable {
+		t.Fatalf("ldap config is expected to be saved with the defaults: %+v", saved)
+	}
+
+	// The bind password is kept if it's not given.
+	cfg.BindPassword = ""
+	cfg.AdminGroupDN = testLDAPAdminDN
+	if err := SetLDAPConfig(cfg); err != nil {
+		t.Fatalf("can not update ldap config: %v", err)
+	}
+	if saved := GetLDAPConfig(); saved.BindPassword != testLDAPPassword || saved.AdminGroupDN != testLDAPAdminDN {
+		t.Errorf("ldap config is expected to be updated and keep the bind password: %+v", saved)
+	}
+
+	// Plaintext is only used when it's explicitly allowed.
+	s.SetRequireTLS(false)
+	cfg.StartTLS, cfg.InsecureSkipVerify, cfg.InsecurePlaintext = false, false, true
+	if err := SetLDAPConfig(cfg); err != nil {
+		t.Fatalf("can not set plaintext ldap config: %v", err)
+	}
+	var m dbLDAPModel
+	db.First(&m)
+	m.InsecurePlaintext = false
+	db.Save(&m)
+	if _, err := GetLDAPConfig().connect(); err == nil {
+		t.Errorf("stored plaintext config is expected to be refused without insecure plaintext")
+	}
+
+	if err := DisableLDAP(); err != nil || IsLDAPEnabled() {
+		t.Errorf("ldap is expected to be disabled: %v", err)
+	}
+}
+
+func TestLDAPSync(t *testing.T) {
+	// Initialize:
+	setupTestCase()
+	CreateDB("sqlite3", ":memory:")
+	defer db.Cease()
+	s := newTestLDAPServer(t)
+	defer s.Close()
+	svr := TheServer()
+
+	// Prepare:
+	if _, err := SyncLDAPUsers(); err == nil {
+		t.Fatalf("sync is expected to fail when ldap is not configured")
+	}
+	svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil)
+	local, err := CreateNewUser("usr1", "1234", false, 0, false, "description")
+	if err != nil {
+		t.Fatalf("user creation failed: %v", err)
+	}
+	cfg := LDAPConfig{URL: s.URL, StartTLS: true, InsecureSkipVerify: true, BindDN: testLDAPBindDN, BindPassword: testLDAPPassword, UserBaseDN: testLDAPPeopleDN, GroupDN: testLDAPGroupDN, AdminGroupDN: testLDAPAdminDN}
+	if err := SetLDAPConfig(cfg); err != nil {
+		t.Fatalf("can not set ldap config: %v", err)
+	}
+
+	// Test:
+	// Members are created, the local user and the invalid username are skipped.
+	result, err := SyncLDAPUsers()
+	if err != nil {
+		t.Fatalf("can not sync ldap users: %v", err)
+	}
+	if strings.Join(result.Created, ",") != "jane,john" {
+		t.Fatalf("jane and john are expected to be created but got: %+v", result)
+	}
+	john, _ := GetUser("john")
+	jane, _ := GetUser("jane")
+	if john.GetAuthSource() != LDAPAuthSource || !john.IsAdmin() || jane.IsAdmin() {
+		t.Errorf("john is expected to be an ldap admin and jane an ldap user: %+v %+v", john.dbUserModel, jane.dbUserModel)
+	}
+	if local, _ = GetUser("usr1"); local.GetAuthSource() != LocalAuthSource || !local.CheckPassword("1234") {
+		t.Errorf("local user is expected to be left alone")
+	}
+
+	// Passwords are checked against the directory.
+	if !john.CheckPassword("john-pass") || john.CheckPassword("1234") || john.CheckPassword("") {
+		t.Errorf("john is expected to authenticate with the directory password")
+	}
+	if err := VerifyUserPass("jane", "jane", "jane-pass"); err != nil {
+		t.Errorf("vpn password of jane is expected to be checked against the directory: %v", err)
+	}
+	if err := john.ResetPassword("1234"); err == nil {
+		t.Errorf("password of an ldap user is expected to be managed by the directory")
+	}
+
+	// Users that leave the group are locked out right away and disabled on the next sync.
+	s.RemoveValue(testLDAPGroupDN, "member", "UID=jane, OU=people, DC=example, DC=com")
+	s.RemoveValue(testLDAPAdminDN, "member", "uid=john"+testLDAPUserSuffix)
+	if jane.CheckPassword("jane-pass") {
+		t.Errorf("jane is expected to be locked out after leaving the group")
+	}
+	if result, err = SyncLDAPUsers(); err != nil {
+		t.Fatalf("can not sync ldap users: %v", err)
+	}
+	if strings.Join(result.Disabled, ",") != "jane" || strings.Join(result.Demoted, ",") != "john" {
+		t.Fatalf("jane is expected to be disabled and john demoted but got: %+v", result)
+	}
+	jane, _ = GetUser("jane")
+	john, _ = GetUser("john")
+	if !jane.IsDisabled() || john.IsAdmin() {
+		t.Errorf("jane is expected to be disabled and john to not be an admin")
+	}
+	if ccd := fs[filepath.Join(_DefaultVPNCCDPath, "jane")]; !strings.HasPrefix(ccd, "\ndisable\n") {
+		t.Errorf("ccd of jane is expected to disable the client:\n%s", ccd)
+	}
+
+	// Users that come back are enabled.
+	s.AddValue(testLDAPGroupDN, "member", "uid=jane"+testLDAPUserSuffix)
+	if result, err = SyncLDAPUsers(); err != nil || strings.Join(result.Enabled, ",") != "jane" {
+		t.Fatalf("jane is expected to be enabled but got: %+v %v", result, err)
+	}
+	if ccd := fs[filepath.Join(_DefaultVPNCCDPath, "jane")]; strings.Contains(ccd, "disable") {
+		t.Errorf("ccd of jane is expected to not disable the client:\n%s", ccd)
+	}
+
+	// Delete policy deletes them.
+	cfg.RemovalPolicy = LDAPRemovalDelete
+	if err := SetLDAPConfig(cfg); err != nil {
+		t.Fatalf("can not update ldap config: %v", err)
+	}
+	s.RemoveValue(testLDAPGroupDN, "member", "uid=jane"+testLDAPUserSuffix)
+	if result, err = SyncLDAPUsers(); err != nil || strings.Join(result.Deleted, ",") != "jane" {
+		t.Fatalf("jane is expected to be deleted but got: %+v %v", result, err)
+	}
+	if _, err := GetUser("jane"); err == nil {
+		t.Errorf("jane is expected to be deleted")
+	}
+
+	// An empty group doesn't wipe out the synced users.
+	for _, uid := range []string{"john", "usr1", "bad name"} {
+		s.RemoveValue(testLDAPGroupDN, "member", "uid="+uid+testLDAPUserSuffix)
+	}
+	if _, err := SyncLDAPUsers(); err == nil {
+		t.Errorf("sync is expected to fail when the group is empty")
+	}
+	if _, err := GetUser("john"); err != nil {
+		t.Errorf("john is expected to be kept: %v", err)
+	}
+}
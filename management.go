@@ -0,0 +1,159 @@
+package ovpm
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cad/ovpm/mgmt"
+	"github.com/sirupsen/logrus"
+)
+
+// mgmtEventHandler is called for every real-time event received from the
+// OpenVPN management interface.
+type mgmtEventHandler func(e mgmt.Event)
+
+var (
+	mgmtLock     sync.Mutex
+	mgmtConn     *mgmt.Client
+	mgmtHandlers []mgmtEventHandler
+)
+
+// onManagementEvent registers h to be called for every management event.
+func onManagementEvent(h mgmtEventHandler) {
+	mgmtLock.Lock()
+	defer mgmtLock.Unlock()
+	mgmtHandlers = append(mgmtHandlers, h)
+}
+
+// managementClient returns the current connection to the OpenVPN management
+// interface. It dials a new one if there is no live connection.
+func (svr *Server) managementClient() (*mgmt.Client, error) {
+	mgmtLock.Lock()
+	defer mgmtLock.Unlock()
+
+	if mgmtConn != nil {
+		select {
+		case <-mgmtConn.Done():
+			mgmtConn = nil
+		default:
+			return mgmtConn, nil
+		}
+	}
+
+	c, err := svr.dialManagementFunc(_DefaultManagementSocketPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.ByteCount(DefaultByteCountInterval); err != nil {
+		logrus.Warnf("can not enable byte count notifications: %v", err)
+	}
+	mgmtConn = c
+	go dispatchManagementEvents(c)
+	return c, nil
+}
+
+// connectManagement waits for the OpenVPN process to create the management
+// socket and then connects to it.
+//
+// It's meant to be run in a goroutine, which shouldn't be started while
+// testing, since there is no OpenVPN process to connect to.
+func (svr *Server) connectManagement() {
+	for i := 0; i < 10; i++ {
+		if _, err := svr.managementClient(); err == nil {
+			logrus.Debug("connected to the OpenVPN management interface")
+			return
+		}
+		time.Sleep(1 * time.Second)
+	}
+	logrus.Warn("can not connect to the OpenVPN management interface, falling back to the status log")
+}
+
+// dispatchManagementEvents calls the registered handlers for each event
+// received from c until the connection is closed.
+func dispatchManagementEvents(c *mgmt.Client) {
+	for e := range c.Events() {
+		mgmtLock.Lock()
+		handlers := mgmtHandlers
+		mgmtLock.Unlock()
+
+		for _, h := range handlers {
+			h(e)
+		}
+	}
+	logrus.Debugf("management connection is closed: %v", c.Err())
+}
+
+// logManagementEvent logs client connects and disconnects.
+func logManagementEvent(e mgmt.Event) {
+	switch e.Type {
+	case mgmt.EstablishedEvent:
+		logrus.WithFields(logrus.Fields{
+			"CommonName":  e.CommonName(),
+			"RealAddress": e.Env["trusted_ip"],
+		}).Info("client connected")
+	case mgmt.DisconnectEvent:
+		logrus.WithFields(logrus.Fields{
+			"CommonName":  e.CommonName(),
+			"RealAddress": e.Env["trusted_ip"],
+		}).Info("client disconnected")
+	}
+}
+
+// clientList returns the connected clients and the routing table of the
+// OpenVPN server.
+//
+// It reads them from the management interface when it's available and falls
+// back to parsing the status log otherwise.
+func (svr *Server) clientList() ([]clEntry, []rtEntry) {
+	if c, err := svr.managementClient(); err == nil {
+		mcl, mrt, err := c.Status()
+		if err == nil {
+			var cl []clEntry
+			var rt []rtEntry
+			for _, e := range mcl {
+				cl = append(cl, clEntry{
+					CommonName:     e.CommonName,
+					RealAddress:    e.RealAddress,
+					BytesReceived:  e.BytesReceived,
+					BytesSent:      e.BytesSent,
+					ConnectedSince: e.ConnectedSince,
+					ClientID:       e.ClientID,
+				})
+			}
+			for _, r := range mrt {
+				rt = append(rt, rtEntry{
+					VirtualAddress: r.VirtualAddress,
+					CommonName:     r.CommonName,
+					RealAddress:    r.RealAddress,
+					LastRef:        r.LastRef,
+				})
+			}
+			return cl, rt
+		}
+		logrus.Warnf("can not get status from the management interface: %v", err)
+	}
+
+	// Open the status log file.
+	f, err := svr.openFunc(_DefaultStatusLogPath)
+	if err != nil {
+		panic(err)
+	}
+	return svr.parseStatusLogFunc(f)
+}
+
+// tryClientList is like clientList, but it returns an error instead of
+// panicking when the status log can't be read.
+func (svr *Server) tryClientList() (cl []clEntry, rt []rtEntry, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("can not get the client list: %v", r)
+		}
+	}()
+	cl, rt = svr.clientList()
+	return cl, rt, nil
+}
+
+func init() {
+	onManagementEvent(logManagementEvent)
+}
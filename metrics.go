@@ -0,0 +1,126 @@
+package ovpm
+
+import (
+	"sync/atomic"
+
+	"github.com/cad/ovpm/pki"
+	"github.com/cad/ovpm/supervisor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// vpnProcStates are the supervisor states that are exported as metrics.
+var vpnProcStates = []supervisor.State{
+	supervisor.RUNNING,
+	supervisor.STOPPED,
+	supervisor.STARTING,
+	supervisor.STOPPING,
+	supervisor.FAILED,
+	supervisor.EXITED,
+}
+
+var (
+	// vpnProcRestarts counts the restarts of the OpenVPN process.
+	vpnProcRestarts uint64
+
+	emitDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "ovpm",
+		Name:      "emit_duration_seconds",
+		Help:      "Time it takes to emit the OpenVPN server files.",
+		Buckets:   []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60},
+	})
+
+	vpnProcStateDesc = prometheus.NewDesc("ovpm_vpn_process_state",
+		"Whether the OpenVPN process is in the state.", []string{"state"}, nil)
+	vpnProcRestartsDesc = prometheus.NewDesc("ovpm_vpn_process_restarts_total",
+		"Number of times the OpenVPN process is restarted.", nil, nil)
+	connectedUsersDesc = prometheus.NewDesc("ovpm_connected_users",
+		"Number of users that are connected to the VPN.", nil, nil)
+	userBytesReceivedDesc = prometheus.NewDesc("ovpm_user_received_bytes_total",
+		"Bytes received from the user over the current connections.", []string{"username"}, nil)
+	userBytesSentDesc = prometheus.NewDesc("ovpm_user_sent_bytes_total",
+		"Bytes sent to the user over the current connections.", []string{"username"}, nil)
+	caCertExpiryDesc = prometheus.NewDesc("ovpm_ca_cert_expiry_timestamp_seconds",
+		"Expiry time of the CA certificate.", nil, nil)
+	serverCertExpiryDesc = prometheus.NewDesc("ovpm_server_cert_expiry_timestamp_seconds",
+		"Expiry time of the server certificate.", nil, nil)
+	userCertExpiryDesc = prometheus.NewDesc("ovpm_user_cert_expiry_timestamp_seconds",
+		"Expiry time of the user's certificate.", []string{"username"}, nil)
+)
+
+// serverCollector collects the state of the VPN server when the metrics are
+// scraped.
+type serverCollector struct{}
+
+// Describe implements prometheus.Collector.
+func (serverCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- vpnProcStateDesc
+	ch <- vpnProcRestartsDesc
+	ch <- connectedUsersDesc
+	ch <- userBytesReceivedDesc
+	ch <- userBytesSentDesc
+	ch <- caCertExpiryDesc
+	ch <- serverCertExpiryDesc
+	ch <- userCertExpiryDesc
+}
+
+// Collect implements prometheus.Collector.
+func (serverCollector) Collect(ch chan<- prometheus.Metric) {
+	state := supervisor.UNKNOWN
+	if vpnProc != nil {
+		state = vpnProc.Status()
+	}
+	for _, s := range vpnProcStates {
+		var v float64
+		if s == state {
+			v = 1
+		}
+		ch <- prometheus.MustNewConstMetric(vpnProcStateDesc, prometheus.GaugeValue, v, s.String())
+	}
+	ch <- prometheus.MustNewConstMetric(vpnProcRestartsDesc, prometheus.CounterValue, float64(atomic.LoadUint64(&vpnProcRestarts)))
+
+	if db == nil {
+		return
+	}
+	svr := TheServer()
+	if !svr.IsInitialized() {
+		return
+	}
+	if crt, err := pki.ReadCertFromPEM(svr.CACert); err == nil {
+		ch <- prometheus.MustNewConstMetric(caCertExpiryDesc, prometheus.GaugeValue, float64(crt.NotAfter.Unix()))
+	}
+	if crt, err := pki.ReadCertFromPEM(svr.Cert); err == nil {
+		ch <- prometheus.MustNewConstMetric(serverCertExpiryDesc, prometheus.GaugeValue, float64(crt.NotAfter.Unix()))
+	}
+	users, _ := GetAllUsers()
+	for _, user := range users {
+		if crt, err := pki.ReadCertFromPEM(user.Cert); err == nil {
+			ch <- prometheus.MustNewConstMetric(userCertExpiryDesc, prometheus.GaugeValue, float64(crt.NotAfter.Unix()), user.Username)
+		}
+	}
+
+	if state != supervisor.RUNNING {
+		ch <- prometheus.MustNewConstMetric(connectedUsersDesc, prometheus.GaugeValue, 0)
+		return
+	}
+	cl, _, err := svr.tryClientList()
+	if err != nil {
+		logrus.Debugf("can not collect client metrics: %v", err)
+		return
+	}
+	received := make(map[string]uint64)
+	sent := make(map[string]uint64)
+	for _, c := range cl {
+		received[c.CommonName] += c.BytesReceived
+		sent[c.CommonName] += c.BytesSent
+	}
+	ch <- prometheus.MustNewConstMetric(connectedUsersDesc, prometheus.GaugeValue, float64(len(received)))
+	for username := range received {
+		ch <- prometheus.MustNewConstMetric(userBytesReceivedDesc, prometheus.CounterValue, float64(received[username]), username)
+		ch <- prometheus.MustNewConstMetric(userBytesSentDesc, prometheus.CounterValue, float64(sent[username]), username)
+	}
+}
+
+func init() {
+	prometheus.MustRegister(emitDuration, serverCollector{})
+}
@@ -0,0 +1,81 @@
+package ovpm
+
+import (
+	"io"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// gatherMetrics returns the metrics of the server collector by their names.
+func gatherMetrics(t *testing.T) map[string]*dto.MetricFamily {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(serverCollector{})
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("can not gather metrics: %v", err)
+	}
+	metrics := make(map[string]*dto.MetricFamily)
+	for _, f := range families {
+		metrics[f.GetName()] = f
+	}
+	return metrics
+}
+
+func TestServerCollector(t *testing.T) {
+	// Initialize:
+	setupTestCase()
+	CreateDB("sqlite3", ":memory:")
+	defer db.Cease()
+	svr := TheServer()
+
+	// Prepare:
+	svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil)
+	if _, err := CreateNewUser("usr1", "1234", false, 0, false, "description"); err != nil {
+		t.Fatalf("user creation failed: %v", err)
+	}
+	origOpenFunc, origParseStatusLogFunc := svr.openFunc, svr.parseStatusLogFunc
+	defer func() { svr.openFunc, svr.parseStatusLogFunc = origOpenFunc, origParseStatusLogFunc }()
+	svr.openFunc = func(path string) (io.Reader, error) {
+		return nil, nil
+	}
+	svr.parseStatusLogFunc = func(f io.Reader) ([]clEntry, []rtEntry) {
+		return []clEntry{
+			{CommonName: "usr1", RealAddress: "1.1.1.1:1000", BytesReceived: 1, BytesSent: 5},
+			{CommonName: "usr1", RealAddress: "2.2.2.2:2000", BytesReceived: 2, BytesSent: 10},
+		}, nil
+	}
+
+	// Test:
+	vpnProc.Stop()
+	metrics := gatherMetrics(t)
+	if m := metrics["ovpm_connected_users"]; m == nil || m.Metric[0].GetGauge().GetValue() != 0 {
+		t.Errorf("no users are expected to be connected while the vpn is stopped: %v", m)
+	}
+	for _, name := range []string{"ovpm_ca_cert_expiry_timestamp_seconds", "ovpm_server_cert_expiry_timestamp_seconds", "ovpm_user_cert_expiry_timestamp_seconds"} {
+		if m := metrics[name]; m == nil || m.Metric[0].GetGauge().GetValue() < float64(svr.CreatedAt.Unix()) {
+			t.Errorf("%s is expected to be exported: %v", name, m)
+		}
+	}
+
+	vpnProc.Start()
+	defer vpnProc.Stop()
+	svr.RestartVPNProc()
+	metrics = gatherMetrics(t)
+	for _, m := range metrics["ovpm_vpn_process_state"].Metric {
+		running := m.GetLabel()[0].GetValue() == "RUNNING"
+		if (m.GetGauge().GetValue() == 1) != running {
+			t.Errorf("only the RUNNING state is expected to be set: %v", m)
+		}
+	}
+	if m := metrics["ovpm_vpn_process_restarts_total"]; m == nil || m.Metric[0].GetCounter().GetValue() < 1 {
+		t.Errorf("restart is expected to be counted: %v", m)
+	}
+	if m := metrics["ovpm_connected_users"]; m == nil || m.Metric[0].GetGauge().GetValue() != 1 {
+		t.Errorf("1 user is expected to be connected: %v", m)
+	}
+	if m := metrics["ovpm_user_sent_bytes_total"]; m == nil || m.Metric[0].GetCounter().GetValue() != 15 {
+		t.Errorf("bytes sent over the connections of usr1 are expected to be summed: %v", m)
+	}
+}
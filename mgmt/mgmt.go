@@ -0,0 +1,472 @@
+// Package mgmt implements a client for the OpenVPN management interface.
+//
+// See https://openvpn.net/community-resources/management-interface/ for
+// the protocol specification.
+package mgmt
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultTimeout is how long a command waits for its reply before the
+// connection is considered stuck and closed.
+const DefaultTimeout = 10 * time.Second
+
+// EventType is the type of a real-time notification received from the
+// management interface.
+type EventType uint
+
+// Known event types.
+const (
+	UnknownEvent EventType = iota
+	ConnectEvent
+	ReauthEvent
+	EstablishedEvent
+	DisconnectEvent
+	AddressEvent
+	ByteCountEvent
+)
+
+func (t EventType) String() string {
+	switch t {
+	case ConnectEvent:
+		return "CONNECT"
+	case ReauthEvent:
+		return "REAUTH"
+	case EstablishedEvent:
+		return "ESTABLISHED"
+	case DisconnectEvent:
+		return "DISCONNECT"
+	case AddressEvent:
+		return "ADDRESS"
+	case ByteCountEvent:
+		return "BYTECOUNT_CLI"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Event represents a real-time notification received from the management
+// interface about a client.
+type Event struct {
+	Type     EventType
+	ClientID uint64
+	KeyID    uint64
+
+	// Env holds the environment block that is sent along with the CLIENT
+	// notifications. (e.g. common_name, trusted_ip, ifconfig_pool_remote_ip)
+	Env map[string]string
+
+	// BytesReceived and BytesSent are only set for ByteCountEvent and they
+	// are from the perspective of the server.
+	BytesReceived uint64
+	BytesSent     uint64
+
+	// Raw is the notification line as it is received.
+	Raw string
+}
+
+// CommonName returns the common name of the client that the event is about.
+func (e Event) CommonName() string {
+	return e.Env["common_name"]
+}
+
+// ClientEntry represents a row in the CLIENT_LIST section of the status output.
+type ClientEntry struct {
+	CommonName     string
+	RealAddress    string
+	VirtualAddress string
+	BytesReceived  uint64
+	BytesSent      uint64
+	ConnectedSince time.Time
+	ClientID       uint64
+}
+
+// RouteEntry represents a row in the ROUTING_TABLE section of the status output.
+type RouteEntry struct {
+	VirtualAddress string
+	CommonName     string
+	RealAddress    string
+	LastRef        time.Time
+}
+
+// Client is a connection to an OpenVPN management interface.
+//
+// Commands are serialized, so a Client is safe for concurrent use. A command
+// that isn't replied in time closes the connection, since the replies can't be
+// matched to the commands afterwards.
+type Client struct {
+	conn    net.Conn
+	cmdLock sync.Mutex
+	timeout time.Duration
+	replies chan string
+	events  chan Event
+	done    chan struct{}
+	errLock sync.RWMutex
+	err     error
+}
+
+// Dial connects to the management interface listening on the given unix socket.
+func Dial(path string) (*Client, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("can not connect to the management interface %s: %v", path, err)
+	}
+	return NewClient(conn), nil
+}
+
+// NewClient returns a new Client that speaks the management protocol over conn.
+func NewClient(conn net.Conn) *Client {
+	c := &Client{
+		conn:    conn,
+		timeout: DefaultTimeout,
+		replies: make(chan string, 1024),
+		events:  make(chan Event, 1024),
+		done:    make(chan struct{}),
+	}
+	go c.readLoop()
+	return c
+}
+
+// SetTimeout sets how long the commands wait for their replies.
+func (c *Client) SetTimeout(d time.Duration) {
+	c.cmdLock.Lock()
+	defer c.cmdLock.Unlock()
+	c.timeout = d
+}
+
+// Events returns a channel that delivers the real-time notifications.
+//
+// The channel is closed when the connection is closed.
+func (c *Client) Events() <-chan Event {
+	return c.events
+}
+
+// Done returns a channel that is closed when the connection is lost.
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns the error that caused the connection to be closed, if any.
+func (c *Client) Err() error {
+	c.errLock.RLock()
+	defer c.errLock.RUnlock()
+	return c.err
+}
+
+// Close closes the connection to the management interface.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Status runs `status 3` and returns the parsed client list and routing table.
+func (c *Client) Status() ([]ClientEntry, []RouteEntry, error) {
+	lines, err := c.execMulti("status 3")
+	if err != nil {
+		return nil, nil, err
+	}
+	cl, rt := parseStatus(lines)
+	return cl, rt, nil
+}
+
+// Kill disconnects all the clients with the given common name.
+func (c *Client) Kill(cn string) error {
+	_, err := c.exec(fmt.Sprintf("kill %s", cn))
+	return err
+}
+
+// ClientKill disconnects the client with the given client id.
+func (c *Client) ClientKill(cid uint64) error {
+	_, err := c.exec(fmt.Sprintf("client-kill %d", cid))
+	return err
+}
+
+// ByteCount enables BYTECOUNT_CLI notifications every n seconds. Passing 0 disables them.
+func (c *Client) ByteCount(n int) error {
+	_, err := c.exec(fmt.Sprintf("bytecount %d", n))
+	return err
+}
+
+// Signal sends the given signal (e.g. SIGHUP, SIGUSR1) to the OpenVPN daemon.
+func (c *Client) Signal(sig string) error {
+	_, err := c.exec(fmt.Sprintf("signal %s", sig))
+	return err
+}
+
+// exec sends a command that is replied with a single SUCCESS or ERROR line.
+func (c *Client) exec(cmd string) (string, error) {
+	c.cmdLock.Lock()
+	defer c.cmdLock.Unlock()
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+	if err := c.send(cmd); err != nil {
+		return "", err
+	}
+	line, err := c.readReply(cmd, timer.C)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case strings.HasPrefix(line, "SUCCESS:"):
+		return strings.TrimSpace(strings.TrimPrefix(line, "SUCCESS:")), nil
+	case strings.HasPrefix(line, "ERROR:"):
+		return "", fmt.Errorf("%s: %s", cmd, strings.TrimSpace(strings.TrimPrefix(line, "ERROR:")))
+	}
+	return "", fmt.Errorf("%s: unexpected reply: %s", cmd, line)
+}
+
+// execMulti sends a command that is replied with multiple lines terminated by END.
+func (c *Client) execMulti(cmd string) ([]string, error) {
+	c.cmdLock.Lock()
+	defer c.cmdLock.Unlock()
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+	if err := c.send(cmd); err != nil {
+		return nil, err
+	}
+	var lines []string
+	for {
+		line, err := c.readReply(cmd, timer.C)
+		if err != nil {
+			return nil, err
+		}
+		if len(lines) == 0 && strings.HasPrefix(line, "ERROR:") {
+			return nil, fmt.Errorf("%s: %s", cmd, strings.TrimSpace(strings.TrimPrefix(line, "ERROR:")))
+		}
+		if line == "END" {
+			return lines, nil
+		}
+		lines = append(lines, line)
+	}
+}
+
+func (c *Client) send(cmd string) error {
+	c.conn.SetWriteDeadline(time.Now().Add(c.timeout))
+	if _, err := fmt.Fprintf(c.conn, "%s\n", cmd); err != nil {
+		return fmt.Errorf("can not send command '%s': %v", cmd, err)
+	}
+	return nil
+}
+
+// readReply waits for the next reply line of cmd until the timeout fires.
+func (c *Client) readReply(cmd string, timeout <-chan time.Time) (string, error) {
+	select {
+	case line := <-c.replies:
+		return line, nil
+	case <-timeout:
+		err := fmt.Errorf("%s: no reply in %s, closing the management connection", cmd, c.timeout)
+		c.setErr(err)
+		c.conn.Close()
+		return "", err
+	case <-c.done:
+		// Drain the replies that are received before the connection is closed.
+		select {
+		case line := <-c.replies:
+			return line, nil
+		default:
+		}
+		if err := c.Err(); err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("management connection is closed")
+	}
+}
+
+// readLoop reads lines from the connection and dispatches them either as
+// replies to the commands or as real-time notifications.
+func (c *Client) readLoop() {
+	defer close(c.events)
+	defer close(c.done)
+
+	var pending *Event // CLIENT notification waiting for its ENV block.
+	scanner := bufio.NewScanner(c.conn)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if !strings.HasPrefix(line, ">") {
+			c.replies <- line
+			continue
+		}
+
+		if pending != nil && strings.HasPrefix(line, ">CLIENT:ENV,") {
+			kv := strings.TrimPrefix(line, ">CLIENT:ENV,")
+			if kv == "END" {
+				c.emit(*pending)
+				pending = nil
+				continue
+			}
+			parts := strings.SplitN(kv, "=", 2)
+			if len(parts) == 2 {
+				pending.Env[parts[0]] = parts[1]
+			}
+			continue
+		}
+
+		e, hasEnv := parseNotification(line)
+		if hasEnv {
+			pending = &e
+			continue
+		}
+		if e.Type != UnknownEvent {
+			c.emit(e)
+		}
+	}
+
+	c.setErr(scanner.Err())
+}
+
+// setErr records the error that caused the connection to be closed, unless
+// there is one already.
+func (c *Client) setErr(err error) {
+	c.errLock.Lock()
+	defer c.errLock.Unlock()
+	if c.err == nil {
+		c.err = err
+	}
+}
+
+// emit delivers the event without blocking the read loop.
+func (c *Client) emit(e Event) {
+	select {
+	case c.events <- e:
+	default:
+		logrus.WithField("event", e.Raw).Warn("management event is dropped because nobody is listening")
+	}
+}
+
+// parseNotification parses a real-time notification line. hasEnv is true if
+// the notification is followed by a >CLIENT:ENV block.
+func parseNotification(line string) (e Event, hasEnv bool) {
+	e.Raw = line
+	e.Env = make(map[string]string)
+
+	body := strings.TrimPrefix(line, ">")
+	parts := strings.SplitN(body, ":", 2)
+	if len(parts) != 2 {
+		return e, false
+	}
+	args := strings.Split(parts[1], ",")
+
+	switch parts[0] {
+	case "CLIENT":
+		if len(args) < 2 {
+			return e, false
+		}
+		e.ClientID = stoui64(args[1])
+		switch args[0] {
+		case "CONNECT":
+			e.Type = ConnectEvent
+			if len(args) > 2 {
+				e.KeyID = stoui64(args[2])
+			}
+			return e, true
+		case "REAUTH":
+			e.Type = ReauthEvent
+			if len(args) > 2 {
+				e.KeyID = stoui64(args[2])
+			}
+			return e, true
+		case "ESTABLISHED":
+			e.Type = EstablishedEvent
+			return e, true
+		case "DISCONNECT":
+			e.Type = DisconnectEvent
+			return e, true
+		case "ADDRESS":
+			e.Type = AddressEvent
+			if len(args) > 2 {
+				e.Env["address"] = args[2]
+			}
+			return e, false
+		}
+	case "BYTECOUNT_CLI":
+		if len(args) < 3 {
+			return e, false
+		}
+		e.Type = ByteCountEvent
+		e.ClientID = stoui64(args[0])
+		e.BytesReceived = stoui64(args[1])
+		e.BytesSent = stoui64(args[2])
+	}
+	return e, false
+}
+
+// parseStatus parses the output of the `status 3` command.
+//
+// Column positions are looked up from the HEADER rows since they differ
+// between OpenVPN versions.
+func parseStatus(lines []string) ([]ClientEntry, []RouteEntry) {
+	var cl []ClientEntry
+	var rt []RouteEntry
+	headers := make(map[string]map[string]int)
+
+	for _, line := range lines {
+		cols := strings.Split(line, "\t")
+		if len(cols) < 2 {
+			continue
+		}
+		if cols[0] == "HEADER" {
+			idx := make(map[string]int)
+			for i, name := range cols[2:] {
+				idx[name] = i + 1
+			}
+			headers[cols[1]] = idx
+			continue
+		}
+
+		get := func(name string) string {
+			i, ok := headers[cols[0]][name]
+			if !ok || i >= len(cols) {
+				return ""
+			}
+			return strings.TrimSpace(cols[i])
+		}
+		switch cols[0] {
+		case "CLIENT_LIST":
+			cl = append(cl, ClientEntry{
+				CommonName:     get("Common Name"),
+				RealAddress:    get("Real Address"),
+				VirtualAddress: get("Virtual Address"),
+				BytesReceived:  stoui64(get("Bytes Received")),
+				BytesSent:      stoui64(get("Bytes Sent")),
+				ConnectedSince: unixToTime(get("Connected Since (time_t)")),
+				ClientID:       stoui64(get("Client ID")),
+			})
+		case "ROUTING_TABLE":
+			rt = append(rt, RouteEntry{
+				VirtualAddress: get("Virtual Address"),
+				CommonName:     get("Common Name"),
+				RealAddress:    get("Real Address"),
+				LastRef:        unixToTime(get("Last Ref (time_t)")),
+			})
+		}
+	}
+	return cl, rt
+}
+
+// stoui64 converts string to uint64. It returns 0 if s is not a number.
+func stoui64(s string) uint64 {
+	i, err := strconv.ParseUint(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return i
+}
+
+// unixToTime converts a unix timestamp string to time.Time.
+func unixToTime(s string) time.Time {
+	i, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(i, 0)
+}
@@ -0,0 +1,282 @@
+package mgmt_test
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cad/ovpm/mgmt"
+)
+
+// fakeServer is a fake OpenVPN management interface listening on a unix socket.
+type fakeServer struct {
+	path     string
+	lis      net.Listener
+	conn     net.Conn
+	accepted chan struct{}
+	received chan string
+	replies  map[string]string
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	dir, err := ioutil.TempDir("", "ovpm-mgmt")
+	if err != nil {
+		t.Fatalf("can not create temp dir: %v", err)
+	}
+	path := filepath.Join(dir, "management.sock")
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("can not listen on %s: %v", path, err)
+	}
+	s := &fakeServer{
+		path:     path,
+		lis:      lis,
+		accepted: make(chan struct{}),
+		received: make(chan string, 16),
+		replies:  make(map[string]string),
+	}
+	go s.serve()
+	return s
+}
+
+func (s *fakeServer) serve() {
+	conn, err := s.lis.Accept()
+	if err != nil {
+		return
+	}
+	s.conn = conn
+	fmt.Fprint(conn, ">INFO:OpenVPN Management Interface Version 1 -- type 'help' for more info\n")
+	close(s.accepted)
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		cmd := scanner.Text()
+		s.received <- cmd
+		reply, ok := s.replies[cmd]
+		if !ok {
+			reply = "ERROR: unknown command, enter 'help' for more options\n"
+		}
+		fmt.Fprint(conn, reply)
+	}
+}
+
+// notify sends a real-time notification to the connected client.
+func (s *fakeServer) notify(lines ...string) {
+	<-s.accepted
+	fmt.Fprint(s.conn, strings.Join(lines, "\n")+"\n")
+}
+
+func (s *fakeServer) close() {
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	s.lis.Close()
+	os.RemoveAll(filepath.Dir(s.path))
+}
+
+func TestClientStatus(t *testing.T) {
+	// Initialize:
+	srv := newFakeServer(t)
+	defer srv.close()
+
+	// Prepare:
+	srv.replies["status 3"] = strings.Join([]string{
+		"TITLE\tOpenVPN 2.4.7 x86_64-pc-linux-gnu",
+		"TIME\tThu Jun 18 10:00:00 2020\t1592474400",
+		"HEADER\tCLIENT_LIST\tCommon Name\tReal Address\tVirtual Address\tVirtual IPv6 Address\tBytes Received\tBytes Sent\tConnected Since\tConnected Since (time_t)\tUsername\tClient ID\tPeer ID",
+		"CLIENT_LIST\tusr1\t1.1.1.1:1194\t10.9.0.2\t\t1000\t2000\tThu Jun 18 10:00:00 2020\t1592474400\tUNDEF\t3\t0",
+		"CLIENT_LIST\tusr2\t1.1.1.2:1194\t10.9.0.3\t\t10\t20\tThu Jun 18 10:00:00 2020\t1592474400\tUNDEF\t4\t1",
+		"HEADER\tROUTING_TABLE\tVirtual Address\tCommon Name\tReal Address\tLast Ref\tLast Ref (time_t)",
+		"ROUTING_TABLE\t10.9.0.2\tusr1\t1.1.1.1:1194\tThu Jun 18 10:00:00 2020\t1592474400",
+		"ROUTING_TABLE\t10.9.0.3\tusr2\t1.1.1.2:1194\tThu Jun 18 10:00:00 2020\t1592474400",
+		"GLOBAL_STATS\tMax bcast/mcast queue length\t0",
+		"END",
+	}, "\n") + "\n"
+
+	c, err := mgmt.Dial(srv.path)
+	if err != nil {
+		t.Fatalf("can not dial: %v", err)
+	}
+	defer c.Close()
+
+	// Test:
+	cl, rt, err := c.Status()
+	if err != nil {
+		t.Fatalf("status is expected to succeed but it failed: %v", err)
+	}
+	if len(cl) != 2 || len(rt) != 2 {
+		t.Fatalf("expected 2 clients and 2 routes, got %d clients and %d routes", len(cl), len(rt))
+	}
+
+	want := mgmt.ClientEntry{
+		CommonName:     "usr1",
+		RealAddress:    "1.1.1.1:1194",
+		VirtualAddress: "10.9.0.2",
+		BytesReceived:  1000,
+		BytesSent:      2000,
+		ConnectedSince: time.Unix(1592474400, 0),
+		ClientID:       3,
+	}
+	if cl[0] != want {
+		t.Errorf("got %+v, want %+v", cl[0], want)
+	}
+	if rt[1].CommonName != "usr2" || rt[1].VirtualAddress != "10.9.0.3" {
+		t.Errorf("unexpected routing table entry: %+v", rt[1])
+	}
+}
+
+func TestClientKill(t *testing.T) {
+	// Initialize:
+	srv := newFakeServer(t)
+	defer srv.close()
+
+	// Prepare:
+	srv.replies["kill usr1"] = "SUCCESS: common name 'usr1' found, 1 client(s) killed\n"
+	srv.replies["kill usr2"] = "ERROR: common name 'usr2' not found\n"
+
+	c, err := mgmt.Dial(srv.path)
+	if err != nil {
+		t.Fatalf("can not dial: %v", err)
+	}
+	defer c.Close()
+
+	// Test:
+	if err := c.Kill("usr1"); err != nil {
+		t.Errorf("kill is expected to succeed but it failed: %v", err)
+	}
+	if cmd := <-srv.received; cmd != "kill usr1" {
+		t.Errorf("expected command 'kill usr1', got '%s'", cmd)
+	}
+
+	if err := c.Kill("usr2"); err == nil {
+		t.Errorf("kill is expected to fail for a client that is not connected")
+	}
+}
+
+func TestClientEvents(t *testing.T) {
+	// Initialize:
+	srv := newFakeServer(t)
+	defer srv.close()
+
+	c, err := mgmt.Dial(srv.path)
+	if err != nil {
+		t.Fatalf("can not dial: %v", err)
+	}
+	defer c.Close()
+
+	// Prepare:
+	srv.notify(
+		">CLIENT:ESTABLISHED,7",
+		">CLIENT:ENV,common_name=usr1",
+		">CLIENT:ENV,trusted_ip=1.1.1.1",
+		">CLIENT:ENV,END",
+		">BYTECOUNT_CLI:7,100,200",
+		">CLIENT:DISCONNECT,7",
+		">CLIENT:ENV,common_name=usr1",
+		">CLIENT:ENV,bytes_received=100",
+		">CLIENT:ENV,END",
+	)
+
+	// Test:
+	var eventtests = []struct {
+		typ           mgmt.EventType
+		commonName    string
+		bytesReceived uint64
+		bytesSent     uint64
+	}{
+		{mgmt.EstablishedEvent, "usr1", 0, 0},
+		{mgmt.ByteCountEvent, "", 100, 200},
+		{mgmt.DisconnectEvent, "usr1", 0, 0},
+	}
+	for _, tt := range eventtests {
+		select {
+		case e := <-c.Events():
+			if e.Type != tt.typ {
+				t.Fatalf("expected %s event, got %s", tt.typ, e.Type)
+			}
+			if e.ClientID != 7 {
+				t.Errorf("expected client id 7, got %d", e.ClientID)
+			}
+			if e.CommonName() != tt.commonName {
+				t.Errorf("expected common name '%s', got '%s'", tt.commonName, e.CommonName())
+			}
+			if e.BytesReceived != tt.bytesReceived || e.BytesSent != tt.bytesSent {
+				t.Errorf("expected bytes %d/%d, got %d/%d", tt.bytesReceived, tt.bytesSent, e.BytesReceived, e.BytesSent)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for %s event", tt.typ)
+		}
+	}
+}
+
+func TestClientClosed(t *testing.T) {
+	// Initialize:
+	srv := newFakeServer(t)
+
+	c, err := mgmt.Dial(srv.path)
+	if err != nil {
+		t.Fatalf("can not dial: %v", err)
+	}
+	defer c.Close()
+	<-srv.accepted
+
+	// Test:
+	srv.close()
+
+	select {
+	case <-c.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatalf("client is expected to notice the closed connection")
+	}
+	if err := c.Kill("usr1"); err == nil {
+		t.Errorf("commands are expected to fail on a closed connection")
+	}
+}
+
+func TestClientTimeout(t *testing.T) {
+	// Initialize:
+	srv := newFakeServer(t)
+	defer srv.close()
+	srv.replies["status 3"] = "" // never replied
+
+	c, err := mgmt.Dial(srv.path)
+	if err != nil {
+		t.Fatalf("can not dial: %v", err)
+	}
+	defer c.Close()
+	c.SetTimeout(100 * time.Millisecond)
+
+	// Test:
+	errc := make(chan error, 1)
+	go func() {
+		_, _, err := c.Status()
+		errc <- err
+	}()
+	select {
+	case err := <-errc:
+		if err == nil {
+			t.Fatalf("command is expected to time out")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("command is expected to time out but it's still waiting")
+	}
+
+	// Replies can't be matched to the commands anymore, so the connection is closed.
+	select {
+	case <-c.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatalf("connection is expected to be closed after the timeout")
+	}
+	if c.Err() == nil {
+		t.Errorf("timeout is expected to be the error of the connection")
+	}
+	if err := c.Kill("usr1"); err == nil {
+		t.Errorf("commands are expected to fail after the timeout")
+	}
+}
@@ -454,6 +454,11 @@ func routableIP(network string, ip net.IP) net.IP {
 
 // ensureNatEnabled launches a goroutine that constantly tries to enable nat.
 func ensureNatEnabled() {
+	// Testing is read before the goroutine is started, since the tests
+	// change it.
+	if Testing {
+		return
+	}
 	// Nat enablerer
 	go func() {
 		for {
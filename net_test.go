@@ -12,7 +12,7 @@ func TestVPNCreateNewNetwork(t *testing.T) {
 	setupTestCase()
 	CreateDB("sqlite3", ":memory:")
 	defer db.Cease()
-	TheServer().Init("localhost", "", UDPProto, "", "", "", "", false)
+	TheServer().Init("localhost", "", UDPProto, "", "", "", "", false, nil)
 
 	// Prepare:
 	// Test:
@@ -80,7 +80,7 @@ func TestVPNDeleteNetwork(t *testing.T) {
 	setupTestCase()
 	CreateDB("sqlite3", ":memory:")
 	defer db.Cease()
-	TheServer().Init("localhost", "", UDPProto, "", "", "", "", false)
+	TheServer().Init("localhost", "", UDPProto, "", "", "", "", false, nil)
 
 	// Prepare:
 	// Test:
@@ -118,7 +118,7 @@ func TestVPNGetNetwork(t *testing.T) {
 	setupTestCase()
 	CreateDB("sqlite3", ":memory:")
 	defer db.Cease()
-	TheServer().Init("localhost", "", UDPProto, "", "", "", "", false)
+	TheServer().Init("localhost", "", UDPProto, "", "", "", "", false, nil)
 
 	// Prepare:
 	// Test:
@@ -153,7 +153,7 @@ func TestVPNGetAllNetworks(t *testing.T) {
 	setupTestCase()
 	CreateDB("sqlite3", ":memory:")
 	defer db.Cease()
-	TheServer().Init("localhost", "", UDPProto, "", "", "", "", false)
+	TheServer().Init("localhost", "", UDPProto, "", "", "", "", false, nil)
 
 	// Prepare:
 	// Test:
@@ -199,7 +199,7 @@ func TestNetAssociate(t *testing.T) {
 	setupTestCase()
 	CreateDB("sqlite3", ":memory:")
 	defer db.Cease()
-	TheServer().Init("localhost", "", UDPProto, "", "", "", "", false)
+	TheServer().Init("localhost", "", UDPProto, "", "", "", "", false, nil)
 
 	// Prepare:
 	// Test:
@@ -245,7 +245,7 @@ func TestNetDissociate(t *testing.T) {
 	CreateDB("sqlite3", ":memory:")
 	defer db.Cease()
 
-	if err := TheServer().Init("localhost", "", UDPProto, "", "", "", "", false); err != nil {
+	if err := TheServer().Init("localhost", "", UDPProto, "", "", "", "", false, nil); err != nil {
 		t.Fatal(err)
 	}
 
@@ -297,7 +297,7 @@ func TestNetGetAssociatedUsers(t *testing.T) {
 	setupTestCase()
 	CreateDB("sqlite3", ":memory:")
 	defer db.Cease()
-	TheServer().Init("localhost", "", UDPProto, "", "", "", "", false)
+	TheServer().Init("localhost", "", UDPProto, "", "", "", "", false, nil)
 
 	// Prepare:
 	// Test:
@@ -331,7 +331,7 @@ func TestNetworkTypeFromString(t *testing.T) {
 	setupTestCase()
 	CreateDB("sqlite3", ":memory:")
 	defer db.Cease()
-	TheServer().Init("localhost", "", UDPProto, "", "", "", "", false)
+	TheServer().Init("localhost", "", UDPProto, "", "", "", "", false, nil)
 
 	// Test
 	type args struct {
@@ -360,7 +360,7 @@ func TestGetAllNetworkTypes(t *testing.T) {
 	setupTestCase()
 	CreateDB("sqlite3", ":memory:")
 	defer db.Cease()
-	TheServer().Init("localhost", "", UDPProto, "", "", "", "", false)
+	TheServer().Init("localhost", "", UDPProto, "", "", "", "", false, nil)
 
 	// Test
 	tests := []struct {
@@ -383,7 +383,7 @@ func TestIsNetworkType(t *testing.T) {
 	setupTestCase()
 	CreateDB("sqlite3", ":memory:")
 	defer db.Cease()
-	TheServer().Init("localhost", "", UDPProto, "", "", "", "", false)
+	TheServer().Init("localhost", "", UDPProto, "", "", "", "", false, nil)
 
 	// Test
 	type args struct {
@@ -412,7 +412,7 @@ func TestIncrementIP(t *testing.T) {
 	setupTestCase()
 	CreateDB("sqlite3", ":memory:")
 	defer db.Cease()
-	TheServer().Init("localhost", "", UDPProto, "", "", "", "", false)
+	TheServer().Init("localhost", "", UDPProto, "", "", "", "", false, nil)
 
 	// Test
 	type args struct {
@@ -446,7 +446,7 @@ func Test_routableIP(t *testing.T) {
 	setupTestCase()
 	CreateDB("sqlite3", ":memory:")
 	defer db.Cease()
-	TheServer().Init("localhost", "", UDPProto, "", "", "", "", false)
+	TheServer().Init("localhost", "", UDPProto, "", "", "", "", false, nil)
 
 	// Test
 	type args struct {
@@ -0,0 +1,324 @@
+package ovpm
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/cad/ovpm/oidc"
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+)
+
+// Default OIDC settings.
+const (
+	DefaultOIDCUsernameClaim = "email"
+	DefaultOIDCGroupsClaim   = "groups"
+)
+
+// OIDCLoginTimeout is how long a started login can be completed.
+const OIDCLoginTimeout = 10 * time.Minute
+
+// OIDCConfig represents the OpenID Connect provider that the users log in to
+// the web UI and the REST API with.
+//
+// Users aren't created on login, they log in with the subject (sub) of the ID
+// token that they're linked to by LinkOIDC. The other claims may be changed by
+// the users themselves, so only a verified email links a user on the first
+// login, if it's the username claim and it matches an existing user. Admins
+// are never linked on login.
+type OIDCConfig struct {
+	Issuer        string // e.g. https://accounts.example.com
+	ClientID      string
+	ClientSecret  string
+	RedirectURL   string // Callback of ovpm, e.g. https://vpn.example.com/api/v1/auth/oidc/callback
+	UsernameClaim string // Claim that holds the usernames. Only email links the users on login.
+	GroupsClaim   string // Claim that holds the groups of the user.
+	AdminGroup    string // Users in this group are admins. "" leaves the admin flag alone.
+}
+
+// dbOIDCModel is database model for the OIDC configuration.
+type dbOIDCModel struct {
+	gorm.Model
+	OIDCConfig
+}
+
+// oidcLogin is a login that is started but isn't completed yet.
+type oidcLogin struct {
+	nonce     string
+	verifier  string
+	returnTo  string
+	expiresAt time.Time
+}
+
+var oidcState = struct {
+	sync.Mutex
+	client *oidc.Client
+	cfg    OIDCConfig           // Config that the client is discovered with.
+	logins map[string]oidcLogin // state -> login
+}{logins: make(map[string]oidcLogin)}
+
+// GetOIDCConfig returns the OIDC configuration or nil if OIDC isn't
+// configured.
+func GetOIDCConfig() *OIDCConfig {
+	var m dbOIDCModel
+	if db.First(&m).RecordNotFound() {
+		return nil
+	}
+	return &m.OIDCConfig
+}
+
+// IsOIDCEnabled returns whether OIDC is configured.
+func IsOIDCEnabled() bool {
+	return GetOIDCConfig() != nil
+}
+
+// SetOIDCConfig validates the OIDC configuration by discovering the provider
+// and then stores it.
+//
+// If the client secret is empty, the previous one is kept.
+func SetOIDCConfig(cfg OIDCConfig) error {
+	if cfg.ClientSecret == "" {
+		if prev := GetOIDCConfig(); prev != nil {
+			cfg.ClientSecret = prev.ClientSecret
+		}
+	}
+	if cfg.UsernameClaim == "" {
+		cfg.UsernameClaim = DefaultOIDCUsernameClaim
+	}
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = DefaultOIDCGroupsClaim
+	}
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+
+	// Make sure that the provider is reachable.
+	if _, err := oidcClient(&cfg); err != nil {
+		return err
+	}
+
+	db.Unscoped().Delete(dbOIDCModel{})
+	m := dbOIDCModel{OIDCConfig: cfg}
+	db.Create(&m)
+	if db.NewRecord(&m) {
+		return fmt.Errorf("can not save oidc config")
+	}
+	logrus.Infof("oidc configured: %s", cfg.Issuer)
+	return nil
+}
+
+// DisableOIDC removes the OIDC configuration.
+//
+// Users can still log in with their passwords.
+func DisableOIDC() error {
+	db.Unscoped().Delete(dbOIDCModel{})
+	oidcState.Lock()
+	oidcState.client = nil
+	oidcState.logins = make(map[string]oidcLogin)
+	oidcState.Unlock()
+	logrus.Info("oidc disabled")
+	return nil
+}
+
+func (cfg *OIDCConfig) validate() error {
+	for _, field := range [][2]string{{"issuer", cfg.Issuer}, {"redirect url", cfg.RedirectURL}} {
+		u, err := url.Parse(field[1])
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("validation error: %s:`%s` should be an http:// or https:// url", field[0], field[1])
+		}
+	}
+	for _, field := range [][2]string{{"client id", cfg.ClientID}, {"client secret", cfg.ClientSecret}} {
+		if govalidator.IsNull(field[1]) {
+			return fmt.Errorf("validation error: %s can not be empty", field[0])
+		}
+	}
+	return nil
+}
+
+// oidcClient returns the client of the provider. The provider is discovered
+// again only if the config is changed.
+func oidcClient(cfg *OIDCConfig) (*oidc.Client, error) {
+	oidcState.Lock()
+	defer oidcState.Unlock()
+	if oidcState.client != nil && oidcState.cfg == *cfg {
+		return oidcState.client, nil
+	}
+	client, err := oidc.Discover(cfg.Issuer, oidc.Config{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.RedirectURL,
+		Scopes:       []string{"profile", "email"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	oidcState.client, oidcState.cfg = client, *cfg
+	return client, nil
+}
+
+// BeginOIDCLogin starts a login and returns the URL of the provider that the
+// user should be sent to, and the state of the login.
+//
+// The state should be tied to the user's browser, e.g. by a cookie, so that
+// the login can't be completed in another browser. returnTo is handed back
+// when the login is completed, e.g. the page that the user should land on.
+func BeginOIDCLogin(returnTo string) (authURL string, state string, err error) {
+	cfg := GetOIDCConfig()
+	if cfg == nil {
+		return "", "", fmt.Errorf("oidc is not configured")
+	}
+	client, err := oidcClient(cfg)
+	if err != nil {
+		return "", "", err
+	}
+
+	state = oidc.RandomString()
+	login := oidcLogin{
+		nonce:     oidc.RandomString(),
+		verifier:  oidc.RandomString(),
+		returnTo:  returnTo,
+		expiresAt: time.Now().Add(OIDCLoginTimeout),
+	}
+	oidcState.Lock()
+	for s, l := range oidcState.logins {
+		if time.Now().After(l.expiresAt) {
+			delete(oidcState.logins, s)
+		}
+	}
+	oidcState.logins[state] = login
+	oidcState.Unlock()
+	return client.AuthCodeURL(state, login.nonce, login.verifier), state, nil
+}
+
+// LinkOIDC links the user to the subject (sub) of the OIDC account, so that
+// the user logs in with that account. An empty subject unlinks the user.
+func (u *User) LinkOIDC(subject string) error {
+	if subject == "" {
+		u.OIDCIssuer, u.OIDCSubject = "", ""
+		db.Save(u.dbUserModel)
+		logrus.Infof("user is unlinked from oidc: %s", u.Username)
+		return nil
+	}
+	cfg := GetOIDCConfig()
+	if cfg == nil {
+		return fmt.Errorf("oidc is not configured")
+	}
+	if other := findOIDCUser(cfg.Issuer, subject); other != nil && other.ID != u.ID {
+		return fmt.Errorf("oidc subject %s is already linked to user %s", subject, other.Username)
+	}
+	u.OIDCIssuer, u.OIDCSubject = cfg.Issuer, subject
+	db.Save(u.dbUserModel)
+	logrus.Infof("user is linked to oidc subject %s: %s", subject, u.Username)
+	return nil
+}
+
+// GetOIDCSubject returns the subject of the OIDC account that the user is
+// linked to, or "".
+func (u *User) GetOIDCSubject() string {
+	return u.OIDCSubject
+}
+
+// findOIDCUser returns the user that is linked to the subject of the issuer,
+// or nil.
+func findOIDCUser(issuer, subject string) *User {
+	var m dbUserModel
+	if err := db.Where("oidc_issuer = ? AND oidc_subject = ?", issuer, subject).First(&m).Error; err != nil {
+		return nil
+	}
+	return &User{dbUserModel: m}
+}
+
+// oidcUser returns the user that the ID token belongs to, and links it to the
+// subject on the first login if the username claim is a verified email.
+func oidcUser(cfg *OIDCConfig, claims oidc.Claims) (*User, error) {
+	subject := claims.String("sub")
+	if subject == "" {
+		return nil, fmt.Errorf("id token doesn't have the sub claim")
+	}
+	if user := findOIDCUser(cfg.Issuer, subject); user != nil {
+		return user, nil
+	}
+
+	if cfg.UsernameClaim != "email" {
+		return nil, fmt.Errorf("oidc subject %s is not linked to any user, it needs to be linked by an admin: $ ovpm oidc link --user <username> --subject %s", subject, subject)
+	}
+	username := claims.String(cfg.UsernameClaim)
+	if username == "" {
+		return nil, fmt.Errorf("id token doesn't have the username claim %s", cfg.UsernameClaim)
+	}
+	if !claims.Bool("email_verified") {
+		return nil, fmt.Errorf("email of oidc user %s is not verified", username)
+	}
+	user, err := GetUser(username)
+	if err != nil {
+		return nil, fmt.Errorf("oidc user %s not found: %v", username, err)
+	}
+	if user.OIDCSubject != "" && user.OIDCIssuer == cfg.Issuer {
+		return nil, fmt.Errorf("user %s is linked to another oidc account", username)
+	}
+	if user.IsAdmin() {
+		return nil, fmt.Errorf("admin user %s needs to be linked by an admin: $ ovpm oidc link --user %s --subject %s", username, username, subject)
+	}
+	if err := user.LinkOIDC(subject); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// CompleteOIDCLogin redeems the authorization code that the provider sent
+// back for the state, and issues an auth token for the user in the ID token.
+//
+// It returns the token and the returnTo of the login.
+func CompleteOIDCLogin(state, code, userAgent string) (token string, returnTo string, err error) {
+	oidcState.Lock()
+	login, ok := oidcState.logins[state]
+	delete(oidcState.logins, state)
+	oidcState.Unlock()
+	if !ok || time.Now().After(login.expiresAt) {
+		return "", "", fmt.Errorf("oidc login is unknown or expired")
+	}
+	cfg := GetOIDCConfig()
+	if cfg == nil {
+		return "", login.returnTo, fmt.Errorf("oidc is not configured")
+	}
+	client, err := oidcClient(cfg)
+	if err != nil {
+		return "", login.returnTo, err
+	}
+	claims, err := client.Exchange(code, login.verifier, login.nonce)
+	if err != nil {
+		return "", login.returnTo, err
+	}
+
+	user, err := oidcUser(cfg, claims)
+	if err != nil {
+		return "", login.returnTo, err
+	}
+	username := user.Username
+	if user.IsDisabled() {
+		return "", login.returnTo, fmt.Errorf("oidc user %s is disabled", username)
+	}
+	if cfg.AdminGroup != "" {
+		var isAdmin bool
+		for _, group := range claims.Strings(cfg.GroupsClaim) {
+			if group == cfg.AdminGroup {
+				isAdmin = true
+			}
+		}
+		if user.Admin != isAdmin {
+			user.Admin = isAdmin
+			db.Save(user.dbUserModel)
+			logrus.Infof("oidc user %s admin flag is set to %t", username, isAdmin)
+		}
+	}
+
+	token, err = user.IssueToken("oidc", userAgent)
+	if err != nil {
+		return "", login.returnTo, fmt.Errorf("can not issue token for %s: %v", username, err)
+	}
+	logrus.Infof("oidc user logged in: %s", username)
+	return token, login.returnTo, nil
+}
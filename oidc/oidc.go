@@ -0,0 +1,348 @@
+// Package oidc implements a minimal OpenID Connect relying party that is just
+// enough to log the users in with the authorization code flow.
+//
+// Provider metadata is discovered from the issuer, authorization codes are
+// protected with PKCE and only the ID tokens that are signed with RS256 are
+// accepted.
+//
+// See OpenID Connect Core 1.0, OpenID Connect Discovery 1.0 and RFC 7636.
+package oidc
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout is the timeout of the requests to the provider.
+const DefaultTimeout = 10 * time.Second
+
+// clockSkew is tolerated while checking the expiry of the ID tokens.
+const clockSkew = time.Minute
+
+// Config represents the client registration at the provider.
+type Config struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string   // Where the provider sends the users back with the code.
+	Scopes       []string // Requested in addition to openid.
+}
+
+// Client logs the users in with a provider.
+//
+// A Client can be used by multiple goroutines.
+type Client struct {
+	Config
+	Issuer                string
+	AuthorizationEndpoint string
+	TokenEndpoint         string
+	JWKSURI               string
+
+	httpClient *http.Client
+	mu         sync.Mutex
+	keys       map[string]*rsa.PublicKey // kid -> key
+}
+
+// Discover fetches the metadata of the provider at the issuer URL and
+// returns a Client for it.
+func Discover(issuer string, cfg Config) (*Client, error) {
+	c := &Client{Config: cfg, httpClient: &http.Client{Timeout: DefaultTimeout}}
+	var meta struct {
+		Issuer                string `json:"issuer"`
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		JWKSURI               string `json:"jwks_uri"`
+	}
+	wellKnown := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	if err := c.getJSON(wellKnown, &meta); err != nil {
+		return nil, fmt.Errorf("can not discover oidc provider %s: %v", issuer, err)
+	}
+	if meta.Issuer != issuer {
+		return nil, fmt.Errorf("oidc: issuer of the provider is %s instead of %s", meta.Issuer, issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, fmt.Errorf("oidc: provider metadata of %s is missing an endpoint", issuer)
+	}
+	c.Issuer = meta.Issuer
+	c.AuthorizationEndpoint = meta.AuthorizationEndpoint
+	c.TokenEndpoint = meta.TokenEndpoint
+	c.JWKSURI = meta.JWKSURI
+	return c, nil
+}
+
+// RandomString returns a random URL safe string that can be used as a state,
+// a nonce or a PKCE code verifier.
+func RandomString() string {
+	b := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		panic(fmt.Sprintf("oidc: can not read random bytes: %v", err))
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// CodeChallenge returns the S256 PKCE code challenge of the verifier.
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthCodeURL returns the URL of the provider that the users should be sent
+// to for logging in.
+func (c *Client) AuthCodeURL(state, nonce, verifier string) string {
+	scopes := append([]string{"openid"}, c.Scopes...)
+	v := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {c.ClientID},
+		"redirect_uri":          {c.RedirectURL},
+		"scope":                 {strings.Join(scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {CodeChallenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(c.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return c.AuthorizationEndpoint + sep + v.Encode()
+}
+
+// Exchange redeems the authorization code at the token endpoint and returns
+// the verified claims of the ID token.
+func (c *Client) Exchange(code, verifier, nonce string) (Claims, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {c.RedirectURL},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequest("POST", c.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("oidc: can not create token request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(c.ClientID), url.QueryEscape(c.ClientSecret))
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: can not redeem code: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("oidc: can not read token response: %v", err)
+	}
+	var tok struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &tok); err != nil {
+		return nil, fmt.Errorf("oidc: can not parse token response (%s): %v", resp.Status, err)
+	}
+	if resp.StatusCode != http.StatusOK || tok.Error != "" {
+		return nil, fmt.Errorf("oidc: token request failed (%s): %s %s", resp.Status, tok.Error, tok.ErrorDescription)
+	}
+	if tok.IDToken == "" {
+		return nil, fmt.Errorf("oidc: token response doesn't have an id token")
+	}
+	return c.Verify(tok.IDToken, nonce)
+}
+
+// Verify checks the signature, the issuer, the audience, the expiry and the
+// nonce of the ID token and returns its claims.
+func (c *Client) Verify(rawIDToken, nonce string) (Claims, error) {
+	parts := strings.Split(rawIDToken, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("oidc: malformed id token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("oidc: malformed id token header: %v", err)
+	}
+	if header.Alg != "RS256" {
+		return nil, fmt.Errorf("oidc: id token algorithm %s is not supported", header.Alg)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("oidc: malformed id token signature: %v", err)
+	}
+	key, err := c.key(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig); err != nil {
+		return nil, fmt.Errorf("oidc: invalid id token signature")
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("oidc: malformed id token claims: %v", err)
+	}
+	if iss := claims.String("iss"); iss != c.Issuer {
+		return nil, fmt.Errorf("oidc: id token is issued by %s instead of %s", iss, c.Issuer)
+	}
+	aud := claims.Strings("aud")
+	if !contains(aud, c.ClientID) {
+		return nil, fmt.Errorf("oidc: id token is not issued for %s", c.ClientID)
+	}
+	if len(aud) > 1 && claims.String("azp") != c.ClientID {
+		return nil, fmt.Errorf("oidc: id token is not authorized for %s", c.ClientID)
+	}
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return nil, fmt.Errorf("oidc: id token doesn't have an expiry")
+	}
+	if time.Now().Add(-clockSkew).After(time.Unix(int64(exp), 0)) {
+		return nil, fmt.Errorf("oidc: id token is expired")
+	}
+	if nonce != "" && claims.String("nonce") != nonce {
+		return nil, fmt.Errorf("oidc: id token nonce doesn't match")
+	}
+	return claims, nil
+}
+
+// key returns the signing key with the given id. Keys are fetched again if
+// the key isn't known, so that the rotated keys are picked up.
+func (c *Client) key(kid string) (*rsa.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if key := c.findKey(kid); key != nil {
+		return key, nil
+	}
+	keys, err := c.fetchKeys()
+	if err != nil {
+		return nil, err
+	}
+	c.keys = keys
+	if key := c.findKey(kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("oidc: signing key %s not found", kid)
+}
+
+// findKey returns the key with the given id. If the id is empty, the only
+// key of the provider is returned.
+func (c *Client) findKey(kid string) *rsa.PublicKey {
+	if kid == "" && len(c.keys) == 1 {
+		for _, key := range c.keys {
+			return key
+		}
+	}
+	return c.keys[kid]
+}
+
+func (c *Client) fetchKeys() (map[string]*rsa.PublicKey, error) {
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Use string `json:"use"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := c.getJSON(c.JWKSURI, &jwks); err != nil {
+		return nil, fmt.Errorf("can not fetch oidc signing keys: %v", err)
+	}
+	keys := make(map[string]*rsa.PublicKey)
+	for _, k := range jwks.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("oidc: malformed signing key %s: %v", k.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil || len(e) > 4 {
+			return nil, fmt.Errorf("oidc: malformed signing key exponent %s", k.Kid)
+		}
+		keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	return keys, nil
+}
+
+func (c *Client) getJSON(u string, v interface{}) error {
+	resp, err := c.httpClient.Get(u)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response from %s: %s", u, resp.Status)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
+
+// Claims are the claims of an ID token.
+type Claims map[string]interface{}
+
+// String returns the claim if it's a string, or "".
+func (c Claims) String(name string) string {
+	s, _ := c[name].(string)
+	return s
+}
+
+// Bool returns the claim if it's a boolean, or false. Some providers send the
+// booleans as strings. (e.g. "true")
+func (c Claims) Bool(name string) bool {
+	switch v := c[name].(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
+
+// Strings returns the claim if it's a string or a list of strings. (e.g. aud
+// or groups)
+func (c Claims) Strings(name string) []string {
+	switch v := c[name].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var values []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
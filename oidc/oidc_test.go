@@ -0,0 +1,139 @@
+package oidc_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cad/ovpm/oidc"
+	"github.com/cad/ovpm/oidc/oidctest"
+)
+
+const testRedirectURL = "http://127.0.0.1:1/callback"
+
+func newTestClient(t *testing.T) (*oidctest.Server, *oidc.Client) {
+	s, err := oidctest.NewServer("ovpm", "s3cret/+")
+	if err != nil {
+		t.Fatalf("can not start oidc provider: %v", err)
+	}
+	c, err := oidc.Discover(s.URL, oidc.Config{ClientID: "ovpm", ClientSecret: "s3cret/+", RedirectURL: testRedirectURL, Scopes: []string{"profile"}})
+	if err != nil {
+		s.Close()
+		t.Fatalf("can not discover: %v", err)
+	}
+	return s, c
+}
+
+// authorize follows the authorization URL and returns the query of the
+// redirect back to the client.
+func authorize(t *testing.T, authURL string) url.Values {
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("can not authorize: %v", err)
+	}
+	resp.Body.Close()
+	loc, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil || !strings.HasPrefix(loc.String(), testRedirectURL) {
+		t.Fatalf("authorization is expected to redirect to the client: %v %s", err, resp.Header.Get("Location"))
+	}
+	return loc.Query()
+}
+
+func TestDiscover(t *testing.T) {
+	s, c := newTestClient(t)
+	defer s.Close()
+
+	if c.Issuer != s.URL || c.TokenEndpoint != s.URL+"/token" {
+		t.Errorf("unexpected provider metadata: %+v", c)
+	}
+	if _, err := oidc.Discover(s.URL+"/", oidc.Config{}); err == nil {
+		t.Errorf("discovery is expected to fail when the issuer doesn't match")
+	}
+	if _, err := oidc.Discover(s.URL+"/nowhere", oidc.Config{}); err == nil {
+		t.Errorf("discovery is expected to fail when there is no provider")
+	}
+}
+
+func TestExchange(t *testing.T) {
+	s, c := newTestClient(t)
+	defer s.Close()
+	s.SetUser(map[string]interface{}{"sub": "1", "preferred_username": "john", "groups": []string{"vpn", "admins"}})
+
+	// Test:
+	state, nonce, verifier := oidc.RandomString(), oidc.RandomString(), oidc.RandomString()
+	q := authorize(t, c.AuthCodeURL(state, nonce, verifier))
+	if q.Get("state") != state || q.Get("code") == "" {
+		t.Fatalf("authorization is expected to return the state and a code: %v", q)
+	}
+	if _, err := c.Exchange(q.Get("code"), oidc.RandomString(), nonce); err == nil {
+		t.Errorf("exchange is expected to fail with a wrong code verifier")
+	}
+
+	q = authorize(t, c.AuthCodeURL(state, nonce, verifier))
+	if _, err := c.Exchange(q.Get("code"), verifier, "other"); err == nil {
+		t.Errorf("exchange is expected to fail with a wrong nonce")
+	}
+
+	q = authorize(t, c.AuthCodeURL(state, nonce, verifier))
+	claims, err := c.Exchange(q.Get("code"), verifier, nonce)
+	if err != nil {
+		t.Fatalf("can not exchange code: %v", err)
+	}
+	if claims.String("preferred_username") != "john" || strings.Join(claims.Strings("groups"), ",") != "vpn,admins" {
+		t.Errorf("unexpected claims: %v", claims)
+	}
+	if _, err := c.Exchange(q.Get("code"), verifier, nonce); err == nil {
+		t.Errorf("code is expected to be redeemed only once")
+	}
+
+	// Denied logins are reported to the client.
+	s.SetUser(nil)
+	if q = authorize(t, c.AuthCodeURL(state, nonce, verifier)); q.Get("error") != "access_denied" {
+		t.Errorf("authorization is expected to be denied: %v", q)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	s, c := newTestClient(t)
+	defer s.Close()
+
+	if _, err := c.Verify(s.SignToken(map[string]interface{}{"sub": "1"}), ""); err != nil {
+		t.Fatalf("valid token is rejected: %v", err)
+	}
+
+	var invalidtests = []struct {
+		name  string
+		token string
+	}{
+		{"malformed", "a.b"},
+		{"wrong issuer", s.SignToken(map[string]interface{}{"iss": "http://evil.example.com"})},
+		{"wrong audience", s.SignToken(map[string]interface{}{"aud": "other"})},
+		{"unauthorized party", s.SignToken(map[string]interface{}{"aud": []string{"ovpm", "other"}, "azp": "other"})},
+		{"expired", s.SignToken(map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()})},
+		{"no expiry", s.SignToken(map[string]interface{}{"exp": nil})},
+		{"tampered", tamper(s.SignToken(nil))},
+		{"unsigned", "eyJhbGciOiJub25lIn0." + strings.Split(s.SignToken(nil), ".")[1] + "."},
+	}
+	for _, tt := range invalidtests {
+		if _, err := c.Verify(tt.token, ""); err == nil {
+			t.Errorf("%s token is expected to be rejected", tt.name)
+		}
+	}
+
+	// Rotated keys are fetched again.
+	if err := s.RotateKey(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Verify(s.SignToken(nil), ""); err != nil {
+		t.Errorf("token signed with the rotated key is rejected: %v", err)
+	}
+}
+
+// tamper changes the claims of the token without signing it again.
+func tamper(token string) string {
+	parts := strings.Split(token, ".")
+	return parts[0] + "." + strings.TrimRight(parts[1], "=") + "e30" + "." + parts[2]
+}
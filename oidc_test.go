@@ -0,0 +1,234 @@
+package ovpm
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/cad/ovpm/oidc/oidctest"
+)
+
+const (
+	testOIDCClientID     = "ovpm"
+	testOIDCClientSecret = "secret"
+	testOIDCRedirectURL  = "http://127.0.0.1:1/api/v1/auth/oidc/callback"
+)
+
+func newTestOIDCProvider(t *testing.T) *oidctest.Server {
+	s, err := oidctest.NewServer(testOIDCClientID, testOIDCClientSecret)
+	if err != nil {
+		t.Fatalf("can not start oidc provider: %v", err)
+	}
+	return s
+}
+
+// oidcAuthorize starts a login, follows it through the provider and returns
+// the state and the code that the provider sends back.
+func oidcAuthorize(t *testing.T, returnTo string) (string, string) {
+	authURL, _, err := BeginOIDCLogin(returnTo)
+	if err != nil {
+		t.Fatalf("can not begin oidc login: %v", err)
+	}
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("can not authorize: %v", err)
+	}
+	resp.Body.Close()
+	loc, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("can not parse redirect: %v", err)
+	}
+	return loc.Query().Get("state"), loc.Query().Get("code")
+}
+
+func TestOIDCConfig(t *testing.T) {
+	// Initialize:
+	setupTestCase()
+	CreateDB("sqlite3", ":memory:")
+	defer db.Cease()
+	s := newTestOIDCProvider(t)
+	defer s.Close()
+
+	// Test:
+	var invalidtests = []OIDCConfig{
+		{Issuer: "", ClientID: testOIDCClientID, ClientSecret: testOIDCClientSecret, RedirectURL: testOIDCRedirectURL},
+		{Issuer: s.URL, ClientID: testOIDCClientID, ClientSecret: testOIDCClientSecret, RedirectURL: "/callback"},
+		{Issuer: s.URL, ClientID: "", ClientSecret: testOIDCClientSecret, RedirectURL: testOIDCRedirectURL},
+		{Issuer: s.URL, ClientID: testOIDCClientID, ClientSecret: "", RedirectURL: testOIDCRedirectURL},
+		{Issuer: s.URL + "/nowhere", ClientID: testOIDCClientID, ClientSecret: testOIDCClientSecret, RedirectURL: testOIDCRedirectURL},
+	}
+	for _, cfg := range invalidtests {
+		if err := SetOIDCConfig(cfg); err == nil {
+			t.Errorf("oidc config %+v is expected to be rejected", cfg)
+		}
+	}
+	if IsOIDCEnabled() {
+		t.Fatalf("oidc is expected to be disabled after the rejected configs")
+	}
+	if _, _, err := BeginOIDCLogin(""); err == nil {
+		t.Errorf("login is expected to fail when oidc is not configured")
+	}
+
+	cfg := OIDCConfig{Issuer: s.URL, ClientID: testOIDCClientID, ClientSecret: testOIDCClientSecret, RedirectURL: testOIDCRedirectURL}
+	if err := SetOIDCConfig(cfg); err != nil {
+		t.Fatalf("can not set oidc config: %v", err)
+	}
+	saved := GetOIDCConfig()
+	if saved == nil || saved.UsernameClaim != DefaultOIDCUsernameClaim || saved.GroupsClaim != DefaultOIDCGroupsClaim {
+		t.Fatalf("oidc config is expected to be saved with the defaults: %+v", saved)
+	}
+
+	// The client secret is kept if it's not given.
+	cfg.ClientSecret = ""
+	cfg.UsernameClaim = "email"
+	if err := SetOIDCConfig(cfg); err != nil {
+		t.Fatalf("can not update oidc config: %v", err)
+	}
+	if saved := GetOIDCConfig(); saved.ClientSecret != testOIDCClientSecret || saved.UsernameClaim != "email" {
+		t.Errorf("oidc config is expected to be updated and keep the client secret: %+v", saved)
+	}
+
+	if err := DisableOIDC(); err != nil || IsOIDCEnabled() {
+		t.Errorf("oidc is expected to be disabled: %v", err)
+	}
+}
+
+func TestOIDCLogin(t *testing.T) {
+	// Initialize:
+	setupTestCase()
+	CreateDB("sqlite3", ":memory:")
+	defer db.Cease()
+	s := newTestOIDCProvider(t)
+	defer s.Close()
+	svr := TheServer()
+
+	// Prepare:
+	svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil)
+	if _, err := CreateNewUser("john", "1234", false, 0, false, "description"); err != nil {
+		t.Fatalf("user creation failed: %v", err)
+	}
+	cfg := OIDCConfig{Issuer: s.URL, ClientID: testOIDCClientID, ClientSecret: testOIDCClientSecret, RedirectURL: testOIDCRedirectURL, UsernameClaim: "email", AdminGroup: "vpn-admins"}
+	if err := SetOIDCConfig(cfg); err != nil {
+		t.Fatalf("can not set oidc config: %v", err)
+	}
+
+	// Test:
+	// Username is mapped from the claim and the admin flag from the groups.
+	s.SetUser(map[string]interface{}{"sub": "1", "preferred_username": "someone", "email": "john", "email_verified": true, "groups": []string{"vpn-admins"}})
+	state, code := oidcAuthorize(t, "/dashboard")
+	token, returnTo, err := CompleteOIDCLogin(state, code, "")
+	if err != nil {
+		t.Fatalf("can not complete oidc login: %v", err)
+	}
+	if returnTo != "/dashboard" {
+		t.Errorf("returnTo is expected to be handed back but got %s", returnTo)
+	}
+	user, err := GetUserByToken(token)
+	if err != nil || user.GetUsername() != "john" || !user.IsAdmin() {
+		t.Fatalf("token is expected to authenticate john as an admin: %v", err)
+	}
+
+	// States are used only once.
+	if _, _, err := CompleteOIDCLogin(state, code, ""); err == nil {
+		t.Errorf("login is expected to be completed only once")
+	}
+	if _, _, err := CompleteOIDCLogin("unknown", code, ""); err == nil {
+		t.Errorf("login with an unknown state is expected to fail")
+	}
+
+	// Users that leave the admin group are demoted.
+	s.SetUser(map[string]interface{}{"sub": "1", "email": "john", "email_verified": true, "groups": []string{"vpn"}})
+	state, code = oidcAuthorize(t, "")
+	if _, _, err := CompleteOIDCLogin(state, code, ""); err != nil {
+		t.Fatalf("can not complete oidc login: %v", err)
+	}
+	if user, _ := GetUser("john"); user.IsAdmin() {
+		t.Errorf("john is expected to be demoted")
+	}
+
+	// Users are linked to the subject, and the other claims can't take
+	// over the linked users.
+	if user, _ := GetUser("john"); user.GetOIDCSubject() != "1" {
+		t.Errorf("john is expected to be linked to the subject 1 but it's %s", user.GetOIDCSubject())
+	}
+	s.SetUser(map[string]interface{}{"sub": "3", "email": "john", "email_verified": true})
+	state, code = oidcAuthorize(t, "")
+	if _, _, err := CompleteOIDCLogin(state, code, ""); err == nil {
+		t.Errorf("login of another account with the same email is expected to fail")
+	}
+	s.SetUser(map[string]interface{}{"sub": "1", "email": "renamed", "email_verified": true})
+	state, code = oidcAuthorize(t, "")
+	token, _, err = CompleteOIDCLogin(state, code, "")
+	if err != nil {
+		t.Fatalf("linked user is expected to login with the subject: %v", err)
+	}
+	if user, _ := GetUserByToken(token); user == nil || user.GetUsername() != "john" {
+		t.Errorf("token is expected to authenticate john")
+	}
+
+	// Unverified emails are rejected.
+	if _, err := CreateNewUser("jack", "1234", false, 0, false, "description"); err != nil {
+		t.Fatalf("user creation failed: %v", err)
+	}
+	s.SetUser(map[string]interface{}{"sub": "4", "email": "jack", "email_verified": false})
+	state, code = oidcAuthorize(t, "")
+	if _, _, err := CompleteOIDCLogin(state, code, ""); err == nil {
+		t.Errorf("login with an unverified email is expected to fail")
+	}
+
+	// Admins need to be linked by an admin.
+	admin, err := CreateNewUser("admin", "1234", false, 0, true, "description")
+	if err != nil {
+		t.Fatalf("user creation failed: %v", err)
+	}
+	s.SetUser(map[string]interface{}{"sub": "5", "email": "admin", "email_verified": true})
+	state, code = oidcAuthorize(t, "")
+	if _, _, err := CompleteOIDCLogin(state, code, ""); err == nil {
+		t.Errorf("login of an unlinked admin is expected to fail")
+	}
+	if err := admin.LinkOIDC("1"); err == nil {
+		t.Errorf("subject is expected to be linked to only one user")
+	}
+	if err := admin.LinkOIDC("5"); err != nil {
+		t.Fatalf("can not link admin: %v", err)
+	}
+	state, code = oidcAuthorize(t, "")
+	if _, _, err := CompleteOIDCLogin(state, code, ""); err != nil {
+		t.Errorf("linked admin is expected to login: %v", err)
+	}
+
+	// Other claims can be changed by the users themselves, so they need to
+	// be linked by an admin.
+	cfg.UsernameClaim = "preferred_username"
+	if err := SetOIDCConfig(cfg); err != nil {
+		t.Fatalf("can not update oidc config: %v", err)
+	}
+	s.SetUser(map[string]interface{}{"sub": "6", "preferred_username": "jack", "email": "jack", "email_verified": true})
+	state, code = oidcAuthorize(t, "")
+	if _, _, err := CompleteOIDCLogin(state, code, ""); err == nil {
+		t.Errorf("login of an unlinked user is expected to fail unless the username claim is email")
+	}
+	jack, _ := GetUser("jack")
+	if err := jack.LinkOIDC("6"); err != nil {
+		t.Fatalf("can not link user: %v", err)
+	}
+	state, code = oidcAuthorize(t, "")
+	if _, _, err := CompleteOIDCLogin(state, code, ""); err != nil {
+		t.Errorf("linked user is expected to login: %v", err)
+	}
+
+	// Unknown and disabled users are rejected.
+	s.SetUser(map[string]interface{}{"sub": "2", "email": "jane", "email_verified": true})
+	state, code = oidcAuthorize(t, "")
+	if _, _, err := CompleteOIDCLogin(state, code, ""); err == nil {
+		t.Errorf("login of an unknown user is expected to fail")
+	}
+	user, _ = GetUser("john")
+	user.setDisabled(true)
+	s.SetUser(map[string]interface{}{"sub": "1", "email": "john", "email_verified": true})
+	state, code = oidcAuthorize(t, "")
+	if _, _, err := CompleteOIDCLogin(state, code, ""); err == nil {
+		t.Errorf("login of a disabled user is expected to fail")
+	}
+}
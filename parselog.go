@@ -18,6 +18,7 @@ type clEntry struct {
 	BytesReceived  uint64    `json:"bytes_received"`
 	BytesSent      uint64    `json:"bytes_sent"`
 	ConnectedSince time.Time `json:"connected_since"`
+	ClientID       uint64    `json:"client_id"` // Only known when it's read from the management interface.
 }
 
 // rtEntry reprsents a parsed entry that is present on OpenVPN
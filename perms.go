@@ -14,12 +14,42 @@ const (
 	RenewAnyUserPerm
 	GenConfigAnyUserPerm
 	GenConfigSelfPerm
+	DisconnectAnyUserPerm
+	SignCSRAnyUserPerm
+	SignCSRSelfPerm
+	EnrollTOTPAnyUserPerm
+	EnrollTOTPSelfPerm
+	ResetTOTPAnyUserPerm
+	ListSessionsAnyUserPerm
+	ListSessionsSelfPerm
+	RevokeSessionAnyUserPerm
+	RevokeSessionSelfPerm
+	GetSessionHistoryAnyUserPerm
+	GetSessionHistorySelfPerm
 
 	// VPN permissions
 	GetVPNStatusPerm
 	InitVPNPerm
 	UpdateVPNPerm
 	RestartVPNPerm
+	RotateCAPerm
+	TakeCAOfflinePerm
+	VerifyUserPassPerm
+
+	// Cert permissions
+	ListRevokedCertsPerm
+
+	// LDAP permissions
+	GetLDAPConfigPerm
+	UpdateLDAPConfigPerm
+	SyncLDAPPerm
+
+	// OIDC permissions
+	GetOIDCConfigPerm
+	UpdateOIDCConfigPerm
+
+	// Audit permissions
+	ListAuditLogPerm
 
 	// Network permissions
 	ListNetworksPerm
@@ -43,10 +73,32 @@ func AdminPerms() []permset.Perm {
 		RenewAnyUserPerm,
 		GenConfigAnyUserPerm,
 		GenConfigSelfPerm,
+		DisconnectAnyUserPerm,
+		SignCSRAnyUserPerm,
+		SignCSRSelfPerm,
+		EnrollTOTPAnyUserPerm,
+		EnrollTOTPSelfPerm,
+		ResetTOTPAnyUserPerm,
+		ListSessionsAnyUserPerm,
+		ListSessionsSelfPerm,
+		RevokeSessionAnyUserPerm,
+		RevokeSessionSelfPerm,
+		GetSessionHistoryAnyUserPerm,
+		GetSessionHistorySelfPerm,
 		GetVPNStatusPerm,
 		InitVPNPerm,
 		UpdateVPNPerm,
 		RestartVPNPerm,
+		RotateCAPerm,
+		TakeCAOfflinePerm,
+		VerifyUserPassPerm,
+		ListRevokedCertsPerm,
+		GetLDAPConfigPerm,
+		UpdateLDAPConfigPerm,
+		SyncLDAPPerm,
+		GetOIDCConfigPerm,
+		UpdateOIDCConfigPerm,
+		ListAuditLogPerm,
 		ListNetworksPerm,
 		CreateNetworkPerm,
 		DeleteNetworkPerm,
@@ -63,5 +115,10 @@ func UserPerms() []permset.Perm {
 		GetSelfPerm,
 		UpdateSelfPerm,
 		GenConfigSelfPerm,
+		SignCSRSelfPerm,
+		EnrollTOTPSelfPerm,
+		ListSessionsSelfPerm,
+		RevokeSessionSelfPerm,
+		GetSessionHistorySelfPerm,
 	}
 }
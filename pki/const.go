@@ -2,8 +2,15 @@ package pki
 
 // PEM encoding types
 const (
-	PEMCertificateBlockType   string = "CERTIFICATE"
-	PEMRSAPrivateKeyBlockType        = "RSA PRIVATE KEY"
-	PEMx509CRLBlockType              = "X509 CRL"
-	PEMCSRBlockType                  = "CERTIFICATE REQUEST"
+	PEMCertificateBlockType         string = "CERTIFICATE"
+	PEMRSAPrivateKeyBlockType              = "RSA PRIVATE KEY"
+	PEMECPrivateKeyBlockType               = "EC PRIVATE KEY"
+	PEMPrivateKeyBlockType                 = "PRIVATE KEY"
+	PEMEncryptedPrivateKeyBlockType        = "OVPM ENCRYPTED PRIVATE KEY"
+	PEMx509CRLBlockType                    = "X509 CRL"
+	PEMCSRBlockType                        = "CERTIFICATE REQUEST"
+	PEMStaticKeyBlockType                  = "OpenVPN Static key V1"
+	PEMTLSCryptV2ServerBlockType           = "OpenVPN tls-crypt-v2 server key"
+	PEMTLSCryptV2ClientBlockType           = "OpenVPN tls-crypt-v2 client key"
+	PEMDHParamsBlockType                   = "DH PARAMETERS"
 )
@@ -0,0 +1,122 @@
+package pki
+
+import (
+	"crypto/rand"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+)
+
+// smallPrimes are the odd primes from 5 to 2000, used for sieving the safe
+// prime candidates before the expensive primality tests.
+var smallPrimes = func() []*big.Int {
+	var primes []*big.Int
+	composite := make([]bool, 2000)
+	for i := 2; i < len(composite); i++ {
+		if composite[i] {
+			continue
+		}
+		for j := i * i; j < len(composite); j += i {
+			composite[j] = true
+		}
+		if i >= 5 {
+			primes = append(primes, big.NewInt(int64(i)))
+		}
+	}
+	return primes
+}()
+
+// dhParams is the ASN.1 structure of the PKCS #3 DH parameters.
+type dhParams struct {
+	P *big.Int
+	G *big.Int
+}
+
+// NewDHParams generates PEM encoded Diffie-Hellman parameters with a safe
+// prime of the given size and the generator 2, like `openssl dhparam` does.
+//
+// It takes minutes for the 2048 bit primes. If progress isn't nil, it's called
+// with the number of candidates tested so far.
+func NewDHParams(bits int, progress func(tries int)) (string, error) {
+	if bits < 256 {
+		return "", fmt.Errorf("dh params should be at least 256 bits")
+	}
+
+	var (
+		one    = big.NewInt(1)
+		two    = big.NewInt(2)
+		eleven = big.NewInt(11)
+		twelve = big.NewInt(12)
+		max    = new(big.Int).Lsh(one, uint(bits-1))
+		r      = new(big.Int)
+		p      = new(big.Int)
+	)
+	for tries := 1; ; tries++ {
+		if progress != nil {
+			progress(tries)
+		}
+
+		// p = 2q + 1 is a safe prime if both q and p are prime. Choosing
+		// q ≡ 11 (mod 12) makes p ≡ 23 (mod 24), so that 2 generates the
+		// subgroup of order q.
+		q, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		q.SetBit(q, bits-2, 1)
+		q.Sub(q, r.Mod(q, twelve))
+		q.Add(q, eleven)
+		p.Lsh(q, 1)
+		p.Add(p, one)
+		if p.BitLen() != bits {
+			continue
+		}
+
+		// Neither q nor p can have a small factor.
+		sieved := true
+		for _, sp := range smallPrimes {
+			r.Mod(q, sp)
+			if r.Sign() == 0 {
+				sieved = false
+				break
+			}
+			r.Lsh(r, 1)
+			r.Add(r, one)
+			if r.Cmp(sp) == 0 {
+				sieved = false
+				break
+			}
+		}
+		if !sieved {
+			continue
+		}
+
+		// A quick Fermat test on p rules out most of the remaining candidates.
+		if r.Exp(two, new(big.Int).Sub(p, one), p).Cmp(one) != 0 {
+			continue
+		}
+		if !q.ProbablyPrime(20) || !p.ProbablyPrime(20) {
+			continue
+		}
+
+		der, err := asn1.Marshal(dhParams{P: p, G: two})
+		if err != nil {
+			return "", err
+		}
+		return string(pem.EncodeToMemory(&pem.Block{Type: PEMDHParamsBlockType, Bytes: der})), nil
+	}
+}
+
+// DecodeDHParams returns the prime and the generator of the PEM encoded DH parameters.
+func DecodeDHParams(params string) (*big.Int, *big.Int, error) {
+	block, _ := pem.Decode([]byte(params))
+	if block == nil || block.Type != PEMDHParamsBlockType {
+		return nil, nil, fmt.Errorf("failed to decode dh params")
+	}
+	var v dhParams
+	if _, err := asn1.Unmarshal(block.Bytes, &v); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse dh params: %v", err)
+	}
+	return v.P, v.G, nil
+}
@@ -0,0 +1,166 @@
+package pki
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ImportCA returns a CA from an existing PEM encoded authority certificate and its private key.
+//
+// It's used to issue certificates by a corporate CA or an intermediate of it
+// instead of a self-signed one. certPEM starts with the authority certificate
+// and may be followed by its issuer certificates up to the root. If the
+// authority certificate isn't self-signed, the issuer certificates are
+// required, along with their CRLs that are checked by IssuerCRLs.
+func ImportCA(certPEM, keyPEM string) (*CA, error) {
+	certs, err := ReadCertsFromPEM(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("ca cert is not found")
+	}
+	crt := certs[0]
+
+	if !crt.BasicConstraintsValid || !crt.IsCA {
+		return nil, fmt.Errorf("cert is not a ca cert")
+	}
+	if crt.KeyUsage != 0 && crt.KeyUsage&x509.KeyUsageCertSign == 0 {
+		return nil, fmt.Errorf("ca cert is not allowed to sign certificates")
+	}
+	if now := time.Now(); now.Before(crt.NotBefore) || now.After(crt.NotAfter) {
+		return nil, fmt.Errorf("ca cert is not valid between %s and %s", crt.NotBefore, crt.NotAfter)
+	}
+
+	key, err := ParsePrivateKey(keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("can not parse ca key: %v", err)
+	}
+	pub, ok := key.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(crt.PublicKey) {
+		return nil, fmt.Errorf("ca key does not match the ca cert")
+	}
+
+	// Make sure the authority certificate chains up to a root with the given issuer certificates.
+	roots := x509.NewCertPool()
+	intermediates := x509.NewCertPool()
+	for _, c := range certs {
+		if isSelfSigned(c) {
+			roots.AddCert(c)
+		} else {
+			intermediates.AddCert(c)
+		}
+	}
+	if _, err := crt.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return nil, fmt.Errorf("can not verify the ca cert chain: %v", err)
+	}
+
+	var chain []string
+	for _, c := range certs[1:] {
+		chain = append(chain, string(pem.EncodeToMemory(&pem.Block{Type: PEMCertificateBlockType, Bytes: c.Raw})))
+	}
+
+	return &CA{
+		CertHolder: CertHolder{
+			Cert: string(pem.EncodeToMemory(&pem.Block{Type: PEMCertificateBlockType, Bytes: crt.Raw})),
+			Key:  strings.TrimSpace(keyPEM) + "\n",
+		},
+		Chain: strings.Join(chain, ""),
+	}, nil
+}
+
+// IssuerCRLs returns the CRLs in crlPEM that the issuers of the imported CA
+// publish.
+//
+// OpenVPN checks the CRLs of every certificate in the chain, so an
+// intermediate CA needs the current CRL of each of its issuers up to the
+// root. It fails if any of them is missing or expired, or if it revokes the
+// chain. A self-signed CA doesn't need any.
+func IssuerCRLs(ca *CA, crlPEM string) (string, error) {
+	certs, err := ReadCertsFromPEM(ca.Cert + ca.Chain)
+	if err != nil {
+		return "", err
+	}
+
+	var crls []*x509.RevocationList
+	var blocks [][]byte
+	rest := []byte(crlPEM)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != PEMx509CRLBlockType {
+			continue
+		}
+		crl, err := x509.ParseRevocationList(block.Bytes)
+		if err != nil {
+			return "", fmt.Errorf("can not parse crl: %v", err)
+		}
+		crls = append(crls, crl)
+		blocks = append(blocks, block.Bytes)
+	}
+
+	now := time.Now()
+	used := make(map[int]bool)
+	for _, crt := range certs {
+		if isSelfSigned(crt) {
+			continue
+		}
+		issuer := findIssuer(crt, certs)
+		if issuer == nil {
+			return "", fmt.Errorf("issuer of %s is not found in the ca chain", crt.Subject)
+		}
+		found := false
+		for i, crl := range crls {
+			if crl.CheckSignatureFrom(issuer) != nil {
+				continue
+			}
+			if now.After(crl.NextUpdate) {
+				return "", fmt.Errorf("crl of %s has expired at %s", issuer.Subject, crl.NextUpdate)
+			}
+			for _, r := range crl.RevokedCertificateEntries {
+				if r.SerialNumber.Cmp(crt.SerialNumber) == 0 {
+					return "", fmt.Errorf("%s is revoked by %s", crt.Subject, issuer.Subject)
+				}
+			}
+			used[i], found = true, true
+			break
+		}
+		if !found {
+			return "", fmt.Errorf("crl of %s is required to trust %s", issuer.Subject, crt.Subject)
+		}
+	}
+
+	var out []string
+	for i, b := range blocks {
+		if used[i] {
+			out = append(out, string(pem.EncodeToMemory(&pem.Block{Type: PEMx509CRLBlockType, Bytes: b})))
+		}
+	}
+	return strings.Join(out, ""), nil
+}
+
+// findIssuer returns the certificate in certs that signed crt, or nil.
+func findIssuer(crt *x509.Certificate, certs []*x509.Certificate) *x509.Certificate {
+	for _, c := range certs {
+		if c != crt && crt.CheckSignatureFrom(c) == nil {
+			return c
+		}
+	}
+	return nil
+}
+
+// isSelfSigned returns whether the certificate is signed by its own key.
+func isSelfSigned(crt *x509.Certificate) bool {
+	return crt.CheckSignatureFrom(crt) == nil
+}
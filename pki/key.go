@@ -0,0 +1,267 @@
+package pki
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// KeyType represents the algorithm and the size of a private key.
+type KeyType string
+
+// Supported key types.
+const (
+	KeyTypeRSA2048   KeyType = "rsa2048"
+	KeyTypeRSA3072   KeyType = "rsa3072"
+	KeyTypeRSA4096   KeyType = "rsa4096"
+	KeyTypeECDSAP256 KeyType = "ecdsa-p256"
+	KeyTypeECDSAP384 KeyType = "ecdsa-p384"
+	KeyTypeEd25519   KeyType = "ed25519"
+
+	// DefaultKeyType is used when no key type is specified.
+	DefaultKeyType = KeyTypeRSA2048
+)
+
+// KeyTypes returns the supported key types.
+func KeyTypes() []KeyType {
+	return []KeyType{
+		KeyTypeRSA2048,
+		KeyTypeRSA3072,
+		KeyTypeRSA4096,
+		KeyTypeECDSAP256,
+		KeyTypeECDSAP384,
+		KeyTypeEd25519,
+	}
+}
+
+// ParseKeyType returns the KeyType with the given name. It returns DefaultKeyType for "".
+func ParseKeyType(name string) (KeyType, error) {
+	if name == "" {
+		return DefaultKeyType, nil
+	}
+	for _, t := range KeyTypes() {
+		if string(t) == name {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("unknown key type: %s", name)
+}
+
+// IsRSA returns whether the key type is one of the RSA key types.
+func (t KeyType) IsRSA() bool {
+	switch t {
+	case KeyTypeRSA2048, KeyTypeRSA3072, KeyTypeRSA4096:
+		return true
+	}
+	return false
+}
+
+// ECDHCurve returns the OpenVPN ecdh-curve name that matches the key type.
+//
+// It returns "" for the RSA key types.
+func (t KeyType) ECDHCurve() string {
+	switch t {
+	case KeyTypeECDSAP256:
+		return "prime256v1"
+	case KeyTypeECDSAP384:
+		return "secp384r1"
+	case KeyTypeEd25519:
+		return "X25519"
+	}
+	return ""
+}
+
+// generateKey generates a new private key of the given type.
+func generateKey(t KeyType) (crypto.Signer, error) {
+	switch t {
+	case KeyTypeRSA2048:
+		return rsa.GenerateKey(rand.Reader, 2048)
+	case KeyTypeRSA3072:
+		return rsa.GenerateKey(rand.Reader, 3072)
+	case KeyTypeRSA4096:
+		return rsa.GenerateKey(rand.Reader, 4096)
+	case KeyTypeECDSAP256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case KeyTypeECDSAP384:
+		return ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case KeyTypeEd25519:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	}
+	return nil, fmt.Errorf("unknown key type: %s", t)
+}
+
+// encodePrivateKey PEM encodes the given private key as PKCS#8 regardless of
+// its type.
+//
+// The existing PKCS#1 encoded RSA keys are still parsed by ParsePrivateKey.
+func encodePrivateKey(key crypto.Signer) (string, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", fmt.Errorf("can not marshal private key: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  PEMPrivateKeyBlockType,
+		Bytes: der,
+	})), nil
+}
+
+// ParsePrivateKey parses a PEM encoded PKCS#1, SEC 1 or PKCS#8 private key.
+func ParsePrivateKey(keyPEM string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(keyPEM))
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode private key")
+	}
+
+	switch block.Type {
+	case PEMRSAPrivateKeyBlockType:
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case PEMECPrivateKeyBlockType:
+		return x509.ParseECPrivateKey(block.Bytes)
+	case PEMPrivateKeyBlockType:
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type: %T", key)
+		}
+		return signer, nil
+	}
+	return nil, fmt.Errorf("unsupported private key block type: %s", block.Type)
+}
+
+// keyTypeOf returns the KeyType of the given public key.
+func keyTypeOf(pub crypto.PublicKey) (KeyType, error) {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		switch k.N.BitLen() {
+		case 2048:
+			return KeyTypeRSA2048, nil
+		case 3072:
+			return KeyTypeRSA3072, nil
+		case 4096:
+			return KeyTypeRSA4096, nil
+		}
+		// Keys generated before the key types were introduced are 2024 bits.
+		return KeyTypeRSA2048, nil
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return KeyTypeECDSAP256, nil
+		case elliptic.P384():
+			return KeyTypeECDSAP384, nil
+		}
+	case ed25519.PublicKey:
+		return KeyTypeEd25519, nil
+	}
+	return "", fmt.Errorf("unsupported public key type: %T", pub)
+}
+
+// CertKeyType returns the KeyType of the given PEM encoded certificate's key.
+func CertKeyType(certPEM string) (KeyType, error) {
+	crt, err := ReadCertFromPEM(certPEM)
+	if err != nil {
+		return "", err
+	}
+	return keyTypeOf(crt.PublicKey)
+}
+
+// scrypt parameters to derive the encryption key from a passphrase.
+const (
+	_ScryptN      = 1 << 15
+	_ScryptR      = 8
+	_ScryptP      = 1
+	_ScryptKeyLen = 32
+	_SaltLength   = 16
+)
+
+// EncryptPrivateKey encrypts the given PEM encoded private key with the passphrase
+// and returns it PEM encoded.
+//
+// The encryption key is derived from the passphrase by scrypt and the private
+// key is sealed with AES-256-GCM.
+func EncryptPrivateKey(keyPEM string, passphrase []byte) (string, error) {
+	if len(passphrase) == 0 {
+		return "", fmt.Errorf("passphrase can not be empty")
+	}
+	if _, err := ParsePrivateKey(keyPEM); err != nil {
+		return "", err
+	}
+
+	salt := make([]byte, _SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	aead, err := newKeyAEAD(passphrase, salt)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type: PEMEncryptedPrivateKeyBlockType,
+		Headers: map[string]string{
+			"KDF":  "scrypt",
+			"Salt": hex.EncodeToString(salt),
+		},
+		Bytes: aead.Seal(nonce, nonce, []byte(keyPEM), nil),
+	})), nil
+}
+
+// DecryptPrivateKey decrypts a private key encrypted by EncryptPrivateKey.
+func DecryptPrivateKey(encPEM string, passphrase []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(encPEM))
+	if block == nil || block.Type != PEMEncryptedPrivateKeyBlockType {
+		return nil, fmt.Errorf("failed to decode encrypted private key")
+	}
+	if kdf := block.Headers["KDF"]; kdf != "scrypt" {
+		return nil, fmt.Errorf("unsupported key derivation function: %s", kdf)
+	}
+	salt, err := hex.DecodeString(block.Headers["Salt"])
+	if err != nil {
+		return nil, fmt.Errorf("can not decode salt: %v", err)
+	}
+
+	aead, err := newKeyAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(block.Bytes) < aead.NonceSize() {
+		return nil, fmt.Errorf("encrypted private key is too short")
+	}
+	nonce, ciphertext := block.Bytes[:aead.NonceSize()], block.Bytes[aead.NonceSize():]
+	keyPEM, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("can not decrypt private key, passphrase might be wrong")
+	}
+	return ParsePrivateKey(string(keyPEM))
+}
+
+// newKeyAEAD derives an AES-256-GCM cipher from the passphrase and the salt.
+func newKeyAEAD(passphrase, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, salt, _ScryptN, _ScryptR, _ScryptP, _ScryptKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("can not derive key: %v", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,38 @@
+package pki
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// EncodePKCS12 bundles the PEM encoded private key, the certificate and the CA
+// certificates into a password protected PKCS#12 archive.
+//
+// The archive is encrypted with the legacy algorithms (3DES and SHA-1) since
+// macOS keychains and older Windows certificate stores can't import the
+// modern ones.
+func EncodePKCS12(keyPEM, certPEM, caPEM string, password string) ([]byte, error) {
+	if password == "" {
+		return nil, fmt.Errorf("password can not be empty")
+	}
+	key, err := ParsePrivateKey(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	crt, err := ReadCertFromPEM(certPEM)
+	if err != nil || crt == nil {
+		return nil, fmt.Errorf("can not parse certificate: %v", err)
+	}
+	caCerts, err := ReadCertsFromPEM(caPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	pfx, err := pkcs12.Legacy.WithRand(rand.Reader).Encode(key, crt, caCerts, password)
+	if err != nil {
+		return nil, fmt.Errorf("can not encode pkcs12: %v", err)
+	}
+	return pfx, nil
+}
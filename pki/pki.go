@@ -2,6 +2,7 @@
 package pki
 
 import (
+	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -17,7 +18,6 @@ import (
 
 const (
 	_CrtExpireYears = 10
-	_CrtKeyLength   = 2024
 )
 
 // CertHolder encapsulates a public certificate and the corresponding private key.
@@ -29,19 +29,52 @@ type CertHolder struct {
 // CA is a special type of CertHolder that also has a CSR in it.
 type CA struct {
 	CertHolder
-	CSR string
+	CSR   string
+	Chain string // PEM Encoded issuer certificates of an imported CA, if any.
+
+	// Signer signs on behalf of the CA when its private key is kept offline,
+	// e.g. in an encrypted file or an external signer. If it's set, Key is
+	// not used.
+	Signer crypto.Signer
+}
+
+// signer returns the signer of the CA's private key.
+func (ca *CA) signer() (crypto.Signer, error) {
+	if ca.Signer != nil {
+		return ca.Signer, nil
+	}
+	return ParsePrivateKey(ca.Key)
+}
+
+// CertOptions holds the optional parameters of the generated certificates.
+type CertOptions struct {
+	Validity time.Duration // Defaults to _CrtExpireYears if 0.
+	KeyType  KeyType       // Defaults to DefaultKeyType if "".
+}
+
+func (o CertOptions) keyType() KeyType {
+	if o.KeyType == "" {
+		return DefaultKeyType
+	}
+	return o.KeyType
 }
 
 // NewCA returns a newly generated CA.
 //
 // This will generate a public/private RSA keypair and a authority certificate signed by itself.
 func NewCA() (*CA, error) {
+	return NewCAWithOptions(CertOptions{})
+}
+
+// NewCAWithOptions is like NewCA but the key type and the validity of the
+// authority certificate are taken from the given options.
+func NewCAWithOptions(opts CertOptions) (*CA, error) {
 	type basicConstraints struct {
 		IsCA       bool `asn1:"optional"`
 		MaxPathLen int  `asn1:"optional,default:-1"`
 	}
 
-	key, err := rsa.GenerateKey(rand.Reader, _CrtKeyLength)
+	key, err := generateKey(opts.keyType())
 	if err != nil {
 		return nil, fmt.Errorf("private key cannot be created: %s", err)
 	}
@@ -53,8 +86,7 @@ func NewCA() (*CA, error) {
 
 	names := pkix.Name{CommonName: "CA"}
 	var csrTemplate = x509.CertificateRequest{
-		Subject:            names,
-		SignatureAlgorithm: x509.SHA512WithRSA,
+		Subject: names,
 		ExtraExtensions: []pkix.Extension{
 			{
 				Id:       asn1.ObjectIdentifier{2, 5, 29, 19},
@@ -64,6 +96,10 @@ func NewCA() (*CA, error) {
 		},
 	}
 
+	if opts.keyType().IsRSA() {
+		csrTemplate.SignatureAlgorithm = x509.SHA512WithRSA
+	}
+
 	csrCertificate, err := x509.CreateCertificateRequest(rand.Reader, &csrTemplate, key)
 	if err != nil {
 		return nil, fmt.Errorf("can not create certificate request: %s", err)
@@ -85,7 +121,7 @@ func NewCA() (*CA, error) {
 		SerialNumber:          serial,
 		Subject:               names,
 		NotBefore:             now.Add(-10 * time.Minute).UTC(),
-		NotAfter:              now.Add(time.Duration(24*365*_CrtExpireYears) * time.Hour).UTC(),
+		NotAfter:              now.Add(certValidity(opts.Validity)).UTC(),
 		BasicConstraintsValid: true,
 		IsCA:     true,
 		KeyUsage: x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
@@ -93,23 +129,23 @@ func NewCA() (*CA, error) {
 	}
 
 	// Sign the certificate authority
-	certificate, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	certificate, err := x509.CreateCertificate(rand.Reader, &template, &template, key.Public(), key)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate certificate error: %s", err)
 	}
 
 	var request bytes.Buffer
-	var privateKey bytes.Buffer
 	if err := pem.Encode(&request, &pem.Block{Type: PEMCertificateBlockType, Bytes: certificate}); err != nil {
 		return nil, err
 	}
-	if err := pem.Encode(&privateKey, &pem.Block{Type: PEMRSAPrivateKeyBlockType, Bytes: x509.MarshalPKCS1PrivateKey(key)}); err != nil {
+	privateKey, err := encodePrivateKey(key)
+	if err != nil {
 		return nil, err
 	}
 
 	return &CA{
 		CertHolder: CertHolder{
-			Key:  privateKey.String(),
+			Key:  privateKey,
 			Cert: request.String(),
 		},
 		CSR: string(csr),
@@ -119,52 +155,114 @@ func NewCA() (*CA, error) {
 
 // NewServerCertHolder generates a RSA key-pair and a x509 certificate signed by the CA for the server.
 func NewServerCertHolder(ca *CA) (*CertHolder, error) {
-	return newCert(ca, true, "localhost")
+	return newCert(ca, true, "localhost", CertOptions{})
+}
+
+// NewServerCertHolderWithOptions is like NewServerCertHolder but the key type and the validity are taken from the given options.
+func NewServerCertHolderWithOptions(ca *CA, opts CertOptions) (*CertHolder, error) {
+	return newCert(ca, true, "localhost", opts)
 }
 
 // NewClientCertHolder generates a RSA key-pair and a x509 certificate signed by the CA for the client.
 func NewClientCertHolder(ca *CA, username string) (*CertHolder, error) {
-	return newCert(ca, false, username)
+	return newCert(ca, false, username, CertOptions{})
 }
 
-// newCert generates a RSA key-pair and a x509 certificate signed by the CA.
-func newCert(ca *CA, server bool, cn string) (*CertHolder, error) {
-	// Get CA private key
-	block, _ := pem.Decode([]byte(ca.Key))
-	if block == nil {
-		return nil, fmt.Errorf("failed to parse ca private key")
+// NewClientCertHolderWithOptions is like NewClientCertHolder but the key type and the validity are taken from the given options.
+func NewClientCertHolderWithOptions(ca *CA, username string, opts CertOptions) (*CertHolder, error) {
+	return newCert(ca, false, username, opts)
+}
+
+// certValidity returns the given validity or the default one if it's not set.
+func certValidity(validity time.Duration) time.Duration {
+	if validity <= 0 {
+		return time.Duration(24*365*_CrtExpireYears) * time.Hour
+	}
+	return validity
+}
+
+// newCert generates a key-pair and a x509 certificate signed by the CA.
+func newCert(ca *CA, server bool, cn string, opts CertOptions) (*CertHolder, error) {
+	// Create new cert's key
+	key, err := generateKey(opts.keyType())
+	if err != nil {
+		return nil, fmt.Errorf("private key cannot be created: %s", err)
 	}
 
-	caKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	cert, err := signCert(ca, server, cn, key.Public(), opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse ca private key: %s", err)
+		return nil, err
 	}
 
-	caCert, err := ReadCertFromPEM(ca.Cert)
+	priKeyPem, err := encodePrivateKey(key)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse ca cert: %v", err)
+		return nil, err
 	}
 
-	// Create new cert's key
-	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	return &CertHolder{
+		Key:  priKeyPem,
+		Cert: cert,
+	}, nil
+}
+
+// SignClientCSR issues a client certificate for the given PEM encoded PKCS#10
+// certificate request signed by the CA and returns it PEM encoded.
+//
+// The common name of the request should be the username. Only the public key
+// is taken from the request, the rest of the certificate is the same as the
+// ones issued by NewClientCertHolder.
+func SignClientCSR(ca *CA, csrPEM string, username string, opts CertOptions) (string, error) {
+	block, _ := pem.Decode([]byte(csrPEM))
+	if block == nil || (block.Type != PEMCSRBlockType && block.Type != "NEW "+PEMCSRBlockType) {
+		return "", fmt.Errorf("failed to decode certificate request")
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
 	if err != nil {
-		return nil, fmt.Errorf("private key cannot be created: %s", err)
+		return "", fmt.Errorf("can not parse certificate request: %v", err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return "", fmt.Errorf("invalid certificate request signature: %v", err)
+	}
+	if csr.Subject.CommonName != username {
+		return "", fmt.Errorf("certificate request common name '%s' should be the username '%s'", csr.Subject.CommonName, username)
+	}
+	if _, err := keyTypeOf(csr.PublicKey); err != nil {
+		return "", err
+	}
+	if k, ok := csr.PublicKey.(*rsa.PublicKey); ok && k.N.BitLen() < 2048 {
+		return "", fmt.Errorf("rsa keys should be at least 2048 bits")
+	}
+
+	return signCert(ca, false, username, csr.PublicKey, opts)
+}
+
+// signCert issues a x509 certificate for the given public key signed by the CA and returns it PEM encoded.
+func signCert(ca *CA, server bool, cn string, pub crypto.PublicKey, opts CertOptions) (string, error) {
+	// Get CA private key
+	caKey, err := ca.signer()
+	if err != nil {
+		return "", fmt.Errorf("failed to parse ca private key: %s", err)
+	}
+
+	caCert, err := ReadCertFromPEM(ca.Cert)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse ca cert: %v", err)
 	}
 
 	serial, err := rand.Int(rand.Reader, (&big.Int{}).Exp(big.NewInt(2), big.NewInt(159), nil))
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
 	val, err := asn1.Marshal(asn1.BitString{Bytes: []byte{0x80}, BitLength: 2}) // setting nsCertType to Client Type
 	if err != nil {
-		return nil, fmt.Errorf("can not marshal nsCertType: %v", err)
+		return "", fmt.Errorf("can not marshal nsCertType: %v", err)
 	}
 
 	now := time.Now()
 	tml := x509.Certificate{
 		NotBefore:    now.Add(-10 * time.Minute).UTC(),
-		NotAfter:     now.Add(time.Duration(24*365*_CrtExpireYears) * time.Hour).UTC(),
+		NotAfter:     now.Add(certValidity(opts.Validity)).UTC(),
 		SerialNumber: serial,
 		Subject: pkix.Name{
 			CommonName:   cn,
@@ -182,63 +280,89 @@ func newCert(ca *CA, server bool, cn string) (*CertHolder, error) {
 	}
 
 	if server {
-		tml.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyAgreement | x509.KeyUsageKeyEncipherment
+		if _, ok := pub.(*rsa.PublicKey); ok {
+			tml.KeyUsage |= x509.KeyUsageKeyEncipherment
+		}
 		tml.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
 		val, err := asn1.Marshal(asn1.BitString{Bytes: []byte{0x40}, BitLength: 2}) // setting nsCertType to Server Type
 		if err != nil {
-			return nil, fmt.Errorf("can not marshal nsCertType: %v", err)
+			return "", fmt.Errorf("can not marshal nsCertType: %v", err)
 		}
 		tml.ExtraExtensions[0].Id = asn1.ObjectIdentifier{2, 16, 840, 1, 113730, 1, 1}
 		tml.ExtraExtensions[0].Value = val
 	}
 
 	// Sign with CA's private key
-	cert, err := x509.CreateCertificate(rand.Reader, &tml, caCert, &key.PublicKey, caKey)
+	cert, err := x509.CreateCertificate(rand.Reader, &tml, caCert, pub, caKey)
 	if err != nil {
-		return nil, fmt.Errorf("certificate cannot be created: %s", err)
+		return "", fmt.Errorf("certificate cannot be created: %s", err)
 	}
 
-	priKeyPem := pem.EncodeToMemory(&pem.Block{
-		Type:  PEMRSAPrivateKeyBlockType,
-		Bytes: x509.MarshalPKCS1PrivateKey(key),
-	})
-
 	certPem := pem.EncodeToMemory(&pem.Block{
 		Type:  PEMCertificateBlockType,
 		Bytes: cert,
 	})
-
-	return &CertHolder{
-		Key:  string(priKeyPem[:]),
-		Cert: string(certPem[:]),
-	}, nil
+	return string(certPem[:]), nil
 }
 
 // NewCRL takes in a list of certificate serial numbers to-be-revoked and a CA then makes a PEM encoded CRL and returns it as a string.
+//
+// Serials are stamped with the current time and no reason. Use NewCRLWithNumber
+// to issue a CRL with the actual revocation times and reasons.
 func NewCRL(ca *CA, serials ...*big.Int) (string, error) {
+	var revoked []RevokedCert
+	for _, serial := range serials {
+		revoked = append(revoked, RevokedCert{
+			SerialNumber: serial,
+			RevokedAt:    time.Now(),
+		})
+	}
+	return NewCRLWithNumber(ca, nil, revoked...)
+}
+
+// NewCRLWithNumber makes a PEM encoded CRL that carries the given CRL number
+// and revoked certificates then returns it as a string.
+//
+// If number is nil, the CRL is issued without a CRL number.
+func NewCRLWithNumber(ca *CA, number *big.Int, revoked ...RevokedCert) (string, error) {
 	caCrt, err := ReadCertFromPEM(ca.Cert)
 	if err != nil {
 		return "", err
 	}
 
-	block, _ := pem.Decode([]byte(ca.Key))
-	if block == nil {
-		return "", fmt.Errorf("failed to parse ca private key")
-	}
-
-	priv, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	priv, err := ca.signer()
 	if err != nil {
 		return "", fmt.Errorf("failed to parse ca private key: %s", err)
 	}
 	var revokedCertList []pkix.RevokedCertificate
-	for _, serial := range serials {
+	for _, r := range revoked {
 		revokedCert := pkix.RevokedCertificate{
-			SerialNumber:   serial,
-			RevocationTime: time.Now().UTC(),
+			SerialNumber:   r.SerialNumber,
+			RevocationTime: r.RevokedAt.UTC(),
+		}
+		if r.Reason != ReasonUnspecified {
+			val, err := asn1.Marshal(asn1.Enumerated(r.Reason))
+			if err != nil {
+				return "", fmt.Errorf("can not marshal revocation reason: %v", err)
+			}
+			revokedCert.Extensions = []pkix.Extension{{Id: oidExtensionReasonCode, Value: val}}
 		}
 		revokedCertList = append(revokedCertList, revokedCert)
 	}
-	crl, err := caCrt.CreateCRL(rand.Reader, priv, revokedCertList, time.Now().UTC(), time.Now().Add(365*24*60*time.Minute).UTC())
+
+	now := time.Now()
+	var crl []byte
+	if number != nil && len(caCrt.SubjectKeyId) > 0 {
+		crl, err = x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+			Number:              number,
+			ThisUpdate:          now.UTC(),
+			NextUpdate:          now.Add(365 * 24 * 60 * time.Minute).UTC(),
+			RevokedCertificates: revokedCertList,
+		}, caCrt, priv)
+	} else {
+		// CAs without a subject key id can't issue CRLs with a CRL number.
+		crl, err = caCrt.CreateCRL(rand.Reader, priv, revokedCertList, now.UTC(), now.Add(365*24*60*time.Minute).UTC())
+	}
 	if err != nil {
 		return "", err
 	}
@@ -249,13 +373,39 @@ func NewCRL(ca *CA, serials ...*big.Int) (string, error) {
 	})
 
 	return string(crlPem[:]), nil
-
 }
 
 // ReadCertFromPEM decodes a PEM encoded string into a x509.Certificate.
 func ReadCertFromPEM(s string) (*x509.Certificate, error) {
 	block, _ := pem.Decode([]byte(s))
-	var cert *x509.Certificate
-	cert, _ = x509.ParseCertificate(block.Bytes)
+	if block == nil {
+		return nil, fmt.Errorf("can not decode pem: no pem block is found")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("can not parse cert: %v", err)
+	}
 	return cert, nil
 }
+
+// ReadCertsFromPEM decodes all of the certificates in a PEM encoded string.
+func ReadCertsFromPEM(s string) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	rest := []byte(s)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != PEMCertificateBlockType {
+			continue
+		}
+		crt, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("can not parse certificate: %v", err)
+		}
+		certs = append(certs, crt)
+	}
+	return certs, nil
+}
@@ -1,11 +1,23 @@
 package pki_test
 
 import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
 	"encoding/pem"
 	"fmt"
 	"math/big"
 	"math/rand"
+	"strings"
 	"testing"
 	"time"
 
@@ -38,7 +50,7 @@ func TestNewCA(t *testing.T) {
 	}{
 		{"ca.CSR", ca.CSR, pki.PEMCSRBlockType},
 		{"ca.CertHolder.Cert", ca.CertHolder.Cert, pki.PEMCertificateBlockType},
-		{"ca.CertHolder.Key", ca.CertHolder.Key, pki.PEMRSAPrivateKeyBlockType},
+		{"ca.CertHolder.Key", ca.CertHolder.Key, pki.PEMPrivateKeyBlockType},
 	}
 
 	// Is PEM encoded properly?
@@ -87,7 +99,7 @@ func TestNewCertHolders(t *testing.T) {
 			typ   string // expected pem block type
 		}{
 			{tt.name + "CertHolder.Cert", tt.certHolder.Cert, pki.PEMCertificateBlockType},
-			{tt.name + "CertHolder.Key", tt.certHolder.Key, pki.PEMRSAPrivateKeyBlockType},
+			{tt.name + "CertHolder.Key", tt.certHolder.Key, pki.PEMPrivateKeyBlockType},
 		}
 
 		// Is PEM encoded properly?
@@ -101,6 +113,154 @@ func TestNewCertHolders(t *testing.T) {
 
 }
 
+// TestCertValidity tests that the issued certificates respect the given validity.
+func TestCertValidity(t *testing.T) {
+	// Initialize:
+	ca, err := pki.NewCAWithOptions(pki.CertOptions{Validity: 30 * 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("can not create CA in test: %v", err)
+	}
+
+	// Prepare:
+	sch, err := pki.NewServerCertHolderWithOptions(ca, pki.CertOptions{Validity: 20 * 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("can not create server cert holder: %v", err)
+	}
+	cch, err := pki.NewClientCertHolderWithOptions(ca, "test-user", pki.CertOptions{Validity: 10 * 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("can not create client cert holder: %v", err)
+	}
+	dch, err := pki.NewClientCertHolder(ca, "test-user")
+	if err != nil {
+		t.Fatalf("can not create client cert holder: %v", err)
+	}
+
+	// Test:
+	var validitytests = []struct {
+		name     string
+		cert     string
+		validity time.Duration
+	}{
+		{"ca", ca.Cert, 30 * 24 * time.Hour},
+		{"server", sch.Cert, 20 * 24 * time.Hour},
+		{"client", cch.Cert, 10 * 24 * time.Hour},
+		{"default", dch.Cert, 10 * 365 * 24 * time.Hour},
+	}
+	for _, tt := range validitytests {
+		crt, err := pki.ReadCertFromPEM(tt.cert)
+		if err != nil {
+			t.Fatalf("can not read '%s' cert: %v", tt.name, err)
+		}
+		expected := time.Now().Add(tt.validity)
+		if crt.NotAfter.Sub(expected) > time.Minute || expected.Sub(crt.NotAfter) > time.Minute {
+			t.Errorf("'%s' cert is expected to expire at %v but it expires at %v", tt.name, expected, crt.NotAfter)
+		}
+	}
+}
+
+// TestKeyTypes tests that every key type can issue certificates and CRLs.
+func TestKeyTypes(t *testing.T) {
+	for _, keyType := range pki.KeyTypes() {
+		// Initialize:
+		opts := pki.CertOptions{KeyType: keyType}
+		ca, err := pki.NewCAWithOptions(opts)
+		if err != nil {
+			t.Fatalf("can not create %s CA: %v", keyType, err)
+		}
+
+		// Prepare:
+		sch, err := pki.NewServerCertHolderWithOptions(ca, opts)
+		if err != nil {
+			t.Fatalf("can not create %s server cert holder: %v", keyType, err)
+		}
+		cch, err := pki.NewClientCertHolderWithOptions(ca, "test-user", opts)
+		if err != nil {
+			t.Fatalf("can not create %s client cert holder: %v", keyType, err)
+		}
+
+		// Test:
+		caCrt, _ := pki.ReadCertFromPEM(ca.Cert)
+		for _, ch := range []*pki.CertHolder{&ca.CertHolder, sch, cch} {
+			if kt, err := pki.CertKeyType(ch.Cert); err != nil || kt != keyType {
+				t.Errorf("cert key type is expected to be %s but it's %s: %v", keyType, kt, err)
+			}
+			if _, err := pki.ParsePrivateKey(ch.Key); err != nil {
+				t.Errorf("can not parse %s private key: %v", keyType, err)
+			}
+			crt, _ := pki.ReadCertFromPEM(ch.Cert)
+			if err := crt.CheckSignatureFrom(caCrt); err != nil && ch != &ca.CertHolder {
+				t.Errorf("%s cert is not signed by the ca: %v", keyType, err)
+			}
+		}
+
+		crl, err := pki.NewCRLWithNumber(ca, big.NewInt(1), pki.RevokedCert{SerialNumber: big.NewInt(1), RevokedAt: time.Now()})
+		if err != nil {
+			t.Fatalf("can not create %s crl: %v", keyType, err)
+		}
+		block, _ := pem.Decode([]byte(crl))
+		rl, err := x509.ParseRevocationList(block.Bytes)
+		if err != nil {
+			t.Fatalf("can not parse %s crl: %v", keyType, err)
+		}
+		if err := rl.CheckSignatureFrom(caCrt); err != nil {
+			t.Errorf("%s crl is not signed by the ca: %v", keyType, err)
+		}
+	}
+}
+
+// TestParsePrivateKey tests that PKCS#1, SEC 1 and PKCS#8 encoded keys can be parsed.
+func TestParsePrivateKey(t *testing.T) {
+	// Initialize:
+	ca, err := pki.NewCAWithOptions(pki.CertOptions{KeyType: pki.KeyTypeECDSAP256})
+	if err != nil {
+		t.Fatalf("can not create CA: %v", err)
+	}
+	key, err := pki.ParsePrivateKey(ca.Key)
+	if err != nil {
+		t.Fatalf("can not parse private key: %v", err)
+	}
+
+	// Prepare:
+	ecKey, err := x509.MarshalECPrivateKey(key.(*ecdsa.PrivateKey))
+	if err != nil {
+		t.Fatalf("can not marshal ec private key: %v", err)
+	}
+	rsaCA, err := pki.NewCA()
+	if err != nil {
+		t.Fatalf("can not create CA: %v", err)
+	}
+	rsaKey, err := pki.ParsePrivateKey(rsaCA.Key)
+	if err != nil {
+		t.Fatalf("can not parse private key: %v", err)
+	}
+	// Keys that were generated before the keys are PKCS#8 encoded.
+	legacyKey := string(pem.EncodeToMemory(&pem.Block{Type: pki.PEMRSAPrivateKeyBlockType, Bytes: x509.MarshalPKCS1PrivateKey(rsaKey.(*rsa.PrivateKey))}))
+
+	// Test:
+	var parsetests = []struct {
+		name string
+		key  string
+		ok   bool
+	}{
+		{"pkcs1", legacyKey, true},
+		{"pkcs8", ca.Key, true},
+		{"rsa pkcs8", rsaCA.Key, true},
+		{"sec1", string(pem.EncodeToMemory(&pem.Block{Type: pki.PEMECPrivateKeyBlockType, Bytes: ecKey})), true},
+		{"certificate", ca.Cert, false},
+		{"garbage", "garbage", false},
+	}
+	for _, tt := range parsetests {
+		_, err := pki.ParsePrivateKey(tt.key)
+		if (err == nil) != tt.ok {
+			t.Errorf("ParsePrivateKey(%s) is expected to succeed=%t but got err: %v", tt.name, tt.ok, err)
+		}
+	}
+
+	if _, err := pki.ParseKeyType("dsa1024"); err == nil {
+		t.Errorf("unknown key type is expected to fail")
+	}
+}
+
 func TestNewCRL(t *testing.T) {
 	// Initialize:
 	max := 5
@@ -167,6 +327,363 @@ func TestNewCRL(t *testing.T) {
 	}
 }
 
+func TestNewCRLWithNumber(t *testing.T) {
+	// Initialize:
+	ca, _ := pki.NewCA()
+	ch, _ := pki.NewClientCertHolder(ca, "user")
+	revokedAt := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+
+	// Prepare:
+	crl, err := pki.NewCRLWithNumber(ca, big.NewInt(42), pki.RevokedCert{
+		SerialNumber: getSerial(t, ch.Cert),
+		RevokedAt:    revokedAt,
+		Reason:       pki.ReasonKeyCompromise,
+	})
+	if err != nil {
+		t.Fatalf("crl can not be created: %v", err)
+	}
+
+	// Test:
+	block, _ := pem.Decode([]byte(crl))
+	rl, err := x509.ParseRevocationList(block.Bytes)
+	if err != nil {
+		t.Fatalf("crl can not be parsed: %v", err)
+	}
+	if rl.Number == nil || rl.Number.Cmp(big.NewInt(42)) != 0 {
+		t.Errorf("crl number is expected to be 42 but it's %v", rl.Number)
+	}
+	if len(rl.RevokedCertificateEntries) != 1 {
+		t.Fatalf("1 revoked cert is expected but there are %d", len(rl.RevokedCertificateEntries))
+	}
+	entry := rl.RevokedCertificateEntries[0]
+	if !entry.RevocationTime.Equal(revokedAt) {
+		t.Errorf("revocation time is expected to be %v but it's %v", revokedAt, entry.RevocationTime)
+	}
+	if pki.RevocationReason(entry.ReasonCode) != pki.ReasonKeyCompromise {
+		t.Errorf("revocation reason is expected to be %s but it's %s", pki.ReasonKeyCompromise, pki.RevocationReason(entry.ReasonCode))
+	}
+}
+
+func TestParseRevocationReason(t *testing.T) {
+	for _, r := range []pki.RevocationReason{pki.ReasonKeyCompromise, pki.ReasonSuperseded, pki.ReasonCessationOfOperation} {
+		parsed, err := pki.ParseRevocationReason(r.String())
+		if err != nil || parsed != r {
+			t.Errorf("'%s' is expected to be parsed as %d but it's %d: %v", r, r, parsed, err)
+		}
+	}
+	if _, err := pki.ParseRevocationReason("bogus"); err == nil {
+		t.Errorf("unknown reasons are expected to fail")
+	}
+}
+
+// TestImportCA tests that only the valid CAs can be imported.
+func TestImportCA(t *testing.T) {
+	// Initialize:
+	root, err := pki.NewCA()
+	if err != nil {
+		t.Fatalf("can not create CA: %v", err)
+	}
+	other, err := pki.NewCA()
+	if err != nil {
+		t.Fatalf("can not create CA: %v", err)
+	}
+
+	// Prepare:
+	interCert, interKey := newIntermediateCA(t, root, true)
+	nonCACert, nonCAKey := newIntermediateCA(t, root, false)
+	client, err := pki.NewClientCertHolder(root, "test-user")
+	if err != nil {
+		t.Fatalf("can not create client cert holder: %v", err)
+	}
+
+	// Test:
+	var importtests = []struct {
+		name  string
+		cert  string
+		key   string
+		chain string
+		ok    bool
+	}{
+		{"self-signed", root.Cert, root.Key, "", true},
+		{"intermediate", interCert + root.Cert, interKey, root.Cert, true},
+		{"intermediate without chain", interCert, interKey, "", false},
+		{"intermediate with wrong chain", interCert + other.Cert, interKey, "", false},
+		{"key mismatch", root.Cert, other.Key, "", false},
+		{"not a ca", nonCACert + root.Cert, nonCAKey, "", false},
+		{"client cert", client.Cert + root.Cert, client.Key, "", false},
+		{"no cert", "", root.Key, "", false},
+	}
+	for _, tt := range importtests {
+		ca, err := pki.ImportCA(tt.cert, tt.key)
+		if (err == nil) != tt.ok {
+			t.Errorf("ImportCA(%s) is expected to succeed=%t but got err: %v", tt.name, tt.ok, err)
+			continue
+		}
+		if !tt.ok {
+			continue
+		}
+		if ca.Chain != tt.chain {
+			t.Errorf("ImportCA(%s) chain is expected to be %q but it's %q", tt.name, tt.chain, ca.Chain)
+		}
+
+		// Imported CA should be able to issue certificates.
+		cch, err := pki.NewClientCertHolder(ca, "test-user")
+		if err != nil {
+			t.Fatalf("can not issue a cert by the imported ca (%s): %v", tt.name, err)
+		}
+		caCrt, _ := pki.ReadCertFromPEM(ca.Cert)
+		crt, _ := pki.ReadCertFromPEM(cch.Cert)
+		if err := crt.CheckSignatureFrom(caCrt); err != nil {
+			t.Errorf("cert is not signed by the imported ca (%s): %v", tt.name, err)
+		}
+	}
+}
+
+// TestIssuerCRLs tests that an imported intermediate CA requires the
+// current CRLs of its issuers.
+func TestIssuerCRLs(t *testing.T) {
+	// Initialize:
+	root, err := pki.NewCA()
+	if err != nil {
+		t.Fatalf("can not create CA: %v", err)
+	}
+	other, err := pki.NewCA()
+	if err != nil {
+		t.Fatalf("can not create CA: %v", err)
+	}
+
+	// Prepare:
+	interCert, interKey := newIntermediateCA(t, root, true)
+	inter, err := pki.ImportCA(interCert+root.Cert, interKey)
+	if err != nil {
+		t.Fatalf("can not import intermediate ca: %v", err)
+	}
+	interCrt, _ := pki.ReadCertFromPEM(interCert)
+	rootCRL, _ := pki.NewCRLWithNumber(root, big.NewInt(1))
+	otherCRL, _ := pki.NewCRLWithNumber(other, big.NewInt(1))
+	revokingCRL, _ := pki.NewCRL(root, interCrt.SerialNumber)
+
+	// Test:
+	var crltests = []struct {
+		name string
+		ca   *pki.CA
+		crl  string
+		want string
+		ok   bool
+	}{
+		{"self-signed", root, "", "", true},
+		{"intermediate", inter, otherCRL + rootCRL, rootCRL, true},
+		{"intermediate without crl", inter, "", "", false},
+		{"intermediate with wrong crl", inter, otherCRL, "", false},
+		{"revoked intermediate", inter, revokingCRL, "", false},
+		{"garbage", inter, "-----BEGIN X509 CRL-----\nAAAA\n-----END X509 CRL-----\n", "", false},
+	}
+	for _, tt := range crltests {
+		crls, err := pki.IssuerCRLs(tt.ca, tt.crl)
+		if (err == nil) != tt.ok {
+			t.Errorf("IssuerCRLs(%s) is expected to succeed=%t but got err: %v", tt.name, tt.ok, err)
+			continue
+		}
+		if tt.ok && crls != tt.want {
+			t.Errorf("IssuerCRLs(%s) is expected to return %q but it's %q", tt.name, tt.want, crls)
+		}
+	}
+}
+
+// newIntermediateCA returns a PEM encoded cert and key signed by the given CA.
+func newIntermediateCA(t *testing.T, ca *pki.CA, isCA bool) (string, string) {
+	t.Helper()
+	caKey, err := pki.ParsePrivateKey(ca.Key)
+	if err != nil {
+		t.Fatalf("can not parse ca key: %v", err)
+	}
+	caCrt, _ := pki.ReadCertFromPEM(ca.Cert)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), crand.Reader)
+	if err != nil {
+		t.Fatalf("can not generate key: %v", err)
+	}
+	tml := x509.Certificate{
+		SerialNumber:          big.NewInt(rand.Int63()),
+		Subject:               pkix.Name{CommonName: "Intermediate CA"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(crand.Reader, &tml, caCrt, key.Public(), caKey)
+	if err != nil {
+		t.Fatalf("can not create intermediate cert: %v", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("can not marshal key: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: pki.PEMCertificateBlockType, Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: pki.PEMPrivateKeyBlockType, Bytes: keyDER}))
+}
+
+// TestEncryptPrivateKey tests that an encrypted key can only be decrypted with the same passphrase.
+func TestSignClientCSR(t *testing.T) {
+	// Initialize:
+	ca, err := pki.NewCA()
+	if err != nil {
+		t.Fatalf("can not create CA: %v", err)
+	}
+
+	// Prepare:
+	key, err := ecdsa.GenerateKey(elliptic.P256(), crand.Reader)
+	if err != nil {
+		t.Fatalf("can not generate key: %v", err)
+	}
+	newCSR := func(cn string, blockType string) string {
+		der, err := x509.CreateCertificateRequest(crand.Reader, &x509.CertificateRequest{Subject: pkix.Name{CommonName: cn}}, key)
+		if err != nil {
+			t.Fatalf("can not create csr: %v", err)
+		}
+		return string(pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}))
+	}
+
+	// Test:
+	var csrtests = []struct {
+		name string
+		csr  string
+		ok   bool
+	}{
+		{"valid", newCSR("test-user", pki.PEMCSRBlockType), true},
+		{"legacy block type", newCSR("test-user", "NEW "+pki.PEMCSRBlockType), true},
+		{"wrong common name", newCSR("other-user", pki.PEMCSRBlockType), false},
+		{"wrong block type", newCSR("test-user", pki.PEMCertificateBlockType), false},
+		{"not pem", "test-user", false},
+	}
+	for _, tt := range csrtests {
+		certPEM, err := pki.SignClientCSR(ca, tt.csr, "test-user", pki.CertOptions{})
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: signing ok is expected to be %t but got error: %v", tt.name, tt.ok, err)
+			continue
+		}
+		if !tt.ok {
+			continue
+		}
+		crt, err := pki.ReadCertFromPEM(certPEM)
+		if err != nil {
+			t.Fatalf("%s: can not read signed cert: %v", tt.name, err)
+		}
+		if !key.PublicKey.Equal(crt.PublicKey) {
+			t.Errorf("%s: signed cert is expected to have the csr's public key", tt.name)
+		}
+		if len(crt.ExtKeyUsage) != 1 || crt.ExtKeyUsage[0] != x509.ExtKeyUsageClientAuth {
+			t.Errorf("%s: signed cert is expected to be a client cert: %v", tt.name, crt.ExtKeyUsage)
+		}
+	}
+}
+
+func TestTLSCryptKeys(t *testing.T) {
+	// Test:
+	// Static key.
+	staticKey, err := pki.NewStaticKey()
+	if err != nil {
+		t.Fatalf("can not generate static key: %v", err)
+	}
+	typ, data, err := pki.DecodeOpenVPNKey(staticKey)
+	if err != nil {
+		t.Fatalf("can not decode static key: %v", err)
+	}
+	if typ != pki.PEMStaticKeyBlockType || len(data) != 256 {
+		t.Errorf("static key is expected to be a 2048 bit %s but it's a %d bit %s", pki.PEMStaticKeyBlockType, len(data)*8, typ)
+	}
+
+	// tls-crypt-v2 server key.
+	serverKey, err := pki.NewTLSCryptV2ServerKey()
+	if err != nil {
+		t.Fatalf("can not generate tls-crypt-v2 server key: %v", err)
+	}
+	typ, ks, err := pki.DecodeOpenVPNKey(serverKey)
+	if err != nil {
+		t.Fatalf("can not decode tls-crypt-v2 server key: %v", err)
+	}
+	if typ != pki.PEMTLSCryptV2ServerBlockType || len(ks) != 128 {
+		t.Fatalf("tls-crypt-v2 server key is expected to be 128 bytes %s but it's %d bytes %s", pki.PEMTLSCryptV2ServerBlockType, len(ks), typ)
+	}
+
+	// tls-crypt-v2 client key should be unwrapped by the server key.
+	if _, err := pki.NewTLSCryptV2ClientKey(staticKey); err == nil {
+		t.Errorf("tls-crypt-v2 client key is expected to be wrapped by a tls-crypt-v2 server key only")
+	}
+	clientKey, err := pki.NewTLSCryptV2ClientKey(serverKey)
+	if err != nil {
+		t.Fatalf("can not generate tls-crypt-v2 client key: %v", err)
+	}
+	typ, data, err = pki.DecodeOpenVPNKey(clientKey)
+	if err != nil {
+		t.Fatalf("can not decode tls-crypt-v2 client key: %v", err)
+	}
+	if typ != pki.PEMTLSCryptV2ClientBlockType || len(data) <= 256+32+2 {
+		t.Fatalf("tls-crypt-v2 client key is expected to be a %s but it's %d bytes %s", pki.PEMTLSCryptV2ClientBlockType, len(data), typ)
+	}
+	kc, wkc := data[:256], data[256:]
+	if n := int(binary.BigEndian.Uint16(wkc[len(wkc)-2:])); n != len(wkc) {
+		t.Fatalf("wrapped client key length is expected to be %d but it's %d", len(wkc), n)
+	}
+	tag, ciphertext := wkc[:32], wkc[32:len(wkc)-2]
+	block, _ := aes.NewCipher(ks[:32])
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCTR(block, tag[:16]).XORKeyStream(plaintext, ciphertext)
+	if !bytes.Equal(plaintext[:256], kc) {
+		t.Errorf("wrapped client key is expected to be the same with the client key")
+	}
+	if plaintext[256] != 0x01 {
+		t.Errorf("client key metadata is expected to be a timestamp but its type is %d", plaintext[256])
+	}
+	mac := hmac.New(sha256.New, ks[64:96])
+	mac.Write(wkc[len(wkc)-2:])
+	mac.Write(plaintext)
+	if !hmac.Equal(mac.Sum(nil), tag) {
+		t.Errorf("wrapped client key tag is expected to be authenticated by the server key")
+	}
+}
+
+func TestEncryptPrivateKey(t *testing.T) {
+	// Initialize:
+	ca, err := pki.NewCAWithOptions(pki.CertOptions{KeyType: pki.KeyTypeECDSAP256})
+	if err != nil {
+		t.Fatalf("can not create CA: %v", err)
+	}
+
+	// Prepare:
+	if _, err := pki.EncryptPrivateKey(ca.Key, nil); err == nil {
+		t.Fatalf("empty passphrase is expected to be rejected")
+	}
+	encrypted, err := pki.EncryptPrivateKey(ca.Key, []byte("secret"))
+	if err != nil {
+		t.Fatalf("can not encrypt private key: %v", err)
+	}
+
+	// Test:
+	if strings.Contains(encrypted, strings.TrimSpace(ca.Key)) {
+		t.Errorf("encrypted key is expected not to contain the plain key")
+	}
+	if _, err := pki.DecryptPrivateKey(encrypted, []byte("wrong")); err == nil {
+		t.Errorf("decryption is expected to fail with a wrong passphrase")
+	}
+	signer, err := pki.DecryptPrivateKey(encrypted, []byte("secret"))
+	if err != nil {
+		t.Fatalf("can not decrypt private key: %v", err)
+	}
+
+	// CA should be able to sign with the decrypted signer instead of the key.
+	offline := &pki.CA{CertHolder: pki.CertHolder{Cert: ca.Cert}, Signer: signer}
+	cch, err := pki.NewClientCertHolder(offline, "test-user")
+	if err != nil {
+		t.Fatalf("can not issue a cert with the signer: %v", err)
+	}
+	caCrt, _ := pki.ReadCertFromPEM(ca.Cert)
+	crt, _ := pki.ReadCertFromPEM(cch.Cert)
+	if err := crt.CheckSignatureFrom(caCrt); err != nil {
+		t.Errorf("cert is not signed by the ca: %v", err)
+	}
+}
+
 func TestReadCertFromPEM(t *testing.T) {
 	// Initialize:
 	ca, _ := pki.NewCA()
@@ -223,6 +740,17 @@ func isPEMEncodedProperly(t *testing.T, s string, typ string) bool {
 			return false
 		}
 
+		if key == nil {
+			t.Logf("couldn't parse private key %+v", block)
+			return false
+		}
+	case pki.PEMPrivateKeyBlockType:
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			t.Logf("private key parse failed %+v: %v", block, err)
+			return false
+		}
+
 		if key == nil {
 			t.Logf("couldn't parse private key %+v", block)
 			return false
@@ -270,6 +798,43 @@ func getSerial(t *testing.T, crt string) *big.Int {
 	return cert.SerialNumber
 }
 
+func TestNewDHParams(t *testing.T) {
+	// Prepare:
+	var tries int
+	params, err := pki.NewDHParams(256, func(n int) { tries = n })
+	if err != nil {
+		t.Fatalf("can not generate dh params: %v", err)
+	}
+
+	// Test:
+	p, g, err := pki.DecodeDHParams(params)
+	if err != nil {
+		t.Fatalf("generated dh params can not be decoded: %v", err)
+	}
+	if p.BitLen() != 256 {
+		t.Errorf("prime is expected to be 256 bits but it's %d bits", p.BitLen())
+	}
+	if g.Cmp(big.NewInt(2)) != 0 {
+		t.Errorf("generator is expected to be 2 but it's %s", g)
+	}
+	q := new(big.Int).Rsh(p, 1)
+	if !p.ProbablyPrime(20) || !q.ProbablyPrime(20) {
+		t.Errorf("prime is expected to be a safe prime: %s", p)
+	}
+	if new(big.Int).Mod(p, big.NewInt(24)).Int64() != 23 {
+		t.Errorf("prime is expected to be 23 mod 24 for the generator 2: %s", p)
+	}
+	if tries == 0 {
+		t.Errorf("progress is expected to be reported")
+	}
+	if _, err := pki.NewDHParams(128, nil); err == nil {
+		t.Errorf("too small dh params are expected to be refused")
+	}
+	if _, _, err := pki.DecodeDHParams("garbage"); err == nil {
+		t.Errorf("garbage is expected to fail to decode")
+	}
+}
+
 // randomBetween returns a random int between min and max
 func randomBetween(min, max int) int {
 	rand.Seed(time.Now().Unix())
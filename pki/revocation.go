@@ -0,0 +1,65 @@
+package pki
+
+import (
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// RevocationReason is the reason code of a revoked certificate as defined in RFC 5280.
+type RevocationReason int
+
+// Known revocation reasons.
+const (
+	ReasonUnspecified          RevocationReason = 0
+	ReasonKeyCompromise        RevocationReason = 1
+	ReasonCACompromise         RevocationReason = 2
+	ReasonAffiliationChanged   RevocationReason = 3
+	ReasonSuperseded           RevocationReason = 4
+	ReasonCessationOfOperation RevocationReason = 5
+	ReasonCertificateHold      RevocationReason = 6
+	ReasonRemoveFromCRL        RevocationReason = 8
+	ReasonPrivilegeWithdrawn   RevocationReason = 9
+	ReasonAACompromise         RevocationReason = 10
+)
+
+var revocationReasonNames = map[RevocationReason]string{
+	ReasonUnspecified:          "unspecified",
+	ReasonKeyCompromise:        "keyCompromise",
+	ReasonCACompromise:         "cACompromise",
+	ReasonAffiliationChanged:   "affiliationChanged",
+	ReasonSuperseded:           "superseded",
+	ReasonCessationOfOperation: "cessationOfOperation",
+	ReasonCertificateHold:      "certificateHold",
+	ReasonRemoveFromCRL:        "removeFromCRL",
+	ReasonPrivilegeWithdrawn:   "privilegeWithdrawn",
+	ReasonAACompromise:         "aACompromise",
+}
+
+func (r RevocationReason) String() string {
+	if name, ok := revocationReasonNames[r]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", int(r))
+}
+
+// ParseRevocationReason returns the RevocationReason with the given RFC 5280 name. (e.g. keyCompromise)
+func ParseRevocationReason(name string) (RevocationReason, error) {
+	for r, n := range revocationReasonNames {
+		if n == name {
+			return r, nil
+		}
+	}
+	return ReasonUnspecified, fmt.Errorf("unknown revocation reason: %s", name)
+}
+
+// RevokedCert represents a revoked certificate entry of a CRL.
+type RevokedCert struct {
+	SerialNumber *big.Int
+	RevokedAt    time.Time
+	Reason       RevocationReason
+}
+
+// oidExtensionReasonCode is the OID of the CRL entry reason code extension.
+var oidExtensionReasonCode = asn1.ObjectIdentifier{2, 5, 29, 21}
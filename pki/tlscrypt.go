@@ -0,0 +1,141 @@
+package pki
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// OpenVPN key sizes and tls-crypt-v2 constants.
+const (
+	_StaticKeySize        = 256  // 2048 bits, two pairs of 512 bit cipher and hmac keys.
+	_TLSCryptV2ServerSize = 128  // a 512 bit cipher key followed by a 512 bit hmac key.
+	_TLSCryptV2TagSize    = 32   // HMAC-SHA256
+	_TLSCryptV2MaxWKcSize = 1024 // maximum size of a wrapped client key.
+	_TLSCryptV2MetaTime   = 0x01 // metadata type of a timestamp.
+)
+
+// NewStaticKey generates a 2048 bit OpenVPN static key to use with the
+// tls-crypt (and tls-auth) directives.
+//
+// It's the same thing with what `openvpn --genkey secret` generates.
+func NewStaticKey() (string, error) {
+	key := make([]byte, _StaticKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString("#\n# 2048 bit OpenVPN static key\n#\n")
+	b.WriteString("-----BEGIN " + PEMStaticKeyBlockType + "-----\n")
+	for i := 0; i < len(key); i += 16 {
+		b.WriteString(hex.EncodeToString(key[i:i+16]) + "\n")
+	}
+	b.WriteString("-----END " + PEMStaticKeyBlockType + "-----\n")
+	return b.String(), nil
+}
+
+// NewTLSCryptV2ServerKey generates an OpenVPN tls-crypt-v2 server key.
+//
+// It's the same thing with what `openvpn --genkey tls-crypt-v2-server` generates.
+func NewTLSCryptV2ServerKey() (string, error) {
+	key := make([]byte, _TLSCryptV2ServerSize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: PEMTLSCryptV2ServerBlockType, Bytes: key})), nil
+}
+
+// NewTLSCryptV2ClientKey generates an OpenVPN tls-crypt-v2 client key that is
+// wrapped by the given server key.
+//
+// It's the same thing with what `openvpn --tls-crypt-v2 server.key --genkey
+// tls-crypt-v2-client` generates. The creation time is stored as the metadata.
+func NewTLSCryptV2ClientKey(serverKey string) (string, error) {
+	block, _ := pem.Decode([]byte(serverKey))
+	if block == nil || block.Type != PEMTLSCryptV2ServerBlockType || len(block.Bytes) != _TLSCryptV2ServerSize {
+		return "", fmt.Errorf("failed to decode tls-crypt-v2 server key")
+	}
+
+	kc := make([]byte, _StaticKeySize)
+	if _, err := rand.Read(kc); err != nil {
+		return "", err
+	}
+	metadata := make([]byte, 9)
+	metadata[0] = _TLSCryptV2MetaTime
+	binary.BigEndian.PutUint64(metadata[1:], uint64(time.Now().Unix()))
+
+	wkc, err := wrapTLSCryptV2ClientKey(block.Bytes, kc, metadata)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: PEMTLSCryptV2ClientBlockType, Bytes: append(kc, wkc...)})), nil
+}
+
+// wrapTLSCryptV2ClientKey wraps the client key Kc and its metadata with the
+// server key the way OpenVPN does:
+//
+//	len = len(WKc) as uint16
+//	T = HMAC-SHA256(Ka, len || Kc || metadata)
+//	WKc = T || AES-256-CTR(Ke, IV=T[:16], Kc || metadata) || len
+func wrapTLSCryptV2ClientKey(serverKey, kc, metadata []byte) ([]byte, error) {
+	// AES-256 and HMAC-SHA256 use the first 256 bits of their keys.
+	ke := serverKey[:32]
+	ka := serverKey[64 : 64+32]
+
+	size := _TLSCryptV2TagSize + len(kc) + len(metadata) + 2
+	if size > _TLSCryptV2MaxWKcSize {
+		return nil, fmt.Errorf("tls-crypt-v2 metadata is too long")
+	}
+	netLen := make([]byte, 2)
+	binary.BigEndian.PutUint16(netLen, uint16(size))
+
+	mac := hmac.New(sha256.New, ka)
+	mac.Write(netLen)
+	mac.Write(kc)
+	mac.Write(metadata)
+	tag := mac.Sum(nil)
+
+	c, err := aes.NewCipher(ke)
+	if err != nil {
+		return nil, err
+	}
+	plaintext := append(append([]byte{}, kc...), metadata...)
+	ciphertext := make([]byte, len(plaintext))
+	cipher.NewCTR(c, tag[:aes.BlockSize]).XORKeyStream(ciphertext, plaintext)
+
+	wkc := append(tag, ciphertext...)
+	return append(wkc, netLen...), nil
+}
+
+// DecodeOpenVPNKey returns the raw bytes of an OpenVPN static key or a
+// tls-crypt-v2 key along with its type.
+func DecodeOpenVPNKey(key string) (string, []byte, error) {
+	// Static keys are hex encoded, so pem can't decode them.
+	if i := strings.Index(key, "-----BEGIN "+PEMStaticKeyBlockType+"-----"); i >= 0 {
+		body := key[i+len("-----BEGIN "+PEMStaticKeyBlockType+"-----"):]
+		j := strings.Index(body, "-----END "+PEMStaticKeyBlockType+"-----")
+		if j < 0 {
+			return "", nil, fmt.Errorf("failed to decode static key")
+		}
+		data, err := hex.DecodeString(strings.Join(strings.Fields(body[:j]), ""))
+		if err != nil || len(data) != _StaticKeySize {
+			return "", nil, fmt.Errorf("failed to decode static key")
+		}
+		return PEMStaticKeyBlockType, data, nil
+	}
+
+	block, _ := pem.Decode([]byte(key))
+	if block == nil {
+		return "", nil, fmt.Errorf("failed to decode openvpn key")
+	}
+	return block.Type, block.Bytes, nil
+}
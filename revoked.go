@@ -0,0 +1,127 @@
+package ovpm
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/cad/ovpm/pki"
+)
+
+// RevokedCert represents a revoked user certificate.
+type RevokedCert struct {
+	SerialNumber string // Hex encoded serial number of the certificate.
+	Username     string
+	Reason       pki.RevocationReason
+	RevokedAt    time.Time
+	RevokedBy    string
+}
+
+// GetRevokedCerts returns all the revoked certificates, oldest first.
+func GetRevokedCerts() ([]*RevokedCert, error) {
+	var items []*dbRevokedModel
+	q := db.Order("id asc").Find(&items)
+	if q.Error != nil {
+		return nil, q.Error
+	}
+	var revoked []*RevokedCert
+	for _, item := range items {
+		revoked = append(revoked, item.revokedCert())
+	}
+	return revoked, nil
+}
+
+// revokedCert converts the database model to RevokedCert.
+//
+// Revocations that are recorded before the reasons and times were stored
+// fall back to the record's creation time and the unspecified reason.
+func (r *dbRevokedModel) revokedCert() *RevokedCert {
+	reason, err := pki.ParseRevocationReason(r.Reason)
+	if err != nil {
+		reason = pki.ReasonUnspecified
+	}
+	revokedAt := r.RevokedAt
+	if revokedAt.IsZero() {
+		revokedAt = r.CreatedAt
+	}
+	return &RevokedCert{
+		SerialNumber: r.SerialNumber,
+		Username:     r.Username,
+		Reason:       reason,
+		RevokedAt:    revokedAt,
+		RevokedBy:    r.RevokedBy,
+	}
+}
+
+// revokeCert records the given PEM encoded certificate as revoked at the given time.
+//
+// Revocations in the future take effect when the CRL is emitted after that
+// time. Certificates that are already revoked are skipped. It doesn't emit
+// the CRL.
+func revokeCert(cert, username string, reason pki.RevocationReason, actor string, at time.Time) error {
+	crt, err := pki.ReadCertFromPEM(cert)
+	if err != nil {
+		return fmt.Errorf("can not get user's certificate: %v", err)
+	}
+	serial := crt.SerialNumber.Text(16)
+
+	var count int
+	db.Model(&dbRevokedModel{}).Where("serial_number = ?", serial).Count(&count)
+	if count > 0 {
+		return nil
+	}
+	db.Create(&dbRevokedModel{
+		SerialNumber: serial,
+		Username:     username,
+		Reason:       reason.String(),
+		RevokedAt:    at,
+		RevokedBy:    actor,
+	})
+	return nil
+}
+
+// RefreshCRL emits the CRL if any revocation has taken effect since the last
+// time the CRL was emitted.
+//
+// It's meant to be called periodically, so that the revocations with a grace
+// period get into the CRL. Live sessions with those certificates are dropped
+// on the next TLS renegotiation.
+func (svr *Server) RefreshCRL() error {
+	if !svr.IsInitialized() {
+		return fmt.Errorf("server is not initialized")
+	}
+	svr.emittedLock.Lock()
+	since := svr.crlEmittedAt
+	svr.emittedLock.Unlock()
+
+	var count int
+	db.Model(&dbRevokedModel{}).Where("revoked_at > ? AND revoked_at <= ?", since, time.Now()).Count(&count)
+	if count == 0 {
+		return nil
+	}
+	return svr.emitCRL()
+}
+
+// crlEntries returns the revoked certificates that are in effect at the given time as CRL entries.
+func crlEntries(at time.Time) ([]pki.RevokedCert, error) {
+	revoked, err := GetRevokedCerts()
+	if err != nil {
+		return nil, err
+	}
+	var entries []pki.RevokedCert
+	for _, r := range revoked {
+		if r.RevokedAt.After(at) {
+			continue
+		}
+		serial, ok := big.NewInt(0).SetString(r.SerialNumber, 16)
+		if !ok {
+			continue
+		}
+		entries = append(entries, pki.RevokedCert{
+			SerialNumber: serial,
+			RevokedAt:    r.RevokedAt,
+			Reason:       r.Reason,
+		})
+	}
+	return entries, nil
+}
@@ -0,0 +1,337 @@
+package ovpm
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cad/ovpm/mgmt"
+	"github.com/cad/ovpm/supervisor"
+	"github.com/sirupsen/logrus"
+)
+
+// Reasons of the VPN session ends.
+const (
+	SessionDisconnected  = "disconnected"   // The client disconnected or timed out.
+	SessionKicked        = "kicked"         // The client is disconnected through ovpm.
+	SessionServerStopped = "server stopped" // The OpenVPN server is stopped or restarted.
+)
+
+// sessionEndReasonTimeout is how long the reason of a disconnect that is
+// initiated by ovpm waits for the disconnect events.
+const sessionEndReasonTimeout = 1 * time.Minute
+
+// dbVPNSessionModel is database model for the VPN session history.
+//
+// Times are stored in the local zone, because sqlite compares them as text.
+type dbVPNSessionModel struct {
+	ID               uint       `gorm:"primary_key"`
+	Username         string     `gorm:"index"`
+	ClientID         uint64     // Management interface client id, 0 if it's unknown.
+	RealAddress      string     // e.g. 1.2.3.4:1194
+	VirtualAddress   string     // e.g. 10.9.0.2
+	StartedAt        time.Time  `gorm:"index"`
+	EndedAt          *time.Time `gorm:"index"` // nil while the session is active.
+	BytesReceived    uint64
+	BytesSent        uint64
+	DisconnectReason string
+}
+
+// VPNSession represents a connection of a user to the VPN server.
+type VPNSession struct {
+	dbVPNSessionModel
+}
+
+// VPNSessionFilter selects the VPN sessions.
+type VPNSessionFilter struct {
+	Username string    // Empty means any user.
+	Since    time.Time // Sessions that were active since then, zero means any time.
+	Limit    int       // Zero means no limit.
+}
+
+// pendingSessionEnd is the reason of a disconnect that is initiated by ovpm.
+type pendingSessionEnd struct {
+	reason string
+	until  time.Time
+}
+
+var (
+	sessionLock sync.Mutex
+
+	// sessionEndReasons holds the reasons of the disconnects that are
+	// initiated by ovpm for each username, until the disconnect events
+	// are received.
+	sessionEndReasons = make(map[string]pendingSessionEnd)
+)
+
+// GetVPNSessions returns the VPN sessions that match the filter, the most
+// recent first.
+func GetVPNSessions(filter VPNSessionFilter) ([]*VPNSession, error) {
+	q := db.Order("started_at desc, id desc")
+	if filter.Username != "" {
+		q = q.Where("username = ?", filter.Username)
+	}
+	if !filter.Since.IsZero() {
+		q = q.Where("ended_at IS NULL OR ended_at >= ?", filter.Since.Local())
+	}
+	if filter.Limit > 0 {
+		q = q.Limit(filter.Limit)
+	}
+	var models []*dbVPNSessionModel
+	if err := q.Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("can not get vpn sessions: %v", err)
+	}
+	var sessions []*VPNSession
+	for _, m := range models {
+		sessions = append(sessions, &VPNSession{dbVPNSessionModel: *m})
+	}
+	return sessions, nil
+}
+
+// PruneVPNSessions deletes the sessions that ended before the retention
+// period and returns how many of them are deleted.
+func PruneVPNSessions(retention time.Duration) (int64, error) {
+	q := db.Where("ended_at < ?", time.Now().Add(-retention)).Delete(&dbVPNSessionModel{})
+	if q.Error != nil {
+		return 0, fmt.Errorf("can not prune vpn sessions: %v", q.Error)
+	}
+	if q.RowsAffected > 0 {
+		logrus.Debugf("%d vpn sessions are pruned", q.RowsAffected)
+	}
+	return q.RowsAffected, nil
+}
+
+// SyncVPNSessions reconciles the VPN session history with the clients that
+// are connected to the OpenVPN server.
+//
+// Sessions are recorded from the management interface events as they
+// happen. This catches up with the connects and disconnects that are
+// missed, e.g. while ovpmd isn't running or when only the status log is
+// available. It also updates the byte counters of the active sessions.
+func SyncVPNSessions() error {
+	if vpnProc == nil || vpnProc.Status() != supervisor.RUNNING {
+		return nil
+	}
+	cl, rt, err := TheServer().tryClientList()
+	if err != nil {
+		return err
+	}
+
+	sessionLock.Lock()
+	defer sessionLock.Unlock()
+
+	virtualAddresses := make(map[string]string)
+	for _, r := range rt {
+		virtualAddresses[r.CommonName+"|"+r.RealAddress] = r.VirtualAddress
+	}
+	seen := make(map[uint]bool)
+	for _, c := range cl {
+		s := findOpenVPNSession(c.ClientID, c.CommonName, c.RealAddress)
+		if s == nil {
+			s = startVPNSession(c.CommonName, c.RealAddress, virtualAddresses[c.CommonName+"|"+c.RealAddress], c.ClientID, c.ConnectedSince)
+		}
+		if s.BytesReceived != c.BytesReceived || s.BytesSent != c.BytesSent {
+			s.BytesReceived, s.BytesSent = c.BytesReceived, c.BytesSent
+			db.Save(s)
+		}
+		seen[s.ID] = true
+	}
+
+	var open []*dbVPNSessionModel
+	db.Where("ended_at IS NULL").Find(&open)
+	for _, s := range open {
+		if !seen[s.ID] {
+			endVPNSession(s, s.BytesReceived, s.BytesSent, SessionDisconnected)
+		}
+	}
+	return nil
+}
+
+// expectVPNSessionEnd records the reason of the upcoming disconnects of the
+// user.
+func expectVPNSessionEnd(username, reason string) {
+	sessionLock.Lock()
+	defer sessionLock.Unlock()
+	sessionEndReasons[username] = pendingSessionEnd{reason: reason, until: time.Now().Add(sessionEndReasonTimeout)}
+}
+
+// endVPNSessions ends all of the active sessions for the reason.
+func endVPNSessions(reason string) {
+	sessionLock.Lock()
+	defer sessionLock.Unlock()
+	var open []*dbVPNSessionModel
+	db.Where("ended_at IS NULL").Find(&open)
+	for _, s := range open {
+		endVPNSession(s, s.BytesReceived, s.BytesSent, reason)
+	}
+}
+
+// recordVPNSessionEvent records the VPN sessions from the management events.
+func recordVPNSessionEvent(e mgmt.Event) {
+	sessionLock.Lock()
+	defer sessionLock.Unlock()
+
+	switch e.Type {
+	case mgmt.EstablishedEvent:
+		startedAt := time.Now()
+		if t, err := strconv.ParseInt(e.Env["time_unix"], 10, 64); err == nil {
+			startedAt = time.Unix(t, 0)
+		}
+		if findOpenVPNSession(e.ClientID, e.CommonName(), eventRealAddress(e)) == nil {
+			startVPNSession(e.CommonName(), eventRealAddress(e), e.Env["ifconfig_pool_remote_ip"], e.ClientID, startedAt)
+		}
+	case mgmt.DisconnectEvent:
+		s := findOpenVPNSession(e.ClientID, e.CommonName(), eventRealAddress(e))
+		if s == nil {
+			return
+		}
+		reason := SessionDisconnected
+		p, ok := sessionEndReasons[s.Username]
+		if ok && time.Now().Before(p.until) {
+			reason = p.reason
+		}
+		endVPNSession(s, envUint(e.Env, "bytes_received", s.BytesReceived), envUint(e.Env, "bytes_sent", s.BytesSent), reason)
+
+		// The reason is kept until the other sessions of the user are
+		// ended too, so that a later disconnect doesn't inherit it.
+		if ok {
+			var n int
+			db.Model(&dbVPNSessionModel{}).Where("ended_at IS NULL AND username = ?", s.Username).Count(&n)
+			if n == 0 || reason != p.reason {
+				delete(sessionEndReasons, s.Username)
+			}
+		}
+	}
+}
+
+// findOpenVPNSession returns the active session with the client id, or with
+// the username and the real address if the client id is unknown.
+//
+// sessionLock should be held by the caller.
+func findOpenVPNSession(clientID uint64, username, realAddress string) *dbVPNSessionModel {
+	var s dbVPNSessionModel
+	if clientID != 0 && !db.Where("ended_at IS NULL AND client_id = ?", clientID).First(&s).RecordNotFound() {
+		return &s
+	}
+	if db.Where("ended_at IS NULL AND username = ? AND real_address = ?", username, realAddress).First(&s).RecordNotFound() {
+		return nil
+	}
+	return &s
+}
+
+// startVPNSession records a new active session.
+//
+// sessionLock should be held by the caller.
+func startVPNSession(username, realAddress, virtualAddress string, clientID uint64, startedAt time.Time) *dbVPNSessionModel {
+	s := &dbVPNSessionModel{
+		Username:       username,
+		ClientID:       clientID,
+		RealAddress:    realAddress,
+		VirtualAddress: virtualAddress,
+		StartedAt:      startedAt.Local(),
+	}
+	db.Create(s)
+	logrus.Debugf("vpn session started: %s %s", username, realAddress)
+	return s
+}
+
+// endVPNSession records the end of the session.
+//
+// sessionLock should be held by the caller.
+func endVPNSession(s *dbVPNSessionModel, bytesReceived, bytesSent uint64, reason string) {
+	now := time.Now()
+	s.EndedAt = &now
+	s.BytesReceived, s.BytesSent = bytesReceived, bytesSent
+	s.DisconnectReason = reason
+	db.Save(s)
+	logrus.Debugf("vpn session ended: %s %s: %s", s.Username, s.RealAddress, reason)
+}
+
+// eventRealAddress returns the real address of the client in the same
+// format as the status output.
+func eventRealAddress(e mgmt.Event) string {
+	ip := e.Env["trusted_ip"]
+	if ip == "" {
+		ip = e.Env["trusted_ip6"]
+	}
+	if port := e.Env["trusted_port"]; port != "" {
+		return net.JoinHostPort(ip, port)
+	}
+	return ip
+}
+
+// envUint returns the number in the environment variable, or def if it
+// isn't a number.
+func envUint(env map[string]string, name string, def uint64) uint64 {
+	if n, err := strconv.ParseUint(env[name], 10, 64); err == nil {
+		return n
+	}
+	return def
+}
+
+// GetID returns the session's id.
+func (s *VPNSession) GetID() uint {
+	return s.ID
+}
+
+// GetUsername returns the username of the session's user.
+func (s *VPNSession) GetUsername() string {
+	return s.Username
+}
+
+// GetRealAddress returns the address that the client connected from.
+func (s *VPNSession) GetRealAddress() string {
+	return s.RealAddress
+}
+
+// GetVirtualAddress returns the VPN address that the client was assigned.
+func (s *VPNSession) GetVirtualAddress() string {
+	return s.VirtualAddress
+}
+
+// GetStartedAt returns when the session started.
+func (s *VPNSession) GetStartedAt() time.Time {
+	return s.StartedAt
+}
+
+// GetEndedAt returns when the session ended, or zero time if it's active.
+func (s *VPNSession) GetEndedAt() time.Time {
+	if s.EndedAt == nil {
+		return time.Time{}
+	}
+	return *s.EndedAt
+}
+
+// IsActive returns whether the client is still connected.
+func (s *VPNSession) IsActive() bool {
+	return s.EndedAt == nil
+}
+
+// GetDuration returns how long the session lasted, or has lasted so far.
+func (s *VPNSession) GetDuration() time.Duration {
+	if s.EndedAt == nil {
+		return time.Since(s.StartedAt)
+	}
+	return s.EndedAt.Sub(s.StartedAt)
+}
+
+// GetBytesReceived returns how many bytes the server received from the client.
+func (s *VPNSession) GetBytesReceived() uint64 {
+	return s.BytesReceived
+}
+
+// GetBytesSent returns how many bytes the server sent to the client.
+func (s *VPNSession) GetBytesSent() uint64 {
+	return s.BytesSent
+}
+
+// GetDisconnectReason returns why the session ended.
+func (s *VPNSession) GetDisconnectReason() string {
+	return s.DisconnectReason
+}
+
+func init() {
+	onManagementEvent(recordVPNSessionEvent)
+}
@@ -0,0 +1,108 @@
+package ovpm
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/cad/ovpm/mgmt"
+	"github.com/cad/ovpm/supervisor"
+)
+
+func TestVPNSessions(t *testing.T) {
+	// Initialize:
+	setupTestCase()
+	CreateDB("sqlite3", ":memory:")
+	defer db.Cease()
+	svr := TheServer()
+
+	// Test:
+	// Sessions are recorded from the management events.
+	env := map[string]string{"common_name": "john", "trusted_ip": "1.2.3.4", "trusted_port": "5000", "ifconfig_pool_remote_ip": "10.9.0.2", "time_unix": "1600000000"}
+	established := mgmt.Event{Type: mgmt.EstablishedEvent, ClientID: 1, Env: env}
+	recordVPNSessionEvent(established)
+	recordVPNSessionEvent(established)
+	sessions, err := GetVPNSessions(VPNSessionFilter{Username: "john"})
+	if err != nil || len(sessions) != 1 {
+		t.Fatalf("1 vpn session is expected: %v", err)
+	}
+	s := sessions[0]
+	if !s.IsActive() || s.GetRealAddress() != "1.2.3.4:5000" || s.GetVirtualAddress() != "10.9.0.2" || !s.GetStartedAt().Equal(time.Unix(1600000000, 0)) {
+		t.Errorf("unexpected vpn session: %+v", s.dbVPNSessionModel)
+	}
+
+	expectVPNSessionEnd("john", SessionKicked)
+	env = map[string]string{"common_name": "john", "trusted_ip": "1.2.3.4", "trusted_port": "5000", "bytes_received": "100", "bytes_sent": "200"}
+	recordVPNSessionEvent(mgmt.Event{Type: mgmt.DisconnectEvent, ClientID: 1, Env: env})
+	sessions, _ = GetVPNSessions(VPNSessionFilter{Username: "john"})
+	s = sessions[0]
+	if s.IsActive() || s.GetDisconnectReason() != SessionKicked || s.GetBytesReceived() != 100 || s.GetBytesSent() != 200 || s.GetDuration() <= 0 {
+		t.Errorf("vpn session is expected to be ended: %+v", s.dbVPNSessionModel)
+	}
+
+	// The reason is applied only once.
+	recordVPNSessionEvent(mgmt.Event{Type: mgmt.EstablishedEvent, ClientID: 2, Env: map[string]string{"common_name": "john", "trusted_ip": "1.2.3.4", "trusted_port": "5001"}})
+	recordVPNSessionEvent(mgmt.Event{Type: mgmt.DisconnectEvent, ClientID: 2, Env: map[string]string{"common_name": "john", "trusted_ip": "1.2.3.4", "trusted_port": "5001"}})
+	sessions, _ = GetVPNSessions(VPNSessionFilter{Username: "john"})
+	if len(sessions) != 2 || sessions[0].GetDisconnectReason() != SessionDisconnected {
+		t.Errorf("later vpn session is expected to be ended as disconnected: %+v", sessions)
+	}
+
+	// Sessions are recorded from the status log diffs.
+	origOpenFunc, origParseStatusLogFunc := svr.openFunc, svr.parseStatusLogFunc
+	defer func() { svr.openFunc, svr.parseStatusLogFunc = origOpenFunc, origParseStatusLogFunc }()
+	svr.openFunc = func(path string) (io.Reader, error) {
+		return nil, nil
+	}
+	var cl []clEntry
+	var rt []rtEntry
+	svr.parseStatusLogFunc = func(f io.Reader) ([]clEntry, []rtEntry) {
+		return cl, rt
+	}
+	vpnProc.Start()
+	defer vpnProc.Stop()
+
+	cl = []clEntry{{CommonName: "jane", RealAddress: "5.6.7.8:6000", ConnectedSince: time.Now(), BytesReceived: 1, BytesSent: 2}}
+	rt = []rtEntry{{CommonName: "jane", RealAddress: "5.6.7.8:6000", VirtualAddress: "10.9.0.3"}}
+	if err := SyncVPNSessions(); err != nil {
+		t.Fatalf("can not sync vpn sessions: %v", err)
+	}
+	cl[0].BytesReceived = 10
+	SyncVPNSessions()
+	sessions, _ = GetVPNSessions(VPNSessionFilter{Username: "jane"})
+	if len(sessions) != 1 || !sessions[0].IsActive() || sessions[0].GetVirtualAddress() != "10.9.0.3" || sessions[0].GetBytesReceived() != 10 {
+		t.Fatalf("1 active vpn session of jane is expected: %+v", sessions)
+	}
+
+	cl, rt = nil, nil
+	SyncVPNSessions()
+	sessions, _ = GetVPNSessions(VPNSessionFilter{Username: "jane"})
+	if sessions[0].IsActive() || sessions[0].GetDisconnectReason() != SessionDisconnected {
+		t.Errorf("vpn session of jane is expected to be ended: %+v", sessions[0].dbVPNSessionModel)
+	}
+
+	// Filters.
+	if sessions, _ := GetVPNSessions(VPNSessionFilter{Limit: 1}); len(sessions) != 1 || sessions[0].GetUsername() != "jane" {
+		t.Errorf("the most recent vpn session is expected to be jane's: %+v", sessions)
+	}
+	if sessions, _ := GetVPNSessions(VPNSessionFilter{Since: time.Now().Add(time.Hour)}); len(sessions) != 0 {
+		t.Errorf("no vpn sessions are expected in the future but got %d", len(sessions))
+	}
+
+	// Old sessions are pruned.
+	db.Model(&dbVPNSessionModel{}).Where("username = ?", "john").UpdateColumn("ended_at", time.Now().Add(-DefaultSessionRetention-time.Hour))
+	if n, err := PruneVPNSessions(DefaultSessionRetention); err != nil || n != 2 {
+		t.Errorf("2 vpn sessions are expected to be pruned but got %d: %v", n, err)
+	}
+
+	// Stopping the server ends the active sessions.
+	recordVPNSessionEvent(established)
+	svr.StopVPNProc()
+	if vpnProc.Status() != supervisor.STOPPED {
+		t.Fatalf("vpn is expected to be stopped")
+	}
+	sessions, _ = GetVPNSessions(VPNSessionFilter{Username: "john"})
+	if len(sessions) != 1 || sessions[0].IsActive() || sessions[0].GetDisconnectReason() != SessionServerStopped {
+		t.Errorf("vpn session is expected to be ended by the server stop: %+v", sessions)
+	}
+}
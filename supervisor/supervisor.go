@@ -65,6 +65,68 @@ var tt = []transition{
 	transition{currState: RUNNING, nextState: EXITED},
 	transition{currState: STOPPING, nextState: STOPPED},
 	transition{currState: STOPPING, nextState: STOPPING},
+
+	transition{currState: EXITED, nextState: STARTING},
+	transition{currState: EXITED, nextState: STOPPED},
+	transition{currState: EXITED, nextState: FAILED},
+	transition{currState: FAILED, nextState: STARTING},
+	transition{currState: FAILED, nextState: STOPPED},
+}
+
+// RestartPolicy decides whether a process is restarted when it exits on its
+// own.
+type RestartPolicy string
+
+// Restart policies
+const (
+	RestartAlways    RestartPolicy = "always"
+	RestartOnFailure RestartPolicy = "on-failure" // Only if it exits with an error.
+	RestartNever     RestartPolicy = "never"
+)
+
+// ParseRestartPolicy returns the restart policy with the given name.
+func ParseRestartPolicy(s string) (RestartPolicy, error) {
+	switch p := RestartPolicy(s); p {
+	case RestartAlways, RestartOnFailure, RestartNever:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown restart policy '%s', it should be one of always, on-failure or never", s)
+}
+
+// RestartOptions configures how a process is restarted when it exits on its
+// own.
+//
+// The delay before each restart starts from MinBackoff and doubles with each
+// restart in the Window, up to MaxBackoff. If the process is restarted
+// MaxRestarts times in the Window, it's not restarted anymore and it
+// transitions to FAILED.
+type RestartOptions struct {
+	Policy      RestartPolicy
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+	MaxRestarts int // Zero means no limit.
+	Window      time.Duration
+}
+
+// DefaultRestartOptions are the restart options of the new processes.
+var DefaultRestartOptions = RestartOptions{
+	Policy:      RestartAlways,
+	MinBackoff:  1 * time.Second,
+	MaxBackoff:  1 * time.Minute,
+	MaxRestarts: 5,
+	Window:      10 * time.Minute,
+}
+
+// backoff returns the delay before the restart after n restarts.
+func (o RestartOptions) backoff(n int) time.Duration {
+	d := o.MinBackoff
+	for i := 0; i < n && d < o.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > o.MaxBackoff {
+		d = o.MaxBackoff
+	}
+	return d
 }
 
 // Supervisable is an interface that represents a process.
@@ -73,6 +135,10 @@ type Supervisable interface {
 	Stop()
 	Restart()
 	Status() State
+
+	// OnStateChange sets f to be called after each state transition. f
+	// should return quickly, since the transitions wait for it.
+	OnStateChange(f func(from, to State))
 }
 
 // Process represents a unix process to be supervised.
@@ -85,9 +151,13 @@ type Process struct {
 	wdir            string
 	args            []string
 	done            chan error
+	exitErr         error // Error that the last run exited with.
 	stop            chan bool
-	out             *bytes.Buffer
+	out             *syncBuffer
 	stateChangeCond *sync.Cond
+	restartOpts     RestartOptions
+	restarts        []time.Time // Times of the restarts in the window.
+	onStateChange   func(from, to State)
 	// stdin     io.WriteCloser
 	// stdoutLog Logger
 	// stderrLog Logger
@@ -101,6 +171,7 @@ func NewProcess(executable string, dir string, args []string) (*Process, error)
 		return &p, fmt.Errorf("executable can not be found: %s", executable)
 	}
 	p.maxRetry = 3
+	p.restartOpts = DefaultRestartOptions
 	p.executable = executable
 	p.wdir = dir
 	p.args = args
@@ -109,11 +180,36 @@ func NewProcess(executable string, dir string, args []string) (*Process, error)
 	p.state = STOPPED
 	p.done = make(chan error)
 	p.stop = make(chan bool)
-	p.out = new(bytes.Buffer)
+	p.out = new(syncBuffer)
 
 	return &p, nil
 }
 
+// syncBuffer is a bytes.Buffer that is safe for concurrent use, since the
+// process writes its output while the supervisor reads it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Len()
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
 // isExist returns whether the given executable binary is found on the filesystem or not.
 func isExist(executable string) bool {
 	if _, err := os.Stat(executable); !os.IsNotExist(err) {
@@ -124,10 +220,19 @@ func isExist(executable string) bool {
 
 // waitFor blocks until the FSM transitions to the given state.
 func (p *Process) waitFor(state State) {
-	for p.state != state {
-		p.stateChangeCond.L.Lock()
+	p.stateChangeCond.L.Lock()
+	defer p.stateChangeCond.L.Unlock()
+	for p.Status() != state {
+		p.stateChangeCond.Wait()
+	}
+}
+
+// waitWhile blocks until the FSM transitions from the given state.
+func (p *Process) waitWhile(state State) {
+	p.stateChangeCond.L.Lock()
+	defer p.stateChangeCond.L.Unlock()
+	for p.Status() == state {
 		p.stateChangeCond.Wait()
-		p.stateChangeCond.L.Unlock()
 	}
 }
 
@@ -137,29 +242,119 @@ func WaitFor(process *Process, state State) {
 }
 
 // Start will run the process.
+//
+// The restarts that are done by the restart policy are forgotten.
 func (p *Process) Start() {
+	p.lock.Lock()
+	p.restarts = nil
+	p.lock.Unlock()
 	p.transitionTo(STARTING)
 }
 
 // Stop will cause the process to stop.
 func (p *Process) Stop() {
-	p.transitionTo(STOPPING)
+	switch p.Status() {
+	case EXITED, FAILED:
+		p.transitionTo(STOPPED)
+	default:
+		p.transitionTo(STOPPING)
+	}
 }
 
 // Restart will cause a running process to restart.
 func (p *Process) Restart() {
-	if p.state == RUNNING {
-		p.Stop()
+	for {
+		s := p.Status()
+		switch s {
+		case STOPPED:
+			p.Start()
+			return
+		case RUNNING, EXITED, FAILED:
+			p.Stop()
+		}
+		p.waitWhile(s)
 	}
-	p.waitFor(STOPPED)
-	p.Start()
 }
 
 // Status returns the current state of the FSM.
 func (p *Process) Status() State {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
 	return p.state
 }
 
+// OnStateChange sets f to be called after each state transition.
+func (p *Process) OnStateChange(f func(from, to State)) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.onStateChange = f
+}
+
+// SetRestartOptions sets how the process is restarted when it exits on its
+// own.
+func (p *Process) SetRestartOptions(o RestartOptions) error {
+	if _, err := ParseRestartPolicy(string(o.Policy)); err != nil {
+		return err
+	}
+	if o.MinBackoff <= 0 || o.MaxBackoff < o.MinBackoff {
+		return fmt.Errorf("backoff should be positive and the max backoff can not be less than the min backoff: %s, %s", o.MinBackoff, o.MaxBackoff)
+	}
+	if o.MaxRestarts < 0 || (o.MaxRestarts > 0 && o.Window <= 0) {
+		return fmt.Errorf("max restarts can not be negative and the window should be positive: %d, %s", o.MaxRestarts, o.Window)
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.restartOpts = o
+	return nil
+}
+
+// ExitError returns the error that the process exited with the last time,
+// nil if it exited successfully.
+func (p *Process) ExitError() error {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.exitErr
+}
+
+// scheduleRestart restarts the exited process after a backoff if the restart
+// policy allows it.
+func (p *Process) scheduleRestart() {
+	p.lock.Lock()
+	opts, exitErr := p.restartOpts, p.exitErr
+	if opts.Policy == RestartNever || (opts.Policy == RestartOnFailure && exitErr == nil) {
+		p.lock.Unlock()
+		logrus.Warnf("process is not restarted due to the '%s' restart policy: %s", opts.Policy, p.executable)
+		return
+	}
+
+	// Forget the restarts that are out of the window.
+	now := time.Now()
+	var restarts []time.Time
+	for _, t := range p.restarts {
+		if now.Sub(t) < opts.Window {
+			restarts = append(restarts, t)
+		}
+	}
+	p.restarts = restarts
+	if opts.MaxRestarts > 0 && len(restarts) >= opts.MaxRestarts {
+		p.lock.Unlock()
+		logrus.Errorf("process is restarted %d times in %s, giving up: %s", len(restarts), opts.Window, p.executable)
+		p.transitionTo(FAILED)
+		return
+	}
+	backoff := opts.backoff(len(restarts))
+	p.restarts = append(p.restarts, now)
+	p.lock.Unlock()
+
+	logrus.Infof("restarting process in %s: %s", backoff, p.executable)
+	time.AfterFunc(backoff, func() {
+		// It may be stopped or started meanwhile.
+		if p.Status() == EXITED {
+			p.transitionTo(STARTING)
+		}
+	})
+}
+
 func (p *Process) permittable(state State) bool {
 	p.lock.Lock()
 	defer p.lock.Unlock()
@@ -178,20 +373,30 @@ func (p *Process) setState(state State) {
 }
 
 func (p *Process) transitionTo(state State) {
-	if p.permittable(state) {
-		p.stateChangeCond.L.Lock()
-		logrus.WithField("cmd", p.executable).Debugf("transition: '%s' -> '%s'", p.state, state)
-		if p.out.Len() > 0 {
-			logrus.Debugf("STDOUT(err): %s", p.out.String())
-		}
-		p.setState(state)
-		go p.run(state)()
+	p.stateChangeCond.L.Lock()
+	if !p.permittable(state) {
 		p.stateChangeCond.L.Unlock()
-		p.stateChangeCond.Broadcast()
+		logrus.Errorf("transition to '%s' from '%s' is not permitted!", state, p.Status())
 		return
 	}
-	logrus.Errorf("transition to '%s' from '%s' is not permitted!", p.state, state)
-	return
+	from := p.Status()
+	logrus.WithField("cmd", p.executable).Debugf("transition: '%s' -> '%s'", from, state)
+	if p.out.Len() > 0 {
+		logrus.Debugf("STDOUT(err): %s", p.out.String())
+	}
+	p.setState(state)
+	p.stateChangeCond.L.Unlock()
+	p.stateChangeCond.Broadcast()
+
+	// Notify before running the state, so that the transitions are notified
+	// in order.
+	p.lock.RLock()
+	onStateChange := p.onStateChange
+	p.lock.RUnlock()
+	if onStateChange != nil {
+		onStateChange(from, state)
+	}
+	go p.run(state)()
 }
 
 func (p *Process) newCommand() *exec.Cmd {
@@ -222,22 +427,28 @@ func (p *Process) newCommand() *exec.Cmd {
 func (p *Process) run(state State) func() {
 	switch state {
 	case STOPPED:
-		return func() {
-			p.done = make(chan error)
-			p.stop = make(chan bool)
-		}
+		return func() {}
 	case STARTING:
 		return func() {
 			// Prepare the command and start.
+			done := make(chan error)
+			p.lock.Lock()
+			p.done = done
+			p.exitErr = nil
+			p.stop = make(chan bool)
+			p.lock.Unlock()
+
+			var cmd *exec.Cmd
 			var err error
 			for i := uint(1); i <= p.maxRetry; i++ {
 				// Prepare the command to run.
+				cmd = p.newCommand()
 				p.lock.Lock()
-				p.cmd = p.newCommand()
+				p.cmd = cmd
 				p.lock.Unlock()
 
 				logrus.Debugf("running %s", p.executable)
-				err = p.cmd.Start()
+				err = cmd.Start()
 				if err != nil {
 					logrus.Debugf("process can not be started: %v", err)
 					logrus.Debugf("retrying... (%d/%d)", i, p.maxRetry)
@@ -252,38 +463,39 @@ func (p *Process) run(state State) func() {
 			}
 
 			// Process started successfully.
-			if p.cmd.Process == nil {
+			if cmd.Process == nil {
 				logrus.Debugf("p.cmd.Process was not created")
 				p.transitionTo(FAILED)
 				return
 			}
-			logrus.Debugf("process is started %s PID %d", p.executable, p.cmd.Process.Pid)
+			logrus.Debugf("process is started %s PID %d", p.executable, cmd.Process.Pid)
 			// Process Observer
 			go func() {
-				err := p.cmd.Wait()
-				if err != nil {
-					logrus.Error(err)
-					close(p.done)
-					return
-				}
-				p.done <- err
-				close(p.done)
+				err := cmd.Wait()
+				p.lock.Lock()
+				p.exitErr = err
+				p.lock.Unlock()
+				close(done)
 			}()
 			p.transitionTo(RUNNING)
 		}
 	case RUNNING:
 		return func() {
+			p.lock.RLock()
+			done, stop := p.done, p.stop
+			p.lock.RUnlock()
+
 			// Stop Observer
 			go func() {
 				select {
 				// process is ordered to stop.
-				case <-p.stop:
+				case <-stop:
 					p.transitionTo(STOPPING)
 					return
 				// process exited on it's own
-				case err := <-p.done:
-					if p.state == RUNNING {
-						logrus.Infof("process exited: %v", err)
+				case <-done:
+					if p.Status() == RUNNING {
+						logrus.Infof("process exited: %v", p.ExitError())
 						p.transitionTo(EXITED)
 						return
 					}
@@ -292,52 +504,54 @@ func (p *Process) run(state State) func() {
 		}
 	case STOPPING:
 		return func() {
-			gracefullyStopped := false
+			p.lock.RLock()
+			done, cmd := p.done, p.cmd
+			p.lock.RUnlock()
+			exited := false
 
 			// first try to kill the process, gracefully
-			err := p.cmd.Process.Signal(os.Interrupt)
+			err := cmd.Process.Signal(os.Interrupt)
 			if err != nil {
 				logrus.Errorf("interrupt signal returned error: %v", err)
 			}
+		retry:
 			for i := uint(1); i <= p.maxRetry; i++ {
 				select {
 				case <-time.After(3 * time.Second):
 					logrus.Debugf("retrying... (%d/%d)", i, p.maxRetry)
-					err := p.cmd.Process.Signal(os.Interrupt)
+					err := cmd.Process.Signal(os.Interrupt)
 					if err != nil {
 						logrus.Errorf("interrupt signal returned error: %v", err)
 					}
-				case err = <-p.done:
-					if err == nil {
-						gracefullyStopped = true
-						break
-					}
-					logrus.Debugf("process stopped with error: %v", err)
-					break
-
+				case <-done:
+					exited = true
+					break retry
 				}
 			}
 
 			// process didn't exit and retry count is full
 			// hard killing
-			if !gracefullyStopped {
-				err := p.cmd.Process.Kill()
+			if !exited {
+				err := cmd.Process.Kill()
 				if err != nil {
-					logrus.Fatal("can not kill process!")
+					logrus.Errorf("can not kill process: %v", err)
 				}
-				<-p.done
+				<-done
+			}
+			if err := p.ExitError(); err != nil {
+				logrus.Debugf("process stopped with error: %v", err)
 			}
 			logrus.Debugf("process stopped %s", p.executable)
 			p.transitionTo(STOPPED)
 		}
 	case FAILED:
 		return func() {
-			logrus.Fatalf("failed to launch process: %s", p.executable)
+			logrus.Errorf("process failed, it should be started again: %s", p.executable)
 		}
 	case EXITED:
 		return func() {
-			logrus.Errorf("process exited unexpectedly: %s", p.executable)
-			os.Exit(1)
+			logrus.Errorf("process exited unexpectedly: %s: %v", p.executable, p.ExitError())
+			p.scheduleRestart()
 		}
 	default: // UNKNOWN
 		return nil
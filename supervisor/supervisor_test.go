@@ -0,0 +1,138 @@
+package supervisor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cad/ovpm/supervisor"
+)
+
+// newTestProcess returns a shell process that runs the script, and a channel
+// that receives the states it transitions to.
+func newTestProcess(t *testing.T, script string, opts supervisor.RestartOptions) (*supervisor.Process, chan supervisor.State) {
+	p, err := supervisor.NewProcess("/bin/sh", "/", []string{"-c", script})
+	if err != nil {
+		t.Skipf("can not create process: %v", err)
+	}
+	if err := p.SetRestartOptions(opts); err != nil {
+		t.Fatalf("can not set restart options: %v", err)
+	}
+	states := make(chan supervisor.State, 64)
+	p.OnStateChange(func(from, to supervisor.State) {
+		states <- to
+	})
+	return p, states
+}
+
+// expectStates fails the test unless the process transitions to the states
+// in order.
+func expectStates(t *testing.T, states chan supervisor.State, expected ...supervisor.State) {
+	t.Helper()
+	for _, e := range expected {
+		select {
+		case s := <-states:
+			if s != e {
+				t.Fatalf("process is expected to transition to %s but it transitioned to %s", e, s)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("process is expected to transition to %s but it didn't", e)
+		}
+	}
+}
+
+// expectNoState fails the test if the process transitions in d.
+func expectNoState(t *testing.T, states chan supervisor.State, d time.Duration) {
+	t.Helper()
+	select {
+	case s := <-states:
+		t.Fatalf("process is not expected to transition but it transitioned to %s", s)
+	case <-time.After(d):
+	}
+}
+
+func testRestartOptions(policy supervisor.RestartPolicy) supervisor.RestartOptions {
+	return supervisor.RestartOptions{
+		Policy:      policy,
+		MinBackoff:  10 * time.Millisecond,
+		MaxBackoff:  50 * time.Millisecond,
+		MaxRestarts: 3,
+		Window:      time.Minute,
+	}
+}
+
+func TestProcessRestartPolicy(t *testing.T) {
+	// Failing processes are restarted until the max restarts is reached.
+	p, states := newTestProcess(t, "exit 1", testRestartOptions(supervisor.RestartOnFailure))
+	p.Start()
+	expectStates(t, states, supervisor.STARTING, supervisor.RUNNING, supervisor.EXITED)
+	for i := 0; i < 3; i++ {
+		expectStates(t, states, supervisor.STARTING, supervisor.RUNNING, supervisor.EXITED)
+	}
+	expectStates(t, states, supervisor.FAILED)
+	if p.ExitError() == nil {
+		t.Errorf("process is expected to have an exit error")
+	}
+
+	// Failed processes can be started again.
+	p.Stop()
+	expectStates(t, states, supervisor.STOPPED)
+	p.SetRestartOptions(testRestartOptions(supervisor.RestartNever))
+	p.Start()
+	expectStates(t, states, supervisor.STARTING, supervisor.RUNNING, supervisor.EXITED)
+	p.Stop()
+	expectStates(t, states, supervisor.STOPPED)
+
+	// Successful exits are not restarted on failure.
+	p, states = newTestProcess(t, "exit 0", testRestartOptions(supervisor.RestartOnFailure))
+	p.Start()
+	expectStates(t, states, supervisor.STARTING, supervisor.RUNNING, supervisor.EXITED)
+	expectNoState(t, states, 200*time.Millisecond)
+	if p.Status() != supervisor.EXITED {
+		t.Errorf("process is expected to be %s but it's %s", supervisor.EXITED, p.Status())
+	}
+
+	// Nothing is restarted with never.
+	p, states = newTestProcess(t, "exit 1", testRestartOptions(supervisor.RestartNever))
+	p.Start()
+	expectStates(t, states, supervisor.STARTING, supervisor.RUNNING, supervisor.EXITED)
+	expectNoState(t, states, 200*time.Millisecond)
+
+	// Processes can be restarted, and stopped processes are not restarted.
+	p, states = newTestProcess(t, "exec sleep 60", testRestartOptions(supervisor.RestartAlways))
+	p.Start()
+	expectStates(t, states, supervisor.STARTING, supervisor.RUNNING)
+	p.Restart()
+	expectStates(t, states, supervisor.STOPPING, supervisor.STOPPED, supervisor.STARTING, supervisor.RUNNING)
+	p.Stop()
+	expectStates(t, states, supervisor.STOPPING, supervisor.STOPPED)
+	expectNoState(t, states, 200*time.Millisecond)
+}
+
+func TestRestartOptions(t *testing.T) {
+	if _, err := supervisor.ParseRestartPolicy("sometimes"); err == nil {
+		t.Errorf("unknown restart policy is expected to be rejected")
+	}
+	for _, s := range []string{"always", "on-failure", "never"} {
+		if _, err := supervisor.ParseRestartPolicy(s); err != nil {
+			t.Errorf("restart policy %s is expected to be parsed: %v", s, err)
+		}
+	}
+
+	p, err := supervisor.NewProcess("/bin/sh", "/", nil)
+	if err != nil {
+		t.Skipf("can not create process: %v", err)
+	}
+	if err := p.SetRestartOptions(supervisor.DefaultRestartOptions); err != nil {
+		t.Errorf("default restart options are expected to be valid: %v", err)
+	}
+	opts := supervisor.DefaultRestartOptions
+	opts.MaxBackoff = opts.MinBackoff / 2
+	if err := p.SetRestartOptions(opts); err == nil {
+		t.Errorf("max backoff that is less than the min backoff is expected to be rejected")
+	}
+	opts = supervisor.DefaultRestartOptions
+	opts.MaxRestarts = -1
+	if err := p.SetRestartOptions(opts); err == nil {
+		t.Errorf("negative max restarts is expected to be rejected")
+	}
+}
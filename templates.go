@@ -1,6 +1,9 @@
 package ovpm
 
 const ccdFileTemplate = `
+{{- if .Disable }}
+disable
+{{- end }}
 ifconfig-push {{ .IP }} {{ .NetMask }}
 
 {{if .RedirectGW }}
@@ -26,8 +29,21 @@ server-poll-timeout 4
 proto {{ .Proto }}
 remote {{ .Hostname }} {{ .Port }}
 resolv-retry infinite
+{{- if .RemoteCertTLS }}
+remote-cert-tls server
+{{- else }}
 ns-cert-type server
-cipher AES-128-CBC
+{{- end }}
+{{- if .DataCiphersFallback }}
+cipher {{ .DataCiphersFallback }}
+{{- end }}
+ignore-unknown-option data-ciphers
+data-ciphers {{ .DataCiphers }}
+auth {{ .AuthDigest }}
+tls-version-min {{ .TLSVersionMin }}
+{{- if .TLSCipher }}
+tls-cipher {{ .TLSCipher }}
+{{- end }}
 nobind
 keepalive {{ .KeepalivePeriod }} {{ .KeepaliveTimeout }}
 persist-key
@@ -35,40 +51,40 @@ persist-tun
 {{ if .UseLZO }}comp-lzo{{ end }}
 verb 3
 auth-nocache
-
+{{- if .AuthUserPass }}
+auth-user-pass
+{{- if .StaticChallenge }}
+static-challenge "Enter the code from your authenticator app" 1
+{{- end }}
+{{- end }}
+
+{{ if .CAFile -}}
+ca {{ .CAFile }}
+cert {{ .CertFile }}
+{{- if .KeyFile }}
+key {{ .KeyFile }}
+{{- end }}
+{{- if .TLSCryptFile }}
+{{ .TLSCrypt }} {{ .TLSCryptFile }}
+{{- end }}
+{{- else -}}
 <ca>
 {{ .CA }}</ca>
 <cert>
 {{ .Cert }}</cert>
+{{- if .Key }}
 <key>
 {{ .Key }}</key>
-`
-
-const dh4096PemTemplate = `
------BEGIN DH PARAMETERS-----
-MIIEDQKCAgEAkeaoNKpzWIU4+8hJUfFeHJJki7lzxK9lVJzoT1O3vRD1pv1M0jm4
-d372rq7crH9lolgi0VydQagf+831JZJ7m9/PYfYRnU2bf53j4I8O8CgFBCjWLBgc
-dzPG7I4NGAc1YHV3R+Guh8bFgOvcfiWw4nEMNjkquHcZ7vivEfy5h2OGmbtqkrkQ
-6IV1uFGagwvQzIta3sxHL2r8Xxq3iGoXnW6azGcc6Zv+0p0gZWSkobu2GUdaIsH6
-jum//TZvouwkDEh60utF5wPwoqMye9ahUOexz+7tI7ue1/lre+hLaUWQigzEe56b
-fR5WUZRhwNg9zTngpFTLXiAI39J8xHbUid3kv/CSDPCq6XhUMWyCyLBsSSdc1Ijq
-pR675BBqLwSo4oON/o1TgMYD3/3xSakXSULvSODYlLdnHniL8Kh+B+2egAZeayUb
-pqV6lFUXFo1XjklWQscq2gwuKvw7XCd0PnYad0rxLVb/aLyYKzv4crL5RRs/xOcf
-O2D3/o4ld3nWQ5iB5iH47CGAlnaR/0jD5gGnOF8mgNGx64PMB+W5QekzZ/aams9K
-M0ctXH7vVdQvc+r86KDt/IQXopuooX1K4kmhqUjkWJrjt0UvVRp2skIYSNEeljA0
-NLeC/k0YB0rNWZx3URg3XS/v0oJO+Pt/O5U2iuopHZp2uWh9LeQ+qisCggIAJtmv
-4H727O/aDcty1c8K32pKGym+LGBxx9nuuMTjpa5N/Ww/wtHcQWKVo1HdknGoBOXc
-9a6I7574fJFuwEzQe4b44VYk+t+9pLxsBT/r36Qi1eFyrYgMhoiLgjfgJ1tarXE9
-HJ6jLJm/DIKoxwu5XRHUw1YJ7KDjqw1IZGeMEWhUxsyvPyGviyCiHPbmrvNJzJnP
-7ig1nMbbHi/Al+P9K1forRA5+VOf5fvTPtq6mxoQ585cCrLY3EqQe+fPlUzcBL/b
-fMVnw90b7sQVI9EEXxpp52uIY9xtTzsfaK3Gv3pmTeFEecyda2/Ow8oGYzobn5SA
-O9xEpc2mNUlONT4thBPh/3NYPGZnIiDYN8vHjKiSnYcYRsbWpxvTEZRNEkI6h5dV
-83EvsNMOoG8duJQhluQxka0OhFg0UTGhec11Xs0iTti8CvnOpKqyg2vXlOn0I94P
-IVcAwG0ppLn8Py3sBrYO5HF5pStopEPTceylCSkkOT31lVKEAu4TMBrEZh9x2nIV
-AHGxQVTkeqx8u7k9Hg02tX9Hm6w8aFF7WCPLJJLe+MWHlOtp4C6ep/PFnc6DESo9
-gWCAPLIM7tcb9iSSWBmugSkqVolAgLbX/T95Sxnvn4cwchgtgsoOV2lNUt89B8kf
-YtMGCwfzKB0Ws3SW31ayu9jzDgyIr+isjqk1a7MCAgEA
------END DH PARAMETERS-----
+{{- end }}
+{{- if .TLSCryptKey }}
+<{{ .TLSCrypt }}>
+{{ .TLSCryptKey }}</{{ .TLSCrypt }}>
+{{- end }}
+{{- end }}
+{{- if not .Key }}
+# The private key is held by the user, point to it with:
+# key /path/to/private.key
+{{- end }}
 `
 
 const serverConfTemplate = `
@@ -134,7 +150,15 @@ key {{ .KeyPath }}
 # 2048 bit keys.
 #dh dh1024.pem
 ;dh easy-rsa/keys/dh2048.pem
+{{- if .DHNone }}
+# Only ECDHE is used for the key exchange.
+dh none
+{{- else }}
 dh {{ .DHParamsPath }}
+{{- end }}
+{{- if .ECDHCurve }}
+ecdh-curve {{ .ECDHCurve }}
+{{- end }}
 
 # Network topology
 # Should be subnet (addressing via IP)
@@ -301,6 +325,11 @@ keepalive {{ .KeepalivePeriod }} {{ .KeepaliveTimeout }}
 # The second parameter should be '0'
 # on the server and '1' on the clients.
 ;tls-auth ta.key 0 # This file is secret
+{{- if eq .TLSCrypt "tls-crypt" }}
+tls-crypt {{ .TLSCryptKeyPath }}
+{{- else if eq .TLSCrypt "tls-crypt-v2" }}
+tls-crypt-v2 {{ .TLSCryptKeyPath }}
+{{- end }}
 
 # Select a cryptographic cipher.
 # This config item must be copied to
@@ -308,7 +337,16 @@ keepalive {{ .KeepalivePeriod }} {{ .KeepaliveTimeout }}
 ;cipher BF-CBC        # Blowfish (default)
 ;cipher AES-128-CBC   # AES
 ;cipher DES-EDE3-CBC  # Triple-DES
-cipher AES-128-CBC
+# data-ciphers requires OpenVPN 2.5 or later.
+data-ciphers {{ .DataCiphers }}
+{{- if .DataCiphersFallback }}
+data-ciphers-fallback {{ .DataCiphersFallback }}
+{{- end }}
+auth {{ .AuthDigest }}
+tls-version-min {{ .TLSVersionMin }}
+{{- if .TLSCipher }}
+tls-cipher {{ .TLSCipher }}
+{{- end }}
 
 {{ if .UseLZO }}
 # Enable compression on the VPN link.
@@ -340,6 +378,18 @@ persist-tun
 # and rewritten every minute.
 status openvpn-status.log 5
 
+# Enable the management interface on a unix
+# socket. ovpmd uses it to watch and control
+# the connected clients in real time.
+management {{ .ManagementPath }} unix
+{{- if .AuthUserPass }}
+
+# Clients need to send the user's password along
+# with the certificate. ovpmd verifies it.
+script-security 2
+auth-user-pass-verify "{{ .VerifyCommand }}" via-file
+{{- end }}
+
 # By default, log messages will go to the syslog (or
 # on Windows, if running as a service, they will go to
 # the "\Program Files\OpenVPN\log" directory).
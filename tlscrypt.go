@@ -0,0 +1,132 @@
+package ovpm
+
+import (
+	"fmt"
+
+	"github.com/cad/ovpm/pki"
+	"github.com/sirupsen/logrus"
+)
+
+// Possible control channel protection modes.
+const (
+	// TLSCryptNone leaves the control channel unprotected.
+	TLSCryptNone string = "none"
+
+	// TLSCrypt encrypts and authenticates the control channel with a static
+	// key that is shared by the server and all of the clients.
+	TLSCrypt string = "tls-crypt"
+
+	// TLSCryptV2 is like TLSCrypt but every client gets its own key which is
+	// wrapped by the server key.
+	TLSCryptV2 string = "tls-crypt-v2"
+)
+
+// validateTLSCrypt checks that mode is one of the control channel protection modes or "".
+func validateTLSCrypt(mode string) error {
+	switch mode {
+	case "", TLSCryptNone, TLSCrypt, TLSCryptV2:
+		return nil
+	}
+	return fmt.Errorf("validation error: tls crypt:`%s` should be either '%s', '%s' or '%s'", mode, TLSCryptNone, TLSCrypt, TLSCryptV2)
+}
+
+// newTLSCryptKey generates the server side key of the given control channel protection mode.
+func newTLSCryptKey(mode string) (string, error) {
+	switch mode {
+	case TLSCrypt:
+		return pki.NewStaticKey()
+	case TLSCryptV2:
+		return pki.NewTLSCryptV2ServerKey()
+	}
+	return "", nil
+}
+
+// GetTLSCrypt returns the control channel protection mode of the server.
+func (svr *Server) GetTLSCrypt() string {
+	if svr.TLSCrypt != "" {
+		return svr.TLSCrypt
+	}
+	return TLSCryptNone
+}
+
+// RotateTLSCryptKey generates a new server side key of the current control
+// channel protection mode and re-keys the users.
+//
+// OpenVPN can't accept two keys at once, so the .ovpn profiles exported
+// before the rotation can't connect anymore. Users need to get their .ovpn
+// profiles exported again right away.
+func (svr *Server) RotateTLSCryptKey() error {
+	if !svr.IsInitialized() {
+		return fmt.Errorf("server is not initialized")
+	}
+	if svr.GetTLSCrypt() == TLSCryptNone {
+		return fmt.Errorf("control channel protection is disabled, there is no key to rotate")
+	}
+	key, err := newTLSCryptKey(svr.GetTLSCrypt())
+	if err != nil {
+		return fmt.Errorf("can not generate tls crypt key: %v", err)
+	}
+	svr.dbServerModel.TLSCryptKey = key
+	db.Save(&svr.dbServerModel)
+	if err := svr.rekeyUsers(); err != nil {
+		return err
+	}
+
+	if err := svr.EmitWithRestart(); err != nil {
+		return err
+	}
+	logrus.Infof("%s key rotated, users need to get their .ovpn profiles exported again", svr.GetTLSCrypt())
+	return nil
+}
+
+// rekeyUsers issues new tls-crypt-v2 client keys for all of the users if the
+// server uses tls-crypt-v2, removes them otherwise.
+func (svr *Server) rekeyUsers() error {
+	users, err := GetAllUsers()
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		key, err := svr.newClientTLSCryptKey()
+		if err != nil {
+			return err
+		}
+		user.TLSCryptV2Key = key
+		db.Save(&user.dbUserModel)
+		if key != "" {
+			logrus.Infof("tls-crypt-v2 key changed for %s, you should run: $ ovpm user genconfig --user %s", user.Username, user.Username)
+		}
+	}
+	return nil
+}
+
+// newClientTLSCryptKey returns a new tls-crypt-v2 client key if the server uses tls-crypt-v2.
+func (svr *Server) newClientTLSCryptKey() (string, error) {
+	if svr.GetTLSCrypt() != TLSCryptV2 {
+		return "", nil
+	}
+	key, err := pki.NewTLSCryptV2ClientKey(svr.TLSCryptKey)
+	if err != nil {
+		return "", fmt.Errorf("can not generate tls-crypt-v2 client key: %v", err)
+	}
+	return key, nil
+}
+
+// clientTLSCryptKey returns the key that the given user should use to protect the control channel.
+func (svr *Server) clientTLSCryptKey(user *User) string {
+	switch svr.GetTLSCrypt() {
+	case TLSCrypt:
+		return svr.TLSCryptKey
+	case TLSCryptV2:
+		return user.TLSCryptV2Key
+	}
+	return ""
+}
+
+func (svr *Server) emitTLSCryptKey() error {
+	if svr.GetTLSCrypt() == TLSCryptNone {
+		return nil
+	}
+	// Write the static key or the tls-crypt-v2 server key into the tls crypt key file.
+	return svr.emitToFile(_DefaultTLSCryptKeyPath, svr.TLSCryptKey, 0600)
+}
@@ -0,0 +1,113 @@
+package ovpm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cad/ovpm/pki"
+)
+
+func TestVPNTLSCrypt(t *testing.T) {
+	// Initialize:
+	setupTestCase()
+	CreateDB("sqlite3", ":memory:")
+	defer db.Cease()
+	svr := TheServer()
+
+	// Prepare:
+	if err := svr.Init("localhost", "", UDPProto, "", "", "", "", false, &ServerOptions{TLSCrypt: "tls-auth"}); err == nil {
+		t.Fatalf("init is expected to fail with an unknown tls crypt mode")
+	}
+	if err := svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil); err != nil {
+		t.Fatalf("can not init server: %v", err)
+	}
+	svr = TheServer()
+	usr, err := CreateNewUser("usr1", "1234", false, 0, true, "description")
+	if err != nil {
+		t.Fatalf("user creation failed: %v", err)
+	}
+
+	// Test:
+	// tls-crypt is the default and the static key is shared with all the clients.
+	if svr.GetTLSCrypt() != TLSCrypt {
+		t.Fatalf("tls crypt is expected to be %s by default but it's %s", TLSCrypt, svr.GetTLSCrypt())
+	}
+	if typ, _, err := pki.DecodeOpenVPNKey(svr.TLSCryptKey); err != nil || typ != pki.PEMStaticKeyBlockType {
+		t.Fatalf("tls crypt key is expected to be a static key but it's not: %v", err)
+	}
+	if fs[_DefaultTLSCryptKeyPath] != svr.TLSCryptKey {
+		t.Errorf("tls crypt key is expected to be emitted to %s", _DefaultTLSCryptKeyPath)
+	}
+	if !strings.Contains(fs[_DefaultVPNConfPath], "\ntls-crypt "+_DefaultTLSCryptKeyPath+"\n") {
+		t.Errorf("server.conf is expected to use tls-crypt:\n%s", fs[_DefaultVPNConfPath])
+	}
+	config, _ := svr.DumpsClientConfig(usr.GetUsername())
+	if !strings.Contains(config, "<tls-crypt>\n"+svr.TLSCryptKey+"</tls-crypt>") {
+		t.Errorf("client config is expected to embed the tls crypt key:\n%s", config)
+	}
+
+	// tls-crypt-v2 gives every user its own key.
+	if err := svr.Update("", "", nil, &ServerOptions{TLSCrypt: TLSCryptV2}); err != nil {
+		t.Fatalf("can not update server: %v", err)
+	}
+	svr = TheServer()
+	if typ, _, err := pki.DecodeOpenVPNKey(svr.TLSCryptKey); err != nil || typ != pki.PEMTLSCryptV2ServerBlockType {
+		t.Fatalf("tls crypt key is expected to be a tls-crypt-v2 server key but it's not: %v", err)
+	}
+	if !strings.Contains(fs[_DefaultVPNConfPath], "\ntls-crypt-v2 "+_DefaultTLSCryptKeyPath+"\n") {
+		t.Errorf("server.conf is expected to use tls-crypt-v2:\n%s", fs[_DefaultVPNConfPath])
+	}
+	usr, _ = GetUser(usr.GetUsername())
+	usr2, err := CreateNewUser("usr2", "1234", false, 0, true, "description")
+	if err != nil {
+		t.Fatalf("user creation failed: %v", err)
+	}
+	for _, u := range []*User{usr, usr2} {
+		if typ, _, err := pki.DecodeOpenVPNKey(u.TLSCryptV2Key); err != nil || typ != pki.PEMTLSCryptV2ClientBlockType {
+			t.Fatalf("user %s is expected to have a tls-crypt-v2 client key but it doesn't: %v", u.GetUsername(), err)
+		}
+	}
+	if usr.TLSCryptV2Key == usr2.TLSCryptV2Key {
+		t.Errorf("users are expected to have different tls-crypt-v2 client keys")
+	}
+	config, _ = svr.DumpsClientConfig(usr.GetUsername())
+	if !strings.Contains(config, "<tls-crypt-v2>\n"+usr.TLSCryptV2Key+"</tls-crypt-v2>") {
+		t.Errorf("client config is expected to embed the user's tls-crypt-v2 key:\n%s", config)
+	}
+
+	// Keys are kept when the CA is rotated, and rotated only on demand.
+	serverKey, clientKey := svr.TLSCryptKey, usr.TLSCryptV2Key
+	if err := svr.RotateCA(0); err != nil {
+		t.Fatalf("can not rotate ca: %v", err)
+	}
+	svr = TheServer()
+	usr, _ = GetUser(usr.GetUsername())
+	if svr.TLSCryptKey != serverKey || usr.TLSCryptV2Key != clientKey {
+		t.Errorf("tls crypt keys are expected to be kept when the ca is rotated")
+	}
+	if err := svr.RotateTLSCryptKey(); err != nil {
+		t.Fatalf("can not rotate tls crypt key: %v", err)
+	}
+	svr = TheServer()
+	usr, _ = GetUser(usr.GetUsername())
+	if svr.TLSCryptKey == serverKey || usr.TLSCryptV2Key == clientKey || usr.TLSCryptV2Key == "" {
+		t.Errorf("tls crypt keys are expected to be rotated")
+	}
+
+	// Disabling it removes the keys.
+	if err := svr.Update("", "", nil, &ServerOptions{TLSCrypt: TLSCryptNone}); err != nil {
+		t.Fatalf("can not update server: %v", err)
+	}
+	svr = TheServer()
+	usr, _ = GetUser(usr.GetUsername())
+	if usr.TLSCryptV2Key != "" {
+		t.Errorf("user's tls-crypt-v2 key is expected to be removed")
+	}
+	config, _ = svr.DumpsClientConfig(usr.GetUsername())
+	if strings.Contains(config, "tls-crypt") || strings.Contains(fs[_DefaultVPNConfPath], "\ntls-crypt") {
+		t.Errorf("tls crypt is expected to be disabled")
+	}
+	if err := svr.RotateTLSCryptKey(); err == nil {
+		t.Errorf("rotating the tls crypt key is expected to fail when tls crypt is disabled")
+	}
+}
@@ -0,0 +1,222 @@
+package ovpm
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+)
+
+// tokenTouchInterval is how often the last used time of a token is written
+// to the db, so that every request doesn't cause a write.
+const tokenTouchInterval = time.Minute
+
+// dbTokenModel is database model for the API tokens of the users.
+//
+// Only the hashes of the tokens are stored, so the tokens can't be read back
+// from the db.
+type dbTokenModel struct {
+	gorm.Model
+	UserID     uint   `gorm:"index"`
+	Hash       string `gorm:"unique_index"` // Hex encoded SHA-256 of the token.
+	Label      string // Given by the user, e.g. laptop.
+	UserAgent  string // User agent of the client that the token is issued to.
+	ExpiresAt  time.Time
+	LastUsedAt time.Time
+}
+
+// Token represents an API token (a login session) of a user.
+type Token struct {
+	dbTokenModel
+}
+
+// GetID returns the id of the token.
+func (t *Token) GetID() uint {
+	return t.ID
+}
+
+// GetLabel returns the label of the token.
+func (t *Token) GetLabel() string {
+	return t.Label
+}
+
+// GetUserAgent returns the user agent of the client that the token is
+// issued to.
+func (t *Token) GetUserAgent() string {
+	return t.UserAgent
+}
+
+// GetCreatedAt returns when the token is issued.
+func (t *Token) GetCreatedAt() time.Time {
+	return t.CreatedAt
+}
+
+// GetLastUsedAt returns when the token is last used, with a precision of a
+// minute.
+func (t *Token) GetLastUsedAt() time.Time {
+	return t.LastUsedAt
+}
+
+// ExpiresAt returns when the token expires if it's not used until then.
+func (t *Token) ExpiresAt() time.Time {
+	idle := t.LastUsedAt.Add(DefaultTokenIdleTimeout)
+	if idle.Before(t.dbTokenModel.ExpiresAt) {
+		return idle
+	}
+	return t.dbTokenModel.ExpiresAt
+}
+
+// IsExpired returns whether the token is expired.
+func (t *Token) IsExpired() bool {
+	return !time.Now().Before(t.ExpiresAt())
+}
+
+// Matches returns whether the token is the given raw token.
+func (t *Token) Matches(token string) bool {
+	return subtle.ConstantTimeCompare([]byte(t.Hash), []byte(hashToken(token))) == 1
+}
+
+// IssueToken issues a new API token for the user and returns it.
+//
+// Tokens expire when they aren't used for DefaultTokenIdleTimeout, or after
+// DefaultTokenLifetime even if they're used. The other tokens of the user
+// stay valid.
+func (u *User) IssueToken(label, userAgent string) (string, error) {
+	if db.NewRecord(u.dbUserModel) {
+		return "", fmt.Errorf("user is not initialized: %s", u.Username)
+	}
+	if u.Disabled {
+		return "", fmt.Errorf("user is disabled: %s", u.Username)
+	}
+	b := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", fmt.Errorf("can not generate token: %v", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	now := time.Now()
+	t := dbTokenModel{
+		UserID:     u.ID,
+		Hash:       hashToken(token),
+		Label:      label,
+		UserAgent:  userAgent,
+		ExpiresAt:  now.Add(DefaultTokenLifetime),
+		LastUsedAt: now,
+	}
+	db.Create(&t)
+	if db.NewRecord(&t) {
+		return "", fmt.Errorf("can not save token of %s", u.Username)
+	}
+	pruneExpiredTokens()
+	logrus.Debugf("token issued for %s: %d", u.Username, t.ID)
+	return token, nil
+}
+
+// RenewToken issues a new API token for the user without a label.
+func (u *User) RenewToken() (string, error) {
+	return u.IssueToken("", "")
+}
+
+// ValidateToken returns whether the given token is a valid token of the user.
+func (u *User) ValidateToken(token string) bool {
+	t, err := getToken(token)
+	return err == nil && t.UserID == u.ID
+}
+
+// GetTokens returns the tokens of the user that aren't expired.
+func (u *User) GetTokens() ([]*Token, error) {
+	var dbTokens []*dbTokenModel
+	q := db.Where("user_id = ?", u.ID).Order("id asc").Find(&dbTokens)
+	if q.Error != nil {
+		return nil, fmt.Errorf("can not get tokens of %s: %v", u.Username, q.Error)
+	}
+	var tokens []*Token
+	for _, t := range dbTokens {
+		token := &Token{dbTokenModel: *t}
+		if !token.IsExpired() {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+// RevokeToken revokes the token of the user with the given id.
+func (u *User) RevokeToken(id uint) error {
+	var t dbTokenModel
+	if db.Where("id = ? AND user_id = ?", id, u.ID).First(&t).RecordNotFound() {
+		return fmt.Errorf("token %d of %s not found", id, u.Username)
+	}
+	db.Unscoped().Delete(&t)
+	logrus.Infof("token revoked for %s: %d", u.Username, id)
+	return nil
+}
+
+// RevokeTokens revokes all tokens of the user.
+func (u *User) RevokeTokens() {
+	if u.ID == 0 {
+		return
+	}
+	db.Unscoped().Where("user_id = ?", u.ID).Delete(dbTokenModel{})
+}
+
+// GetUserByToken finds and returns the user with the given token from database.
+func GetUserByToken(token string) (*User, error) {
+	if token == "" {
+		return nil, fmt.Errorf("token can not be empty")
+	}
+	t, err := getToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	user := dbUserModel{}
+	if db.First(&user, t.UserID).RecordNotFound() {
+		return nil, fmt.Errorf("user not found by token: <token>")
+	}
+	if user.Disabled {
+		return nil, fmt.Errorf("user is disabled: %s", user.Username)
+	}
+
+	// Slide the expiry.
+	if now := time.Now(); now.Sub(t.LastUsedAt) > tokenTouchInterval {
+		db.Model(&t.dbTokenModel).UpdateColumn("last_used_at", now)
+	}
+	return &User{dbUserModel: user}, nil
+}
+
+// getToken returns the valid token that matches the raw token.
+//
+// Tokens are looked up by their hashes, so the time it takes doesn't reveal
+// the stored tokens.
+func getToken(token string) (*Token, error) {
+	var t dbTokenModel
+	if db.Where("hash = ?", hashToken(token)).First(&t).RecordNotFound() {
+		return nil, fmt.Errorf("token not found: <token>")
+	}
+	tok := &Token{dbTokenModel: t}
+	if !tok.Matches(token) {
+		return nil, fmt.Errorf("token not found: <token>")
+	}
+	if tok.IsExpired() {
+		return nil, fmt.Errorf("token is expired: %d", t.ID)
+	}
+	return tok, nil
+}
+
+// pruneExpiredTokens deletes the expired tokens of all users.
+func pruneExpiredTokens() {
+	now := time.Now()
+	db.Unscoped().Where("expires_at <= ? OR last_used_at <= ?", now, now.Add(-DefaultTokenIdleTimeout)).Delete(dbTokenModel{})
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
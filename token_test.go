@@ -0,0 +1,106 @@
+package ovpm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUserTokens(t *testing.T) {
+	// Initialize:
+	setupTestCase()
+	CreateDB("sqlite3", ":memory:")
+	defer db.Cease()
+	svr := TheServer()
+	svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil)
+	user, err := CreateNewUser("usr1", "1234", false, 0, false, "description")
+	if err != nil {
+		t.Fatalf("user creation failed: %v", err)
+	}
+	other, err := CreateNewUser("usr2", "1234", false, 0, false, "description")
+	if err != nil {
+		t.Fatalf("user creation failed: %v", err)
+	}
+
+	// Test:
+	// Logging in again doesn't log out the previous sessions.
+	laptop, err := user.IssueToken("laptop", "curl/7.0")
+	if err != nil {
+		t.Fatalf("can not issue token: %v", err)
+	}
+	phone, err := user.RenewToken()
+	if err != nil {
+		t.Fatalf("can not issue token: %v", err)
+	}
+	for _, token := range []string{laptop, phone} {
+		if u, err := GetUserByToken(token); err != nil || u.GetUsername() != "usr1" {
+			t.Errorf("token is expected to authenticate usr1: %v", err)
+		}
+	}
+	if !user.ValidateToken(laptop) || other.ValidateToken(laptop) {
+		t.Errorf("token is expected to be valid only for its user")
+	}
+	if _, err := GetUserByToken(""); err == nil {
+		t.Errorf("empty token is expected to be rejected")
+	}
+
+	// Only the hashes are stored.
+	tokens, err := user.GetTokens()
+	if err != nil || len(tokens) != 2 {
+		t.Fatalf("user is expected to have 2 tokens: %v", err)
+	}
+	if tokens[0].Hash == laptop || !tokens[0].Matches(laptop) || tokens[0].GetLabel() != "laptop" || tokens[0].GetUserAgent() != "curl/7.0" {
+		t.Errorf("unexpected token: %+v", tokens[0].dbTokenModel)
+	}
+
+	// Revoked tokens are rejected, the others stay valid.
+	if err := other.RevokeToken(tokens[0].GetID()); err == nil {
+		t.Errorf("token is expected to be revoked only by its user")
+	}
+	if err := user.RevokeToken(tokens[0].GetID()); err != nil {
+		t.Fatalf("can not revoke token: %v", err)
+	}
+	if _, err := GetUserByToken(laptop); err == nil {
+		t.Errorf("revoked token is expected to be rejected")
+	}
+	if _, err := GetUserByToken(phone); err != nil {
+		t.Errorf("other token is expected to stay valid: %v", err)
+	}
+
+	// Tokens expire when they're idle and when they're too old.
+	idle, _ := user.IssueToken("idle", "")
+	db.Model(&dbTokenModel{}).Where("hash = ?", hashToken(idle)).UpdateColumn("last_used_at", time.Now().Add(-DefaultTokenIdleTimeout-time.Minute))
+	if _, err := GetUserByToken(idle); err == nil {
+		t.Errorf("idle token is expected to be expired")
+	}
+	old, _ := user.IssueToken("old", "")
+	db.Model(&dbTokenModel{}).Where("hash = ?", hashToken(old)).UpdateColumn("expires_at", time.Now().Add(-time.Minute))
+	if _, err := GetUserByToken(old); err == nil {
+		t.Errorf("old token is expected to be expired")
+	}
+	if tokens, _ := user.GetTokens(); len(tokens) != 1 {
+		t.Errorf("expired tokens are expected to be left out: %d", len(tokens))
+	}
+
+	// Usage slides the idle expiry.
+	db.Model(&dbTokenModel{}).Where("hash = ?", hashToken(phone)).UpdateColumn("last_used_at", time.Now().Add(-time.Hour))
+	GetUserByToken(phone)
+	if tokens, _ := user.GetTokens(); time.Since(tokens[0].GetLastUsedAt()) > time.Minute {
+		t.Errorf("last used time is expected to be updated: %v", tokens[0].GetLastUsedAt())
+	}
+
+	// Password resets and disabling revoke all tokens.
+	if err := user.ResetPassword("4321"); err != nil {
+		t.Fatalf("can not reset password: %v", err)
+	}
+	if _, err := GetUserByToken(phone); err == nil {
+		t.Errorf("tokens are expected to be revoked after a password reset")
+	}
+	token, _ := other.RenewToken()
+	other.setDisabled(true)
+	if _, err := GetUserByToken(token); err == nil {
+		t.Errorf("tokens are expected to be revoked when the user is disabled")
+	}
+	if _, err := other.RenewToken(); err == nil {
+		t.Errorf("disabled user is expected to not get a token")
+	}
+}
@@ -0,0 +1,275 @@
+package ovpm
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+)
+
+// TOTP parameters. They're the defaults of RFC 6238, which every
+// authenticator app supports.
+const (
+	_TOTPIssuer        = "OVPM"
+	_TOTPPeriod        = 30 // seconds
+	_TOTPDigits        = 6
+	_TOTPSkew          = 1 // number of the periods that the clocks are allowed to drift
+	_TOTPSecretSize    = 20
+	_RecoveryCodeCount = 10
+)
+
+// TOTP lockout parameters. A 6 digit code can be guessed in a few hundred
+// thousand attempts, so the attempts are locked out after a few failures and
+// the lockout doubles with each failure afterwards.
+const (
+	_TOTPMaxFailures = 5
+	_TOTPLockout     = time.Minute
+	_TOTPMaxLockout  = time.Hour
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// EnrollTOTP generates a new TOTP secret for the user and returns its
+// otpauth:// provisioning URI to scan with an authenticator app.
+//
+// TOTP isn't enforced until the enrollment is confirmed with the first code
+// through ConfirmTOTP.
+func (u *User) EnrollTOTP() (string, error) {
+	if u.IsTOTPEnabled() {
+		return "", fmt.Errorf("totp is already enrolled for %s, it should be reset first", u.Username)
+	}
+	secret := make([]byte, _TOTPSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("can not generate totp secret: %v", err)
+	}
+	u.dbUserModel.TOTPSecret = totpEncoding.EncodeToString(secret)
+	u.dbUserModel.TOTPLastStep = 0
+	db.Save(&u.dbUserModel)
+	return u.totpURI(), nil
+}
+
+// totpURI returns the otpauth:// provisioning URI of the user's TOTP secret.
+func (u *User) totpURI() string {
+	params := url.Values{}
+	params.Set("secret", u.TOTPSecret)
+	params.Set("issuer", _TOTPIssuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprintf("%d", _TOTPDigits))
+	params.Set("period", fmt.Sprintf("%d", _TOTPPeriod))
+	return fmt.Sprintf("otpauth://totp/%s:%s?%s", url.PathEscape(_TOTPIssuer), url.PathEscape(u.Username), params.Encode())
+}
+
+// ConfirmTOTP completes the TOTP enrollment of the user with the first code
+// from the authenticator app and returns the recovery codes.
+//
+// Recovery codes are only stored as hashes, so they can't be shown again.
+func (u *User) ConfirmTOTP(code string) ([]string, error) {
+	if u.TOTPSecret == "" {
+		return nil, fmt.Errorf("totp is not enrolled for %s", u.Username)
+	}
+	if u.IsTOTPEnabled() {
+		return nil, fmt.Errorf("totp is already confirmed for %s", u.Username)
+	}
+	step, ok := u.matchTOTP(code, time.Now())
+	if !ok {
+		return nil, fmt.Errorf("totp code is wrong")
+	}
+
+	var codes, sums []string
+	for i := 0; i < _RecoveryCodeCount; i++ {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, fmt.Errorf("can not generate recovery codes: %v", err)
+		}
+		c := strings.ToLower(totpEncoding.EncodeToString(b))
+		codes = append(codes, c[:4]+"-"+c[4:])
+		sums = append(sums, recoveryCodeSum(c))
+	}
+	u.dbUserModel.TOTPEnabled = true
+	u.dbUserModel.TOTPLastStep = step
+	u.dbUserModel.RecoveryCodes = strings.Join(sums, "\n")
+	db.Save(&u.dbUserModel)
+
+	if !TheServer().IsAuthUserPass() {
+		logrus.Warnf("totp of %s is only enforced for the web api, since the vpn server doesn't ask for passwords", u.Username)
+	} else {
+		logrus.Infof("totp is enrolled for %s, you should run: $ ovpm user genconfig --user %s", u.Username, u.Username)
+	}
+	return codes, nil
+}
+
+// ResetTOTP removes the TOTP enrollment and the recovery codes of the user.
+func (u *User) ResetTOTP() error {
+	u.dbUserModel.TOTPSecret = ""
+	u.dbUserModel.TOTPEnabled = false
+	u.dbUserModel.TOTPLastStep = 0
+	u.dbUserModel.TOTPFailures = 0
+	u.dbUserModel.TOTPLockedUntil = time.Time{}
+	u.dbUserModel.RecoveryCodes = ""
+	db.Save(&u.dbUserModel)
+	return nil
+}
+
+// IsTOTPEnabled returns whether the user needs a TOTP code to log in.
+func (u *User) IsTOTPEnabled() bool {
+	return u.TOTPEnabled
+}
+
+// IsTOTPLocked returns whether the TOTP attempts of the user are locked out
+// after too many failures.
+func (u *User) IsTOTPLocked() bool {
+	var m dbUserModel
+	if db.Select("totp_locked_until").First(&m, u.ID).Error != nil {
+		return false
+	}
+	return time.Now().Before(m.TOTPLockedUntil)
+}
+
+// VerifyTOTP checks the TOTP code or one of the recovery codes of the user.
+//
+// A TOTP code can't be used twice and a recovery code is removed once it's used.
+// After _TOTPMaxFailures consecutive failures, the attempts are refused for a
+// while. ResetTOTP lifts the lockout.
+func (u *User) VerifyTOTP(code string) error {
+	if !u.IsTOTPEnabled() {
+		return fmt.Errorf("totp is not enrolled for %s", u.Username)
+	}
+	if u.IsTOTPLocked() {
+		return fmt.Errorf("totp of %s is locked out after too many failed attempts", u.Username)
+	}
+	if step, ok := u.matchTOTP(code, time.Now()); ok {
+		// Conditional update, so that concurrent logins can't use the same code.
+		res := db.Model(&dbUserModel{}).Where("id = ? AND totp_last_step < ?", u.ID, step).UpdateColumns(map[string]interface{}{"totp_last_step": step, "totp_failures": 0})
+		if res.Error != nil {
+			return fmt.Errorf("can not verify totp code: %v", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			u.totpFailed()
+			return fmt.Errorf("totp code is already used")
+		}
+		u.dbUserModel.TOTPLastStep = step
+		u.dbUserModel.TOTPFailures = 0
+		return nil
+	}
+
+	sum := recoveryCodeSum(code)
+	sums := strings.Split(u.RecoveryCodes, "\n")
+	for i, s := range sums {
+		if subtle.ConstantTimeCompare([]byte(s), []byte(sum)) == 1 {
+			codes := strings.Join(append(sums[:i:i], sums[i+1:]...), "\n")
+			res := db.Model(&dbUserModel{}).Where("id = ? AND recovery_codes = ?", u.ID, u.RecoveryCodes).UpdateColumns(map[string]interface{}{"recovery_codes": codes, "totp_failures": 0})
+			if res.Error != nil {
+				return fmt.Errorf("can not verify recovery code: %v", res.Error)
+			}
+			if res.RowsAffected == 0 {
+				u.totpFailed()
+				return fmt.Errorf("recovery code is already used")
+			}
+			u.dbUserModel.RecoveryCodes = codes
+			u.dbUserModel.TOTPFailures = 0
+			logrus.Infof("recovery code is used for %s, %d left", u.Username, len(sums)-1)
+			return nil
+		}
+	}
+	u.totpFailed()
+	return fmt.Errorf("totp code is wrong")
+}
+
+// totpFailed counts a failed TOTP attempt of the user and locks the attempts
+// out once there are too many of them.
+func (u *User) totpFailed() {
+	db.Model(&dbUserModel{}).Where("id = ?", u.ID).UpdateColumn("totp_failures", gorm.Expr("totp_failures + ?", 1))
+	var m dbUserModel
+	if err := db.Select("totp_failures").First(&m, u.ID).Error; err != nil {
+		logrus.Errorf("can not count failed totp attempts of %s: %v", u.Username, err)
+		return
+	}
+	u.dbUserModel.TOTPFailures = m.TOTPFailures
+	if m.TOTPFailures < _TOTPMaxFailures {
+		return
+	}
+	lockout := _TOTPMaxLockout
+	if n := uint(m.TOTPFailures - _TOTPMaxFailures); n < 6 {
+		lockout = _TOTPLockout << n
+	}
+	if lockout > _TOTPMaxLockout {
+		lockout = _TOTPMaxLockout
+	}
+	u.dbUserModel.TOTPLockedUntil = time.Now().Add(lockout)
+	db.Model(&dbUserModel{}).Where("id = ?", u.ID).UpdateColumn("totp_locked_until", u.TOTPLockedUntil)
+	logrus.Warnf("totp of %s is locked out for %s after %d failed attempts", u.Username, lockout, m.TOTPFailures)
+}
+
+// matchTOTP returns the time step that the code is valid for, allowing the
+// clocks to drift by _TOTPSkew periods.
+func (u *User) matchTOTP(code string, now time.Time) (int64, bool) {
+	secret, err := totpEncoding.DecodeString(strings.ToUpper(u.TOTPSecret))
+	if err != nil || len(code) != _TOTPDigits {
+		return 0, false
+	}
+	step := now.Unix() / _TOTPPeriod
+	for i := int64(-_TOTPSkew); i <= _TOTPSkew; i++ {
+		if subtle.ConstantTimeCompare([]byte(totpCode(secret, step+i)), []byte(code)) == 1 {
+			return step + i, true
+		}
+	}
+	return 0, false
+}
+
+// totpCode returns the RFC 6238 code of the secret for the given time step.
+func totpCode(secret []byte, step int64) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(step))
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation of RFC 4226.
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for i := 0; i < _TOTPDigits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", _TOTPDigits, value%mod)
+}
+
+// recoveryCodeSum returns the hash of the recovery code to store.
+//
+// Recovery codes are random, so they don't need a slow password hash.
+func recoveryCodeSum(code string) string {
+	code = strings.ToLower(strings.Replace(strings.TrimSpace(code), "-", "", -1))
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(code)))
+}
+
+// parseStaticChallenge splits the password that OpenVPN sends for the static
+// challenge (SCRV1:<base64 password>:<base64 response>) into the password and
+// the response.
+//
+// It returns the password as it is if it isn't a static challenge response.
+func parseStaticChallenge(password string) (string, string, bool) {
+	parts := strings.Split(password, ":")
+	if len(parts) != 3 || parts[0] != "SCRV1" {
+		return password, "", false
+	}
+	pass, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return password, "", false
+	}
+	response, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return password, "", false
+	}
+	return string(pass), string(response), true
+}
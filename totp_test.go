@@ -0,0 +1,221 @@
+package ovpm
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/thriftrw/ptr"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// Test vectors of RFC 6238, truncated to 6 digits.
+	secret := []byte("12345678901234567890")
+	var codetests = []struct {
+		time int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, tt := range codetests {
+		if code := totpCode(secret, tt.time/_TOTPPeriod); code != tt.code {
+			t.Errorf("totpCode(%d) = %s, want %s", tt.time, code, tt.code)
+		}
+	}
+}
+
+func TestUserTOTP(t *testing.T) {
+	// Initialize:
+	setupTestCase()
+	CreateDB("sqlite3", ":memory:")
+	defer db.Cease()
+	svr := TheServer()
+	if err := svr.Init("localhost", "", UDPProto, "", "", "", "", false, &ServerOptions{AuthUserPass: ptr.Bool(true)}); err != nil {
+		t.Fatalf("can not init server: %v", err)
+	}
+	svr = TheServer()
+	usr, err := CreateNewUser("usr1", "1234", false, 0, true, "description")
+	if err != nil {
+		t.Fatalf("user creation failed: %v", err)
+	}
+
+	// Test:
+	// Enrollment.
+	uri, err := usr.EnrollTOTP()
+	if err != nil {
+		t.Fatalf("can not enroll totp: %v", err)
+	}
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/OVPM:usr1" {
+		t.Fatalf("provisioning uri is malformed: %s", uri)
+	}
+	if u.Query().Get("secret") != usr.TOTPSecret || u.Query().Get("issuer") != "OVPM" {
+		t.Errorf("provisioning uri is expected to have the secret and the issuer: %s", uri)
+	}
+	secret, _ := totpEncoding.DecodeString(usr.TOTPSecret)
+	if len(secret) != _TOTPSecretSize {
+		t.Errorf("totp secret is expected to be %d bytes but it's %d bytes", _TOTPSecretSize, len(secret))
+	}
+	if usr.IsTOTPEnabled() {
+		t.Fatalf("totp is expected to be enforced only after it's confirmed")
+	}
+	if err := VerifyUserPass("usr1", "usr1", "1234"); err != nil {
+		t.Errorf("unconfirmed totp is expected to not be required: %v", err)
+	}
+	if _, err := usr.ConfirmTOTP("abcdef"); err == nil {
+		t.Fatalf("confirmation is expected to fail with a wrong code")
+	}
+	step := time.Now().Unix() / _TOTPPeriod
+	codes, err := usr.ConfirmTOTP(totpCode(secret, step))
+	if err != nil {
+		t.Fatalf("can not confirm totp: %v", err)
+	}
+	if len(codes) != _RecoveryCodeCount {
+		t.Errorf("%d recovery codes are expected but got %d", _RecoveryCodeCount, len(codes))
+	}
+	usr, _ = GetUser("usr1")
+	if !usr.IsTOTPEnabled() {
+		t.Fatalf("totp is expected to be enabled after the confirmation")
+	}
+	if _, err := usr.EnrollTOTP(); err == nil {
+		t.Errorf("enrolling twice is expected to fail")
+	}
+
+	// Codes can't be reused.
+	if err := usr.VerifyTOTP(totpCode(secret, step)); err == nil {
+		t.Errorf("totp code is expected to be refused when it's reused")
+	}
+	if err := usr.VerifyTOTP(totpCode(secret, step+1)); err != nil {
+		t.Errorf("totp code of the next period is expected to be accepted: %v", err)
+	}
+	if err := usr.VerifyTOTP(totpCode(secret, step+3)); err == nil {
+		t.Errorf("totp code is expected to be refused when it's out of the window")
+	}
+
+	// Recovery codes are consumed.
+	if err := usr.VerifyTOTP(strings.ToUpper(codes[0])); err != nil {
+		t.Errorf("recovery code is expected to be accepted: %v", err)
+	}
+	usr, _ = GetUser("usr1")
+	if err := usr.VerifyTOTP(codes[0]); err == nil {
+		t.Errorf("recovery code is expected to be refused when it's reused")
+	}
+
+	// VPN clients answer the static challenge with the code.
+	config, _ := svr.DumpsClientConfig("usr1")
+	if !strings.Contains(config, "\nstatic-challenge ") {
+		t.Errorf("client config is expected to have the static challenge:\n%s", config)
+	}
+	scrv1 := func(password, response string) string {
+		return "SCRV1:" + base64.StdEncoding.EncodeToString([]byte(password)) + ":" + base64.StdEncoding.EncodeToString([]byte(response))
+	}
+	if err := VerifyUserPass("usr1", "usr1", "1234"); err == nil {
+		t.Errorf("vpn login without a totp code is expected to fail")
+	}
+	if err := VerifyUserPass("usr1", "usr1", scrv1("4321", codes[1])); err == nil {
+		t.Errorf("vpn login with a wrong password is expected to fail")
+	}
+	if err := VerifyUserPass("usr1", "usr1", scrv1("1234", "123")); err == nil {
+		t.Errorf("vpn login with a wrong totp code is expected to fail")
+	}
+	if err := VerifyUserPass("usr1", "usr1", scrv1("1234", codes[1])); err != nil {
+		t.Errorf("vpn login with the password and a recovery code is expected to succeed: %v", err)
+	}
+
+	// Admins can reset the enrollment.
+	usr, _ = GetUser("usr1")
+	if err := usr.ResetTOTP(); err != nil {
+		t.Fatalf("can not reset totp: %v", err)
+	}
+	usr, _ = GetUser("usr1")
+	if usr.IsTOTPEnabled() || usr.TOTPSecret != "" || usr.RecoveryCodes != "" {
+		t.Errorf("totp is expected to be reset")
+	}
+	if err := VerifyUserPass("usr1", "usr1", "1234"); err != nil {
+		t.Errorf("totp is expected to not be required after the reset: %v", err)
+	}
+}
+
+func TestUserTOTPLockout(t *testing.T) {
+	// Initialize:
+	setupTestCase()
+	CreateDB("sqlite3", ":memory:")
+	defer db.Cease()
+	if err := TheServer().Init("localhost", "", UDPProto, "", "", "", "", false, &ServerOptions{AuthUserPass: ptr.Bool(true)}); err != nil {
+		t.Fatalf("can not init server: %v", err)
+	}
+	usr, err := CreateNewUser("usr1", "1234", false, 0, true, "description")
+	if err != nil {
+		t.Fatalf("user creation failed: %v", err)
+	}
+	if _, err := usr.EnrollTOTP(); err != nil {
+		t.Fatalf("can not enroll totp: %v", err)
+	}
+	secret, _ := totpEncoding.DecodeString(usr.TOTPSecret)
+	step := time.Now().Unix() / _TOTPPeriod
+	if _, err := usr.ConfirmTOTP(totpCode(secret, step-1)); err != nil {
+		t.Fatalf("can not confirm totp: %v", err)
+	}
+
+	// Test:
+	// Concurrent logins can't use the same code, even with stale users.
+	usr1, _ := GetUser("usr1")
+	usr2, _ := GetUser("usr1")
+	if err := usr1.VerifyTOTP(totpCode(secret, step)); err != nil {
+		t.Fatalf("totp code is expected to be accepted: %v", err)
+	}
+	if err := usr2.VerifyTOTP(totpCode(secret, step)); err == nil {
+		t.Errorf("totp code is expected to be refused when it's used by a concurrent login")
+	}
+
+	// Attempts are locked out after too many failures, the reused code above
+	// counts as one too.
+	for i := 2; i < _TOTPMaxFailures; i++ {
+		usr.VerifyTOTP("000000")
+	}
+	if usr.IsTOTPLocked() {
+		t.Fatalf("totp is expected to not be locked before %d failures", _TOTPMaxFailures)
+	}
+	if err := usr.VerifyTOTP(totpCode(secret, step+1)); err != nil {
+		t.Fatalf("totp code is expected to be accepted: %v", err)
+	}
+	for i := 0; i < _TOTPMaxFailures; i++ {
+		usr.VerifyTOTP("000000")
+	}
+	if !usr.IsTOTPLocked() {
+		t.Fatalf("totp is expected to be locked after %d failures", _TOTPMaxFailures)
+	}
+	usr, _ = GetUser("usr1")
+	if lockout := time.Until(usr.TOTPLockedUntil); lockout <= 0 || lockout > _TOTPLockout {
+		t.Errorf("totp is expected to be locked for %s but it's locked for %s", _TOTPLockout, lockout)
+	}
+	if err := usr.VerifyTOTP(totpCode(secret, step+1)); err == nil {
+		t.Errorf("totp code is expected to be refused while it's locked")
+	}
+	if err := VerifyUserPass("usr1", "usr1", "SCRV1:"+base64.StdEncoding.EncodeToString([]byte("1234"))+":"+base64.StdEncoding.EncodeToString([]byte(totpCode(secret, step+1)))); err == nil {
+		t.Errorf("vpn login is expected to be refused while totp is locked")
+	}
+
+	// The lockout doubles with each failure after it expires.
+	db.Model(&dbUserModel{}).Where("id = ?", usr.ID).UpdateColumn("totp_locked_until", time.Now())
+	usr.VerifyTOTP("000000")
+	usr, _ = GetUser("usr1")
+	if lockout := time.Until(usr.TOTPLockedUntil); lockout <= _TOTPLockout || lockout > 2*_TOTPLockout {
+		t.Errorf("totp is expected to be locked for %s but it's locked for %s", 2*_TOTPLockout, lockout)
+	}
+
+	// Resetting lifts the lockout.
+	if err := usr.ResetTOTP(); err != nil {
+		t.Fatalf("can not reset totp: %v", err)
+	}
+	usr, _ = GetUser("usr1")
+	if usr.IsTOTPLocked() || usr.TOTPFailures != 0 {
+		t.Errorf("totp lockout is expected to be lifted after the reset")
+	}
+}
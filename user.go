@@ -10,7 +10,6 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/asaskevich/govalidator"
 	"github.com/cad/ovpm/pki"
-	"github.com/google/uuid"
 	"github.com/jinzhu/gorm"
 )
 
@@ -18,6 +17,10 @@ import (
 type dbRevokedModel struct {
 	gorm.Model
 	SerialNumber string
+	Username     string    // Owner of the revoked certificate.
+	Reason       string    // RFC 5280 name of the revocation reason. (e.g. keyCompromise)
+	RevokedAt    time.Time // When the certificate is revoked.
+	RevokedBy    string    // Username of the one who revoked the certificate.
 }
 
 // dbUserModel is database model for VPN users.
@@ -30,12 +33,22 @@ type dbUserModel struct {
 	Cert               string // not user writable
 	ServerSerialNumber string // not user writable
 	Hash               string
-	Key                string // not user writable
+	Key                string    // not user writable
+	TLSCryptV2Key      string    // not user writable
+	TOTPSecret         string    // not user writable
+	TOTPEnabled        bool      // not user writable
+	TOTPLastStep       int64     // not user writable
+	TOTPFailures       int       // not user writable, consecutive failed totp attempts.
+	TOTPLockedUntil    time.Time // not user writable, totp attempts are refused until then.
+	RecoveryCodes      string    // not user writable
 	NoGW               bool
 	HostID             uint32 // not user writable
 	Admin              bool
-	AuthToken          string // auth token
 	Description        string
+	AuthSource         string // "" means LocalAuthSource.
+	Disabled           bool
+	OIDCIssuer         string `gorm:"column:oidc_issuer"`        // not user writable
+	OIDCSubject        string `gorm:"column:oidc_subject;index"` // not user writable, "" if the user isn't linked to an OIDC account.
 }
 
 // User represents a vpn user.
@@ -58,27 +71,21 @@ func (u *dbUserModel) setPassword(password string) error {
 	return nil
 }
 
-// RenewToken generates a new AuthToken and sets it to the db.
-func (u *User) RenewToken() (string, error) {
-	token := uuid.New().String()
-	u.AuthToken = token
-	db.Save(u.dbUserModel)
-	if db.Error != nil {
-		return "", db.Error
-	}
-	return token, nil
-}
-
-// ValidateToken returns whether the given token is valid or not.
-func (u *User) ValidateToken(token string) bool {
-	if u.AuthToken == "" {
-		return false
-	}
-	return u.AuthToken == token
-}
-
 // CheckPassword returns whether the given password is correct for the user.
+//
+// LDAP users are authenticated against the directory and disabled users are
+// always rejected.
 func (u *User) CheckPassword(password string) bool {
+	if u.Disabled {
+		return false
+	}
+	if u.AuthSource == LDAPAuthSource {
+		if err := authenticateLDAP(u.Username, password); err != nil {
+			logrus.Infof("ldap authentication failed for %s: %v", u.Username, err)
+			return false
+		}
+		return true
+	}
 	_, err := passlib.Verify(password, u.Hash)
 	if err != nil {
 		logrus.Error(err)
@@ -98,21 +105,6 @@ func GetUser(username string) (*User, error) {
 	return &User{dbUserModel: user}, nil
 }
 
-// GetUserByToken finds and returns the user with the given token from database.
-func GetUserByToken(token string) (*User, error) {
-	if token == "" {
-		return nil, fmt.Errorf("token can not be empty")
-	}
-
-	user := dbUserModel{}
-	db.Where(&dbUserModel{AuthToken: token}).First(&user)
-	if db.NewRecord(&user) {
-		// user is not found
-		return nil, fmt.Errorf("user not found by token: <token>")
-	}
-	return &User{dbUserModel: user}, nil
-}
-
 // GetAllUsers returns all recorded users in the database.
 func GetAllUsers() ([]*User, error) {
 	var users []*User
@@ -150,11 +142,16 @@ func CreateNewUser(username, password string, nogw bool, hostid uint32, admin bo
 		return nil, err
 	}
 
-	clientCert, err := pki.NewClientCertHolder(ca, username)
+	clientCert, err := pki.NewClientCertHolderWithOptions(ca, username, svr.certOptions(svr.GetClientCertValidityDays()))
 	if err != nil {
 		return nil, fmt.Errorf("can not create client cert %s: %v", username, err)
 	}
 
+	tlsCryptKey, err := svr.newClientTLSCryptKey()
+	if err != nil {
+		return nil, err
+	}
+
 	if hostid != 0 {
 		ip := HostID2IP(hostid)
 		if ip == nil {
@@ -188,6 +185,7 @@ func CreateNewUser(username, password string, nogw bool, hostid uint32, admin bo
 		Username:           username,
 		Cert:               clientCert.Cert,
 		Key:                clientCert.Key,
+		TLSCryptV2Key:      tlsCryptKey,
 		ServerSerialNumber: svr.SerialNumber,
 		NoGW:               nogw,
 		HostID:             hostid,
@@ -221,6 +219,9 @@ func (u *User) Update(password string, nogw bool, hostid uint32, admin bool, des
 
 	// If password is provided; set it. If not; leave it as it is.
 	if password != "" {
+		if u.AuthSource == LDAPAuthSource {
+			return fmt.Errorf("password of %s is managed by ldap", u.Username)
+		}
 		u.setPassword(password)
 	}
 
@@ -250,36 +251,56 @@ func (u *User) Update(password string, nogw bool, hostid uint32, admin bool, des
 }
 
 // Delete deletes a user by the given username from the database.
+//
+// The user's certificate is revoked, so it fails while the offline CA is
+// locked, since the CRL can't be signed.
 func (u *User) Delete() error {
+	return u.DeleteBy("")
+}
+
+// DeleteBy is like Delete but it records actor as the one who revoked the user's certificate.
+func (u *User) DeleteBy(actor string) error {
 	if db.NewRecord(u.dbUserModel) {
 		// user is not found
 		return fmt.Errorf("user is not initialized: %s", u.Username)
 	}
-	crt, err := pki.ReadCertFromPEM(u.Cert)
+	// Revoked certificate has to get into the CRL right away.
+	if !TheServer().IsCAUnlocked() {
+		return fmt.Errorf("can not delete user %s: ca is offline and locked", u.Username)
+	}
+	err := revokeCert(u.Cert, u.Username, pki.ReasonCessationOfOperation, actor, time.Now())
 	if err != nil {
-		return fmt.Errorf("can not get user's certificate: %v", err)
+		return err
 	}
-	db.Create(&dbRevokedModel{
-		SerialNumber: crt.SerialNumber.Text(16),
-	})
+	u.RevokeTokens()
 	db.Unscoped().Delete(u.dbUserModel)
 	logrus.Infof("user deleted: %s", u.GetUsername())
 
 	if err = TheServer().EmitWithRestart(); err != nil {
 		return err
 	}
+
+	// The CRL is only checked on TLS handshakes, so kick the user's live
+	// sessions out if there are any.
+	if err = u.Disconnect(); err != nil {
+		logrus.Debugf("user is not disconnected: %v", err)
+	}
 	u = nil // delete the existing user struct
 	return nil
 }
 
 // ResetPassword resets the users password into the provided password.
 func (u *User) ResetPassword(password string) error {
+	if u.AuthSource == LDAPAuthSource {
+		return fmt.Errorf("password of %s is managed by ldap", u.Username)
+	}
 	err := u.dbUserModel.setPassword(password)
 	if err != nil {
 		// user password can not be updated
 		return fmt.Errorf("user password can not be updated %s: %v", u.Username, err)
 	}
 	db.Save(u.dbUserModel)
+	u.RevokeTokens()
 	if err = TheServer().EmitWithRestart(); err != nil {
 		return err
 	}
@@ -294,34 +315,193 @@ func (u *User) ResetPassword(password string) error {
 // still  existing users in the database.
 //
 // Also it can be used when a user cert is expired or user's private key stolen, missing etc.
+//
+// The previous certificate is revoked right away as superseded.
 func (u *User) Renew() error {
+	return u.RenewBy("", 0)
+}
+
+// RenewBy is like Renew but the previous certificate stays valid for the given
+// grace period, so that the user can keep using the old .ovpn profile while
+// picking up the new one.
+//
+// actor is recorded as the one who revoked the previous certificate.
+//
+// Users that hold their own private key can't be renewed, since that would
+// make ovpm hold a key for them again. They need to upload a new CSR.
+func (u *User) RenewBy(actor string, grace time.Duration) error {
 	svr := TheServer()
 	if !svr.IsInitialized() {
 		return fmt.Errorf("you first need to create server")
 	}
+	if u.HasClientKey() {
+		return fmt.Errorf("can not renew cert of user %s: private key is held by the user, upload a new csr: $ ovpm user sign-csr --user %s", u.Username, u.Username)
+	}
+	if grace < 0 {
+		grace = 0
+	}
 	ca, err := svr.GetSystemCA()
 	if err != nil {
 		return err
 	}
 
-	clientCert, err := pki.NewClientCertHolder(ca, u.Username)
+	clientCert, err := pki.NewClientCertHolderWithOptions(ca, u.Username, svr.certOptions(svr.GetClientCertValidityDays()))
 	if err != nil {
 		return fmt.Errorf("can not create client cert %s: %v", u.Username, err)
 	}
 
+	if err := u.replaceCert(clientCert, actor, grace); err != nil {
+		return err
+	}
+	logrus.Infof("user renewed cert: %s", u.GetUsername())
+	return nil
+}
+
+// SignCSR issues the user a certificate for the given PEM encoded PKCS#10
+// certificate request signed by the current server's CA.
+//
+// The private key stays with the user, so the user's .ovpn profile doesn't
+// include it anymore. The common name of the request should be the username.
+// The previous certificate is revoked right away as superseded and actor is
+// recorded as the one who revoked it.
+func (u *User) SignCSR(csr string, actor string) error {
+	svr := TheServer()
+	if !svr.IsInitialized() {
+		return fmt.Errorf("you first need to create server")
+	}
+	ca, err := svr.GetSystemCA()
+	if err != nil {
+		return err
+	}
+
+	cert, err := pki.SignClientCSR(ca, csr, u.Username, svr.certOptions(svr.GetClientCertValidityDays()))
+	if err != nil {
+		return fmt.Errorf("can not sign csr of %s: %v", u.Username, err)
+	}
+
+	if err := u.replaceCert(&pki.CertHolder{Cert: cert}, actor, 0); err != nil {
+		return err
+	}
+	logrus.Infof("user csr signed: %s", u.GetUsername())
+	return nil
+}
+
+// HasClientKey returns whether the user's private key is held by the user
+// instead of ovpm, which is the case after SignCSR.
+func (u *User) HasClientKey() bool {
+	return u.Cert != "" && u.Key == ""
+}
+
+// replaceCert revokes the user's current certificate as superseded after the
+// grace period and replaces it with the given one.
+func (u *User) replaceCert(clientCert *pki.CertHolder, actor string, grace time.Duration) error {
+	svr := TheServer()
+	if u.Cert != "" {
+		err := revokeCert(u.Cert, u.Username, pki.ReasonSuperseded, actor, time.Now().Add(grace))
+		if err != nil {
+			return err
+		}
+	}
+
 	u.Cert = clientCert.Cert
 	u.Key = clientCert.Key
 	u.ServerSerialNumber = svr.SerialNumber
 
 	db.Save(u.dbUserModel)
-	if err = svr.EmitWithRestart(); err != nil {
+	return svr.EmitWithRestart()
+}
+
+// RenewExpiringCerts renews the client certificates that expire within the
+// server's renew window and returns the renewed users.
+//
+// Renewed users need to get their .ovpn profiles exported again.
+func RenewExpiringCerts() ([]*User, error) {
+	svr := TheServer()
+	if !svr.IsInitialized() {
+		return nil, fmt.Errorf("you first need to create server")
+	}
+	users, err := GetAllUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(days(svr.GetCertRenewWindowDays()))
+	var renewed []*User
+	for _, user := range users {
+		expiresAt := user.ExpiresAt()
+		if expiresAt.After(deadline) {
+			continue
+		}
+		// ovpm can't renew the certificate without the user's key.
+		if user.HasClientKey() {
+			logrus.Warnf("user %s has a certificate that expires at %s, user needs to submit a new csr: $ ovpm user sign-csr --user %s", user.Username, expiresAt.Format(time.RFC3339), user.Username)
+			continue
+		}
+		// Keep the previous cert valid until it expires, since the user
+		// doesn't have the new .ovpn profile yet.
+		if err := user.RenewBy("", time.Until(expiresAt)); err != nil {
+			logrus.Errorf("can not renew cert of user %s: %v", user.Username, err)
+			continue
+		}
+		renewed = append(renewed, user)
+	}
+	return renewed, nil
+}
+
+// RevokeCert revokes the user's current certificate with the given reason.
+//
+// The user can't connect until the certificate is renewed. actor is recorded as
+// the one who revoked the certificate. It fails while the offline CA is locked,
+// since the CRL can't be signed.
+func (u *User) RevokeCert(reason pki.RevocationReason, actor string) error {
+	if !TheServer().IsCAUnlocked() {
+		return fmt.Errorf("can not revoke cert of user %s: ca is offline and locked", u.Username)
+	}
+	if err := revokeCert(u.Cert, u.Username, reason, actor, time.Now()); err != nil {
+		return err
+	}
+	if err := TheServer().EmitWithRestart(); err != nil {
 		return err
 	}
 
-	logrus.Infof("user renewed cert: %s", u.GetUsername())
+	// The CRL is only checked on TLS handshakes, so kick the user's live
+	// sessions out if there are any.
+	if err := u.Disconnect(); err != nil {
+		logrus.Debugf("user is not disconnected: %v", err)
+	}
+	logrus.Infof("user cert revoked: %s (%s)", u.GetUsername(), reason)
+	return nil
+}
+
+// Disconnect kicks the user's live VPN sessions without restarting the OpenVPN process.
+//
+// It doesn't revoke the user's certificate, so the user is able to connect again.
+func (u *User) Disconnect() error {
+	c, err := TheServer().managementClient()
+	if err != nil {
+		return fmt.Errorf("can not disconnect user %s: %v", u.Username, err)
+	}
+	expectVPNSessionEnd(u.Username, SessionKicked)
+	if err := c.Kill(u.Username); err != nil {
+		return fmt.Errorf("can not disconnect user %s: %v", u.Username, err)
+	}
+	logrus.Infof("user disconnected: %s", u.GetUsername())
 	return nil
 }
 
+// setDisabled enables or disables the user. Disabled users can't
+// authenticate and their VPN connections are rejected.
+func (u *User) setDisabled(disabled bool) {
+	u.Disabled = disabled
+	db.Save(u.dbUserModel)
+	if disabled {
+		u.RevokeTokens()
+		logrus.Infof("user disabled: %s", u.Username)
+	} else {
+		logrus.Infof("user enabled: %s", u.Username)
+	}
+}
+
 // GetUsername returns user's username.
 func (u *User) GetUsername() string {
 	return u.Username
@@ -422,19 +602,27 @@ func (u *User) GetDescription() string {
 	return u.Description
 }
 
+// IsDisabled returns whether the user is disabled.
+func (u *User) IsDisabled() bool {
+	return u.Disabled
+}
+
+// GetAuthSource returns where the user is authenticated, either
+// LocalAuthSource or LDAPAuthSource.
+func (u *User) GetAuthSource() string {
+	if u.AuthSource == "" {
+		return LocalAuthSource
+	}
+	return u.AuthSource
+}
+
 // ConnectionStatus returns information about user's connection to the VPN server.
 func (u *User) ConnectionStatus() (isConnected bool, connectedSince time.Time, bytesSent uint64, bytesReceived uint64) {
 	var found *clEntry
 
 	svr := TheServer()
 
-	// Open the status log file.
-	f, err := svr.openFunc(_DefaultStatusLogPath)
-	if err != nil {
-		panic(err)
-	}
-
-	cl, _ := svr.parseStatusLogFunc(f) // client list from OpenVPN status log
+	cl, _ := svr.clientList() // client list from OpenVPN
 	for _, c := range cl {
 		if c.CommonName == u.Username {
 			found = &c
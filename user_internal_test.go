@@ -5,6 +5,8 @@ import (
 	"reflect"
 	"testing"
 	"time"
+
+	"github.com/cad/ovpm/pki"
 )
 
 func TestUser_ConnectionStatus(t *testing.T) {
@@ -12,7 +14,7 @@ func TestUser_ConnectionStatus(t *testing.T) {
 	db := CreateDB("sqlite3", ":memory:")
 	defer db.Cease()
 	svr := TheServer()
-	svr.Init("localhost", "", UDPProto, "", "", "", "", false)
+	svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil)
 
 	origOpenFunc := svr.openFunc
 	defer func() { svr.openFunc = origOpenFunc }()
@@ -89,6 +91,64 @@ func TestUser_ConnectionStatus(t *testing.T) {
 	}
 }
 
+func TestUser_RenewBy(t *testing.T) {
+	// Init:
+	setupTestCase()
+	db := CreateDB("sqlite3", ":memory:")
+	defer db.Cease()
+	svr := TheServer()
+	svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil)
+
+	usr1, _ := CreateNewUser("usr1", "1234", false, 0, true, "description")
+	usr2, _ := CreateNewUser("usr2", "1234", false, 0, true, "description")
+	oldCert1, _ := pki.ReadCertFromPEM(usr1.Cert)
+	oldCert2, _ := pki.ReadCertFromPEM(usr2.Cert)
+
+	// Test:
+	// Renew without a grace period revokes the previous cert right away.
+	if err := usr1.Renew(); err != nil {
+		t.Fatalf("user can not be renewed: %v", err)
+	}
+	// Renew with a grace period revokes the previous cert later.
+	if err := usr2.RenewBy("admin", 2*time.Hour); err != nil {
+		t.Fatalf("user can not be renewed: %v", err)
+	}
+
+	revoked, _ := GetRevokedCerts()
+	if len(revoked) != 2 {
+		t.Fatalf("2 superseded certs are expected to be revoked but there are %d", len(revoked))
+	}
+	for _, r := range revoked {
+		if r.Reason != pki.ReasonSuperseded {
+			t.Errorf("revocation reason is expected to be %s but it's %s", pki.ReasonSuperseded, r.Reason)
+		}
+	}
+	if revoked[1].RevokedBy != "admin" || revoked[1].RevokedAt.Before(time.Now().Add(time.Hour)) {
+		t.Errorf("revocation is expected to be recorded by admin in 2 hours: %+v", revoked[1])
+	}
+
+	entries, _ := crlEntries(time.Now())
+	if len(entries) != 1 || entries[0].SerialNumber.Cmp(oldCert1.SerialNumber) != 0 {
+		t.Errorf("only usr1's previous cert is expected to be in the crl: %+v", entries)
+	}
+	entries, _ = crlEntries(time.Now().Add(3 * time.Hour))
+	if len(entries) != 2 || entries[1].SerialNumber.Cmp(oldCert2.SerialNumber) != 0 {
+		t.Errorf("usr2's previous cert is expected to be in the crl after the grace period: %+v", entries)
+	}
+
+	// Server init renews every user and revokes their previous certs.
+	svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil)
+	revoked, _ = GetRevokedCerts()
+	if len(revoked) != 2 {
+		t.Errorf("previous certs of 2 users are expected to be revoked by init but there are %d", len(revoked))
+	}
+	for _, r := range revoked {
+		if r.Reason != pki.ReasonSuperseded || r.RevokedAt.After(time.Now()) {
+			t.Errorf("previous certs are expected to be revoked right away as superseded: %+v", r)
+		}
+	}
+}
+
 func init() {
 	Testing = true
 }
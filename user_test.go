@@ -1,10 +1,18 @@
 package ovpm_test
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
 	"net"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/cad/ovpm/pki"
 
@@ -17,7 +25,7 @@ func TestCreateNewUser(t *testing.T) {
 	db := ovpm.CreateDB("sqlite3", ":memory:")
 	defer db.Cease()
 	svr := ovpm.TheServer()
-	svr.Init("localhost", "", ovpm.UDPProto, "", "", "", "", false)
+	svr.Init("localhost", "", ovpm.UDPProto, "", "", "", "", false, nil)
 
 	// Preare:
 	username := "test.User"
@@ -106,7 +114,7 @@ func TestUserUpdate(t *testing.T) {
 	// Initialize:
 	db := ovpm.CreateDB("sqlite3", ":memory:")
 	defer db.Cease()
-	ovpm.TheServer().Init("localhost", "", ovpm.UDPProto, "", "", "", "", false)
+	ovpm.TheServer().Init("localhost", "", ovpm.UDPProto, "", "", "", "", false, nil)
 
 	// Prepare:
 	username := "testUser"
@@ -146,7 +154,7 @@ func TestUserPasswordCorrect(t *testing.T) {
 	// Initialize:
 	db := ovpm.CreateDB("sqlite3", ":memory:")
 	defer db.Cease()
-	ovpm.TheServer().Init("localhost", "", ovpm.UDPProto, "", "", "", "", false)
+	ovpm.TheServer().Init("localhost", "", ovpm.UDPProto, "", "", "", "", false, nil)
 
 	// Prepare:
 	initialPassword := "g00dp@ssW0rd9"
@@ -163,7 +171,7 @@ func TestUserPasswordReset(t *testing.T) {
 	// Initialize:
 	db := ovpm.CreateDB("sqlite3", ":memory:")
 	defer db.Cease()
-	ovpm.TheServer().Init("localhost", "", ovpm.UDPProto, "", "", "", "", false)
+	ovpm.TheServer().Init("localhost", "", ovpm.UDPProto, "", "", "", "", false, nil)
 
 	// Prepare:
 	initialPassword := "g00dp@ssW0rd9"
@@ -190,7 +198,7 @@ func TestUserDelete(t *testing.T) {
 	// Initialize:
 	db := ovpm.CreateDB("sqlite3", ":memory:")
 	defer db.Cease()
-	ovpm.TheServer().Init("localhost", "", ovpm.UDPProto, "", "", "", "", false)
+	ovpm.TheServer().Init("localhost", "", ovpm.UDPProto, "", "", "", "", false, nil)
 
 	// Prepare:
 	username := "testUser"
@@ -228,7 +236,7 @@ func TestUserGet(t *testing.T) {
 	// Initialize:
 	db := ovpm.CreateDB("sqlite3", ":memory:")
 	defer db.Cease()
-	ovpm.TheServer().Init("localhost", "", ovpm.UDPProto, "", "", "", "", false)
+	ovpm.TheServer().Init("localhost", "", ovpm.UDPProto, "", "", "", "", false, nil)
 
 	// Prepare:
 	username := "testUser"
@@ -252,7 +260,7 @@ func TestUserGetAll(t *testing.T) {
 	// Initialize:
 	db := ovpm.CreateDB("sqlite3", ":memory:")
 	defer db.Cease()
-	ovpm.TheServer().Init("localhost", "", ovpm.UDPProto, "", "", "", "", false)
+	ovpm.TheServer().Init("localhost", "", ovpm.UDPProto, "", "", "", "", false, nil)
 	count := 5
 
 	// Prepare:
@@ -291,14 +299,14 @@ func TestUserRenew(t *testing.T) {
 	db := ovpm.CreateDB("sqlite3", ":memory:")
 	defer db.Cease()
 	svr := ovpm.TheServer()
-	svr.Init("localhost", "", ovpm.UDPProto, "", "", "", "", false)
+	svr.Init("localhost", "", ovpm.UDPProto, "", "", "", "", false, nil)
 
 	// Prepare:
 	user, _ := ovpm.CreateNewUser("user", "1234", false, 0, true, "description")
 
 	// Test:
 	// Re initialize the server.
-	svr.Init("example.com", "3333", ovpm.UDPProto, "", "", "", "", false) // This causes implicit Renew() on every user in the system.
+	svr.Init("example.com", "3333", ovpm.UDPProto, "", "", "", "", false, nil) // This causes implicit Renew() on every user in the system.
 
 	// Fetch user back.
 	fetchedUser, _ := ovpm.GetUser(user.GetUsername())
@@ -309,12 +317,138 @@ func TestUserRenew(t *testing.T) {
 	}
 }
 
+func TestRenewExpiringCerts(t *testing.T) {
+	// Initialize:
+	db := ovpm.CreateDB("sqlite3", ":memory:")
+	defer db.Cease()
+	svr := ovpm.TheServer()
+	svr.Init("localhost", "", ovpm.UDPProto, "", "", "", "", false, &ovpm.ServerOptions{ClientCertValidityDays: 10, CertRenewWindowDays: 5})
+
+	// Prepare:
+	user, _ := ovpm.CreateNewUser("user", "1234", false, 0, true, "description")
+
+	// Test:
+	// Cert is valid for 10 days, so it's not in the renew window yet.
+	renewed, err := ovpm.RenewExpiringCerts()
+	if err != nil {
+		t.Fatalf("renewing expiring certs failed: %v", err)
+	}
+	if len(renewed) != 0 {
+		t.Fatalf("no certs are expected to be renewed but %d are renewed", len(renewed))
+	}
+
+	// Widen the renew window so that the cert is in it.
+	if err := svr.Update("", "", nil, &ovpm.ServerOptions{ClientCertValidityDays: 30, CertRenewWindowDays: 20}); err != nil {
+		t.Fatalf("server can not be updated: %v", err)
+	}
+	renewed, err = ovpm.RenewExpiringCerts()
+	if err != nil {
+		t.Fatalf("renewing expiring certs failed: %v", err)
+	}
+	if len(renewed) != 1 || renewed[0].GetUsername() != user.GetUsername() {
+		t.Fatalf("user's cert is expected to be renewed but it's not: %v", renewed)
+	}
+
+	// Renewed cert should get the new validity.
+	fetchedUser, _ := ovpm.GetUser(user.GetUsername())
+	if fetchedUser.ExpiresAt().Before(time.Now().Add(29 * 24 * time.Hour)) {
+		t.Errorf("renewed cert is expected to be valid for 30 days but it expires at %v", fetchedUser.ExpiresAt())
+	}
+}
+
+func TestUserSignCSR(t *testing.T) {
+	// Initialize:
+	db := ovpm.CreateDB("sqlite3", ":memory:")
+	defer db.Cease()
+	svr := ovpm.TheServer()
+	svr.Init("localhost", "", ovpm.UDPProto, "", "", "", "", false, &ovpm.ServerOptions{ClientCertValidityDays: 10, CertRenewWindowDays: 5})
+
+	// Prepare:
+	user, _ := ovpm.CreateNewUser("user", "1234", false, 0, true, "description")
+	oldCert := user.Cert
+	newCSR := func(cn string) string {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatalf("test preparation failed: %v", err)
+		}
+		der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: pkix.Name{CommonName: cn}}, key)
+		if err != nil {
+			t.Fatalf("test preparation failed: %v", err)
+		}
+		return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}))
+	}
+
+	// Test:
+	// CSR of someone else should be rejected.
+	if err := user.SignCSR(newCSR("other"), "admin"); err == nil {
+		t.Fatalf("csr with a different common name is expected to be rejected")
+	}
+	if err := user.SignCSR("garbage", "admin"); err == nil {
+		t.Fatalf("invalid csr is expected to be rejected")
+	}
+
+	if err := user.SignCSR(newCSR(user.GetUsername()), "admin"); err != nil {
+		t.Fatalf("csr is expected to be signed but it's not: %v", err)
+	}
+	fetchedUser, _ := ovpm.GetUser(user.GetUsername())
+	if fetchedUser.Key != "" || !fetchedUser.HasClientKey() {
+		t.Fatalf("user's private key is expected to be held by the user but it's stored")
+	}
+	if fetchedUser.Cert == oldCert {
+		t.Fatalf("user's certificate is expected to be replaced but it's 'SAME'")
+	}
+	cert, err := pki.ReadCertFromPEM(fetchedUser.Cert)
+	if err != nil {
+		t.Fatalf("signed certificate can not be read: %v", err)
+	}
+	if cert.Subject.CommonName != user.GetUsername() {
+		t.Errorf("signed certificate's common name is expected to be %s but it's %s", user.GetUsername(), cert.Subject.CommonName)
+	}
+	ca, _ := svr.GetSystemCA()
+	caCert, _ := pki.ReadCertFromPEM(ca.Cert)
+	if err := cert.CheckSignatureFrom(caCert); err != nil {
+		t.Errorf("signed certificate is expected to be signed by the system ca: %v", err)
+	}
+
+	// Client config should not have the key.
+	config, err := svr.DumpsClientConfig(user.GetUsername())
+	if err != nil {
+		t.Fatalf("client config can not be dumped: %v", err)
+	}
+	if strings.Contains(config, "<key>") {
+		t.Errorf("client config is expected to not have a <key> but it has")
+	}
+
+	// The cert is in the renew window but it can't be renewed without a new csr.
+	if err := svr.Update("", "", nil, &ovpm.ServerOptions{ClientCertValidityDays: 30, CertRenewWindowDays: 20}); err != nil {
+		t.Fatalf("server can not be updated: %v", err)
+	}
+	renewed, err := ovpm.RenewExpiringCerts()
+	if err != nil {
+		t.Fatalf("renewing expiring certs failed: %v", err)
+	}
+	if len(renewed) != 0 {
+		t.Errorf("users with their own keys are expected to be skipped but %d are renewed", len(renewed))
+	}
+
+	// Renewing would make ovpm hold a key for the user again.
+	fetchedUser, _ = ovpm.GetUser(user.GetUsername())
+	if err := fetchedUser.Renew(); err == nil {
+		t.Errorf("user with their own key is expected to not be renewed but it is")
+	}
+	svr.Init("localhost", "", ovpm.UDPProto, "", "", "", "", false, nil)
+	fetchedUser, _ = ovpm.GetUser(user.GetUsername())
+	if fetchedUser.Key != "" {
+		t.Errorf("user's private key is expected to not be generated on init but it is")
+	}
+}
+
 func TestUserIPAllocator(t *testing.T) {
 	// Initialize:
 	db := ovpm.CreateDB("sqlite3", ":memory:")
 	defer db.Cease()
 	svr := ovpm.TheServer()
-	svr.Init("localhost", "", ovpm.UDPProto, "", "", "", "", false)
+	svr.Init("localhost", "", ovpm.UDPProto, "", "", "", "", false, nil)
 
 	// Prepare:
 
@@ -351,7 +485,7 @@ func TestUser_ExpiresAt(t *testing.T) {
 	db := ovpm.CreateDB("sqlite3", ":memory:")
 	defer db.Cease()
 	svr := ovpm.TheServer()
-	svr.Init("localhost", "", ovpm.UDPProto, "", "", "", "", false)
+	svr.Init("localhost", "", ovpm.UDPProto, "", "", "", "", false, nil)
 
 	// Test:
 	u1, err := ovpm.CreateNewUser("test", "1234", true, 0, false, "description")
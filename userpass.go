@@ -0,0 +1,59 @@
+package ovpm
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// SetDaemonPort sets the gRPC port of ovpmd, which the OpenVPN process uses
+// to verify the users' passwords.
+func (svr *Server) SetDaemonPort(port string) {
+	svr.daemonPort = port
+}
+
+// verifyUserPassCommand returns the auth-user-pass-verify command of the
+// OpenVPN process.
+//
+// OpenVPN runs it as nobody with the path of a file that holds the username
+// and the password, so it asks ovpmd through the local gRPC endpoint instead
+// of reading the db.
+func (svr *Server) verifyUserPassCommand() string {
+	ovpmPath := "/usr/bin/ovpm"
+	if path, err := exec.LookPath("ovpm"); err == nil {
+		ovpmPath = path
+	}
+	port := svr.daemonPort
+	if port == "" {
+		port = fmt.Sprintf("%d", DefaultDaemonPort)
+	}
+	return fmt.Sprintf("%s --daemon-port %s vpn verify-user", ovpmPath, port)
+}
+
+// VerifyUserPass checks the username and the password that a VPN client
+// sent along with its certificate.
+//
+// The username should be the common name of the certificate, so that a
+// stolen .ovpn file can't connect with somebody else's password.
+//
+// Users with TOTP enabled send their TOTP codes as the response of the
+// static challenge.
+func VerifyUserPass(commonName, username, password string) error {
+	if username != commonName {
+		return fmt.Errorf("username %s doesn't match the certificate of %s", username, commonName)
+	}
+	user, err := GetUser(username)
+	if err != nil {
+		return err
+	}
+	password, code, challenged := parseStaticChallenge(password)
+	if !user.CheckPassword(password) {
+		return fmt.Errorf("wrong password for %s", username)
+	}
+	if user.IsTOTPEnabled() {
+		if !challenged {
+			return fmt.Errorf("totp code is required for %s", username)
+		}
+		return user.VerifyTOTP(code)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package ovpm
+
+import (
+	"strings"
+	"testing"
+
+	"go.uber.org/thriftrw/ptr"
+)
+
+func TestVPNAuthUserPass(t *testing.T) {
+	// Initialize:
+	setupTestCase()
+	CreateDB("sqlite3", ":memory:")
+	defer db.Cease()
+	svr := TheServer()
+	if err := svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil); err != nil {
+		t.Fatalf("can not init server: %v", err)
+	}
+	svr = TheServer()
+	usr, err := CreateNewUser("usr1", "1234", false, 0, true, "description")
+	if err != nil {
+		t.Fatalf("user creation failed: %v", err)
+	}
+
+	// Test:
+	// It's disabled by default.
+	config, _ := svr.DumpsClientConfig(usr.GetUsername())
+	if svr.IsAuthUserPass() || strings.Contains(config, "auth-user-pass") || strings.Contains(fs[_DefaultVPNConfPath], "auth-user-pass-verify") {
+		t.Fatalf("auth user pass is expected to be disabled by default")
+	}
+
+	svr.SetDaemonPort("9091")
+	if err := svr.Update("", "", nil, &ServerOptions{AuthUserPass: ptr.Bool(true)}); err != nil {
+		t.Fatalf("can not update server: %v", err)
+	}
+	svr = TheServer()
+	if !svr.IsAuthUserPass() {
+		t.Fatalf("auth user pass is expected to be enabled")
+	}
+	if !strings.Contains(fs[_DefaultVPNConfPath], "\nscript-security 2\n") || !strings.Contains(fs[_DefaultVPNConfPath], " --daemon-port 9091 vpn verify-user\" via-file\n") {
+		t.Errorf("server.conf is expected to verify the passwords with ovpm:\n%s", fs[_DefaultVPNConfPath])
+	}
+	config, _ = svr.DumpsClientConfig(usr.GetUsername())
+	if !strings.Contains(config, "\nauth-user-pass\n") {
+		t.Errorf("client config is expected to ask for the password:\n%s", config)
+	}
+
+	var verifytests = []struct {
+		commonName string
+		username   string
+		password   string
+		ok         bool
+	}{
+		{"usr1", "usr1", "1234", true},
+		{"usr1", "usr1", "4321", false},
+		{"usr1", "usr2", "1234", false},
+		{"usr2", "usr2", "1234", false},
+	}
+	for _, tt := range verifytests {
+		if err := VerifyUserPass(tt.commonName, tt.username, tt.password); (err == nil) != tt.ok {
+			t.Errorf("VerifyUserPass(%q, %q, %q) ok is expected to be %t but got error: %v", tt.commonName, tt.username, tt.password, tt.ok, err)
+		}
+	}
+}
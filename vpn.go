@@ -2,8 +2,11 @@ package ovpm
 
 import (
 	"bytes"
+	"crypto"
+	"crypto/sha256"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"math/big"
 	"net"
 	"os"
@@ -11,16 +14,19 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"text/template"
 
 	"time"
 
 	"github.com/asaskevich/govalidator"
+	"github.com/cad/ovpm/mgmt"
 	"github.com/cad/ovpm/pki"
 	"github.com/cad/ovpm/supervisor"
 	"github.com/coreos/go-iptables/iptables"
 	"github.com/google/uuid"
 	"github.com/jinzhu/gorm"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 )
 
@@ -43,6 +49,11 @@ type dbServerModel struct {
 	Key              string // Server RSA private key.
 	CACert           string // Root CA RSA certificate.
 	CAKey            string // Root CA RSA key.
+	CAChain          string // Issuer certificates of an imported CA.
+	CAIssuerCRLs     string // CRLs of the issuers of an imported intermediate CA.
+	CAOffline        bool   // CA key is kept in an encrypted file instead of the db.
+	TLSCrypt         string // Control channel protection mode.
+	TLSCryptKey      string // tls-crypt static key or tls-crypt-v2 server key.
 	Net              string // VPN network.
 	Mask             string // VPN network mask.
 	CRL              string // Certificate Revocation List
@@ -50,6 +61,68 @@ type dbServerModel struct {
 	KeepalivePeriod  string // Keepalive ping period
 	KeepaliveTimeout string // Keepalive timeout
 	UseLZO           bool   // Use LZO compression
+	AuthUserPass     bool   // Clients need the users' passwords along with their certificates.
+
+	CAValidityDays         int    // Validity period of the CA certificate in days.
+	ServerCertValidityDays int    // Validity period of the server certificate in days.
+	ClientCertValidityDays int    // Validity period of the client certificates in days.
+	CertRenewWindowDays    int    // Client certificates are renewed when they expire in less than this many days.
+	KeyType                string // Key type of the newly issued certificates.
+
+	DataCiphers         string // Colon separated data channel ciphers to negotiate.
+	DataCiphersFallback string // Cipher of the clients that can't negotiate.
+	AuthDigest          string // HMAC digest of the non-AEAD ciphers.
+	TLSVersionMin       string // Minimum TLS version.
+	TLSCipher           string // Colon separated TLS 1.2 cipher suites.
+	RemoteCertTLS       bool   // Clients verify the server cert by its key usage.
+	ECDHOnly            bool   // Only use ECDHE for the key exchange.
+
+	DHParams string // PEM encoded DH params, generated in the background.
+
+	CRLNumber int64 // Number of the last emitted CRL.
+}
+
+// ServerOptions represents the optional settings of the VPN server.
+//
+// Periods are in days and zero values mean either the defaults or the current
+// settings depending on the context.
+type ServerOptions struct {
+	CAValidityDays         int    // Validity period of the CA certificate.
+	ServerCertValidityDays int    // Validity period of the server certificate.
+	ClientCertValidityDays int    // Validity period of the client certificates.
+	CertRenewWindowDays    int    // Client certificates are renewed when they expire in less than this many days.
+	KeyType                string // Key type of the certificates. See pki.KeyTypes() for the possible values.
+
+	CACert string // PEM encoded CA certificate followed by its issuers to import instead of generating a new CA.
+	CAKey  string // PEM encoded private key of the imported CA.
+	CACRL  string // PEM encoded CRLs of the imported CA's issuers, required for an intermediate.
+
+	TLSCrypt string // Control channel protection mode. Either "none", "tls-crypt" or "tls-crypt-v2".
+
+	Crypto *CryptoOptions // Changes to the crypto profile. Init applies them to the default preset.
+
+	AuthUserPass *bool // Whether the clients need the users' passwords along with their certificates.
+}
+
+// validate checks that the periods, the key type, the control channel
+// protection mode and the imported CA are meaningful.
+func (v *ServerOptions) validate() error {
+	if _, err := pki.ParseKeyType(v.KeyType); err != nil {
+		return fmt.Errorf("validation error: %v", err)
+	}
+	if err := validateTLSCrypt(v.TLSCrypt); err != nil {
+		return err
+	}
+	if (v.CACert == "") != (v.CAKey == "") {
+		return fmt.Errorf("validation error: ca cert and ca key should be imported together")
+	}
+	if v.CAValidityDays < 0 || v.ServerCertValidityDays < 0 || v.ClientCertValidityDays < 0 || v.CertRenewWindowDays < 0 {
+		return fmt.Errorf("validation error: certificate validity periods can not be negative")
+	}
+	if v.ClientCertValidityDays != 0 && v.CertRenewWindowDays >= v.ClientCertValidityDays {
+		return fmt.Errorf("validation error: renew window (%d days) should be shorter than the client cert validity (%d days)", v.CertRenewWindowDays, v.ClientCertValidityDays)
+	}
+	return nil
 }
 
 var serverInstance *Server
@@ -59,11 +132,38 @@ var once sync.Once
 type Server struct {
 	dbServerModel
 
-	webPort string
+	webPort    string
+	daemonPort string
 
 	emitToFileFunc     func(path, content string, mode uint) error
 	openFunc           func(path string) (io.Reader, error)
 	parseStatusLogFunc func(f io.Reader) ([]clEntry, []rtEntry)
+	dialManagementFunc func(path string) (*mgmt.Client, error)
+	newDHParamsFunc    func(bits int, progress func(tries int)) (string, error)
+
+	emittedLock  sync.Mutex
+	emitted      map[string]string // sha256 sums of the last emitted files by path
+	crlEmittedAt time.Time         // when the CRL is emitted the last time
+
+	caSignerLock sync.Mutex
+	caSigner     crypto.Signer // signer of the offline CA, kept in memory only
+
+	dhParamsLock sync.Mutex
+	dhParamsGen  *dhParamsGen // the last dh params generation
+}
+
+// startupFiles are the files that OpenVPN only reads when it's started.
+//
+// Changes to the rest of the emitted files (e.g. ccd/ and crl.pem) are picked
+// up by the running process, since ccd files are read when a client connects
+// and the crl is checked on every TLS handshake.
+var startupFiles = []string{
+	_DefaultVPNConfPath,
+	_DefaultCertPath,
+	_DefaultKeyPath,
+	_DefaultCACertPath,
+	_DefaultDHParamsPath,
+	_DefaultTLSCryptKeyPath,
 }
 
 // TheServer returns a pointer to the server instance.
@@ -79,6 +179,9 @@ func TheServer() *Server {
 				return os.Open(path)
 			},
 			parseStatusLogFunc: parseStatusLog,
+			dialManagementFunc: mgmt.Dial,
+			newDHParamsFunc:    pki.NewDHParams,
+			emitted:            make(map[string]string),
 		}
 	})
 	if db != nil {
@@ -214,6 +317,61 @@ func (svr *Server) IsUseLZO() bool {
 	return svr.UseLZO
 }
 
+// IsAuthUserPass returns whether the clients need the users' passwords along with their certificates.
+func (svr *Server) IsAuthUserPass() bool {
+	return svr.AuthUserPass
+}
+
+// GetCAValidityDays returns the validity period of the CA certificate in days.
+func (svr *Server) GetCAValidityDays() int {
+	if svr.CAValidityDays > 0 {
+		return svr.CAValidityDays
+	}
+	return DefaultCAValidityDays
+}
+
+// GetServerCertValidityDays returns the validity period of the server certificate in days.
+func (svr *Server) GetServerCertValidityDays() int {
+	if svr.ServerCertValidityDays > 0 {
+		return svr.ServerCertValidityDays
+	}
+	return DefaultServerCertValidityDays
+}
+
+// GetClientCertValidityDays returns the validity period of the client certificates in days.
+func (svr *Server) GetClientCertValidityDays() int {
+	if svr.ClientCertValidityDays > 0 {
+		return svr.ClientCertValidityDays
+	}
+	return DefaultClientCertValidityDays
+}
+
+// GetCertRenewWindowDays returns the number of days before expiry that client certificates get renewed.
+func (svr *Server) GetCertRenewWindowDays() int {
+	if svr.CertRenewWindowDays > 0 {
+		return svr.CertRenewWindowDays
+	}
+	return DefaultCertRenewWindowDays
+}
+
+// GetKeyType returns the key type of the newly issued certificates.
+func (svr *Server) GetKeyType() pki.KeyType {
+	if svr.KeyType != "" {
+		return pki.KeyType(svr.KeyType)
+	}
+	return pki.DefaultKeyType
+}
+
+// certOptions returns the options to issue a certificate that is valid for the given number of days.
+func (svr *Server) certOptions(validityDays int) pki.CertOptions {
+	return pki.CertOptions{Validity: days(validityDays), KeyType: svr.GetKeyType()}
+}
+
+// days converts number of days to time.Duration.
+func days(n int) time.Duration {
+	return time.Duration(n) * 24 * time.Hour
+}
+
 // Init regenerates keys and certs for a Root CA, gets initial settings for the VPN server
 // and saves them in the database.
 //
@@ -231,9 +389,15 @@ func (svr *Server) IsUseLZO() bool {
 // 'useLZO' is used to determine whether to use the lzo compression algorithm to support older clients.
 // It defaults to false due to security issues and deprecation
 //
+// 'opts' is the optional settings of the server. Zero values default to the
+// corresponding Default*Days consts and nil means all defaults. If it has a CA
+// cert and key, they are imported instead of generating a self-signed CA. Its
+// control channel protection mode defaults to const 'DefaultTLSCrypt'.
+//
 // Please note that, Init is potentially destructive procedure, it will cause invalidation of
-// existing .ovpn profiles of the current users. So it should be used carefully.
-func (svr *Server) Init(hostname string, port string, proto string, ipblock string, dns string, keepalivePeriod string, keepaliveTimeout string, useLZO bool) error {
+// existing .ovpn profiles of the current users. So it should be used carefully. Use RotateCA
+// to replace the CA without breaking the existing .ovpn profiles.
+func (svr *Server) Init(hostname string, port string, proto string, ipblock string, dns string, keepalivePeriod string, keepaliveTimeout string, useLZO bool, opts *ServerOptions) error {
 	if port == "" {
 		port = DefaultVPNPort
 	}
@@ -300,8 +464,69 @@ func (svr *Server) Init(hostname string, port string, proto string, ipblock stri
 		return fmt.Errorf("validation error: keepalivePeriod:`%s` should be numeric", keepalivePeriod)
 	}
 
+	// Defaults are filled in on a copy, so that the caller's options aren't modified.
+	o := ServerOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	opts = &o
+	if opts.CAValidityDays == 0 {
+		opts.CAValidityDays = DefaultCAValidityDays
+	}
+	if opts.ServerCertValidityDays == 0 {
+		opts.ServerCertValidityDays = DefaultServerCertValidityDays
+	}
+	if opts.ClientCertValidityDays == 0 {
+		opts.ClientCertValidityDays = DefaultClientCertValidityDays
+	}
+	if opts.CertRenewWindowDays == 0 {
+		opts.CertRenewWindowDays = DefaultCertRenewWindowDays
+	}
+	if opts.KeyType == "" {
+		opts.KeyType = string(pki.DefaultKeyType)
+	}
+	if opts.TLSCrypt == "" {
+		opts.TLSCrypt = DefaultTLSCrypt
+	}
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	defaultCrypto, _ := GetCryptoPreset(DefaultCryptoPreset)
+	crypto, err := opts.Crypto.apply(defaultCrypto)
+	if err != nil {
+		return err
+	}
+
+	// Import the CA before the current server is deleted, so that an invalid CA doesn't break it.
+	keyType := pki.KeyType(opts.KeyType)
+	var ca *pki.CA
+	var issuerCRLs string
+	if opts.CACert != "" {
+		ca, err = pki.ImportCA(opts.CACert, opts.CAKey)
+		if err != nil {
+			return fmt.Errorf("can not import ca: %s", err)
+		}
+		issuerCRLs, err = pki.IssuerCRLs(ca, opts.CACRL)
+		if err != nil {
+			return fmt.Errorf("can not import ca: %s", err)
+		}
+	} else {
+		ca, err = pki.NewCAWithOptions(pki.CertOptions{Validity: days(opts.CAValidityDays), KeyType: keyType})
+		if err != nil {
+			return fmt.Errorf("can not create ca creds: %s", err)
+		}
+	}
+
+	tlsCryptKey, err := newTLSCryptKey(opts.TLSCrypt)
+	if err != nil {
+		return fmt.Errorf("can not generate tls crypt key: %s", err)
+	}
+
+	// DH params can outlive the server, since generating them takes long.
 	serverName := "default"
+	var dhParams string
 	if svr := TheServer(); svr.IsInitialized() {
+		dhParams = svr.DHParams
 		if err := svr.Deinit(); err != nil {
 			logrus.Errorf("server can not be deleted: %v", err)
 			return err
@@ -316,12 +541,7 @@ func (svr *Server) Init(hostname string, port string, proto string, ipblock stri
 		return fmt.Errorf("validation error: dns:`%s` should be an ip address", dns)
 	}
 
-	ca, err := pki.NewCA()
-	if err != nil {
-		return fmt.Errorf("can not create ca creds: %s", err)
-	}
-
-	srv, err := pki.NewServerCertHolder(ca)
+	srv, err := pki.NewServerCertHolderWithOptions(ca, pki.CertOptions{Validity: days(opts.ServerCertValidityDays), KeyType: keyType})
 	if err != nil {
 		return fmt.Errorf("can not create server cert creds: %s", err)
 	}
@@ -338,13 +558,27 @@ func (svr *Server) Init(hostname string, port string, proto string, ipblock stri
 		Key:              srv.Key,
 		CACert:           ca.Cert,
 		CAKey:            ca.Key,
+		CAChain:          ca.Chain,
+		CAIssuerCRLs:     issuerCRLs,
+		TLSCrypt:         opts.TLSCrypt,
+		TLSCryptKey:      tlsCryptKey,
 		Net:              ipnet.IP.To4().String(),
 		Mask:             net.IP(ipnet.Mask).To4().String(),
 		DNS:              dns,
 		KeepalivePeriod:  keepalivePeriod,
 		KeepaliveTimeout: keepaliveTimeout,
 		UseLZO:           useLZO,
+		AuthUserPass:     opts.AuthUserPass != nil && *opts.AuthUserPass,
+
+		CAValidityDays:         opts.CAValidityDays,
+		ServerCertValidityDays: opts.ServerCertValidityDays,
+		ClientCertValidityDays: opts.ClientCertValidityDays,
+		CertRenewWindowDays:    opts.CertRenewWindowDays,
+		KeyType:                opts.KeyType,
+
+		DHParams: dhParams,
 	}
+	serverInstance.setCryptoProfile(crypto)
 
 	db.Create(&serverInstance)
 
@@ -358,15 +592,24 @@ func (svr *Server) Init(hostname string, port string, proto string, ipblock stri
 	}
 	// Sign all users in the db with the new server
 	for _, user := range users {
+		// Set dynamic ip to user.
+		user.HostID = 0
+		db.Save(&user.dbUserModel)
+
+		// ovpm can't sign the users that hold their own key.
+		if user.HasClientKey() {
+			logrus.Warnf("user %s needs to submit a new csr signed by the new ca: $ ovpm user sign-csr --user %s", user.Username, user.Username)
+			continue
+		}
 		err := user.Renew()
 		logrus.Infof("user certificate changed for %s, you should run: $ ovpm user export-config --user %s", user.Username, user.Username)
 		if err != nil {
 			logrus.Errorf("can not sign user %s: %v", user.Username, err)
 			continue
 		}
-		// Set dynamic ip to user.
-		user.HostID = 0
-		db.Save(&user.dbUserModel)
+	}
+	if err := TheServer().rekeyUsers(); err != nil {
+		return err
 	}
 	TheServer().EmitWithRestart()
 	logrus.Infof("server initialized")
@@ -374,20 +617,124 @@ func (svr *Server) Init(hostname string, port string, proto string, ipblock stri
 }
 
 // Update updates VPN server attributes.
-func (svr *Server) Update(ipblock string, dns string, useLzo *bool) error {
+//
+// Only the non-zero fields of opts are updated. New validity periods and key
+// type apply to the certificates issued afterwards, except the server certificate
+// which gets re-issued right away if its validity period or key type is changed.
+//
+// Changing the control channel protection mode generates new keys, so the
+// users need to get their .ovpn profiles exported again.
+func (svr *Server) Update(ipblock string, dns string, useLzo *bool, opts *ServerOptions) error {
 	if !svr.IsInitialized() {
 		return fmt.Errorf("server is not initialized")
 	}
 
-	var changed bool
+	users, err := GetAllUsers()
+	if err != nil {
+		return err
+	}
+
+	var changed, rekey, ipChanged bool
+	if opts != nil {
+		if opts.CACert != "" || opts.CAKey != "" {
+			return fmt.Errorf("validation error: ca can only be imported on init")
+		}
+		v := ServerOptions{
+			CAValidityDays:         svr.GetCAValidityDays(),
+			ServerCertValidityDays: svr.GetServerCertValidityDays(),
+			ClientCertValidityDays: svr.GetClientCertValidityDays(),
+			CertRenewWindowDays:    svr.GetCertRenewWindowDays(),
+			KeyType:                string(svr.GetKeyType()),
+		}
+		if opts.CAValidityDays != 0 {
+			v.CAValidityDays = opts.CAValidityDays
+		}
+		if opts.ServerCertValidityDays != 0 {
+			v.ServerCertValidityDays = opts.ServerCertValidityDays
+		}
+		if opts.ClientCertValidityDays != 0 {
+			v.ClientCertValidityDays = opts.ClientCertValidityDays
+		}
+		if opts.CertRenewWindowDays != 0 {
+			v.CertRenewWindowDays = opts.CertRenewWindowDays
+		}
+		if opts.KeyType != "" {
+			v.KeyType = opts.KeyType
+		}
+		if err := opts.validate(); err != nil {
+			return err
+		}
+		if err := v.validate(); err != nil {
+			return err
+		}
+
+		if v.ServerCertValidityDays != svr.GetServerCertValidityDays() || v.KeyType != string(svr.GetKeyType()) {
+			ca, err := svr.serverCertCA()
+			if err != nil {
+				return err
+			}
+			srv, err := pki.NewServerCertHolderWithOptions(ca, pki.CertOptions{Validity: days(v.ServerCertValidityDays), KeyType: pki.KeyType(v.KeyType)})
+			if err != nil {
+				return fmt.Errorf("can not create server cert creds: %s", err)
+			}
+			svr.dbServerModel.Cert = srv.Cert
+			svr.dbServerModel.Key = srv.Key
+		}
+		svr.dbServerModel.CAValidityDays = v.CAValidityDays
+		svr.dbServerModel.ServerCertValidityDays = v.ServerCertValidityDays
+		svr.dbServerModel.ClientCertValidityDays = v.ClientCertValidityDays
+		svr.dbServerModel.CertRenewWindowDays = v.CertRenewWindowDays
+		svr.dbServerModel.KeyType = v.KeyType
+		if opts.Crypto != nil {
+			current := svr.GetCryptoProfile()
+			crypto, err := opts.Crypto.apply(current)
+			if err != nil {
+				return err
+			}
+			if len(users) > 0 && !opts.Crypto.Force {
+				if err := current.locksOut(crypto); err != nil {
+					return err
+				}
+			}
+			svr.dbServerModel.setCryptoProfile(crypto)
+		}
+		if opts.AuthUserPass != nil {
+			svr.dbServerModel.AuthUserPass = *opts.AuthUserPass
+		}
+		if opts.CACRL != "" {
+			if svr.CAChain == "" {
+				return fmt.Errorf("validation error: issuer crls are only needed by an imported intermediate ca")
+			}
+			crls, err := pki.IssuerCRLs(&pki.CA{CertHolder: pki.CertHolder{Cert: svr.CACert}, Chain: svr.CAChain}, opts.CACRL)
+			if err != nil {
+				return fmt.Errorf("can not update issuer crls: %s", err)
+			}
+			svr.dbServerModel.CAIssuerCRLs = crls
+		}
+		if opts.TLSCrypt != "" && opts.TLSCrypt != svr.GetTLSCrypt() {
+			key, err := newTLSCryptKey(opts.TLSCrypt)
+			if err != nil {
+				return fmt.Errorf("can not generate tls crypt key: %s", err)
+			}
+			svr.dbServerModel.TLSCrypt = opts.TLSCrypt
+			svr.dbServerModel.TLSCryptKey = key
+			rekey = true
+		}
+		changed = true
+	}
+
 	if ipblock != "" && govalidator.IsCIDR(ipblock) {
 		var ipnet *net.IPNet
 		_, ipnet, err := net.ParseCIDR(ipblock)
 		if err != nil {
 			return fmt.Errorf("can not parse CIDR %s: %v", ipblock, err)
 		}
-		svr.dbServerModel.Net = ipnet.IP.To4().String()
-		svr.dbServerModel.Mask = net.IP(ipnet.Mask).To4().String()
+		netAddr, mask := ipnet.IP.To4().String(), net.IP(ipnet.Mask).To4().String()
+		if netAddr != svr.dbServerModel.Net || mask != svr.dbServerModel.Mask {
+			svr.dbServerModel.Net = netAddr
+			svr.dbServerModel.Mask = mask
+			ipChanged = true
+		}
 		changed = true
 	}
 
@@ -401,16 +748,23 @@ func (svr *Server) Update(ipblock string, dns string, useLzo *bool) error {
 	}
 	if changed {
 		db.Save(svr.dbServerModel)
-		users, err := GetAllUsers()
-		if err != nil {
-			return err
+		if rekey {
+			if err := svr.rekeyUsers(); err != nil {
+				return err
+			}
 		}
+		if ipChanged {
+			users, err = GetAllUsers()
+			if err != nil {
+				return err
+			}
 
-		// Set all users to dynamic ip address.
-		// This way we prevent any ip range mismatch.
-		for _, user := range users {
-			user.HostID = 0
-			db.Save(user.dbUserModel)
+			// Set all users to dynamic ip address.
+			// This way we prevent any ip range mismatch.
+			for _, user := range users {
+				user.HostID = 0
+				db.Save(user.dbUserModel)
+			}
 		}
 
 		svr.EmitWithRestart()
@@ -427,17 +781,38 @@ func (svr *Server) Deinit() error {
 
 	db.Unscoped().Delete(&dbServerModel{})
 	db.Unscoped().Delete(&dbRevokedModel{})
+	db.Unscoped().Delete(&dbCAHistoryModel{})
+	svr.setCASigner(nil)
 	svr.EmitWithRestart()
 	return nil
 }
 
 // DumpsClientConfig generates .ovpn file for the given vpn user and returns it as a string.
 func (svr *Server) DumpsClientConfig(username string) (string, error) {
-	var result bytes.Buffer
 	user, err := GetUser(username)
 	if err != nil {
 		return "", err
 	}
+	return svr.renderClientConfig(user, nil)
+}
+
+// clientConfigFiles holds the paths of the credential files that a .ovpn file
+// refers to instead of embedding them.
+type clientConfigFiles struct {
+	CA       string
+	Cert     string
+	Key      string
+	TLSCrypt string
+}
+
+// renderClientConfig renders the .ovpn file of the user.
+//
+// Credentials are embedded into the file unless files is given.
+func (svr *Server) renderClientConfig(user *User, files *clientConfigFiles) (string, error) {
+	var result bytes.Buffer
+	if files == nil {
+		files = &clientConfigFiles{}
+	}
 
 	params := struct {
 		Hostname         string
@@ -445,22 +820,41 @@ func (svr *Server) DumpsClientConfig(username string) (string, error) {
 		CA               string
 		Key              string
 		Cert             string
+		CAFile           string
+		CertFile         string
+		KeyFile          string
+		TLSCrypt         string
+		TLSCryptKey      string
+		TLSCryptFile     string
 		NoGW             bool
 		Proto            string
 		KeepalivePeriod  string
 		KeepaliveTimeout string
 		UseLZO           bool
+		AuthUserPass     bool
+		StaticChallenge  bool
+
+		CryptoProfile
 	}{
 		Hostname:         svr.GetHostname(),
 		Port:             svr.GetPort(),
-		CA:               svr.GetCACert(),
+		CA:               svr.GetCABundle(),
 		Key:              user.getKey(),
 		Cert:             user.GetCert(),
+		CAFile:           files.CA,
+		CertFile:         files.Cert,
+		KeyFile:          files.Key,
+		TLSCrypt:         svr.GetTLSCrypt(),
+		TLSCryptKey:      svr.clientTLSCryptKey(user),
+		TLSCryptFile:     files.TLSCrypt,
+		CryptoProfile:    svr.GetCryptoProfile(),
 		NoGW:             user.IsNoGW(),
 		Proto:            svr.GetProto(),
 		KeepalivePeriod:  svr.GetKeepalivePeriod(),
 		KeepaliveTimeout: svr.GetKeepaliveTimeout(),
 		UseLZO:           svr.IsUseLZO(),
+		AuthUserPass:     svr.IsAuthUserPass(),
+		StaticChallenge:  user.IsTOTPEnabled(),
 	}
 
 	t, err := template.New("client.ovpn").Parse(clientOvpnTemplate)
@@ -488,24 +882,118 @@ func (svr *Server) DumpClientConfig(username, path string) error {
 }
 
 // GetSystemCA returns the system CA from the database if available.
+//
+// If the CA is offline, the returned CA signs with the unlocked CA key. It
+// returns an error if the offline CA is not unlocked yet.
 func (svr *Server) GetSystemCA() (*pki.CA, error) {
 	server := dbServerModel{}
 	db.First(&server)
 	if db.NewRecord(&server) {
 		return nil, fmt.Errorf("server record does not exists in db")
 	}
-	return &pki.CA{
+	ca := &pki.CA{
 		CertHolder: pki.CertHolder{
 			Cert: server.CACert,
 			Key:  server.CAKey,
 		},
-	}, nil
+		Chain: server.CAChain,
+	}
+	if server.CAOffline {
+		ca.Signer = svr.getCASigner()
+		if ca.Signer == nil {
+			return nil, fmt.Errorf("ca is offline and locked, ovpmd should be started with the ca key file")
+		}
+	}
+	return ca, nil
 
 }
 
 // vpnProc represents the OpenVPN process that is managed by the ovpm supervisor globally OpenVPN.
 var vpnProc supervisor.Supervisable
 
+var (
+	vpnProcLock sync.Mutex
+
+	// vpnProcLastExit is when the OpenVPN process exited unexpectedly the
+	// last time.
+	vpnProcLastExit time.Time
+
+	// vpnProcAutoRestarting is whether the supervisor is restarting the
+	// OpenVPN process.
+	vpnProcAutoRestarting bool
+)
+
+// VPNProcStatus is the status of the OpenVPN process.
+type VPNProcStatus struct {
+	State    supervisor.State
+	Restarts uint64    // Number of restarts since ovpmd started.
+	LastExit time.Time // When it exited unexpectedly the last time, zero if it never did.
+}
+
+// GetVPNProcStatus returns the status of the OpenVPN process.
+func GetVPNProcStatus() VPNProcStatus {
+	vpnProcLock.Lock()
+	defer vpnProcLock.Unlock()
+	st := VPNProcStatus{
+		State:    supervisor.UNKNOWN,
+		Restarts: atomic.LoadUint64(&vpnProcRestarts),
+		LastExit: vpnProcLastExit,
+	}
+	if vpnProc != nil {
+		st.State = vpnProc.Status()
+	}
+	return st
+}
+
+// SetVPNRestartOptions sets how the OpenVPN process is restarted when it
+// exits unexpectedly.
+func SetVPNRestartOptions(o supervisor.RestartOptions) error {
+	p, ok := vpnProc.(*supervisor.Process)
+	if !ok {
+		return fmt.Errorf("can not set restart options: OpenVPN process is not supervised")
+	}
+	return p.SetRestartOptions(o)
+}
+
+// vpnProcStateChanged keeps the server in sync with the OpenVPN process when
+// the supervisor restarts it or gives up on it.
+func vpnProcStateChanged(from, to supervisor.State) {
+	switch to {
+	case supervisor.EXITED:
+		vpnProcLock.Lock()
+		vpnProcLastExit = time.Now()
+		vpnProcLock.Unlock()
+		endVPNSessions(SessionServerStopped)
+	case supervisor.STARTING:
+		if from == supervisor.EXITED {
+			atomic.AddUint64(&vpnProcRestarts, 1)
+			vpnProcLock.Lock()
+			vpnProcAutoRestarting = true
+			vpnProcLock.Unlock()
+		}
+	case supervisor.RUNNING:
+		// The restarts that are done through the server reconnect to the
+		// management interface themselves.
+		vpnProcLock.Lock()
+		restarted := vpnProcAutoRestarting
+		vpnProcAutoRestarting = false
+		vpnProcLock.Unlock()
+		if restarted && !Testing {
+			go TheServer().connectManagement()
+		}
+	case supervisor.STOPPED:
+		vpnProcLock.Lock()
+		vpnProcAutoRestarting = false
+		vpnProcLock.Unlock()
+	case supervisor.FAILED:
+		if from == supervisor.EXITED {
+			logrus.Error("OpenVPN keeps exiting, it is not restarted anymore. Check the OpenVPN logs and restart the server.")
+		} else {
+			logrus.Error("OpenVPN can not be started. Check the OpenVPN logs and restart the server.")
+		}
+	}
+}
+
 // StartVPNProc starts the OpenVPN process.
 func (svr *Server) StartVPNProc() {
 	if !svr.IsInitialized() {
@@ -522,6 +1010,9 @@ func (svr *Server) StartVPNProc() {
 	svr.Emit()
 	vpnProc.Start()
 	ensureNatEnabled()
+	if !Testing {
+		go svr.connectManagement()
+	}
 }
 
 // RestartVPNProc restarts the OpenVPN process.
@@ -534,8 +1025,13 @@ func (svr *Server) RestartVPNProc() {
 		panic(fmt.Sprintf("vpnProc is not initialized!"))
 	}
 	svr.Emit()
+	endVPNSessions(SessionServerStopped)
 	vpnProc.Restart()
+	atomic.AddUint64(&vpnProcRestarts, 1)
 	ensureNatEnabled()
+	if !Testing {
+		go svr.connectManagement()
+	}
 }
 
 // StopVPNProc stops the OpenVPN process.
@@ -543,78 +1039,121 @@ func (svr *Server) StopVPNProc() {
 	if vpnProc == nil {
 		panic(fmt.Sprintf("vpnProc is not initialized!"))
 	}
-	if vpnProc.Status() != supervisor.RUNNING {
+	if vpnProc.Status() == supervisor.STOPPED {
 		logrus.Error("OpenVPN is already not running")
 		return
 	}
 	vpnProc.Stop()
+	endVPNSessions(SessionServerStopped)
 }
 
 // Emit generates all needed files for the OpenVPN server and dumps them to their corresponding paths defined in the config.
 func (svr *Server) Emit() error {
+	_, err := svr.emit()
+	return err
+}
+
+// emit generates and dumps all needed files for the OpenVPN server like Emit does.
+//
+// It returns whether any of the startupFiles has changed since the last emit,
+// which means the OpenVPN process needs to be restarted to pick up the changes.
+func (svr *Server) emit() (changed bool, err error) {
+	defer prometheus.NewTimer(emitDuration).ObserveDuration()
+	defer func() { recordEmit(err) }()
+
 	// Check dependencies
 	if !checkOpenVPNExecutable() {
-		return fmt.Errorf("openvpn executable can not be found! you should install OpenVPN on this machine")
+		return false, fmt.Errorf("openvpn executable can not be found! you should install OpenVPN on this machine")
 	}
 
 	if !checkOpenSSLExecutable() {
-		return fmt.Errorf("openssl executable can not be found! you should install openssl on this machine")
+		return false, fmt.Errorf("openssl executable can not be found! you should install openssl on this machine")
 
 	}
 
 	if !checkIptablesExecutable() {
-		return fmt.Errorf("iptables executable can not be found")
+		return false, fmt.Errorf("iptables executable can not be found")
 	}
 
 	if !svr.IsInitialized() {
-		return fmt.Errorf("you should create a server first. e.g. $ ovpm vpn create-server")
+		return false, fmt.Errorf("you should create a server first. e.g. $ ovpm vpn create-server")
 	}
 
+	before := svr.emittedSums(startupFiles...)
+
+	svr.generateDHParams()
+
 	if err := svr.emitServerConf(); err != nil {
-		return fmt.Errorf("can not emit server conf: %s", err)
+		return false, fmt.Errorf("can not emit server conf: %s", err)
 	}
 
 	if err := svr.emitServerCert(); err != nil {
-		return fmt.Errorf("can not emit server cert: %s", err)
+		return false, fmt.Errorf("can not emit server cert: %s", err)
 	}
 
 	if err := svr.emitServerKey(); err != nil {
-		return fmt.Errorf("can not emit server key: %s", err)
+		return false, fmt.Errorf("can not emit server key: %s", err)
 	}
 
 	if err := svr.emitCACert(); err != nil {
-		return fmt.Errorf("can not emit ca cert : %s", err)
+		return false, fmt.Errorf("can not emit ca cert : %s", err)
 	}
 
 	if err := svr.emitCAKey(); err != nil {
-		return fmt.Errorf("can not emit ca key: %s", err)
+		return false, fmt.Errorf("can not emit ca key: %s", err)
+	}
+
+	if err := svr.emitTLSCryptKey(); err != nil {
+		return false, fmt.Errorf("can not emit tls crypt key: %s", err)
 	}
 
 	if err := svr.emitDHParams(); err != nil {
-		return fmt.Errorf("can not emit dhparams: %s", err)
+		return false, fmt.Errorf("can not emit dhparams: %s", err)
 	}
 
 	if err := svr.emitCCD(); err != nil {
-		return fmt.Errorf("can not emit ccd: %s", err)
+		return false, fmt.Errorf("can not emit ccd: %s", err)
 	}
 
 	if err := svr.emitIptables(); err != nil {
-		return fmt.Errorf("can not emit iptables: %s", err)
+		return false, fmt.Errorf("can not emit iptables: %s", err)
 	}
 
-	if err := svr.emitCRL(); err != nil {
-		return fmt.Errorf("can not emit crl: %s", err)
+	// The last emitted CRL is kept until the offline CA is unlocked. Nothing
+	// is revoked in the meantime, since the revocations are refused.
+	if svr.IsCAUnlocked() {
+		if err := svr.emitCRL(); err != nil {
+			return false, fmt.Errorf("can not emit crl: %s", err)
+		}
+	} else {
+		logrus.Warnf("ca is offline and locked, crl is not updated")
 	}
 
 	logrus.Info("configurations emitted to the filesystem")
-	return nil
+
+	after := svr.emittedSums(startupFiles...)
+	for _, path := range startupFiles {
+		if before[path] != after[path] {
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
-// EmitWithRestart restarts vpnProc after calling EmitWithRestart().
+// EmitWithRestart emits the configuration and restarts vpnProc if it's needed.
+//
+// The OpenVPN process is restarted only if any of the files that are read on
+// startup has changed (e.g. server.conf) or if it's not running. Otherwise the
+// changes are applied in place, without dropping the connected clients.
 func (svr *Server) EmitWithRestart() error {
-	if err := svr.Emit(); err != nil {
+	restart, err := svr.emit()
+	if err != nil {
 		return err
 	}
+	if !restart && vpnProc.Status() == supervisor.RUNNING {
+		logrus.Info("configuration changes are applied without restarting OpenVPN")
+		return nil
+	}
 	if svr.IsInitialized() {
 		for {
 			if vpnProc.Status() == supervisor.RUNNING || vpnProc.Status() == supervisor.STOPPED {
@@ -631,8 +1170,27 @@ func (svr *Server) EmitWithRestart() error {
 }
 
 // emitToFile is a proxy that calls svr.emitToFileFunc.
+//
+// It also keeps track of the checksum of the emitted content.
 func (svr *Server) emitToFile(path, content string, mode uint) error {
-	return svr.emitToFileFunc(path, content, mode)
+	if err := svr.emitToFileFunc(path, content, mode); err != nil {
+		return err
+	}
+	svr.emittedLock.Lock()
+	defer svr.emittedLock.Unlock()
+	svr.emitted[path] = fmt.Sprintf("%x", sha256.Sum256([]byte(content)))
+	return nil
+}
+
+// emittedSums returns the checksums of the last emitted contents of the given paths.
+func (svr *Server) emittedSums(paths ...string) map[string]string {
+	svr.emittedLock.Lock()
+	defer svr.emittedLock.Unlock()
+	sums := make(map[string]string)
+	for _, path := range paths {
+		sums[path] = svr.emitted[path]
+	}
+	return sums
 }
 
 // emitToFile is an implementation for svr.emitToFileFunc.
@@ -680,6 +1238,13 @@ func (svr *Server) emitServerConf() error {
 		CCDPath          string
 		CRLPath          string
 		DHParamsPath     string
+		DHNone           bool
+		ECDHCurve        string
+		TLSCrypt         string
+		TLSCryptKeyPath  string
+		ManagementPath   string
+		AuthUserPass     bool
+		VerifyCommand    string
 		Net              string
 		Mask             string
 		Port             string
@@ -688,6 +1253,8 @@ func (svr *Server) emitServerConf() error {
 		KeepalivePeriod  string
 		KeepaliveTimeout string
 		UseLZO           bool
+
+		CryptoProfile
 	}{
 		CertPath:         _DefaultCertPath,
 		KeyPath:          _DefaultKeyPath,
@@ -696,6 +1263,14 @@ func (svr *Server) emitServerConf() error {
 		CCDPath:          _DefaultVPNCCDPath,
 		CRLPath:          _DefaultCRLPath,
 		DHParamsPath:     _DefaultDHParamsPath,
+		DHNone:           !svr.needsDHParams() || svr.DHParams == "",
+		ECDHCurve:        svr.GetKeyType().ECDHCurve(),
+		TLSCrypt:         svr.GetTLSCrypt(),
+		TLSCryptKeyPath:  _DefaultTLSCryptKeyPath,
+		CryptoProfile:    svr.GetCryptoProfile(),
+		ManagementPath:   _DefaultManagementSocketPath,
+		AuthUserPass:     svr.IsAuthUserPass(),
+		VerifyCommand:    svr.verifyUserPassCommand(),
 		Net:              svr.Net,
 		Mask:             svr.Mask,
 		Port:             port,
@@ -741,13 +1316,7 @@ func (svr *Server) Refresh() error {
 func (svr *Server) GetConnectedUsers() ([]User, error) {
 	var users []User
 
-	// Open the status log file.
-	f, err := svr.openFunc(_DefaultStatusLogPath)
-	if err != nil {
-		panic(err)
-	}
-
-	cl, _ := svr.parseStatusLogFunc(f) // client list from OpenVPN status log
+	cl, _ := svr.clientList() // client list from OpenVPN
 	for _, c := range cl {
 		var u dbUserModel
 		q := db.Where(dbUserModel{Username: c.CommonName}).First(&u)
@@ -796,32 +1365,65 @@ func (svr *Server) emitServerCert() error {
 }
 
 func (svr *Server) emitCRL() error {
-	var revokedDBItems []*dbRevokedModel
-	db.Find(&revokedDBItems)
-	var revokedCertSerials []*big.Int
-	for _, item := range revokedDBItems {
-		bi := big.NewInt(0)
-		bi.SetString(item.SerialNumber, 16)
-		revokedCertSerials = append(revokedCertSerials, bi)
-	}
-	systemCA, err := svr.GetSystemCA()
+	// Locked offline CA can't sign a new CRL.
+	if !svr.IsCAUnlocked() {
+		return fmt.Errorf("ca is offline and locked, crl can not be signed")
+	}
+
+	now := time.Now()
+	entries, err := crlEntries(now)
 	if err != nil {
 		return fmt.Errorf("can not emit CRL: %v", err)
 	}
-	crl, err := pki.NewCRL(systemCA, revokedCertSerials...)
+
+	// Every emitted CRL gets a new CRL number.
+	svr.dbServerModel.CRLNumber++
+	db.Model(&svr.dbServerModel).UpdateColumn("crl_number", svr.dbServerModel.CRLNumber)
+	number := big.NewInt(svr.dbServerModel.CRLNumber)
+
+	// OpenVPN requires a CRL for each trusted CA.
+	cas, err := svr.allCAs()
 	if err != nil {
-		return fmt.Errorf("can not emit crl: %v", err)
+		return fmt.Errorf("can not emit CRL: %v", err)
 	}
+	var crls []string
+	for _, ca := range cas {
+		crl, err := pki.NewCRLWithNumber(ca, number, entries...)
+		if err != nil {
+			return fmt.Errorf("can not emit crl: %v", err)
+		}
+		crls = append(crls, crl)
+	}
+	// Issuers of the imported intermediate CAs publish their own CRLs.
+	crls = append(crls, svr.issuerCRLs()...)
 
-	return svr.emitToFile(_DefaultCRLPath, crl, 0)
+	if err := svr.emitToFile(_DefaultCRLPath, strings.Join(crls, ""), 0); err != nil {
+		return err
+	}
+	svr.emittedLock.Lock()
+	svr.crlEmittedAt = now
+	svr.emittedLock.Unlock()
+	return nil
 }
 
 func (svr *Server) emitCACert() error {
-	// Write rendered content into the ca cert file.
-	return svr.emitToFile(_DefaultCACertPath, svr.CACert, 0)
+	// Write the trusted CA certs into the ca cert file.
+	return svr.emitToFile(_DefaultCACertPath, svr.GetCABundle(), 0)
 }
 
 func (svr *Server) emitCAKey() error {
+	// Offline CA key is never written to the filesystem. Remove the one
+	// that is emitted before the CA is taken offline.
+	if svr.IsCAOffline() {
+		if Testing {
+			return nil
+		}
+		if err := os.Remove(_DefaultCAKeyPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
 	// Write rendered content into the ca key file.
 	return svr.emitToFile(_DefaultCAKeyPath, svr.CAKey, 0600)
 }
@@ -833,23 +1435,35 @@ func (svr *Server) emitCCD() error {
 	}
 
 	// Filesystem related stuff. Skipping when testing.
+	//
+	// ccd files are updated in place instead of recreating the whole
+	// directory, because the running OpenVPN process reads them whenever
+	// a client connects.
 	if !Testing {
-		// Clean and then create and write rendered ccd data.
-		err = os.RemoveAll(_DefaultVPNCCDPath)
+		err = os.Mkdir(_DefaultVPNCCDPath, 0755)
 		if err != nil {
-			if os.IsNotExist(err) {
-			} else {
+			if !os.IsExist(err) {
 				return err
 			}
 		}
 
-		if _, err := os.Stat(_DefaultVPNCCDPath); err != nil {
-		}
-
-		err = os.Mkdir(_DefaultVPNCCDPath, 0755)
+		// Remove the ccd files of the users that don't exist anymore.
+		files, err := ioutil.ReadDir(_DefaultVPNCCDPath)
 		if err != nil {
-			if !os.IsExist(err) {
-				return err
+			return err
+		}
+		for _, f := range files {
+			var found bool
+			for _, user := range users {
+				if user.Username == f.Name() {
+					found = true
+					break
+				}
+			}
+			if !found {
+				if err := os.Remove(filepath.Join(_DefaultVPNCCDPath, f.Name())); err != nil {
+					return err
+				}
 			}
 		}
 	}
@@ -892,7 +1506,8 @@ func (svr *Server) emitCCD() error {
 			Routes     [][3]string // [0] is IP, [1] is Netmask, [2] is Via
 			Servernets [][2]string // [0] is IP, [1] is Netmask
 			RedirectGW bool
-		}{IP: user.getIP().String(), NetMask: svr.Mask, Routes: associatedRoutes, Servernets: serverNets, RedirectGW: !user.NoGW}
+			Disable    bool
+		}{IP: user.getIP().String(), NetMask: svr.Mask, Routes: associatedRoutes, Servernets: serverNets, RedirectGW: !user.NoGW, Disable: user.Disabled}
 
 		t, err := template.New("ccd.file.tmpl").Parse(ccdFileTemplate)
 		if err != nil {
@@ -911,19 +1526,10 @@ func (svr *Server) emitCCD() error {
 }
 
 func (svr *Server) emitDHParams() error {
-	var result bytes.Buffer
-
-	t, err := template.New("dh4096.pem.tmpl").Parse(dh4096PemTemplate)
-	if err != nil {
-		return fmt.Errorf("can not parse dh4096.pem template: %s", err)
-	}
-
-	err = t.Execute(&result, nil)
-	if err != nil {
-		return fmt.Errorf("can not render dh4096.pem file: %s", err)
+	if !svr.needsDHParams() || svr.DHParams == "" {
+		return nil
 	}
-
-	return svr.emitToFile(_DefaultDHParamsPath, result.String(), 0)
+	return svr.emitToFile(_DefaultDHParamsPath, svr.DHParams, 0)
 }
 
 func (svr *Server) emitIptables() error {
@@ -1046,5 +1652,7 @@ func init() {
 	vpnProc, err = supervisor.NewProcess(getOpenVPNExecutable(), varBasePath, []string{"--config", _DefaultVPNConfPath})
 	if err != nil {
 		logrus.Errorf("can not create process: %v", err)
+		return
 	}
+	vpnProc.OnStateChange(vpnProcStateChanged)
 }
@@ -1,13 +1,23 @@
 package ovpm
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
 	"io"
+	"math/big"
+	"net"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/cad/ovpm/mgmt"
 	"github.com/cad/ovpm/pki"
 	"github.com/cad/ovpm/supervisor"
 	"github.com/sirupsen/logrus"
@@ -40,12 +50,12 @@ func TestVPNInit(t *testing.T) {
 
 	// Wrongfully initialize server.
 
-	if err := TheServer().Init("localhost", "asdf", UDPProto, "", "", "", "", false); err == nil {
+	if err := TheServer().Init("localhost", "asdf", UDPProto, "", "", "", "", false, nil); err == nil {
 		t.Fatalf("error is expected to be not nil but it's nil instead")
 	}
 
 	// Initialize the server.
-	TheServer().Init("localhost", "", UDPProto, "", "", "", "", false)
+	TheServer().Init("localhost", "", UDPProto, "", "", "", "", false, nil)
 
 	// Check database if the database has no server.
 	var server2 dbServerModel
@@ -65,7 +75,7 @@ func TestVPNDeinit(t *testing.T) {
 
 	// Prepare:
 	// Initialize the server.
-	TheServer().Init("localhost", "", UDPProto, "", "", "", "", false)
+	TheServer().Init("localhost", "", UDPProto, "", "", "", "", false, nil)
 	u, err := CreateNewUser("user", "p", false, 0, true, "description")
 	if err != nil {
 		t.Fatal(err)
@@ -116,7 +126,7 @@ func TestVPNUpdate(t *testing.T) {
 	CreateDB("sqlite3", ":memory:")
 	defer db.Cease()
 	// Prepare:
-	TheServer().Init("localhost", "", UDPProto, "", "", "", "", false)
+	TheServer().Init("localhost", "", UDPProto, "", "", "", "", false, nil)
 	// Test:
 
 	var updatetests = []struct {
@@ -134,11 +144,11 @@ func TestVPNUpdate(t *testing.T) {
 	}
 	for i, tt := range updatetests {
 		svr := TheServer()
-		svr.Init("localhost", "", UDPProto, "", "", "", "", false)
+		svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil)
 
 		oldIP := svr.Net
 		oldDNS := svr.DNS
-		svr.Update(tt.vpnnet, tt.dns, tt.useLZO)
+		svr.Update(tt.vpnnet, tt.dns, tt.useLZO, nil)
 		svr = nil
 		svr = TheServer()
 		if (svr.Net != oldIP) != tt.vpnChanged {
@@ -152,6 +162,42 @@ func TestVPNUpdate(t *testing.T) {
 
 }
 
+func TestVPNUpdateStaticIP(t *testing.T) {
+	// Init:
+	setupTestCase()
+	CreateDB("sqlite3", ":memory:")
+	defer db.Cease()
+	// Prepare:
+	svr := TheServer()
+	svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil)
+	hostid := IP2HostID(net.ParseIP("10.9.0.10").To4())
+	if _, err := CreateNewUser("user", "password", false, hostid, false, "description"); err != nil {
+		t.Fatalf("can not create user: %v", err)
+	}
+
+	// Test:
+	// Static ips should survive the updates that don't change the ip block.
+	if err := svr.Update("", "1.1.1.1", nil, &ServerOptions{ClientCertValidityDays: 90}); err != nil {
+		t.Fatalf("can not update server: %v", err)
+	}
+	if err := svr.Update("10.9.0.0/24", "", nil, nil); err != nil {
+		t.Fatalf("can not update server: %v", err)
+	}
+	user, _ := GetUser("user")
+	if user.HostID != hostid {
+		t.Errorf("user's static ip is expected to be kept but it's reset")
+	}
+
+	// Changing the ip block resets them.
+	if err := svr.Update("192.168.9.0/24", "", nil, nil); err != nil {
+		t.Fatalf("can not update server: %v", err)
+	}
+	user, _ = GetUser("user")
+	if user.HostID != 0 {
+		t.Errorf("user's static ip is expected to be reset but it's kept")
+	}
+}
+
 func TestVPNIsInitialized(t *testing.T) {
 	// Init:
 	setupTestCase()
@@ -167,7 +213,7 @@ func TestVPNIsInitialized(t *testing.T) {
 	}
 
 	// Initialize the server.
-	TheServer().Init("localhost", "", UDPProto, "", "", "", "", false)
+	TheServer().Init("localhost", "", UDPProto, "", "", "", "", false, nil)
 
 	// Isn't initialized?
 	if !TheServer().IsInitialized() {
@@ -192,7 +238,7 @@ func TestVPNTheServer(t *testing.T) {
 	}
 
 	// Initialize server.
-	svr.Init("localhost", "", UDPProto, "", "", "", "", false)
+	svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil)
 
 	svr = TheServer()
 
@@ -208,7 +254,7 @@ func TestVPNDumpsClientConfig(t *testing.T) {
 	CreateDB("sqlite3", ":memory:")
 	defer db.Cease()
 	svr := TheServer()
-	svr.Init("localhost", "", UDPProto, "", "", "", "", false)
+	svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil)
 
 	// Prepare:
 	user, err := CreateNewUser("user", "password", false, 0, true, "description")
@@ -234,7 +280,7 @@ func TestVPNDumpClientConfig(t *testing.T) {
 	CreateDB("sqlite3", ":memory:")
 	defer db.Cease()
 	svr := TheServer()
-	svr.Init("localhost", "", UDPProto, "", "", "", "", false)
+	svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil)
 
 	// Prepare:
 	noGW := false
@@ -295,7 +341,7 @@ func TestVPNGetSystemCA(t *testing.T) {
 	}
 
 	// Initialize system.
-	svr.Init("localhost", "", UDPProto, "", "", "", "", false)
+	svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil)
 
 	ca, err = svr.GetSystemCA()
 	if err != nil {
@@ -336,7 +382,7 @@ func TestVPNStartVPNProc(t *testing.T) {
 	}
 
 	// Initialize OVPM server.
-	svr.Init("localhost", "", UDPProto, "", "", "", "", false)
+	svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil)
 
 	// Call start again..
 	svr.StartVPNProc()
@@ -353,7 +399,7 @@ func TestVPNStopVPNProc(t *testing.T) {
 	CreateDB("sqlite3", ":memory:")
 	defer db.Cease()
 	svr := TheServer()
-	svr.Init("localhost", "", UDPProto, "", "", "", "", false)
+	svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil)
 
 	// Prepare:
 	vpnProc.Start()
@@ -378,7 +424,7 @@ func TestVPNRestartVPNProc(t *testing.T) {
 	CreateDB("sqlite3", ":memory:")
 	defer db.Cease()
 	svr := TheServer()
-	svr.Init("localhost", "", UDPProto, "", "", "", "", false)
+	svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil)
 
 	// Prepare:
 
@@ -407,7 +453,7 @@ func TestVPNEmit(t *testing.T) {
 	CreateDB("sqlite3", ":memory:")
 	defer db.Cease()
 	svr := TheServer()
-	svr.Init("localhost", "", UDPProto, "", "", "", "", false)
+	svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil)
 
 	// Prepare:
 
@@ -459,7 +505,8 @@ func TestVPNemitToFile(t *testing.T) {
 }
 
 type fakeProcess struct {
-	state supervisor.State
+	state    supervisor.State
+	restarts int
 }
 
 func (f *fakeProcess) Start() {
@@ -472,19 +519,626 @@ func (f *fakeProcess) Stop() {
 
 func (f *fakeProcess) Restart() {
 	f.state = supervisor.RUNNING
+	f.restarts++
 }
 
 func (f *fakeProcess) Status() supervisor.State {
 	return f.state
 }
 
+func (f *fakeProcess) OnStateChange(func(from, to supervisor.State)) {}
+
+func TestVPNUpdateCertValidity(t *testing.T) {
+	// Initialize:
+	setupTestCase()
+	CreateDB("sqlite3", ":memory:")
+	defer db.Cease()
+	svr := TheServer()
+
+	// Prepare:
+	if err := svr.Init("localhost", "", UDPProto, "", "", "", "", false, &ServerOptions{ClientCertValidityDays: 1, CertRenewWindowDays: 1}); err == nil {
+		t.Fatalf("init is expected to fail when the renew window is not shorter than the client cert validity")
+	}
+	opts := &ServerOptions{CAValidityDays: 100, ServerCertValidityDays: 50}
+	if err := svr.Init("localhost", "", UDPProto, "", "", "", "", false, opts); err != nil {
+		t.Fatalf("can not init server: %v", err)
+	}
+	if opts.ClientCertValidityDays != 0 {
+		t.Errorf("init is expected to not modify the given options")
+	}
+	svr = TheServer()
+
+	// Test:
+	var validitytests = []struct {
+		name     string
+		got      int
+		expected int
+	}{
+		{"CA", svr.GetCAValidityDays(), 100},
+		{"Server", svr.GetServerCertValidityDays(), 50},
+		{"Client", svr.GetClientCertValidityDays(), DefaultClientCertValidityDays},
+		{"RenewWindow", svr.GetCertRenewWindowDays(), DefaultCertRenewWindowDays},
+	}
+	for _, tt := range validitytests {
+		if tt.got != tt.expected {
+			t.Errorf("%s validity is expected to be %d but it's %d", tt.name, tt.expected, tt.got)
+		}
+	}
+	if svr.CAExpiresAt().After(time.Now().Add(101 * 24 * time.Hour)) {
+		t.Errorf("CA cert is expected to expire in 100 days but it expires at %v", svr.CAExpiresAt())
+	}
+
+	// Updating the server cert validity re-issues the server cert.
+	oldCert := svr.Cert
+	if err := svr.Update("", "", nil, &ServerOptions{ServerCertValidityDays: 20}); err != nil {
+		t.Fatalf("can not update server: %v", err)
+	}
+	svr = TheServer()
+	if svr.Cert == oldCert {
+		t.Errorf("server cert is expected to be re-issued")
+	}
+	if svr.ExpiresAt().After(time.Now().Add(21 * 24 * time.Hour)) {
+		t.Errorf("server cert is expected to expire in 20 days but it expires at %v", svr.ExpiresAt())
+	}
+	if svr.GetCAValidityDays() != 100 {
+		t.Errorf("CA validity is expected to stay unchanged but it's %d", svr.GetCAValidityDays())
+	}
+
+	if err := svr.Update("", "", nil, &ServerOptions{CertRenewWindowDays: 5000}); err == nil {
+		t.Errorf("update is expected to fail when the renew window is not shorter than the client cert validity")
+	}
+}
+
+func TestVPNKeyType(t *testing.T) {
+	// Initialize:
+	setupTestCase()
+	CreateDB("sqlite3", ":memory:")
+	defer db.Cease()
+	svr := TheServer()
+
+	// Prepare:
+	if err := svr.Init("localhost", "", UDPProto, "", "", "", "", false, &ServerOptions{KeyType: "dsa1024"}); err == nil {
+		t.Fatalf("init is expected to fail with an unknown key type")
+	}
+	if err := svr.Init("localhost", "", UDPProto, "", "", "", "", false, &ServerOptions{KeyType: string(pki.KeyTypeECDSAP256)}); err != nil {
+		t.Fatalf("can not init server: %v", err)
+	}
+	svr = TheServer()
+	usr, err := CreateNewUser("usr1", "1234", false, 0, true, "description")
+	if err != nil {
+		t.Fatalf("user creation failed: %v", err)
+	}
+
+	// Test:
+	for name, cert := range map[string]string{"ca": svr.CACert, "server": svr.Cert, "user": usr.Cert} {
+		if kt, _ := pki.CertKeyType(cert); kt != pki.KeyTypeECDSAP256 {
+			t.Errorf("%s key type is expected to be %s but it's %s", name, pki.KeyTypeECDSAP256, kt)
+		}
+	}
+	if !strings.Contains(fs[_DefaultVPNConfPath], "dh none") || !strings.Contains(fs[_DefaultVPNConfPath], "ecdh-curve prime256v1") {
+		t.Errorf("server.conf is expected to use ecdh with prime256v1:\n%s", fs[_DefaultVPNConfPath])
+	}
+
+	// Switching back to RSA re-issues the server cert and brings the dh params back.
+	if err := svr.Update("", "", nil, &ServerOptions{KeyType: string(pki.KeyTypeRSA2048)}); err != nil {
+		t.Fatalf("can not update server: %v", err)
+	}
+	svr = TheServer()
+	if kt, _ := pki.CertKeyType(svr.Cert); kt != pki.KeyTypeRSA2048 {
+		t.Errorf("server key type is expected to be %s but it's %s", pki.KeyTypeRSA2048, kt)
+	}
+	if !strings.Contains(fs[_DefaultVPNConfPath], "dh "+_DefaultDHParamsPath) {
+		t.Errorf("server.conf is expected to use the dh params:\n%s", fs[_DefaultVPNConfPath])
+	}
+}
+
+func TestVPNImportCA(t *testing.T) {
+	// Initialize:
+	setupTestCase()
+	CreateDB("sqlite3", ":memory:")
+	defer db.Cease()
+	svr := TheServer()
+	svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil)
+	svr = TheServer()
+
+	// Prepare:
+	root, err := pki.NewCA()
+	if err != nil {
+		t.Fatalf("can not create ca: %v", err)
+	}
+	interCert, interKey := newTestIntermediateCA(t, root)
+	rootCRL, err := pki.NewCRLWithNumber(root, big.NewInt(1))
+	if err != nil {
+		t.Fatalf("can not create crl: %v", err)
+	}
+	oldCACert := svr.CACert
+
+	// Test:
+	// Invalid CA doesn't break the current server.
+	if err := svr.Init("localhost", "", UDPProto, "", "", "", "", false, &ServerOptions{CACert: interCert, CAKey: root.Key}); err == nil {
+		t.Fatalf("init is expected to fail when the ca key doesn't match")
+	}
+	if svr = TheServer(); !svr.IsInitialized() || svr.CACert != oldCACert {
+		t.Fatalf("server is expected to stay unchanged after a failed import")
+	}
+
+	// OpenVPN can't verify the intermediate without the root's CRL.
+	if err := svr.Init("localhost", "", UDPProto, "", "", "", "", false, &ServerOptions{CACert: interCert + root.Cert, CAKey: interKey}); err == nil {
+		t.Fatalf("init is expected to fail when the issuer crl is missing")
+	}
+	if err := svr.Init("localhost", "", UDPProto, "", "", "", "", false, &ServerOptions{CACert: interCert + root.Cert, CAKey: interKey, CACRL: rootCRL}); err != nil {
+		t.Fatalf("can not init server with an imported ca: %v", err)
+	}
+	svr = TheServer()
+	if strings.TrimSpace(svr.CACert) != strings.TrimSpace(interCert) {
+		t.Errorf("imported ca cert is expected to be the intermediate")
+	}
+	usr, err := CreateNewUser("usr1", "1234", false, 0, true, "description")
+	if err != nil {
+		t.Fatalf("user creation failed: %v", err)
+	}
+
+	// Both the server and the clients should trust the full chain, and
+	// there should be a CRL for every cert in the chain like OpenVPN checks.
+	if err := verifyEmittedCert(usr.Cert); err != nil {
+		t.Errorf("user cert can not be verified with the emitted files: %v", err)
+	}
+	if err := verifyEmittedCert(svr.Cert); err != nil {
+		t.Errorf("server cert can not be verified with the emitted files: %v", err)
+	}
+	clientConfig, err := svr.DumpsClientConfig(usr.GetUsername())
+	if err != nil {
+		t.Fatalf("can not dump client config: %v", err)
+	}
+	for _, c := range []string{interCert, root.Cert} {
+		if !strings.Contains(fs[_DefaultCACertPath], strings.TrimSpace(c)) || !strings.Contains(clientConfig, strings.TrimSpace(c)) {
+			t.Errorf("ca chain is expected to be emitted into the server and the client configs")
+		}
+	}
+
+	if err := svr.Update("", "", nil, &ServerOptions{CACert: root.Cert, CAKey: root.Key}); err == nil {
+		t.Errorf("update is expected to reject importing a ca")
+	}
+
+	// Issuer CRLs can be replaced before they expire.
+	newRootCRL, _ := pki.NewCRLWithNumber(root, big.NewInt(2))
+	if err := svr.Update("", "", nil, &ServerOptions{CACRL: newRootCRL}); err != nil {
+		t.Fatalf("can not update issuer crls: %v", err)
+	}
+	if !strings.Contains(fs[_DefaultCRLPath], strings.TrimSpace(newRootCRL)) || strings.Contains(fs[_DefaultCRLPath], strings.TrimSpace(rootCRL)) {
+		t.Errorf("issuer crl is expected to be replaced")
+	}
+
+	// Revoked users shouldn't pass the CRL check.
+	if err := usr.RevokeCert(pki.ReasonKeyCompromise, "admin"); err != nil {
+		t.Fatalf("can not revoke user cert: %v", err)
+	}
+	if err := verifyEmittedCert(usr.Cert); err == nil {
+		t.Errorf("revoked user cert is expected to fail the crl check")
+	}
+}
+
+// verifyEmittedCert verifies the cert with the emitted CA bundle and checks
+// the emitted CRLs of every cert in the chain like OpenVPN's crl-verify.
+func verifyEmittedCert(certPEM string) error {
+	bundle, err := pki.ReadCertsFromPEM(fs[_DefaultCACertPath])
+	if err != nil {
+		return err
+	}
+	roots, intermediates := x509.NewCertPool(), x509.NewCertPool()
+	for _, c := range bundle {
+		if c.CheckSignatureFrom(c) == nil {
+			roots.AddCert(c)
+		} else {
+			intermediates.AddCert(c)
+		}
+	}
+	crt, err := pki.ReadCertFromPEM(certPEM)
+	if err != nil {
+		return err
+	}
+	chains, err := crt.Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}})
+	if err != nil {
+		return err
+	}
+
+	var crls []*x509.RevocationList
+	rest := []byte(fs[_DefaultCRLPath])
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		crl, err := x509.ParseRevocationList(block.Bytes)
+		if err != nil {
+			return err
+		}
+		crls = append(crls, crl)
+	}
+
+	chain := chains[0]
+	for i, c := range chain {
+		issuer := c
+		if i+1 < len(chain) {
+			issuer = chain[i+1]
+		}
+		var crl *x509.RevocationList
+		for _, l := range crls {
+			if l.CheckSignatureFrom(issuer) == nil {
+				crl = l
+				break
+			}
+		}
+		if crl == nil {
+			return fmt.Errorf("crl of %s is not found", issuer.Subject)
+		}
+		if time.Now().After(crl.NextUpdate) {
+			return fmt.Errorf("crl of %s has expired", issuer.Subject)
+		}
+		for _, r := range crl.RevokedCertificateEntries {
+			if r.SerialNumber.Cmp(c.SerialNumber) == 0 {
+				return fmt.Errorf("%s is revoked", c.Subject)
+			}
+		}
+	}
+	return nil
+}
+
+// newTestIntermediateCA returns a PEM encoded intermediate CA cert and key signed by the given CA.
+func newTestIntermediateCA(t *testing.T, ca *pki.CA) (string, string) {
+	t.Helper()
+	caKey, err := pki.ParsePrivateKey(ca.Key)
+	if err != nil {
+		t.Fatalf("can not parse ca key: %v", err)
+	}
+	caCrt, _ := pki.ReadCertFromPEM(ca.Cert)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("can not generate key: %v", err)
+	}
+	tml := x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: "Intermediate CA"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &tml, caCrt, key.Public(), caKey)
+	if err != nil {
+		t.Fatalf("can not create intermediate cert: %v", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("can not marshal key: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: pki.PEMCertificateBlockType, Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: pki.PEMPrivateKeyBlockType, Bytes: keyDER}))
+}
+
+func TestVPNTakeCAOffline(t *testing.T) {
+	// Initialize:
+	setupTestCase()
+	CreateDB("sqlite3", ":memory:")
+	defer db.Cease()
+	svr := TheServer()
+	svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil)
+	svr = TheServer()
+	keyFile := filepath.Join(t.TempDir(), "ca.key.enc")
+
+	// Prepare:
+	db.Create(&dbCAHistoryModel{Cert: svr.CACert, Key: svr.CAKey, Retired: true})
+	db.Create(&dbCAHistoryModel{Cert: svr.CACert, Key: svr.CAKey, RetiresAt: time.Now().Add(time.Hour)})
+	if err := svr.TakeCAOffline(keyFile, []byte("secret")); err == nil {
+		t.Fatalf("ca is expected not to be taken offline while a previous ca is trusted")
+	}
+	db.Model(&dbCAHistoryModel{}).Update("retired", true)
+	if err := svr.TakeCAOffline(_DefaultCAKeyPath, []byte("secret")); err == nil {
+		t.Fatalf("ca key file is expected to be rejected under the var directory")
+	}
+	if err := svr.TakeCAOffline(keyFile, []byte("secret")); err != nil {
+		t.Fatalf("can not take ca offline: %v", err)
+	}
+	svr = TheServer()
+
+	// Test:
+	if !svr.IsCAOffline() || svr.CAKey != "" {
+		t.Fatalf("ca key is expected to be removed from the db")
+	}
+	var keys int
+	db.Unscoped().Model(&dbCAHistoryModel{}).Where("key <> ?", "").Count(&keys)
+	if keys != 0 {
+		t.Errorf("previous ca keys are expected to be removed from the db but %d are left", keys)
+	}
+	if err := svr.TakeCAOffline(keyFile, []byte("secret")); err == nil {
+		t.Errorf("taking an offline ca offline again is expected to fail")
+	}
+	if err := svr.RotateCA(10); err == nil {
+		t.Errorf("offline ca is expected not to be rotated")
+	}
+
+	// Locked CA can't issue certificates.
+	svr.setCASigner(nil)
+	if _, err := CreateNewUser("usr1", "1234", false, 0, true, "description"); err == nil {
+		t.Fatalf("user creation is expected to fail when the ca is locked")
+	}
+	if err := svr.UnlockCAKeyFile(keyFile, []byte("wrong")); err == nil {
+		t.Fatalf("unlocking is expected to fail with a wrong passphrase")
+	}
+	if err := svr.UnlockCAKeyFile(keyFile, []byte("secret")); err != nil {
+		t.Fatalf("can not unlock ca: %v", err)
+	}
+	usr, err := CreateNewUser("usr1", "1234", false, 0, true, "description")
+	if err != nil {
+		t.Fatalf("user creation failed: %v", err)
+	}
+	if !svr.isSignedByCurrentCA(usr.Cert) {
+		t.Errorf("user cert is expected to be signed by the offline ca")
+	}
+	if err := usr.RevokeCert(pki.ReasonKeyCompromise, ""); err != nil {
+		t.Errorf("can not revoke cert with the offline ca: %v", err)
+	}
+
+	// Locked CA can't sign the CRL, so the revocations are refused.
+	svr.setCASigner(nil)
+	if err := usr.RevokeCert(pki.ReasonKeyCompromise, ""); err == nil {
+		t.Errorf("revocation is expected to fail when the ca is locked")
+	}
+	if err := usr.Delete(); err == nil {
+		t.Errorf("user deletion is expected to fail when the ca is locked")
+	}
+	if _, err := GetUser("usr1"); err != nil {
+		t.Errorf("user is expected to be kept when the ca is locked: %v", err)
+	}
+	if err := svr.emitCRL(); err == nil {
+		t.Errorf("crl is expected not to be emitted when the ca is locked")
+	}
+	if err := svr.Emit(); err != nil {
+		t.Errorf("emit is expected to keep the last crl when the ca is locked: %v", err)
+	}
+}
+
+func TestVPNRotateCA(t *testing.T) {
+	// Initialize:
+	setupTestCase()
+	CreateDB("sqlite3", ":memory:")
+	defer db.Cease()
+	svr := TheServer()
+	svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil)
+	svr = TheServer()
+
+	// Prepare:
+	usr, err := CreateNewUser("usr1", "1234", false, 0, true, "description")
+	if err != nil {
+		t.Fatalf("user creation failed: %v", err)
+	}
+	oldCACert := svr.CACert
+	oldCert := svr.Cert
+
+	// Test:
+	if err := svr.RotateCA(10); err != nil {
+		t.Fatalf("can not rotate ca: %v", err)
+	}
+	svr = TheServer()
+	if svr.CACert == oldCACert {
+		t.Fatalf("ca cert is expected to change after the rotation")
+	}
+	if svr.Cert != oldCert {
+		t.Errorf("server cert is expected to stay signed by the previous ca during the grace period")
+	}
+	if svr.GetCARetiresAt().IsZero() {
+		t.Errorf("previous ca is expected to have a retirement time")
+	}
+
+	// Both CAs should be trusted by the server and the clients.
+	svr.DumpClientConfig(usr.GetUsername(), "/tmp/user.ovpn")
+	for _, blob := range []string{fs[_DefaultCACertPath], fs["/tmp/user.ovpn"]} {
+		if !strings.Contains(blob, strings.TrimSpace(oldCACert)) || !strings.Contains(blob, strings.TrimSpace(svr.CACert)) {
+			t.Errorf("both previous and new ca certs are expected to be trusted: %s", blob)
+		}
+	}
+	if n := strings.Count(fs[_DefaultCRLPath], "BEGIN X509 CRL"); n != 2 {
+		t.Errorf("a crl is expected for each trusted ca but there are %d", n)
+	}
+
+	// Users should be re-signed by the new CA.
+	resigned, err := ResignUsers(0)
+	if err != nil {
+		t.Fatalf("can not re-sign users: %v", err)
+	}
+	if len(resigned) != 1 {
+		t.Fatalf("1 user is expected to be re-signed but %d are re-signed", len(resigned))
+	}
+	usr, _ = GetUser(usr.GetUsername())
+	if !svr.isSignedByCurrentCA(usr.Cert) {
+		t.Errorf("user's cert is expected to be signed by the new ca")
+	}
+
+	// Previous CA shouldn't be retired before its grace period is over.
+	if n, err := RetireCAs(); err != nil || n != 0 {
+		t.Fatalf("no ca is expected to be retired: %d, %v", n, err)
+	}
+	db.Model(&dbCAHistoryModel{}).Update("retires_at", time.Now().Add(-time.Minute))
+	if n, err := RetireCAs(); err != nil || n != 1 {
+		t.Fatalf("previous ca is expected to be retired: %d, %v", n, err)
+	}
+	var retiredCA dbCAHistoryModel
+	db.First(&retiredCA)
+	if !retiredCA.Retired || retiredCA.Key != "" {
+		t.Errorf("retired ca's key is expected to be removed from the db")
+	}
+	svr = TheServer()
+	if !svr.isSignedByCurrentCA(svr.Cert) {
+		t.Errorf("server cert is expected to be re-issued by the new ca after the previous ca is retired")
+	}
+	if strings.Contains(fs[_DefaultCACertPath], strings.TrimSpace(oldCACert)) {
+		t.Errorf("retired ca is not expected to be trusted anymore")
+	}
+	if !svr.GetCARetiresAt().IsZero() {
+		t.Errorf("there shouldn't be a ca rotation in progress")
+	}
+}
+
+func TestVPNEmitCRL(t *testing.T) {
+	// Initialize:
+	setupTestCase()
+	CreateDB("sqlite3", ":memory:")
+	defer db.Cease()
+	svr := TheServer()
+	svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil)
+
+	// Prepare:
+	usr1, _ := CreateNewUser("usr1", "1234", false, 0, true, "description")
+	usr2, _ := CreateNewUser("usr2", "1234", false, 0, true, "description")
+	if err := usr1.DeleteBy("admin"); err != nil {
+		t.Fatalf("user can not be deleted: %v", err)
+	}
+	if err := usr2.RevokeCert(pki.ReasonKeyCompromise, "root"); err != nil {
+		t.Fatalf("user cert can not be revoked: %v", err)
+	}
+
+	// Test:
+	revoked, err := GetRevokedCerts()
+	if err != nil {
+		t.Fatalf("revoked certs can not be fetched: %v", err)
+	}
+	var revokedtests = []struct {
+		username  string
+		reason    pki.RevocationReason
+		revokedBy string
+	}{
+		{"usr1", pki.ReasonCessationOfOperation, "admin"},
+		{"usr2", pki.ReasonKeyCompromise, "root"},
+	}
+	if len(revoked) != len(revokedtests) {
+		t.Fatalf("%d revoked certs are expected but there are %d", len(revokedtests), len(revoked))
+	}
+	for i, tt := range revokedtests {
+		if revoked[i].Username != tt.username || revoked[i].Reason != tt.reason || revoked[i].RevokedBy != tt.revokedBy {
+			t.Errorf("revoked cert is expected to be %+v but it's %+v", tt, revoked[i])
+		}
+	}
+
+	// CRL should carry the stored revocation times and reasons.
+	parseCRL := func() *x509.RevocationList {
+		block, _ := pem.Decode([]byte(fs[_DefaultCRLPath]))
+		if block == nil {
+			t.Fatalf("crl is not emitted")
+		}
+		crl, err := x509.ParseRevocationList(block.Bytes)
+		if err != nil {
+			t.Fatalf("crl can not be parsed: %v", err)
+		}
+		return crl
+	}
+	crl := parseCRL()
+	if len(crl.RevokedCertificateEntries) != 2 {
+		t.Fatalf("2 revoked certs are expected in the crl but there are %d", len(crl.RevokedCertificateEntries))
+	}
+	for i, entry := range crl.RevokedCertificateEntries {
+		if pki.RevocationReason(entry.ReasonCode) != revoked[i].Reason {
+			t.Errorf("crl entry reason is expected to be %s but it's %s", revoked[i].Reason, pki.RevocationReason(entry.ReasonCode))
+		}
+		if !entry.RevocationTime.Equal(revoked[i].RevokedAt.UTC().Truncate(time.Second)) {
+			t.Errorf("crl entry revocation time is expected to be %v but it's %v", revoked[i].RevokedAt, entry.RevocationTime)
+		}
+	}
+
+	// CRL number should increase on every emit.
+	number := crl.Number
+	if err := svr.Emit(); err != nil {
+		t.Fatalf("can not emit: %v", err)
+	}
+	if next := parseCRL().Number; next.Cmp(number) <= 0 {
+		t.Errorf("crl number is expected to increase but it's %v after %v", next, number)
+	}
+}
+
+func TestVPNEmitWithRestart(t *testing.T) {
+	// Initialize:
+	setupTestCase()
+	CreateDB("sqlite3", ":memory:")
+	defer db.Cease()
+	svr := TheServer()
+	svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil)
+	proc := &fakeProcess{state: supervisor.RUNNING}
+	vpnProc = proc
+	defer func() { vpnProc = &fakeProcess{state: supervisor.STOPPED} }()
+
+	// Prepare:
+	if err := svr.EmitWithRestart(); err != nil {
+		t.Fatalf("can not emit: %v", err)
+	}
+	proc.restarts = 0
+
+	// Test:
+	// Changes that only touch ccd files shouldn't restart OpenVPN.
+	usr, err := CreateNewUser("usr1", "1234", false, 0, true, "description")
+	if err != nil {
+		t.Fatalf("user creation failed: %v", err)
+	}
+	if err := usr.Update("", true, 0, false, "description"); err != nil {
+		t.Fatalf("user update failed: %v", err)
+	}
+	if proc.restarts != 0 {
+		t.Errorf("OpenVPN is expected not to be restarted on ccd changes but it's restarted %d times", proc.restarts)
+	}
+
+	// Changes to the server config should restart OpenVPN.
+	if err := svr.Update("", "1.1.1.1", nil, nil); err != nil {
+		t.Fatalf("server update failed: %v", err)
+	}
+	if proc.restarts != 1 {
+		t.Errorf("OpenVPN is expected to be restarted once on server config changes but it's restarted %d times", proc.restarts)
+	}
+
+	// A stopped OpenVPN should be started regardless of the changes.
+	proc.state = supervisor.STOPPED
+	if err := svr.EmitWithRestart(); err != nil {
+		t.Fatalf("can not emit: %v", err)
+	}
+	if proc.restarts != 2 {
+		t.Errorf("stopped OpenVPN is expected to be restarted but it's not")
+	}
+}
+
+func TestVPNProcStateChanged(t *testing.T) {
+	// Initialize:
+	setupTestCase()
+	CreateDB("sqlite3", ":memory:")
+	defer db.Cease()
+	env := map[string]string{"common_name": "john", "trusted_ip": "1.2.3.4", "trusted_port": "5000"}
+	recordVPNSessionEvent(mgmt.Event{Type: mgmt.EstablishedEvent, ClientID: 1, Env: env})
+	before := GetVPNProcStatus()
+
+	// Test:
+	// Unexpected exits end the sessions and the supervisor's restarts are counted.
+	vpnProcStateChanged(supervisor.RUNNING, supervisor.EXITED)
+	vpnProcStateChanged(supervisor.EXITED, supervisor.STARTING)
+	vpnProcStateChanged(supervisor.STARTING, supervisor.RUNNING)
+
+	st := GetVPNProcStatus()
+	if st.Restarts != before.Restarts+1 {
+		t.Errorf("restarts are expected to be %d but it's %d", before.Restarts+1, st.Restarts)
+	}
+	if st.LastExit.IsZero() {
+		t.Errorf("last exit is expected to be recorded")
+	}
+	sessions, _ := GetVPNSessions(VPNSessionFilter{Username: "john"})
+	if len(sessions) != 1 || sessions[0].IsActive() || sessions[0].GetDisconnectReason() != SessionServerStopped {
+		t.Errorf("vpn session is expected to end when OpenVPN exits")
+	}
+}
+
 func TestGetConnectedUsers(t *testing.T) {
 	// Init:
 	setupTestCase()
 	CreateDB("sqlite3", ":memory:")
 	defer db.Cease()
 	svr := TheServer()
-	svr.Init("localhost", "", UDPProto, "", "", "", "", false)
+	svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil)
 
 	// Mock funcs.
 	svr.openFunc = func(path string) (io.Reader, error) {
@@ -569,7 +1223,7 @@ func TestVPN_ExpiresAt(t *testing.T) {
 	db := CreateDB("sqlite3", ":memory:")
 	defer db.Cease()
 	svr := TheServer()
-	svr.Init("localhost", "", UDPProto, "", "", "", "", false)
+	svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil)
 
 	// Test:
 	cert, err := pki.ReadCertFromPEM(svr.Cert)
@@ -587,7 +1241,7 @@ func TestVPN_CAExpiresAt(t *testing.T) {
 	db := CreateDB("sqlite3", ":memory:")
 	defer db.Cease()
 	svr := TheServer()
-	svr.Init("localhost", "", UDPProto, "", "", "", "", false)
+	svr.Init("localhost", "", UDPProto, "", "", "", "", false, nil)
 
 	// Test:
 	cert, err := pki.ReadCertFromPEM(svr.CACert)
@@ -612,5 +1266,9 @@ func init() {
 		fs[path] = content
 		return nil
 	}
+	// Small dh params are enough for the tests.
+	TheServer().newDHParamsFunc = func(bits int, progress func(tries int)) (string, error) {
+		return pki.NewDHParams(256, progress)
+	}
 	vpnProc = &fakeProcess{state: supervisor.STOPPED}
 }
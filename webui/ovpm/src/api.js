@@ -16,6 +16,10 @@ export const endpoints = {
     path: "/auth/status",
     method: "GET"
   },
+  oidcStatus: {
+    path: "/auth/oidc",
+    method: "GET"
+  },
   genConfig: {
     path: "/user/genconfig",
     method: "POST"
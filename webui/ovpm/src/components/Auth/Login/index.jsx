@@ -25,12 +25,33 @@ export default class Login extends React.Component {
       password: "",
       isAuthenticated: false,
       isAdmin: false,
+      oidcEnabled: false,
       error: null
     };
     this.api = new API(baseURL, endpoints);
   }
 
   componentWillMount() {
+    // Complete the OIDC login if the token is handed back in the fragment.
+    let hash = this.props.location ? this.props.location.hash : "";
+    let match = /^#token=(.+)$/.exec(hash);
+    if (match) {
+      this.handleAuthenticateSuccess({
+        data: { token: decodeURIComponent(match[1]) }
+      });
+      return;
+    }
+    if (/^#error=/.test(hash)) {
+      this.setState({ error: "Single sign-on failed." });
+    }
+    this.api.call(
+      "oidcStatus",
+      {},
+      false,
+      res => this.setState({ oidcEnabled: res.data.enabled }),
+      error => console.log(error)
+    );
+
     let isAdmin = false;
     if (GetItem("isAdmin")) {
       isAdmin = true;
@@ -65,7 +86,7 @@ export default class Login extends React.Component {
       SetItem("username", "root");
     } else {
       SetItem("isAdmin", res.data.user.is_admin);
-      SetItem("username", this.state.username);
+      SetItem("username", res.data.user.username);
     }
   }
 
@@ -171,6 +192,14 @@ export default class Login extends React.Component {
               <Button type="submit" color="primary" required={true}>
                 Login
               </Button>
+              {this.state.oidcEnabled && (
+                <a
+                  className="mui-btn"
+                  href={baseURL + "/auth/oidc/login?return_to=/"}
+                >
+                  Login with SSO
+                </a>
+              )}
             </form>
           </Panel>
         </Container>
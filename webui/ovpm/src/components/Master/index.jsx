@@ -10,6 +10,10 @@ import LoginRequired from "../Auth/LoginRequired";
 import Dashboard from "../Dashboard";
 
 function Home(props) {
+  // OIDC logins come back to / with the token in the fragment.
+  if (/^#(token|error)=/.test(props.location.hash)) {
+    return <Redirect to={{ pathname: "/login", hash: props.location.hash }} />;
+  }
   return <Redirect to="/dashboard" />;
 }
 